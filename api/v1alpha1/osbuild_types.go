@@ -33,16 +33,46 @@ type OSBuildSpec struct {
 
 	// TriggeredBy explains what triggered the build out
 	TriggeredBy TriggeredBy `json:"triggeredBy"`
+	// UserConfiguration is the user configuration of the OSBuildConfig that produced this OSBuild, restored when the
+	// OSBuildConfig is rolled back to it (optional)
+	UserConfiguration *UserConfiguration `json:"userConfiguration,omitempty"`
+	// VulnerabilityScan is the vulnerability scan of the OSBuildConfig, run once the build is finished (optional)
+	VulnerabilityScan *VulnerabilityScan `json:"vulnerabilityScan,omitempty"`
 }
 
 type NameRef struct {
-	// The ConfigMap to select from.
+	// Name of the referenced ConfigMap or Secret
 	Name string `json:"name"`
 }
 
-// +kubebuilder:validation:Enum=UpdateCR;Webhook
+// +kubebuilder:validation:Enum=UpdateCR;Webhook;GitHub;GitLab
 type TriggeredBy string
 
+const (
+	TriggeredByUpdateCR TriggeredBy = "UpdateCR"
+	TriggeredByWebhook  TriggeredBy = "Webhook"
+	TriggeredByGitHub   TriggeredBy = "GitHub"
+	TriggeredByGitLab   TriggeredBy = "GitLab"
+)
+
+// The annotations of the OSBuilds triggered by a push to a Git repository
+const (
+	// CommitAnnotationKey is the SHA of the pushed commit
+	CommitAnnotationKey = "osbuilder.project-flotta.io/commit"
+	// CommitAuthorAnnotationKey is the author of the pushed commit
+	CommitAuthorAnnotationKey = "osbuilder.project-flotta.io/commit-author"
+	// RefAnnotationKey is the pushed Git ref, e.g. refs/heads/main
+	RefAnnotationKey = "osbuilder.project-flotta.io/ref"
+)
+
+// TriggerIDAnnotationKey is the annotation of the OSBuilds triggered by a webhook call, holding the unique ID of the
+// call
+const TriggerIDAnnotationKey = "osbuilder.project-flotta.io/trigger-id"
+
+// BuildOverridesAnnotationKey is the annotation of the OSBuilds triggered by a webhook call overriding values of the
+// OSBuildConfig, holding the JSON BuildOverrides of the call
+const BuildOverridesAnnotationKey = "osbuilder.project-flotta.io/build-overrides"
+
 // OSBuildStatus defines the observed state of OSBuild
 type OSBuildStatus struct {
 	// The conditions present the latest available observations of a build's current state
@@ -63,6 +93,70 @@ type OSBuildStatus struct {
 	// ComposerIso is the URL for the iso that composer build returns before
 	// packaing with the kickstart
 	ComposerIso string `json:"composer_iso,omitempty"`
+
+	// OSTreeCommit is the ID (hash) of the OSTree commit built by this OSBuild
+	// +optional
+	OSTreeCommit string `json:"ostreeCommit,omitempty"`
+
+	// StaticDeltas lists the static deltas generated to the OSTree commit of this OSBuild
+	// +optional
+	StaticDeltas []StaticDelta `json:"staticDeltas,omitempty"`
+
+	// Diff summarizes the changes of this OSBuild compared to the previous Ready OSBuild of its OSBuildConfig
+	// +optional
+	Diff *DiffSummary `json:"diff,omitempty"`
+
+	// Vulnerabilities summarizes the vulnerabilities of the packages of this OSBuild found in the feed of its
+	// vulnerability scan
+	// +optional
+	Vulnerabilities *VulnerabilitySummary `json:"vulnerabilities,omitempty"`
+}
+
+type StaticDelta struct {
+	// From is the ID (hash) of the commit the delta applies to
+	From string `json:"from"`
+	// Size is the size in bytes of the delta, i.e. what a device on the From commit downloads to upgrade
+	Size int64 `json:"size"`
+}
+
+type DiffSummary struct {
+	// PreviousOSBuild is the name of the OSBuild this OSBuild was compared to, empty for the first build
+	// +optional
+	PreviousOSBuild string `json:"previousOSBuild,omitempty"`
+	// AddedPackages is the number of packages added since the previous OSBuild
+	AddedPackages int `json:"addedPackages"`
+	// RemovedPackages is the number of packages removed since the previous OSBuild
+	RemovedPackages int `json:"removedPackages"`
+	// UpgradedPackages is the number of packages upgraded since the previous OSBuild
+	UpgradedPackages int `json:"upgradedPackages"`
+	// DowngradedPackages is the number of packages downgraded since the previous OSBuild
+	DowngradedPackages int `json:"downgradedPackages"`
+	// ChangedCustomizations lists the customizations and the template parameters that changed since the previous OSBuild
+	// +optional
+	ChangedCustomizations []string `json:"changedCustomizations,omitempty"`
+	// ReportConfigMap is the name of the ConfigMap holding the full report, with the versions of the packages
+	ReportConfigMap string `json:"reportConfigMap"`
+}
+
+type VulnerabilitySummary struct {
+	// Critical is the number of vulnerabilities of Critical severity
+	Critical int `json:"critical"`
+	// High is the number of vulnerabilities of High severity
+	High int `json:"high"`
+	// Medium is the number of vulnerabilities of Medium severity
+	Medium int `json:"medium"`
+	// Low is the number of vulnerabilities of Low severity
+	Low int `json:"low"`
+	// Unknown is the number of vulnerabilities without a known severity
+	Unknown int `json:"unknown"`
+	// PolicyViolations lists the IDs of the vulnerabilities the policy applies to
+	// +optional
+	PolicyViolations []string `json:"policyViolations,omitempty"`
+	// Error is the reason why the packages could not be scanned. The policy, if any, is then considered violated
+	// +optional
+	Error string `json:"error,omitempty"`
+	// ScanTime is when the packages were scanned
+	ScanTime metav1.Time `json:"scanTime"`
 }
 
 type Condition struct {
@@ -91,8 +185,31 @@ const (
 	ConditionInProgress ConditionType = "InProgress"
 	// Whether the resource failed
 	ConditionFailed ConditionType = "Failed"
+	// Whether the packages of the build failed the validation against its repositories
+	ConditionValidationFailed ConditionType = "ValidationFailed"
+	// Whether the packages of the build have vulnerabilities failing the build according to the policy
+	ConditionVulnerabilitiesFound ConditionType = "VulnerabilitiesFound"
+	// Whether the revisions of the repositories of an OSBuildConfig changed since its last OSBuild
+	ConditionUpdatesAvailable ConditionType = "UpdatesAvailable"
 )
 
+// OSBuildFailureConditions are the conditions of the OSBuilds that failed and will not be Ready
+var OSBuildFailureConditions = []ConditionType{
+	ConditionFailed,
+	ConditionValidationFailed,
+	ConditionVulnerabilitiesFound,
+}
+
+// IsFailureCondition returns whether the condition type is one of the failure conditions of the OSBuilds
+func IsFailureCondition(conditionType ConditionType) bool {
+	for _, failureCondition := range OSBuildFailureConditions {
+		if conditionType == failureCondition {
+			return true
+		}
+	}
+	return false
+}
+
 //+kubebuilder:object:root=true
 //+kubebuilder:subresource:status
 
@@ -105,6 +222,35 @@ type OSBuild struct {
 	Status OSBuildStatus `json:"status,omitempty"`
 }
 
+// IsReady returns whether the OSBuild is Ready
+func (in *OSBuild) IsReady() bool {
+	return in.isConditionTrue(ConditionReady)
+}
+
+// IsFailed returns whether any failure condition of the OSBuild is true
+func (in *OSBuild) IsFailed() bool {
+	for _, conditionType := range OSBuildFailureConditions {
+		if in.isConditionTrue(conditionType) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsFinished returns whether the OSBuild is Ready or failed
+func (in *OSBuild) IsFinished() bool {
+	return in.IsReady() || in.IsFailed()
+}
+
+func (in *OSBuild) isConditionTrue(conditionType ConditionType) bool {
+	for _, condition := range in.Status.Conditions {
+		if condition.Type == conditionType && condition.Status == metav1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
+
 // EdgeInstallerBuildDetails includes all the information needed to build the edge-installer image
 type EdgeInstallerBuildDetails struct {
 	// Distribution is the name of the O/S distribution
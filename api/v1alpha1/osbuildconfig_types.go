@@ -18,6 +18,7 @@ package v1alpha1
 
 import (
 	buildv1 "github.com/openshift/api/build/v1"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -35,6 +36,83 @@ type OSBuildConfigSpec struct {
 	Triggers BuildTriggers `json:"triggers"`
 	// Template specifying template configuration to use
 	Template *Template `json:"template,omitempty"`
+	// TrackOSTreeHistory if True sets the OSTree parent of every new edge-container build to the commit of the last
+	// Ready edge-container OSBuild of this OSBuildConfig, so that successive builds form an upgrade chain, unless the
+	// parent is set. Requires the OSTree url to point at a repository holding the previous commits (optional)
+	TrackOSTreeHistory *bool `json:"trackOSTreeHistory,omitempty"`
+	// HistoryLimits defines how many finished OSBuilds of this OSBuildConfig are kept. Older ones are deleted along
+	// with their artifacts. When not set, all the OSBuilds are kept (optional)
+	HistoryLimits *HistoryLimits `json:"historyLimits,omitempty"`
+	// VulnerabilityScan matches the packages of every build against a vulnerability feed and applies a policy to the
+	// builds with vulnerabilities (optional)
+	VulnerabilityScan *VulnerabilityScan `json:"vulnerabilityScan,omitempty"`
+}
+
+// HistoryLimits defines how many finished OSBuilds are kept, like the history limits of a CronJob. The last OSBuild,
+// the OSTree parents of the kept OSBuilds and the OSBuilds pinned by a release are always kept
+type HistoryLimits struct {
+	// SuccessfulBuildsHistoryLimit is the number of Ready OSBuilds to keep. Default: 3
+	// +kubebuilder:default=3
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	SuccessfulBuildsHistoryLimit *int32 `json:"successfulBuildsHistoryLimit,omitempty"`
+	// FailedBuildsHistoryLimit is the number of Failed OSBuilds to keep. Default: 1
+	// +kubebuilder:default=1
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	FailedBuildsHistoryLimit *int32 `json:"failedBuildsHistoryLimit,omitempty"`
+}
+
+// VulnerabilityScan defines the feed the packages of the builds are matched against and what happens to the builds
+// with vulnerabilities
+type VulnerabilityScan struct {
+	// Feed is the vulnerability feed, a JSON list of OSV entries
+	Feed VulnerabilityFeed `json:"feed"`
+	// Policy applies to the builds with vulnerabilities of its severity or higher. When not set, the vulnerabilities
+	// are only reported (optional)
+	Policy *VulnerabilityPolicy `json:"policy,omitempty"`
+}
+
+// VulnerabilityFeed defines where the feed is stored, exactly one of its fields must be set
+type VulnerabilityFeed struct {
+	// ConfigMapKeyRef selects the key of a ConfigMap in the namespace holding the feed. Keys ending with .gz hold a
+	// gzip-compressed feed in the binary data of the ConfigMap (optional)
+	ConfigMapKeyRef *corev1.ConfigMapKeySelector `json:"configMapKeyRef,omitempty"`
+	// Path is the path of the feed relative to the vulnerability feeds directory of the operator, where a PVC holding
+	// the feeds can be mounted (optional)
+	Path *string `json:"path,omitempty"`
+}
+
+// +kubebuilder:validation:Enum=Critical;High;Medium;Low
+type VulnerabilitySeverity string
+
+const (
+	VulnerabilitySeverityCritical VulnerabilitySeverity = "Critical"
+	VulnerabilitySeverityHigh     VulnerabilitySeverity = "High"
+	VulnerabilitySeverityMedium   VulnerabilitySeverity = "Medium"
+	VulnerabilitySeverityLow      VulnerabilitySeverity = "Low"
+)
+
+// +kubebuilder:validation:Enum=BlockRelease;FailBuild
+type VulnerabilityPolicyAction string
+
+const (
+	// VulnerabilityPolicyBlockRelease keeps the OSBuild Ready but OSBuildReleases refuse to promote it
+	VulnerabilityPolicyBlockRelease VulnerabilityPolicyAction = "BlockRelease"
+	// VulnerabilityPolicyFailBuild sets the VulnerabilitiesFound condition of the OSBuild instead of Ready
+	VulnerabilityPolicyFailBuild VulnerabilityPolicyAction = "FailBuild"
+)
+
+type VulnerabilityPolicy struct {
+	// Severity is the lowest severity of the vulnerabilities the policy applies to. Default: Critical
+	// +kubebuilder:default=Critical
+	// +optional
+	Severity VulnerabilitySeverity `json:"severity,omitempty"`
+	// Action is what happens to the builds with such vulnerabilities, one of BlockRelease and FailBuild.
+	// Default: BlockRelease
+	// +kubebuilder:default=BlockRelease
+	// +optional
+	Action VulnerabilityPolicyAction `json:"action,omitempty"`
 }
 
 // Template contains OSBuildConfigTemplate configuration
@@ -69,8 +147,61 @@ type Customizations struct {
 	Packages []string `json:"packages,omitempty"`
 	// Users is the list of Users to add to the image (optional)
 	Users []User `json:"users,omitempty"`
+	// Groups is the list of Groups to add to the image (optional)
+	Groups []Group `json:"groups,omitempty"`
 	// Services defines the services to enable or disable (optional)
 	Services *Services `json:"services,omitempty"`
+	// Subscription defines the RHSM subscription and Insights registration of the image (optional)
+	Subscription *Subscription `json:"subscription,omitempty"`
+	// PayloadRepositories is the list of additional RPM repositories used only to depsolve and retrieve the packages
+	// of the image itself. Unlike TargetImage.Repositories, they are not used for the build root (optional)
+	PayloadRepositories []Repository `json:"payloadRepositories,omitempty"`
+	// Hostname is the hostname of the image (optional)
+	Hostname *string `json:"hostname,omitempty"`
+	// Kernel defines the kernel to boot and its command-line arguments (optional)
+	Kernel *Kernel `json:"kernel,omitempty"`
+	// Timezone defines the timezone and the NTP servers of the image (optional)
+	Timezone *Timezone `json:"timezone,omitempty"`
+	// Locale defines the languages and the keyboard layout of the image (optional)
+	Locale *Locale `json:"locale,omitempty"`
+	// Firewall defines the ports and services to open in the firewall of the image (optional)
+	Firewall *Firewall `json:"firewall,omitempty"`
+	// Directories is the list of directories to create in the image (optional)
+	Directories []Directory `json:"directories,omitempty"`
+	// Files is the list of files to embed in the image, with their content read from ConfigMaps or Secrets (optional)
+	Files []File `json:"files,omitempty"`
+}
+
+// Kernel defines the kernel configuration
+type Kernel struct {
+	// Name is the name of the kernel package to use (optional)
+	Name *string `json:"name,omitempty"`
+	// Append is the list of arguments to append to the kernel command line (optional)
+	Append []string `json:"append,omitempty"`
+}
+
+// Timezone defines the time configuration
+type Timezone struct {
+	// Timezone is the name of the timezone, e.g. US/Eastern. Defaults to UTC (optional)
+	Timezone *string `json:"timezone,omitempty"`
+	// NTPServers is the list of NTP servers to synchronize with (optional)
+	NTPServers []string `json:"ntpServers,omitempty"`
+}
+
+// Locale defines the localization configuration
+type Locale struct {
+	// Languages is the list of locales to install, the first one becomes the primary locale (optional)
+	Languages []string `json:"languages,omitempty"`
+	// Keyboard is the keyboard layout, e.g. us (optional)
+	Keyboard *string `json:"keyboard,omitempty"`
+}
+
+// Firewall defines the firewalld configuration
+type Firewall struct {
+	// Ports is the list of ports or port ranges and protocols to open, e.g. 22:tcp or 30000-32767:tcp (optional)
+	Ports []string `json:"ports,omitempty"`
+	// Services defines the firewalld services to enable or disable (optional)
+	Services *Services `json:"services,omitempty"`
 }
 
 // User defines a single user to be configured
@@ -81,6 +212,82 @@ type User struct {
 	Key *string `json:"key,omitempty"`
 	// Name is the username for the new user
 	Name string `json:"name"`
+	// Description is the GECOS field of the user (optional)
+	Description *string `json:"description,omitempty"`
+	// UID is the ID of the user (optional)
+	// +kubebuilder:validation:Minimum=0
+	UID *int64 `json:"uid,omitempty"`
+	// GID is the ID of the primary group of the user (optional)
+	// +kubebuilder:validation:Minimum=0
+	GID *int64 `json:"gid,omitempty"`
+	// Home is the home directory of the user (optional)
+	Home *string `json:"home,omitempty"`
+	// Shell is the login shell of the user (optional)
+	Shell *string `json:"shell,omitempty"`
+	// PasswordSecretKeyRef selects the Secret key holding the password hash of the user, e.g. the output of
+	// `openssl passwd -6` (optional)
+	PasswordSecretKeyRef *corev1.SecretKeySelector `json:"passwordSecretKeyRef,omitempty"`
+	// SSHKeysSecretKeyRefs selects the Secret keys holding SSH public keys of the user, in addition to Key (optional)
+	SSHKeysSecretKeyRefs []corev1.SecretKeySelector `json:"sshKeysSecretKeyRefs,omitempty"`
+}
+
+// Group defines a single group to be created
+type Group struct {
+	// Name is the name of the group
+	Name string `json:"name"`
+	// GID is the ID of the group (optional)
+	// +kubebuilder:validation:Minimum=0
+	GID *int64 `json:"gid,omitempty"`
+}
+
+// Subscription defines the RHSM registration of the image.
+// The organization ID and the activation key are never set inline, they are read from a Secret when the image is composed
+type Subscription struct {
+	// SecretRef is a reference to a secret holding the organization ID under the `organization` key and the
+	// activation key under the `activation_key` key
+	SecretRef NameRef `json:"secretRef"`
+	// ServerUrl is the URL of the RHSM server to register to (optional)
+	// +kubebuilder:default=subscription.rhsm.redhat.com
+	ServerUrl string `json:"serverUrl,omitempty"`
+	// BaseUrl is the URL of the content delivery network (optional)
+	// +kubebuilder:default="https://cdn.redhat.com/"
+	BaseUrl string `json:"baseUrl,omitempty"`
+	// Insights if True registers the image to Red Hat Insights (optional)
+	Insights bool `json:"insights,omitempty"`
+}
+
+// Directory defines a directory to create in the image
+type Directory struct {
+	// Path is the absolute path of the directory
+	Path string `json:"path"`
+	// Mode is the permissions of the directory in octal format, e.g. 0755 (optional)
+	// +kubebuilder:validation:Pattern=`^0?[0-7]{3}$`
+	Mode *string `json:"mode,omitempty"`
+	// User is the name of the owner of the directory (optional)
+	User *string `json:"user,omitempty"`
+	// Group is the name of the group of the directory (optional)
+	Group *string `json:"group,omitempty"`
+}
+
+// File defines a file to embed in the image.
+// The content of the file is the value of a key of a ConfigMap or a Secret, exactly one of them must be set
+type File struct {
+	// Path is the absolute path of the file
+	Path string `json:"path"`
+	// ConfigMapKeyRef selects the ConfigMap key holding the content of the file (optional)
+	ConfigMapKeyRef *corev1.ConfigMapKeySelector `json:"configMapKeyRef,omitempty"`
+	// SecretKeyRef selects the Secret key holding the content of the file (optional)
+	SecretKeyRef *corev1.SecretKeySelector `json:"secretKeyRef,omitempty"`
+	// Mode is the permissions of the file in octal format, e.g. 0644 (optional)
+	// +kubebuilder:validation:Pattern=`^0?[0-7]{3}$`
+	Mode *string `json:"mode,omitempty"`
+	// User is the name of the owner of the file (optional)
+	User *string `json:"user,omitempty"`
+	// Group is the name of the group of the file (optional)
+	Group *string `json:"group,omitempty"`
+	// EnableSystemdUnit if True the file is a systemd unit to enable in the image.
+	// The path of the file must then be under /etc/systemd/system (optional)
+	EnableSystemdUnit bool `json:"enableSystemdUnit,omitempty"`
 }
 
 type Services struct {
@@ -147,9 +354,62 @@ type BuildTriggers struct {
 	ConfigChange *bool `json:"configChange,omitempty"`
 	// WebHook defines the way to trigger a build using a REST call (optional)
 	WebHook *buildv1.WebHookTrigger `json:"webHook,omitempty"`
+	// GitHub defines the way to trigger a build from the webhook events of a GitHub repository (optional)
+	GitHub *GitWebHookTrigger `json:"github,omitempty"`
+	// GitLab defines the way to trigger a build from the webhook events of a GitLab repository (optional)
+	GitLab *GitWebHookTrigger `json:"gitlab,omitempty"`
 	// TemplateConfigChange if True trigger a new build upon any change to associated BuildConfigTemplate CR (optional).
 	// Default: True.
 	TemplateConfigChange *bool `json:"templateConfigChange,omitempty"`
+	// Schedule triggers a new build periodically, e.g. every night to pick up the errata of the repositories (optional)
+	Schedule *ScheduleTrigger `json:"schedule,omitempty"`
+	// RepositoryChange checks the revisions of the repositories periodically and reports when they change, e.g. to
+	// pick up the errata as soon as they are published (optional)
+	RepositoryChange *RepositoryChangeTrigger `json:"repositoryChange,omitempty"`
+}
+
+type GitWebHookTrigger struct {
+	// SecretReference is the Secret whose WebHookSecretKey key holds the secret of the webhook: the key of the
+	// X-Hub-Signature-256 signature of the GitHub payloads, or the X-Gitlab-Token token of the GitLab ones
+	SecretReference *buildv1.SecretLocalReference `json:"secretReference"`
+	// Branches are the names of the branches, or glob patterns, whose pushes trigger a build. All the branches trigger
+	// a build when not set (optional)
+	Branches []string `json:"branches,omitempty"`
+	// Events are the events triggering a build. Default: Push
+	// +kubebuilder:default={Push}
+	Events []GitEventType `json:"events,omitempty"`
+}
+
+// +kubebuilder:validation:Enum=Push;TagPush
+type GitEventType string
+
+const (
+	// GitEventPush is a push to a branch
+	GitEventPush GitEventType = "Push"
+	// GitEventTagPush is a push of a tag, whatever the branches the trigger is limited to
+	GitEventTagPush GitEventType = "TagPush"
+)
+
+type ScheduleTrigger struct {
+	// Cron is the schedule in the cron format, e.g. "0 2 * * *" for every night at 2am, or one of the @yearly,
+	// @monthly, @weekly, @daily and @hourly macros
+	// +kubebuilder:validation:MinLength=1
+	Cron string `json:"cron"`
+	// TimeZone is the name of the time zone the schedule is evaluated in, e.g. Europe/Paris. Default: UTC
+	// +kubebuilder:default=UTC
+	TimeZone *string `json:"timeZone,omitempty"`
+	// SkipIfUnchanged if True skips the scheduled builds when neither the OSBuildConfig, its template nor the revisions
+	// of the repositories changed since the last OSBuild (optional)
+	SkipIfUnchanged *bool `json:"skipIfUnchanged,omitempty"`
+}
+
+type RepositoryChangeTrigger struct {
+	// Interval is the period at which the revisions of the repositories are checked, at least one minute. Default: 1h
+	// +kubebuilder:default="1h"
+	Interval *metav1.Duration `json:"interval,omitempty"`
+	// AutomaticBuild if True creates a new OSBuild when the revisions of the repositories changed since the last
+	// OSBuild. Otherwise, only the UpdatesAvailable condition is set (optional)
+	AutomaticBuild *bool `json:"automaticBuild,omitempty"`
 }
 
 // OSBuildConfigStatus defines the observed state of OSBuildConfig
@@ -157,7 +417,7 @@ type OSBuildConfigStatus struct {
 	//LastKnownUserConfiguration denotes the last user configuration to be compared when a new reconcile call was triggered
 	LastKnownUserConfiguration *UserConfiguration `json:"lastKnownUserConfiguration,omitempty"`
 
-	// Last webhook trigger time stamp
+	// Last webhook trigger ID, a time stamp for the triggers of the previous versions of the trigger API
 	LastWebhookTriggerTS string `json:"lastWebhookTriggerTS,omitempty"`
 
 	// LastVersion denotes the number of the last OSBuild CR created for this OSBuildConfig CR
@@ -173,6 +433,110 @@ type OSBuildConfigStatus struct {
 	// CurrentTemplateResourceVersion denotes the most current version of the OSBuildConfigTemplate resource used by this
 	// OSBuildConfig (value of OSBuildConfigTemplate's metadata.resourceVersion).
 	CurrentTemplateResourceVersion *string `json:"CurrentTemplateResourceVersion,omitempty"`
+
+	// LastRollback denotes the result of the last rollback requested with the RollbackAnnotationKey annotation
+	LastRollback *Rollback `json:"lastRollback,omitempty"`
+
+	// Schedule denotes the last and next runs of the schedule trigger
+	Schedule *ScheduleStatus `json:"schedule,omitempty"`
+
+	// RepositoryRevisions denotes the revisions of the repositories when the last OSBuild was created. It is recorded
+	// only when the scheduled builds are skipped if the repositories did not change, or when the repository change
+	// trigger is set
+	RepositoryRevisions []RepositoryRevision `json:"repositoryRevisions,omitempty"`
+
+	// RepositoryCheck denotes the last check of the revisions of the repositories by the repository change trigger
+	RepositoryCheck *RepositoryCheck `json:"repositoryCheck,omitempty"`
+
+	// The conditions present the latest available observations of the OSBuildConfig
+	Conditions []Condition `json:"conditions,omitempty"`
+}
+
+type RepositoryCheck struct {
+	// LastCheckTime is the time the revisions of the repositories were last checked
+	// +optional
+	LastCheckTime *metav1.Time `json:"lastCheckTime,omitempty"`
+	// Revisions are the revisions of the repositories read by the last successful check
+	// +optional
+	Revisions []RepositoryRevision `json:"revisions,omitempty"`
+	// Message explains why the revisions could not be read by the last check
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+type ScheduleStatus struct {
+	// LastScheduleTime is the time of the last scheduled run, recorded once its OSBuild is created or when it is skipped
+	// +optional
+	LastScheduleTime *metav1.Time `json:"lastScheduleTime,omitempty"`
+	// NextScheduleTime is the time of the next scheduled run
+	// +optional
+	NextScheduleTime *metav1.Time `json:"nextScheduleTime,omitempty"`
+	// LastRunSkipped if True the last scheduled run did not create an OSBuild as nothing changed since the last one
+	// +optional
+	LastRunSkipped bool `json:"lastRunSkipped,omitempty"`
+	// LastBuildGeneration is the generation of the OSBuildConfig when the last OSBuild was created. It is recorded
+	// only when the scheduled builds are skipped if nothing changed
+	// +optional
+	LastBuildGeneration *int64 `json:"lastBuildGeneration,omitempty"`
+	// Message explains why the schedule cannot be evaluated
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+type RepositoryRevision struct {
+	// Baseurl is the base URL of the repository
+	Baseurl string `json:"baseurl"`
+	// Revision is the revision of the repository metadata, or the checksum of its primary metadata when the
+	// repository does not set any
+	Revision string `json:"revision"`
+}
+
+// WebHookTriggerAnnotationKey is the annotation the trigger API sets to the unique ID of the last webhook call,
+// requesting a new build. The previous versions of the trigger API set it to the time of the call
+const WebHookTriggerAnnotationKey = "last_webhook_trigger_ts"
+
+// WebHookTriggerDetailsAnnotationKey is the annotation the trigger API sets along with the WebHookTriggerAnnotationKey
+// one, holding the JSON WebHookTriggerDetails of the webhook call
+const WebHookTriggerDetailsAnnotationKey = "osbuilder.project-flotta.io/webhook-trigger"
+
+// WebHookTriggerDetails describes the webhook call that triggered a build, recorded on the OSBuild
+type WebHookTriggerDetails struct {
+	// ID is the unique ID of the webhook call, set from the WebHookTriggerAnnotationKey annotation
+	ID string `json:"id,omitempty"`
+	// TriggeredBy is the kind of webhook that was called
+	TriggeredBy TriggeredBy `json:"triggeredBy"`
+	// Commit is the SHA of the pushed commit
+	Commit string `json:"commit,omitempty"`
+	// Author is the author of the pushed commit
+	Author string `json:"author,omitempty"`
+	// Ref is the pushed Git ref
+	Ref string `json:"ref,omitempty"`
+	// Overrides are the values of the webhook call that apply to the triggered build only
+	Overrides *BuildOverrides `json:"overrides,omitempty"`
+}
+
+// BuildOverrides are values set by a webhook call for the build it triggers, without changing the OSBuildConfig
+type BuildOverrides struct {
+	// Parameters override the values of the parameters of the template, or set the ones the OSBuildConfig does not
+	Parameters []ParameterValue `json:"parameters,omitempty"`
+	// Packages are installed in addition to the packages of the customizations
+	Packages []string `json:"packages,omitempty"`
+}
+
+// RollbackAnnotationKey is the annotation requesting to roll the OSBuildConfig back to the user configuration of the
+// OSBuild of the given version, e.g. "3" for the OSBuild <OSBuildConfig name>-3. A new OSBuild is then built from it
+const RollbackAnnotationKey = "osbuilder.project-flotta.io/rollback-to-version"
+
+type Rollback struct {
+	// Version is the version of the OSBuild the OSBuildConfig was rolled back to
+	Version string `json:"version"`
+	// Succeeded if True the user configuration of the OSBuild was restored
+	Succeeded bool `json:"succeeded"`
+	// Message explains why the rollback failed
+	// +optional
+	Message string `json:"message,omitempty"`
+	// Time is the time the rollback was processed
+	Time metav1.Time `json:"time"`
 }
 
 type UserConfiguration struct {
@@ -0,0 +1,99 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// OSBuildReleaseSpec defines the desired state of OSBuildRelease
+type OSBuildReleaseSpec struct {
+	// OSBuildRef is the name of the released OSBuild, e.g. the stable image of a fleet. The OSBuild must be Ready
+	OSBuildRef string `json:"osBuildRef"`
+	// ContainerImageTag is the tag given to the image of a released edge-container OSBuild (optional)
+	ContainerImageTag *string `json:"containerImageTag,omitempty"`
+	// ImagePullSecretRef is a reference to a docker registry secret used to pull and push the image of a released
+	// edge-container OSBuild (optional)
+	ImagePullSecretRef *NameRef `json:"imagePullSecretRef,omitempty"`
+	// OSTreeRef defines the ref moved to the commit of a released edge-container OSBuild (optional)
+	OSTreeRef *ReleaseOSTreeRef `json:"ostreeRef,omitempty"`
+	// InstallerKey is the S3 key the image of a released edge-installer or guest-image OSBuild is copied to (optional)
+	InstallerKey *string `json:"installerKey,omitempty"`
+}
+
+type ReleaseOSTreeRef struct {
+	// OSTreeRepositoryRef is the name of the OSTreeRepository holding the commit of the OSBuild
+	OSTreeRepositoryRef string `json:"ostreeRepositoryRef"`
+	// Ref is the ref moved to the commit, e.g. rhel/8/x86_64/edge/stable
+	Ref string `json:"ref"`
+}
+
+// OSBuildReleaseStatus defines the observed state of OSBuildRelease
+type OSBuildReleaseStatus struct {
+	// The conditions present the latest available observations of the release
+	Conditions []Condition `json:"conditions,omitempty"`
+
+	// Current is the currently released OSBuild
+	// +optional
+	Current *OSBuildReleaseRecord `json:"current,omitempty"`
+
+	// History lists the past releases, from the oldest to the newest, including the current one
+	// +optional
+	History []OSBuildReleaseRecord `json:"history,omitempty"`
+}
+
+type OSBuildReleaseRecord struct {
+	// OSBuildName is the name of the released OSBuild
+	OSBuildName string `json:"osBuildName"`
+	// ContainerImage is the image of the OSBuild under the release tag
+	// +optional
+	ContainerImage string `json:"containerImage,omitempty"`
+	// OSTreeCommit is the commit the release ref points at
+	// +optional
+	OSTreeCommit string `json:"ostreeCommit,omitempty"`
+	// InstallerUrl is the S3 URL of the image of the OSBuild under the release key
+	// +optional
+	InstallerUrl string `json:"installerUrl,omitempty"`
+	// ReleaseTime is the time the OSBuild was released
+	ReleaseTime metav1.Time `json:"releaseTime"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="OSBuild",type=string,JSONPath=`.status.current.osBuildName`
+
+// OSBuildRelease is the Schema for the osbuildreleases API
+type OSBuildRelease struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   OSBuildReleaseSpec   `json:"spec,omitempty"`
+	Status OSBuildReleaseStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// OSBuildReleaseList contains a list of OSBuildRelease
+type OSBuildReleaseList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []OSBuildRelease `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&OSBuildRelease{}, &OSBuildReleaseList{})
+}
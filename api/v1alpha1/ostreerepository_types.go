@@ -0,0 +1,117 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// OSTreeRepositorySpec defines the desired state of OSTreeRepository
+type OSTreeRepositorySpec struct {
+	// OSBuildConfigRef is the name of the OSBuildConfig whose Ready edge-container OSBuilds are imported into the
+	// repository
+	OSBuildConfigRef string `json:"osBuildConfigRef"`
+	// Ref is the OSTree ref moved to every imported commit, e.g. rhel/8/x86_64/edge
+	Ref string `json:"ref"`
+	// Storage defines the persistent volume holding the repository (optional)
+	Storage *OSTreeRepositoryStorage `json:"storage,omitempty"`
+	// ImagePullSecretRef is a reference to a docker registry secret used to pull the edge-container images (optional)
+	ImagePullSecretRef *NameRef `json:"imagePullSecretRef,omitempty"`
+	// StaticDeltas enables the generation of static deltas to every imported commit (optional)
+	StaticDeltas *OSTreeStaticDeltas `json:"staticDeltas,omitempty"`
+	// GPGKeySecretRef is a reference to a secret holding the armored GPG private key signing the imported commits and
+	// the summary under the `private.key` key, and its public key under the `public.key` key (optional)
+	GPGKeySecretRef *NameRef `json:"gpgKeySecretRef,omitempty"`
+}
+
+type OSTreeStaticDeltas struct {
+	// FromCommits is the number of previously imported commits from which a static delta is generated. Default: 3
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	FromCommits *int `json:"fromCommits,omitempty"`
+}
+
+type OSTreeRepositoryStorage struct {
+	// Size of the persistent volume. Default: 10Gi
+	Size *resource.Quantity `json:"size,omitempty"`
+	// StorageClassName is the storage class of the persistent volume (optional)
+	StorageClassName *string `json:"storageClassName,omitempty"`
+}
+
+// OSTreeRepositoryStatus defines the observed state of OSTreeRepository
+type OSTreeRepositoryStatus struct {
+	// The conditions present the latest available observations of the repository's imports
+	Conditions []Condition `json:"conditions,omitempty"`
+
+	// Url is the URL of the repository, to be used by the devices and by the OSBuilds that need the commits
+	// +optional
+	Url string `json:"url,omitempty"`
+
+	// GPGKeyUrl is the URL of the public GPG key verifying the commits and the summary, when they are signed
+	// +optional
+	GPGKeyUrl string `json:"gpgKeyUrl,omitempty"`
+
+	// Imports lists the last imports of OSBuilds into the repository, from the oldest to the newest
+	// +optional
+	Imports []OSTreeRepositoryImport `json:"imports,omitempty"`
+}
+
+type OSTreeRepositoryImport struct {
+	// OSBuildName is the name of the imported OSBuild
+	OSBuildName string `json:"osBuildName"`
+	// Commit is the ID (hash) of the imported commit, if known
+	// +optional
+	Commit string `json:"commit,omitempty"`
+	// Succeeded tells whether the commit was imported and the ref was moved to it
+	Succeeded bool `json:"succeeded"`
+	// ImportTime is the time the import finished
+	ImportTime metav1.Time `json:"importTime"`
+	// Attempts is the number of failed attempts to import the OSBuild, when the import failed. The import is retried
+	// with backoff
+	// +optional
+	Attempts int32 `json:"attempts,omitempty"`
+	// StaticDeltasGenerated tells whether the static deltas to the commit were generated, once their generation
+	// finished
+	// +optional
+	StaticDeltasGenerated *bool `json:"staticDeltasGenerated,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+
+// OSTreeRepository is the Schema for the ostreerepositories API
+type OSTreeRepository struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   OSTreeRepositorySpec   `json:"spec,omitempty"`
+	Status OSTreeRepositoryStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// OSTreeRepositoryList contains a list of OSTreeRepository
+type OSTreeRepositoryList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []OSTreeRepository `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&OSTreeRepository{}, &OSTreeRepositoryList{})
+}
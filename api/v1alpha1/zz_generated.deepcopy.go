@@ -22,7 +22,9 @@ limitations under the License.
 package v1alpha1
 
 import (
-	"github.com/openshift/api/build/v1"
+	buildv1 "github.com/openshift/api/build/v1"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -63,6 +65,31 @@ func (in *BuildDetails) DeepCopy() *BuildDetails {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BuildOverrides) DeepCopyInto(out *BuildOverrides) {
+	*out = *in
+	if in.Parameters != nil {
+		in, out := &in.Parameters, &out.Parameters
+		*out = make([]ParameterValue, len(*in))
+		copy(*out, *in)
+	}
+	if in.Packages != nil {
+		in, out := &in.Packages, &out.Packages
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BuildOverrides.
+func (in *BuildOverrides) DeepCopy() *BuildOverrides {
+	if in == nil {
+		return nil
+	}
+	out := new(BuildOverrides)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *BuildTriggers) DeepCopyInto(out *BuildTriggers) {
 	*out = *in
@@ -73,7 +100,17 @@ func (in *BuildTriggers) DeepCopyInto(out *BuildTriggers) {
 	}
 	if in.WebHook != nil {
 		in, out := &in.WebHook, &out.WebHook
-		*out = new(v1.WebHookTrigger)
+		*out = new(buildv1.WebHookTrigger)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.GitHub != nil {
+		in, out := &in.GitHub, &out.GitHub
+		*out = new(GitWebHookTrigger)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.GitLab != nil {
+		in, out := &in.GitLab, &out.GitLab
+		*out = new(GitWebHookTrigger)
 		(*in).DeepCopyInto(*out)
 	}
 	if in.TemplateConfigChange != nil {
@@ -81,6 +118,16 @@ func (in *BuildTriggers) DeepCopyInto(out *BuildTriggers) {
 		*out = new(bool)
 		**out = **in
 	}
+	if in.Schedule != nil {
+		in, out := &in.Schedule, &out.Schedule
+		*out = new(ScheduleTrigger)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RepositoryChange != nil {
+		in, out := &in.RepositoryChange, &out.RepositoryChange
+		*out = new(RepositoryChangeTrigger)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BuildTriggers.
@@ -164,7 +211,7 @@ func (in *ContainerRegistryServiceConfig) DeepCopyInto(out *ContainerRegistrySer
 	out.CredsSecretReference = in.CredsSecretReference
 	if in.CABundleSecretReference != nil {
 		in, out := &in.CABundleSecretReference, &out.CABundleSecretReference
-		*out = new(v1.SecretLocalReference)
+		*out = new(buildv1.SecretLocalReference)
 		**out = **in
 	}
 	if in.SkipSSLVerification != nil {
@@ -199,11 +246,69 @@ func (in *Customizations) DeepCopyInto(out *Customizations) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Groups != nil {
+		in, out := &in.Groups, &out.Groups
+		*out = make([]Group, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.Services != nil {
 		in, out := &in.Services, &out.Services
 		*out = new(Services)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Subscription != nil {
+		in, out := &in.Subscription, &out.Subscription
+		*out = new(Subscription)
+		**out = **in
+	}
+	if in.PayloadRepositories != nil {
+		in, out := &in.PayloadRepositories, &out.PayloadRepositories
+		*out = make([]Repository, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Hostname != nil {
+		in, out := &in.Hostname, &out.Hostname
+		*out = new(string)
+		**out = **in
+	}
+	if in.Kernel != nil {
+		in, out := &in.Kernel, &out.Kernel
+		*out = new(Kernel)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Timezone != nil {
+		in, out := &in.Timezone, &out.Timezone
+		*out = new(Timezone)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Locale != nil {
+		in, out := &in.Locale, &out.Locale
+		*out = new(Locale)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Firewall != nil {
+		in, out := &in.Firewall, &out.Firewall
+		*out = new(Firewall)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Directories != nil {
+		in, out := &in.Directories, &out.Directories
+		*out = make([]Directory, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Files != nil {
+		in, out := &in.Files, &out.Files
+		*out = make([]File, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Customizations.
@@ -216,6 +321,56 @@ func (in *Customizations) DeepCopy() *Customizations {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DiffSummary) DeepCopyInto(out *DiffSummary) {
+	*out = *in
+	if in.ChangedCustomizations != nil {
+		in, out := &in.ChangedCustomizations, &out.ChangedCustomizations
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DiffSummary.
+func (in *DiffSummary) DeepCopy() *DiffSummary {
+	if in == nil {
+		return nil
+	}
+	out := new(DiffSummary)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Directory) DeepCopyInto(out *Directory) {
+	*out = *in
+	if in.Mode != nil {
+		in, out := &in.Mode, &out.Mode
+		*out = new(string)
+		**out = **in
+	}
+	if in.User != nil {
+		in, out := &in.User, &out.User
+		*out = new(string)
+		**out = **in
+	}
+	if in.Group != nil {
+		in, out := &in.Group, &out.Group
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Directory.
+func (in *Directory) DeepCopy() *Directory {
+	if in == nil {
+		return nil
+	}
+	out := new(Directory)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *EdgeInstallerBuildDetails) DeepCopyInto(out *EdgeInstallerBuildDetails) {
 	*out = *in
@@ -253,6 +408,71 @@ func (in *ExternalWorkerConfig) DeepCopy() *ExternalWorkerConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *File) DeepCopyInto(out *File) {
+	*out = *in
+	if in.ConfigMapKeyRef != nil {
+		in, out := &in.ConfigMapKeyRef, &out.ConfigMapKeyRef
+		*out = new(v1.ConfigMapKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SecretKeyRef != nil {
+		in, out := &in.SecretKeyRef, &out.SecretKeyRef
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Mode != nil {
+		in, out := &in.Mode, &out.Mode
+		*out = new(string)
+		**out = **in
+	}
+	if in.User != nil {
+		in, out := &in.User, &out.User
+		*out = new(string)
+		**out = **in
+	}
+	if in.Group != nil {
+		in, out := &in.Group, &out.Group
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new File.
+func (in *File) DeepCopy() *File {
+	if in == nil {
+		return nil
+	}
+	out := new(File)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Firewall) DeepCopyInto(out *Firewall) {
+	*out = *in
+	if in.Ports != nil {
+		in, out := &in.Ports, &out.Ports
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Services != nil {
+		in, out := &in.Services, &out.Services
+		*out = new(Services)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Firewall.
+func (in *Firewall) DeepCopy() *Firewall {
+	if in == nil {
+		return nil
+	}
+	out := new(Firewall)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *GenericS3ServiceConfig) DeepCopyInto(out *GenericS3ServiceConfig) {
 	*out = *in
@@ -263,7 +483,7 @@ func (in *GenericS3ServiceConfig) DeepCopyInto(out *GenericS3ServiceConfig) {
 	}
 	if in.CABundleSecretReference != nil {
 		in, out := &in.CABundleSecretReference, &out.CABundleSecretReference
-		*out = new(v1.SecretLocalReference)
+		*out = new(buildv1.SecretLocalReference)
 		**out = **in
 	}
 	if in.SkipSSLVerification != nil {
@@ -283,6 +503,81 @@ func (in *GenericS3ServiceConfig) DeepCopy() *GenericS3ServiceConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GitWebHookTrigger) DeepCopyInto(out *GitWebHookTrigger) {
+	*out = *in
+	if in.SecretReference != nil {
+		in, out := &in.SecretReference, &out.SecretReference
+		*out = new(buildv1.SecretLocalReference)
+		**out = **in
+	}
+	if in.Branches != nil {
+		in, out := &in.Branches, &out.Branches
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Events != nil {
+		in, out := &in.Events, &out.Events
+		*out = make([]GitEventType, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitWebHookTrigger.
+func (in *GitWebHookTrigger) DeepCopy() *GitWebHookTrigger {
+	if in == nil {
+		return nil
+	}
+	out := new(GitWebHookTrigger)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Group) DeepCopyInto(out *Group) {
+	*out = *in
+	if in.GID != nil {
+		in, out := &in.GID, &out.GID
+		*out = new(int64)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Group.
+func (in *Group) DeepCopy() *Group {
+	if in == nil {
+		return nil
+	}
+	out := new(Group)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HistoryLimits) DeepCopyInto(out *HistoryLimits) {
+	*out = *in
+	if in.SuccessfulBuildsHistoryLimit != nil {
+		in, out := &in.SuccessfulBuildsHistoryLimit, &out.SuccessfulBuildsHistoryLimit
+		*out = new(int32)
+		**out = **in
+	}
+	if in.FailedBuildsHistoryLimit != nil {
+		in, out := &in.FailedBuildsHistoryLimit, &out.FailedBuildsHistoryLimit
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HistoryLimits.
+func (in *HistoryLimits) DeepCopy() *HistoryLimits {
+	if in == nil {
+		return nil
+	}
+	out := new(HistoryLimits)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *IsoConfiguration) DeepCopyInto(out *IsoConfiguration) {
 	*out = *in
@@ -303,6 +598,31 @@ func (in *IsoConfiguration) DeepCopy() *IsoConfiguration {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Kernel) DeepCopyInto(out *Kernel) {
+	*out = *in
+	if in.Name != nil {
+		in, out := &in.Name, &out.Name
+		*out = new(string)
+		**out = **in
+	}
+	if in.Append != nil {
+		in, out := &in.Append, &out.Append
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Kernel.
+func (in *Kernel) DeepCopy() *Kernel {
+	if in == nil {
+		return nil
+	}
+	out := new(Kernel)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *KickstartFile) DeepCopyInto(out *KickstartFile) {
 	*out = *in
@@ -328,6 +648,31 @@ func (in *KickstartFile) DeepCopy() *KickstartFile {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Locale) DeepCopyInto(out *Locale) {
+	*out = *in
+	if in.Languages != nil {
+		in, out := &in.Languages, &out.Languages
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Keyboard != nil {
+		in, out := &in.Keyboard, &out.Keyboard
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Locale.
+func (in *Locale) DeepCopy() *Locale {
+	if in == nil {
+		return nil
+	}
+	out := new(Locale)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NameRef) DeepCopyInto(out *NameRef) {
 	*out = *in
@@ -439,6 +784,21 @@ func (in *OSBuildConfigSpec) DeepCopyInto(out *OSBuildConfigSpec) {
 		*out = new(Template)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.TrackOSTreeHistory != nil {
+		in, out := &in.TrackOSTreeHistory, &out.TrackOSTreeHistory
+		*out = new(bool)
+		**out = **in
+	}
+	if in.HistoryLimits != nil {
+		in, out := &in.HistoryLimits, &out.HistoryLimits
+		*out = new(HistoryLimits)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.VulnerabilityScan != nil {
+		in, out := &in.VulnerabilityScan, &out.VulnerabilityScan
+		*out = new(VulnerabilityScan)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OSBuildConfigSpec.
@@ -479,6 +839,33 @@ func (in *OSBuildConfigStatus) DeepCopyInto(out *OSBuildConfigStatus) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.LastRollback != nil {
+		in, out := &in.LastRollback, &out.LastRollback
+		*out = new(Rollback)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Schedule != nil {
+		in, out := &in.Schedule, &out.Schedule
+		*out = new(ScheduleStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RepositoryRevisions != nil {
+		in, out := &in.RepositoryRevisions, &out.RepositoryRevisions
+		*out = make([]RepositoryRevision, len(*in))
+		copy(*out, *in)
+	}
+	if in.RepositoryCheck != nil {
+		in, out := &in.RepositoryCheck, &out.RepositoryCheck
+		*out = new(RepositoryCheck)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OSBuildConfigStatus.
@@ -732,22 +1119,176 @@ func (in *OSBuildList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *OSBuildSpec) DeepCopyInto(out *OSBuildSpec) {
+func (in *OSBuildRelease) DeepCopyInto(out *OSBuildRelease) {
 	*out = *in
-	if in.Details != nil {
-		in, out := &in.Details, &out.Details
-		*out = new(BuildDetails)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.EdgeInstallerDetails != nil {
-		in, out := &in.EdgeInstallerDetails, &out.EdgeInstallerDetails
-		*out = new(EdgeInstallerBuildDetails)
-		(*in).DeepCopyInto(*out)
-	}
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OSBuildSpec.
-func (in *OSBuildSpec) DeepCopy() *OSBuildSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OSBuildRelease.
+func (in *OSBuildRelease) DeepCopy() *OSBuildRelease {
+	if in == nil {
+		return nil
+	}
+	out := new(OSBuildRelease)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *OSBuildRelease) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OSBuildReleaseList) DeepCopyInto(out *OSBuildReleaseList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]OSBuildRelease, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OSBuildReleaseList.
+func (in *OSBuildReleaseList) DeepCopy() *OSBuildReleaseList {
+	if in == nil {
+		return nil
+	}
+	out := new(OSBuildReleaseList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *OSBuildReleaseList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OSBuildReleaseRecord) DeepCopyInto(out *OSBuildReleaseRecord) {
+	*out = *in
+	in.ReleaseTime.DeepCopyInto(&out.ReleaseTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OSBuildReleaseRecord.
+func (in *OSBuildReleaseRecord) DeepCopy() *OSBuildReleaseRecord {
+	if in == nil {
+		return nil
+	}
+	out := new(OSBuildReleaseRecord)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OSBuildReleaseSpec) DeepCopyInto(out *OSBuildReleaseSpec) {
+	*out = *in
+	if in.ContainerImageTag != nil {
+		in, out := &in.ContainerImageTag, &out.ContainerImageTag
+		*out = new(string)
+		**out = **in
+	}
+	if in.ImagePullSecretRef != nil {
+		in, out := &in.ImagePullSecretRef, &out.ImagePullSecretRef
+		*out = new(NameRef)
+		**out = **in
+	}
+	if in.OSTreeRef != nil {
+		in, out := &in.OSTreeRef, &out.OSTreeRef
+		*out = new(ReleaseOSTreeRef)
+		**out = **in
+	}
+	if in.InstallerKey != nil {
+		in, out := &in.InstallerKey, &out.InstallerKey
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OSBuildReleaseSpec.
+func (in *OSBuildReleaseSpec) DeepCopy() *OSBuildReleaseSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(OSBuildReleaseSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OSBuildReleaseStatus) DeepCopyInto(out *OSBuildReleaseStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Current != nil {
+		in, out := &in.Current, &out.Current
+		*out = new(OSBuildReleaseRecord)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.History != nil {
+		in, out := &in.History, &out.History
+		*out = make([]OSBuildReleaseRecord, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OSBuildReleaseStatus.
+func (in *OSBuildReleaseStatus) DeepCopy() *OSBuildReleaseStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(OSBuildReleaseStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OSBuildSpec) DeepCopyInto(out *OSBuildSpec) {
+	*out = *in
+	if in.Details != nil {
+		in, out := &in.Details, &out.Details
+		*out = new(BuildDetails)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.EdgeInstallerDetails != nil {
+		in, out := &in.EdgeInstallerDetails, &out.EdgeInstallerDetails
+		*out = new(EdgeInstallerBuildDetails)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.UserConfiguration != nil {
+		in, out := &in.UserConfiguration, &out.UserConfiguration
+		*out = new(UserConfiguration)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.VulnerabilityScan != nil {
+		in, out := &in.VulnerabilityScan, &out.VulnerabilityScan
+		*out = new(VulnerabilityScan)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OSBuildSpec.
+func (in *OSBuildSpec) DeepCopy() *OSBuildSpec {
 	if in == nil {
 		return nil
 	}
@@ -771,6 +1312,21 @@ func (in *OSBuildStatus) DeepCopyInto(out *OSBuildStatus) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.StaticDeltas != nil {
+		in, out := &in.StaticDeltas, &out.StaticDeltas
+		*out = make([]StaticDelta, len(*in))
+		copy(*out, *in)
+	}
+	if in.Diff != nil {
+		in, out := &in.Diff, &out.Diff
+		*out = new(DiffSummary)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Vulnerabilities != nil {
+		in, out := &in.Vulnerabilities, &out.Vulnerabilities
+		*out = new(VulnerabilitySummary)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OSBuildStatus.
@@ -813,6 +1369,195 @@ func (in *OSTreeConfig) DeepCopy() *OSTreeConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OSTreeRepository) DeepCopyInto(out *OSTreeRepository) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OSTreeRepository.
+func (in *OSTreeRepository) DeepCopy() *OSTreeRepository {
+	if in == nil {
+		return nil
+	}
+	out := new(OSTreeRepository)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *OSTreeRepository) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OSTreeRepositoryImport) DeepCopyInto(out *OSTreeRepositoryImport) {
+	*out = *in
+	in.ImportTime.DeepCopyInto(&out.ImportTime)
+	if in.StaticDeltasGenerated != nil {
+		in, out := &in.StaticDeltasGenerated, &out.StaticDeltasGenerated
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OSTreeRepositoryImport.
+func (in *OSTreeRepositoryImport) DeepCopy() *OSTreeRepositoryImport {
+	if in == nil {
+		return nil
+	}
+	out := new(OSTreeRepositoryImport)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OSTreeRepositoryList) DeepCopyInto(out *OSTreeRepositoryList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]OSTreeRepository, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OSTreeRepositoryList.
+func (in *OSTreeRepositoryList) DeepCopy() *OSTreeRepositoryList {
+	if in == nil {
+		return nil
+	}
+	out := new(OSTreeRepositoryList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *OSTreeRepositoryList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OSTreeRepositorySpec) DeepCopyInto(out *OSTreeRepositorySpec) {
+	*out = *in
+	if in.Storage != nil {
+		in, out := &in.Storage, &out.Storage
+		*out = new(OSTreeRepositoryStorage)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ImagePullSecretRef != nil {
+		in, out := &in.ImagePullSecretRef, &out.ImagePullSecretRef
+		*out = new(NameRef)
+		**out = **in
+	}
+	if in.StaticDeltas != nil {
+		in, out := &in.StaticDeltas, &out.StaticDeltas
+		*out = new(OSTreeStaticDeltas)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.GPGKeySecretRef != nil {
+		in, out := &in.GPGKeySecretRef, &out.GPGKeySecretRef
+		*out = new(NameRef)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OSTreeRepositorySpec.
+func (in *OSTreeRepositorySpec) DeepCopy() *OSTreeRepositorySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(OSTreeRepositorySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OSTreeRepositoryStatus) DeepCopyInto(out *OSTreeRepositoryStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Imports != nil {
+		in, out := &in.Imports, &out.Imports
+		*out = make([]OSTreeRepositoryImport, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OSTreeRepositoryStatus.
+func (in *OSTreeRepositoryStatus) DeepCopy() *OSTreeRepositoryStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(OSTreeRepositoryStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OSTreeRepositoryStorage) DeepCopyInto(out *OSTreeRepositoryStorage) {
+	*out = *in
+	if in.Size != nil {
+		in, out := &in.Size, &out.Size
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.StorageClassName != nil {
+		in, out := &in.StorageClassName, &out.StorageClassName
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OSTreeRepositoryStorage.
+func (in *OSTreeRepositoryStorage) DeepCopy() *OSTreeRepositoryStorage {
+	if in == nil {
+		return nil
+	}
+	out := new(OSTreeRepositoryStorage)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OSTreeStaticDeltas) DeepCopyInto(out *OSTreeStaticDeltas) {
+	*out = *in
+	if in.FromCommits != nil {
+		in, out := &in.FromCommits, &out.FromCommits
+		*out = new(int)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OSTreeStaticDeltas.
+func (in *OSTreeStaticDeltas) DeepCopy() *OSTreeStaticDeltas {
+	if in == nil {
+		return nil
+	}
+	out := new(OSTreeStaticDeltas)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Parameter) DeepCopyInto(out *Parameter) {
 	*out = *in
@@ -843,6 +1588,21 @@ func (in *ParameterValue) DeepCopy() *ParameterValue {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReleaseOSTreeRef) DeepCopyInto(out *ReleaseOSTreeRef) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReleaseOSTreeRef.
+func (in *ReleaseOSTreeRef) DeepCopy() *ReleaseOSTreeRef {
+	if in == nil {
+		return nil
+	}
+	out := new(ReleaseOSTreeRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Repository) DeepCopyInto(out *Repository) {
 	*out = *in
@@ -902,6 +1662,86 @@ func (in *Repository) DeepCopy() *Repository {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RepositoryChangeTrigger) DeepCopyInto(out *RepositoryChangeTrigger) {
+	*out = *in
+	if in.Interval != nil {
+		in, out := &in.Interval, &out.Interval
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.AutomaticBuild != nil {
+		in, out := &in.AutomaticBuild, &out.AutomaticBuild
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RepositoryChangeTrigger.
+func (in *RepositoryChangeTrigger) DeepCopy() *RepositoryChangeTrigger {
+	if in == nil {
+		return nil
+	}
+	out := new(RepositoryChangeTrigger)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RepositoryCheck) DeepCopyInto(out *RepositoryCheck) {
+	*out = *in
+	if in.LastCheckTime != nil {
+		in, out := &in.LastCheckTime, &out.LastCheckTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Revisions != nil {
+		in, out := &in.Revisions, &out.Revisions
+		*out = make([]RepositoryRevision, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RepositoryCheck.
+func (in *RepositoryCheck) DeepCopy() *RepositoryCheck {
+	if in == nil {
+		return nil
+	}
+	out := new(RepositoryCheck)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RepositoryRevision) DeepCopyInto(out *RepositoryRevision) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RepositoryRevision.
+func (in *RepositoryRevision) DeepCopy() *RepositoryRevision {
+	if in == nil {
+		return nil
+	}
+	out := new(RepositoryRevision)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Rollback) DeepCopyInto(out *Rollback) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Rollback.
+func (in *Rollback) DeepCopy() *Rollback {
+	if in == nil {
+		return nil
+	}
+	out := new(Rollback)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *S3ServiceConfig) DeepCopyInto(out *S3ServiceConfig) {
 	*out = *in
@@ -927,6 +1767,59 @@ func (in *S3ServiceConfig) DeepCopy() *S3ServiceConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScheduleStatus) DeepCopyInto(out *ScheduleStatus) {
+	*out = *in
+	if in.LastScheduleTime != nil {
+		in, out := &in.LastScheduleTime, &out.LastScheduleTime
+		*out = (*in).DeepCopy()
+	}
+	if in.NextScheduleTime != nil {
+		in, out := &in.NextScheduleTime, &out.NextScheduleTime
+		*out = (*in).DeepCopy()
+	}
+	if in.LastBuildGeneration != nil {
+		in, out := &in.LastBuildGeneration, &out.LastBuildGeneration
+		*out = new(int64)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScheduleStatus.
+func (in *ScheduleStatus) DeepCopy() *ScheduleStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ScheduleStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScheduleTrigger) DeepCopyInto(out *ScheduleTrigger) {
+	*out = *in
+	if in.TimeZone != nil {
+		in, out := &in.TimeZone, &out.TimeZone
+		*out = new(string)
+		**out = **in
+	}
+	if in.SkipIfUnchanged != nil {
+		in, out := &in.SkipIfUnchanged, &out.SkipIfUnchanged
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScheduleTrigger.
+func (in *ScheduleTrigger) DeepCopy() *ScheduleTrigger {
+	if in == nil {
+		return nil
+	}
+	out := new(ScheduleTrigger)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Services) DeepCopyInto(out *Services) {
 	*out = *in
@@ -952,6 +1845,37 @@ func (in *Services) DeepCopy() *Services {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StaticDelta) DeepCopyInto(out *StaticDelta) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StaticDelta.
+func (in *StaticDelta) DeepCopy() *StaticDelta {
+	if in == nil {
+		return nil
+	}
+	out := new(StaticDelta)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Subscription) DeepCopyInto(out *Subscription) {
+	*out = *in
+	out.SecretRef = in.SecretRef
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Subscription.
+func (in *Subscription) DeepCopy() *Subscription {
+	if in == nil {
+		return nil
+	}
+	out := new(Subscription)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TargetImage) DeepCopyInto(out *TargetImage) {
 	*out = *in
@@ -1003,6 +1927,31 @@ func (in *Template) DeepCopy() *Template {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Timezone) DeepCopyInto(out *Timezone) {
+	*out = *in
+	if in.Timezone != nil {
+		in, out := &in.Timezone, &out.Timezone
+		*out = new(string)
+		**out = **in
+	}
+	if in.NTPServers != nil {
+		in, out := &in.NTPServers, &out.NTPServers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Timezone.
+func (in *Timezone) DeepCopy() *Timezone {
+	if in == nil {
+		return nil
+	}
+	out := new(Timezone)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *User) DeepCopyInto(out *User) {
 	*out = *in
@@ -1020,6 +1969,43 @@ func (in *User) DeepCopyInto(out *User) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.Description != nil {
+		in, out := &in.Description, &out.Description
+		*out = new(string)
+		**out = **in
+	}
+	if in.UID != nil {
+		in, out := &in.UID, &out.UID
+		*out = new(int64)
+		**out = **in
+	}
+	if in.GID != nil {
+		in, out := &in.GID, &out.GID
+		*out = new(int64)
+		**out = **in
+	}
+	if in.Home != nil {
+		in, out := &in.Home, &out.Home
+		*out = new(string)
+		**out = **in
+	}
+	if in.Shell != nil {
+		in, out := &in.Shell, &out.Shell
+		*out = new(string)
+		**out = **in
+	}
+	if in.PasswordSecretKeyRef != nil {
+		in, out := &in.PasswordSecretKeyRef, &out.PasswordSecretKeyRef
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SSHKeysSecretKeyRefs != nil {
+		in, out := &in.SSHKeysSecretKeyRefs, &out.SSHKeysSecretKeyRefs
+		*out = make([]v1.SecretKeySelector, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new User.
@@ -1078,6 +2064,108 @@ func (in *VMWorkerConfig) DeepCopy() *VMWorkerConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VulnerabilityFeed) DeepCopyInto(out *VulnerabilityFeed) {
+	*out = *in
+	if in.ConfigMapKeyRef != nil {
+		in, out := &in.ConfigMapKeyRef, &out.ConfigMapKeyRef
+		*out = new(v1.ConfigMapKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Path != nil {
+		in, out := &in.Path, &out.Path
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VulnerabilityFeed.
+func (in *VulnerabilityFeed) DeepCopy() *VulnerabilityFeed {
+	if in == nil {
+		return nil
+	}
+	out := new(VulnerabilityFeed)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VulnerabilityPolicy) DeepCopyInto(out *VulnerabilityPolicy) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VulnerabilityPolicy.
+func (in *VulnerabilityPolicy) DeepCopy() *VulnerabilityPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(VulnerabilityPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VulnerabilityScan) DeepCopyInto(out *VulnerabilityScan) {
+	*out = *in
+	in.Feed.DeepCopyInto(&out.Feed)
+	if in.Policy != nil {
+		in, out := &in.Policy, &out.Policy
+		*out = new(VulnerabilityPolicy)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VulnerabilityScan.
+func (in *VulnerabilityScan) DeepCopy() *VulnerabilityScan {
+	if in == nil {
+		return nil
+	}
+	out := new(VulnerabilityScan)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VulnerabilitySummary) DeepCopyInto(out *VulnerabilitySummary) {
+	*out = *in
+	if in.PolicyViolations != nil {
+		in, out := &in.PolicyViolations, &out.PolicyViolations
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	in.ScanTime.DeepCopyInto(&out.ScanTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VulnerabilitySummary.
+func (in *VulnerabilitySummary) DeepCopy() *VulnerabilitySummary {
+	if in == nil {
+		return nil
+	}
+	out := new(VulnerabilitySummary)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WebHookTriggerDetails) DeepCopyInto(out *WebHookTriggerDetails) {
+	*out = *in
+	if in.Overrides != nil {
+		in, out := &in.Overrides, &out.Overrides
+		*out = new(BuildOverrides)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WebHookTriggerDetails.
+func (in *WebHookTriggerDetails) DeepCopy() *WebHookTriggerDetails {
+	if in == nil {
+		return nil
+	}
+	out := new(WebHookTriggerDetails)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *WorkerConfig) DeepCopyInto(out *WorkerConfig) {
 	*out = *in
@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"net/http"
@@ -14,13 +15,20 @@ import (
 	"k8s.io/client-go/tools/clientcmd"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/certwatcher"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	osbuildv1alpha1 "github.com/project-flotta/osbuild-operator/api/v1alpha1"
 	"github.com/project-flotta/osbuild-operator/internal/httpapi"
+	"github.com/project-flotta/osbuild-operator/internal/kubeauth"
 	operatorlogger "github.com/project-flotta/osbuild-operator/internal/logger"
 	osbuildconfiginternal "github.com/project-flotta/osbuild-operator/internal/osbuildconfig"
+	"github.com/project-flotta/osbuild-operator/internal/osbuildwatch"
+	"github.com/project-flotta/osbuild-operator/internal/ratelimit"
+	"github.com/project-flotta/osbuild-operator/internal/replay"
+	"github.com/project-flotta/osbuild-operator/internal/repository/osbuild"
 	"github.com/project-flotta/osbuild-operator/internal/repository/osbuildconfig"
+	"github.com/project-flotta/osbuild-operator/internal/repository/osbuildconfigtemplate"
 	secretrepository "github.com/project-flotta/osbuild-operator/internal/repository/secret"
 	"github.com/project-flotta/osbuild-operator/restapi"
 )
@@ -52,7 +60,7 @@ func main() {
 		panic(err.Error())
 	}
 
-	c, err := getClient(clientConfig, client.Options{Scheme: scheme})
+	c, objCache, err := getClient(clientConfig, client.Options{Scheme: scheme})
 	if err != nil {
 		logger.Error(err, "Cannot create k8s client")
 		panic(err.Error())
@@ -60,14 +68,40 @@ func main() {
 
 	osBuildConfigRepository := osbuildconfig.NewOSBuildConfigRepository(c)
 	secretRepository := secretrepository.NewSecretRepository(c)
+	osBuildConfigTemplateRepository := osbuildconfigtemplate.NewOSBuildConfigTemplateRepository(c)
+	osBuildRepository := osbuild.NewOSBuildRepository(c)
+	authorizer := kubeauth.NewReviewAuthorizer(c)
+	triggerLimiter := ratelimit.NewKeyedLimiter(httpapi.GlobalHttpAPIConf.TriggerRateLimit, httpapi.GlobalHttpAPIConf.TriggerRateBurst)
+	replayGuard := replay.NewGuard(httpapi.GlobalHttpAPIConf.ReplayWindow)
+	clientLimiter := ratelimit.NewKeyedLimiter(httpapi.GlobalHttpAPIConf.ClientRateLimit, httpapi.GlobalHttpAPIConf.ClientRateBurst)
+
+	broadcaster := osbuildwatch.NewBroadcaster()
+	osBuildInformer, err := objCache.GetInformer(context.Background(), &osbuildv1alpha1.OSBuild{})
+	if err != nil {
+		logger.Error(err, "Cannot create the OSBuild informer")
+		panic(err.Error())
+	}
+	osBuildInformer.AddEventHandler(broadcaster)
 
-	h := restapi.Handler(osbuildconfiginternal.NewOSBuildConfigHandler(osBuildConfigRepository, secretRepository))
+	h := restapi.Handler(osbuildconfiginternal.NewOSBuildConfigHandler(osBuildConfigRepository, secretRepository, osBuildConfigTemplateRepository,
+		osBuildRepository, authorizer, triggerLimiter, replayGuard, broadcaster))
 	server := &http.Server{
 		Addr:              fmt.Sprintf(":%v", httpapi.GlobalHttpAPIConf.HttpPort),
 		ReadHeaderTimeout: time.Minute,
-		Handler:           h,
+		Handler:           ratelimit.Middleware(clientLimiter, httpapi.GlobalHttpAPIConf.ClientIPHeader)(h),
+	}
+
+	tlsConfig, err := getTLSConfig(httpapi.GlobalHttpAPIConf.TLSCertFile, httpapi.GlobalHttpAPIConf.TLSKeyFile)
+	if err != nil {
+		logger.Error(err, "Cannot load the TLS certificate")
+		panic(err.Error())
 	}
+	server.TLSConfig = tlsConfig
 	go func() {
+		if tlsConfig != nil {
+			logger.Info("Starting listening to OSBuildConfigHandler server with TLS")
+			logger.Fatal(server.ListenAndServeTLS("", ""))
+		}
 		logger.Info("Starting listening to OSBuildConfigHandler server")
 		logger.Fatal(server.ListenAndServe())
 	}()
@@ -93,11 +127,34 @@ func getRestConfig(kubeconfigPath string) (*rest.Config, error) {
 	return ctrl.GetConfig()
 }
 
-func getClient(config *rest.Config, options client.Options) (client.Client, error) {
-	c, err := client.New(config, options)
+// getTLSConfig returns the TLS configuration serving the certificate of the files, reloaded when they change, or nil
+// when they are not set
+func getTLSConfig(certFile string, keyFile string) (*tls.Config, error) {
+	if certFile == "" || keyFile == "" {
+		return nil, nil
+	}
+	watcher, err := certwatcher.New(certFile, keyFile)
 	if err != nil {
 		return nil, err
 	}
+	go func() {
+		err := watcher.Start(context.Background())
+		if err != nil {
+			panic(err.Error())
+		}
+	}()
+	return &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: watcher.GetCertificate,
+	}, nil
+}
+
+// getClient returns a client reading from the cache, along with the cache for its informers
+func getClient(config *rest.Config, options client.Options) (client.Client, cache.Cache, error) {
+	c, err := client.New(config, options)
+	if err != nil {
+		return nil, nil, err
+	}
 
 	cacheOpts := cache.Options{
 		Scheme: options.Scheme,
@@ -105,23 +162,27 @@ func getClient(config *rest.Config, options client.Options) (client.Client, erro
 	}
 	objCache, err := cache.New(config, cacheOpts)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	background := context.Background()
 	go func() {
 		err = objCache.Start(background)
 	}()
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	if !objCache.WaitForCacheSync(background) {
-		return nil, errors.New("cannot sync cache")
+		return nil, nil, errors.New("cannot sync cache")
 	}
-	return client.NewDelegatingClient(client.NewDelegatingClientInput{
+	delegatingClient, err := client.NewDelegatingClient(client.NewDelegatingClientInput{
 		CacheReader:     objCache,
 		Client:          c,
 		UncachedObjects: []client.Object{},
 	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return delegatingClient, objCache, nil
 }
 
 func httpOK(writer http.ResponseWriter, _ *http.Request) {
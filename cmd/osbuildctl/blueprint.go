@@ -0,0 +1,133 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+
+	"github.com/project-flotta/osbuild-operator/api/v1alpha1"
+)
+
+// Blueprint is the YAML description of an image, in the spirit of the osbuild-composer blueprints, that an
+// OSBuildConfig is created from
+type Blueprint struct {
+	// Name is the name of the OSBuildConfig
+	Name string `json:"name"`
+	// Distribution is the name of the O/S distribution, e.g. rhel-90
+	Distribution string `json:"distribution"`
+	// Architecture is the architecture of the image. Default: x86_64
+	Architecture v1alpha1.Architecture `json:"architecture,omitempty"`
+	// ImageType is the type of the image. Default: edge-container
+	ImageType v1alpha1.TargetImageType `json:"imageType,omitempty"`
+	// OSTree is the OSTree configuration of the image (optional)
+	OSTree *v1alpha1.OSTreeConfig `json:"ostree,omitempty"`
+	// Repositories are additional RPM repositories used to build the image (optional)
+	Repositories []v1alpha1.Repository `json:"repositories,omitempty"`
+	// Packages are the packages to install, in addition to the ones of the customizations (optional)
+	Packages []BlueprintPackage `json:"packages,omitempty"`
+	// Customizations are the changes applied on top of the base image (optional)
+	Customizations *v1alpha1.Customizations `json:"customizations,omitempty"`
+	// Template is the OSBuildConfigTemplate the OSBuildConfig is generated with (optional)
+	Template *v1alpha1.Template `json:"template,omitempty"`
+	// Triggers define when the image is built (optional)
+	Triggers v1alpha1.BuildTriggers `json:"triggers,omitempty"`
+}
+
+// BlueprintPackage is a package of a blueprint, its version is a glob of the [epoch:]version[-release] to install
+type BlueprintPackage struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+}
+
+// LoadBlueprint reads a blueprint from a YAML file, rejecting the unknown fields
+func LoadBlueprint(path string) (*Blueprint, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	blueprint := &Blueprint{}
+	err = yaml.UnmarshalStrict(data, blueprint)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse the blueprint %s: %w", path, err)
+	}
+	if blueprint.Name == "" {
+		return nil, fmt.Errorf("the blueprint %s has no name", path)
+	}
+	if blueprint.Distribution == "" {
+		return nil, fmt.Errorf("the blueprint %s has no distribution", path)
+	}
+	for _, pkg := range blueprint.Packages {
+		if pkg.Name == "" {
+			return nil, fmt.Errorf("the blueprint %s has a package without name", path)
+		}
+	}
+	return blueprint, nil
+}
+
+// ToOSBuildConfig returns the OSBuildConfig of the blueprint in the namespace
+func (b *Blueprint) ToOSBuildConfig(namespace string) *v1alpha1.OSBuildConfig {
+	architecture := b.Architecture
+	if architecture == "" {
+		architecture = "x86_64"
+	}
+	imageType := b.ImageType
+	if imageType == "" {
+		imageType = v1alpha1.EdgeContainerImageType
+	}
+
+	var customizations *v1alpha1.Customizations
+	if b.Customizations != nil {
+		customizations = b.Customizations.DeepCopy()
+	}
+	if len(b.Packages) > 0 {
+		if customizations == nil {
+			customizations = &v1alpha1.Customizations{}
+		}
+		for _, pkg := range b.Packages {
+			customizations.Packages = append(customizations.Packages, pkg.spec())
+		}
+	}
+
+	osBuildConfig := &v1alpha1.OSBuildConfig{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: v1alpha1.GroupVersion.String(),
+			Kind:       "OSBuildConfig",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      b.Name,
+			Namespace: namespace,
+		},
+		Spec: v1alpha1.OSBuildConfigSpec{
+			Details: v1alpha1.BuildDetails{
+				Distribution:   b.Distribution,
+				Customizations: customizations,
+				TargetImage: v1alpha1.TargetImage{
+					Architecture:    architecture,
+					TargetImageType: imageType,
+					OSTree:          b.OSTree.DeepCopy(),
+				},
+			},
+			Triggers: *b.Triggers.DeepCopy(),
+			Template: b.Template.DeepCopy(),
+		},
+	}
+	if len(b.Repositories) > 0 {
+		repositories := make([]v1alpha1.Repository, len(b.Repositories))
+		for i := range b.Repositories {
+			b.Repositories[i].DeepCopyInto(&repositories[i])
+		}
+		osBuildConfig.Spec.Details.TargetImage.Repositories = &repositories
+	}
+	return osBuildConfig
+}
+
+// spec returns the package the way DNF selects it, i.e. name-version when the version is set
+func (p BlueprintPackage) spec() string {
+	if p.Version == "" || p.Version == "*" {
+		return p.Name
+	}
+	return fmt.Sprintf("%s-%s", p.Name, p.Version)
+}
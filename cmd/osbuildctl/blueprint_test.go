@@ -0,0 +1,107 @@
+package main_test
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	buildv1 "github.com/openshift/api/build/v1"
+	"k8s.io/utils/pointer"
+
+	"github.com/project-flotta/osbuild-operator/api/v1alpha1"
+	osbuildctl "github.com/project-flotta/osbuild-operator/cmd/osbuildctl"
+)
+
+var _ = Describe("Blueprint", func() {
+	var blueprintPath string
+
+	BeforeEach(func() {
+		blueprintPath = filepath.Join(GinkgoT().TempDir(), "blueprint.yaml")
+	})
+
+	writeBlueprint := func(content string) {
+		err := os.WriteFile(blueprintPath, []byte(content), 0600)
+		Expect(err).NotTo(HaveOccurred())
+	}
+
+	It("should convert the blueprint to an OSBuildConfig", func() {
+		// given
+		writeBlueprint(`
+name: edge-device
+distribution: rhel-90
+architecture: aarch64
+ostree:
+  ref: rhel/9/aarch64/edge
+repositories:
+  - baseurl: https://repo.example.com/edge
+packages:
+  - name: podman
+  - name: vim-enhanced
+    version: "8.2*"
+customizations:
+  hostname: edge
+  packages: [git]
+triggers:
+  webHook:
+    secretReference:
+      name: webhook-secret
+`)
+
+		// when
+		blueprint, err := osbuildctl.LoadBlueprint(blueprintPath)
+
+		// then
+		Expect(err).NotTo(HaveOccurred())
+		osBuildConfig := blueprint.ToOSBuildConfig("edge")
+		Expect(osBuildConfig.Name).To(Equal("edge-device"))
+		Expect(osBuildConfig.Namespace).To(Equal("edge"))
+		Expect(osBuildConfig.Spec.Details).To(Equal(v1alpha1.BuildDetails{
+			Distribution: "rhel-90",
+			Customizations: &v1alpha1.Customizations{
+				Hostname: pointer.String("edge"),
+				Packages: []string{"git", "podman", "vim-enhanced-8.2*"},
+			},
+			TargetImage: v1alpha1.TargetImage{
+				Architecture:    "aarch64",
+				TargetImageType: v1alpha1.EdgeContainerImageType,
+				OSTree:          &v1alpha1.OSTreeConfig{Ref: pointer.String("rhel/9/aarch64/edge")},
+				Repositories:    &[]v1alpha1.Repository{{Baseurl: pointer.String("https://repo.example.com/edge")}},
+			},
+		}))
+		Expect(osBuildConfig.Spec.Triggers).To(Equal(v1alpha1.BuildTriggers{
+			WebHook: &buildv1.WebHookTrigger{SecretReference: &buildv1.SecretLocalReference{Name: "webhook-secret"}},
+		}))
+	})
+
+	It("should default the architecture and the image type", func() {
+		// given
+		writeBlueprint("name: edge-device\ndistribution: rhel-90\n")
+
+		// when
+		blueprint, err := osbuildctl.LoadBlueprint(blueprintPath)
+
+		// then
+		Expect(err).NotTo(HaveOccurred())
+		targetImage := blueprint.ToOSBuildConfig("edge").Spec.Details.TargetImage
+		Expect(targetImage.Architecture).To(BeEquivalentTo("x86_64"))
+		Expect(targetImage.TargetImageType).To(Equal(v1alpha1.EdgeContainerImageType))
+		Expect(targetImage.Repositories).To(BeNil())
+	})
+
+	DescribeTable("should reject the invalid blueprints", func(content string, expectedError string) {
+		// given
+		writeBlueprint(content)
+
+		// when
+		_, err := osbuildctl.LoadBlueprint(blueprintPath)
+
+		// then
+		Expect(err).To(MatchError(ContainSubstring(expectedError)))
+	},
+		Entry("without name", "distribution: rhel-90\n", "has no name"),
+		Entry("without distribution", "name: edge-device\n", "has no distribution"),
+		Entry("with a package without name", "name: edge-device\ndistribution: rhel-90\npackages:\n  - version: '1.0'\n", "package without name"),
+		Entry("with an unknown field", "name: edge-device\ndistribution: rhel-90\ndistro: rhel-90\n", "unknown field"),
+	)
+})
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/urfave/cli/v2"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/clientcmd"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/project-flotta/osbuild-operator/api/v1alpha1"
+)
+
+// getKubeClient returns a client of the cluster of the kubeconfig and the namespace to work in, the one of the
+// current context of the kubeconfig unless the namespace flag is set
+func getKubeClient(c *cli.Context) (client.Client, string, error) {
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	loadingRules.ExplicitPath = c.String(kubeconfigFlag)
+	config, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, &clientcmd.ConfigOverrides{}).ClientConfig()
+	if err != nil {
+		return nil, "", fmt.Errorf("cannot load the kubeconfig: %w", err)
+	}
+	namespace, err := getNamespace(c)
+	if err != nil {
+		return nil, "", fmt.Errorf("cannot get the namespace: %w", err)
+	}
+
+	scheme := runtime.NewScheme()
+	if err = corev1.AddToScheme(scheme); err != nil {
+		return nil, "", err
+	}
+	if err = v1alpha1.AddToScheme(scheme); err != nil {
+		return nil, "", err
+	}
+	kubeClient, err := client.New(config, client.Options{Scheme: scheme})
+	if err != nil {
+		return nil, "", fmt.Errorf("cannot create the client of the cluster: %w", err)
+	}
+	return kubeClient, namespace, nil
+}
+
+// getNamespace returns the namespace flag, or the namespace of the current context of the kubeconfig
+func getNamespace(c *cli.Context) (string, error) {
+	if namespace := c.String(namespaceFlag); namespace != "" {
+		return namespace, nil
+	}
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	loadingRules.ExplicitPath = c.String(kubeconfigFlag)
+	namespace, _, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, &clientcmd.ConfigOverrides{}).Namespace()
+	return namespace, err
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/project-flotta/osbuild-operator/api/v1alpha1"
+	"github.com/project-flotta/osbuild-operator/internal/diff"
+)
+
+// diffReportKey is the key of the diff report in the ConfigMap the operator stores it in
+const diffReportKey = "report.json"
+
+// DiffOSBuilds returns the changes between two versions of the OSBuildConfig. The operator reports the changes of each
+// Ready OSBuild compared to the previous Ready one of the same target image type, the reports of the OSBuilds from
+// the newer version back to the older one are chained.
+func DiffOSBuilds(ctx context.Context, kubeClient client.Client, namespace string, osBuildConfigName string, fromVersion int, toVersion int) (*diff.Report, error) {
+	if fromVersion >= toVersion {
+		return nil, fmt.Errorf("the version %d is not older than the version %d", fromVersion, toVersion)
+	}
+	fromOSBuildName := fmt.Sprintf("%s-%d", osBuildConfigName, fromVersion)
+
+	var reports []*diff.Report
+	osBuildName := fmt.Sprintf("%s-%d", osBuildConfigName, toVersion)
+	for osBuildName != fromOSBuildName {
+		report, err := readDiffReport(ctx, kubeClient, namespace, osBuildName)
+		if err != nil {
+			return nil, err
+		}
+		if report.PreviousOSBuild == "" {
+			return nil, fmt.Errorf("the OSBuild %s is not a Ready build of the same image type as %s", fromOSBuildName, report.OSBuild)
+		}
+		reports = append([]*diff.Report{report}, reports...)
+		osBuildName = report.PreviousOSBuild
+		if version, ok := getOSBuildVersion(osBuildConfigName, osBuildName); !ok || version < fromVersion {
+			return nil, fmt.Errorf("the OSBuild %s is not a Ready build of the same image type as %s", fromOSBuildName, report.OSBuild)
+		}
+	}
+	return diff.Chain(reports), nil
+}
+
+// readDiffReport returns the report of the changes of the OSBuild compared to its previous one
+func readDiffReport(ctx context.Context, kubeClient client.Client, namespace string, osBuildName string) (*diff.Report, error) {
+	osBuild := &v1alpha1.OSBuild{}
+	err := kubeClient.Get(ctx, client.ObjectKey{Namespace: namespace, Name: osBuildName}, osBuild)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get the OSBuild %s: %w", osBuildName, err)
+	}
+	if osBuild.Status.Diff == nil {
+		return nil, fmt.Errorf("the OSBuild %s has no diff report, it is not Ready", osBuildName)
+	}
+
+	configMap := &corev1.ConfigMap{}
+	err = kubeClient.Get(ctx, client.ObjectKey{Namespace: namespace, Name: osBuild.Status.Diff.ReportConfigMap}, configMap)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get the diff report of the OSBuild %s: %w", osBuildName, err)
+	}
+	report := &diff.Report{}
+	err = json.Unmarshal([]byte(configMap.Data[diffReportKey]), report)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse the diff report of the OSBuild %s: %w", osBuildName, err)
+	}
+	return report, nil
+}
+
+// PrintReport writes the changed packages and customizations of the report, one per line
+func PrintReport(out io.Writer, report *diff.Report) {
+	fmt.Fprintf(out, "Changes of %s since %s\n", report.OSBuild, report.PreviousOSBuild)
+	for _, pkg := range report.AddedPackages {
+		fmt.Fprintf(out, "+ %s.%s %s\n", pkg.Name, pkg.Arch, pkg.Version)
+	}
+	for _, pkg := range report.RemovedPackages {
+		fmt.Fprintf(out, "- %s.%s %s\n", pkg.Name, pkg.Arch, pkg.Version)
+	}
+	for _, change := range report.UpgradedPackages {
+		fmt.Fprintf(out, "^ %s.%s %s -> %s\n", change.Name, change.Arch, change.OldVersion, change.NewVersion)
+	}
+	for _, change := range report.DowngradedPackages {
+		fmt.Fprintf(out, "v %s.%s %s -> %s\n", change.Name, change.Arch, change.OldVersion, change.NewVersion)
+	}
+	for _, name := range report.ChangedCustomizations {
+		fmt.Fprintf(out, "~ %s\n", name)
+	}
+}
+
+// getOSBuildVersion returns the version of an OSBuild named after its OSBuildConfig
+func getOSBuildVersion(osBuildConfigName string, osBuildName string) (int, bool) {
+	if !strings.HasPrefix(osBuildName, osBuildConfigName+"-") {
+		return 0, false
+	}
+	version, err := strconv.Atoi(strings.TrimPrefix(osBuildName, osBuildConfigName+"-"))
+	if err != nil {
+		return 0, false
+	}
+	return version, true
+}
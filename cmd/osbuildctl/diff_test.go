@@ -0,0 +1,113 @@
+package main_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/project-flotta/osbuild-operator/api/v1alpha1"
+	osbuildctl "github.com/project-flotta/osbuild-operator/cmd/osbuildctl"
+	"github.com/project-flotta/osbuild-operator/internal/diff"
+)
+
+var _ = Describe("Diff", func() {
+	var (
+		ctx     context.Context
+		objects []client.Object
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		objects = nil
+	})
+
+	// addOSBuild adds a Ready OSBuild with its diff report, and a Failed one when the report is nil
+	addOSBuild := func(name string, report *diff.Report) {
+		osBuild := &v1alpha1.OSBuild{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "edge"}}
+		objects = append(objects, osBuild)
+		if report == nil {
+			return
+		}
+		configMapName := fmt.Sprintf("%s-diff", name)
+		osBuild.Status.Diff = report.Summary(configMapName)
+		data, err := json.Marshal(report)
+		Expect(err).NotTo(HaveOccurred())
+		objects = append(objects, &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: configMapName, Namespace: "edge"},
+			Data:       map[string]string{"report.json": string(data)},
+		})
+	}
+	newClient := func() client.Client {
+		scheme := runtime.NewScheme()
+		Expect(corev1.AddToScheme(scheme)).To(Succeed())
+		Expect(v1alpha1.AddToScheme(scheme)).To(Succeed())
+		return fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build()
+	}
+
+	BeforeEach(func() {
+		addOSBuild("edge-1", &diff.Report{
+			OSBuild:       "edge-1",
+			AddedPackages: []diff.Package{{Name: "bash", Arch: "x86_64", Version: "5.1.8-4.el9"}},
+		})
+		addOSBuild("edge-2", &diff.Report{
+			OSBuild:          "edge-2",
+			PreviousOSBuild:  "edge-1",
+			UpgradedPackages: []diff.PackageChange{{Name: "bash", Arch: "x86_64", OldVersion: "5.1.8-4.el9", NewVersion: "5.1.8-6.el9"}},
+		})
+		addOSBuild("edge-3", nil)
+		addOSBuild("edge-4", &diff.Report{
+			OSBuild:               "edge-4",
+			PreviousOSBuild:       "edge-2",
+			AddedPackages:         []diff.Package{{Name: "tmux", Arch: "x86_64", Version: "3.2a-4.el9"}},
+			ChangedCustomizations: []string{"customizations.packages"},
+		})
+	})
+
+	It("should chain the reports between the versions", func() {
+		// when
+		report, err := osbuildctl.DiffOSBuilds(ctx, newClient(), "edge", "edge", 1, 4)
+
+		// then
+		Expect(err).NotTo(HaveOccurred())
+		out := &bytes.Buffer{}
+		osbuildctl.PrintReport(out, report)
+		Expect(out.String()).To(Equal(`Changes of edge-4 since edge-1
++ tmux.x86_64 3.2a-4.el9
+^ bash.x86_64 5.1.8-4.el9 -> 5.1.8-6.el9
+~ customizations.packages
+`))
+	})
+
+	It("should return the report of the newer version when it follows the older one", func() {
+		// when
+		report, err := osbuildctl.DiffOSBuilds(ctx, newClient(), "edge", "edge", 2, 4)
+
+		// then
+		Expect(err).NotTo(HaveOccurred())
+		Expect(report.PreviousOSBuild).To(Equal("edge-2"))
+		Expect(report.AddedPackages).To(HaveLen(1))
+		Expect(report.UpgradedPackages).To(BeEmpty())
+	})
+
+	DescribeTable("should fail", func(fromVersion int, toVersion int, expectedError string) {
+		// when
+		_, err := osbuildctl.DiffOSBuilds(ctx, newClient(), "edge", "edge", fromVersion, toVersion)
+
+		// then
+		Expect(err).To(MatchError(ContainSubstring(expectedError)))
+	},
+		Entry("when the versions are not in order", 4, 2, "the version 4 is not older than the version 2"),
+		Entry("when the older version is not in the chain of the newer one", 3, 4, "the OSBuild edge-3 is not a Ready build"),
+		Entry("when the newer version is not Ready", 1, 3, "the OSBuild edge-3 has no diff report"),
+		Entry("when the newer version does not exist", 1, 5, "cannot get the OSBuild edge-5"),
+	)
+})
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+)
+
+// DownloadArtifact writes the artifact at the URL to the file, and returns the number of bytes written. The file is
+// removed when the download fails. Only HTTP(S) URLs can be downloaded, the artifacts of edge-container OSBuilds are
+// container images to copy from their registry instead.
+func DownloadArtifact(ctx context.Context, httpClient *http.Client, artifactURL string, file string) (int64, error) {
+	if parsed, err := url.Parse(artifactURL); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+		return 0, fmt.Errorf("cannot download %s: it is not an HTTP(S) URL, copy a container image with e.g. skopeo copy docker://%s", artifactURL, artifactURL)
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, artifactURL, nil)
+	if err != nil {
+		return 0, err
+	}
+	response, err := httpClient.Do(request)
+	if err != nil {
+		return 0, fmt.Errorf("cannot download %s: %w", artifactURL, err)
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("cannot download %s: %s", artifactURL, response.Status)
+	}
+
+	output, err := os.Create(file)
+	if err != nil {
+		return 0, err
+	}
+	written, err := io.Copy(output, response.Body)
+	if closeErr := output.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(file)
+		return 0, fmt.Errorf("cannot write %s: %w", file, err)
+	}
+	return written, nil
+}
+
+// getArtifactFileName returns the last element of the path of the artifact URL, e.g. the name of the S3 object,
+// falling back to the name of the OSBuild
+func getArtifactFileName(artifactURL string, osBuildName string) string {
+	parsed, err := url.Parse(artifactURL)
+	if err != nil {
+		return osBuildName
+	}
+	name := path.Base(parsed.Path)
+	if name == "." || name == "/" {
+		return osBuildName
+	}
+	return name
+}
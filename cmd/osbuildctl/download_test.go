@@ -0,0 +1,68 @@
+package main_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	osbuildctl "github.com/project-flotta/osbuild-operator/cmd/osbuildctl"
+)
+
+var _ = Describe("Download", func() {
+	var (
+		server *httptest.Server
+		file   string
+	)
+
+	BeforeEach(func() {
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/bucket/edge-1.tar" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			_, _ = w.Write([]byte("artifact"))
+		}))
+		file = filepath.Join(GinkgoT().TempDir(), "edge-1.tar")
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("should write the artifact to the file", func() {
+		// when
+		written, err := osbuildctl.DownloadArtifact(context.Background(), server.Client(), server.URL+"/bucket/edge-1.tar", file)
+
+		// then
+		Expect(err).NotTo(HaveOccurred())
+		Expect(written).To(BeEquivalentTo(len("artifact")))
+		Expect(os.ReadFile(file)).To(Equal([]byte("artifact")))
+	})
+
+	It("should fail without creating the file when the artifact cannot be downloaded", func() {
+		// when
+		_, err := osbuildctl.DownloadArtifact(context.Background(), server.Client(), server.URL+"/bucket/other.tar", file)
+
+		// then
+		Expect(err).To(MatchError(ContainSubstring("404 Not Found")))
+		Expect(file).NotTo(BeAnExistingFile())
+	})
+
+	DescribeTable("should fail without creating the file when the artifact is not an HTTP(S) URL", func(artifactURL string) {
+		// when
+		_, err := osbuildctl.DownloadArtifact(context.Background(), server.Client(), artifactURL, file)
+
+		// then
+		Expect(err).To(MatchError(ContainSubstring("skopeo copy docker://" + artifactURL)))
+		Expect(file).NotTo(BeAnExistingFile())
+	},
+		Entry("container image", "registry.example.com/edge/config:2"),
+		Entry("container image on a registry port", "registry.example.com:5000/edge/config:2"),
+		Entry("container image by digest", "registry.example.com/edge/config@sha256:abc"),
+	)
+})
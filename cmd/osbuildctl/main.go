@@ -0,0 +1,385 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"time"
+
+	"github.com/urfave/cli/v2" // imports as package "cli"
+	"sigs.k8s.io/yaml"
+
+	"github.com/project-flotta/osbuild-operator/restapi"
+)
+
+const (
+	kubeconfigFlag = "kubeconfig"
+	namespaceFlag  = "namespace"
+	fileFlag       = "file"
+	dryRunFlag     = "dry-run"
+	apiURLFlag     = "api-url"
+	secretFlag     = "secret"
+	tokenFlag      = "token"
+	packageFlag    = "package"
+	paramFlag      = "param"
+	followFlag     = "follow"
+	intervalFlag   = "interval"
+	outputFlag     = "output"
+
+	outputText = "text"
+	outputJSON = "json"
+
+	// the time the OSBuildConfig controller is given to create the OSBuild of a trigger
+	triggeredOSBuildTimeout = 2 * time.Minute
+)
+
+var (
+	app *cli.App
+
+	InfoLogger  = log.New(os.Stderr, "INFO: ", log.Ldate|log.Ltime)
+	ErrorLogger = log.New(os.Stderr, "ERROR: ", log.Ldate|log.Ltime)
+
+	intervalFlagDefinition = &cli.DurationFlag{
+		Name:  intervalFlag,
+		Usage: "period at which the OSBuild is checked",
+		Value: 5 * time.Second,
+	}
+)
+
+func init() {
+	app = cli.NewApp()
+	app.Name = "osbuildctl"
+	app.Usage = "create OSBuildConfigs, trigger and follow their builds"
+	app.Flags = []cli.Flag{
+		&cli.StringFlag{
+			Name:    kubeconfigFlag,
+			Usage:   "path of the kubeconfig file, ~/.kube/config by default",
+			EnvVars: []string{"KUBECONFIG"},
+		},
+		&cli.StringFlag{
+			Name:    namespaceFlag,
+			Aliases: []string{"n"},
+			Usage:   "namespace of the OSBuildConfigs, the one of the current context of the kubeconfig by default",
+		},
+	}
+	app.Commands = []*cli.Command{
+		{
+			Name:   "create",
+			Usage:  "create an OSBuildConfig from a blueprint",
+			Action: create,
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:     fileFlag,
+					Aliases:  []string{"f"},
+					Usage:    "YAML blueprint of the image",
+					Required: true,
+				},
+				&cli.BoolFlag{
+					Name:  dryRunFlag,
+					Usage: "print the OSBuildConfig instead of creating it",
+				},
+			},
+		},
+		{
+			Name:      "trigger",
+			Usage:     "trigger a build of an OSBuildConfig",
+			ArgsUsage: "<osbuildconfig>",
+			Action:    trigger,
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:    apiURLFlag,
+					Usage:   "URL of the HTTP API of the operator, e.g. http://osbuild-operator-httpapi:8080. The OSBuildConfig is annotated directly when not set",
+					EnvVars: []string{"OSBUILD_API_URL"},
+				},
+				&cli.StringFlag{
+					Name:    secretFlag,
+					Usage:   "secret of the webhook trigger of the OSBuildConfig, to call the HTTP API with",
+					EnvVars: []string{"OSBUILD_WEBHOOK_SECRET"},
+				},
+				&cli.StringFlag{
+					Name:    tokenFlag,
+					Usage:   "Kubernetes bearer token to call the HTTP API with, instead of the secret",
+					EnvVars: []string{"OSBUILD_TOKEN"},
+				},
+				&cli.StringSliceFlag{
+					Name:  packageFlag,
+					Usage: "package to install in this build only, in addition to the ones of the OSBuildConfig",
+				},
+				&cli.StringSliceFlag{
+					Name:  paramFlag,
+					Usage: "name=value of a template parameter overridden in this build only",
+				},
+				&cli.BoolFlag{
+					Name:  followFlag,
+					Usage: "follow the triggered build until it is Ready or Failed",
+				},
+				intervalFlagDefinition,
+			},
+		},
+		{
+			Name:      "follow",
+			Usage:     "print the phase and condition changes of an OSBuild until it is Ready or Failed",
+			ArgsUsage: "<osbuild>",
+			Action:    follow,
+			Flags:     []cli.Flag{intervalFlagDefinition},
+		},
+		{
+			Name:      "status",
+			Usage:     "print the phase, the conditions and the failure reasons of an OSBuild",
+			ArgsUsage: "<osbuild>",
+			Action:    status,
+		},
+		{
+			Name:      "download",
+			Usage:     "download the artifact of a Ready OSBuild",
+			ArgsUsage: "<osbuild>",
+			Action:    download,
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:    outputFlag,
+					Aliases: []string{"o"},
+					Usage:   "file to write the artifact to, the name of the artifact in the current directory by default",
+				},
+			},
+		},
+		{
+			Name:      "diff",
+			Usage:     "print the packages and customizations changed between two versions of an OSBuildConfig",
+			ArgsUsage: "<osbuildconfig> <from-version> <to-version>",
+			Action:    diffVersions,
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:    outputFlag,
+					Aliases: []string{"o"},
+					Usage:   "output format, text or json",
+					Value:   outputText,
+				},
+			},
+		},
+	}
+}
+
+func create(c *cli.Context) error {
+	blueprint, err := LoadBlueprint(c.String(fileFlag))
+	if err != nil {
+		return err
+	}
+
+	if c.Bool(dryRunFlag) {
+		namespace, err := getNamespace(c)
+		if err != nil {
+			return err
+		}
+		data, err := yaml.Marshal(blueprint.ToOSBuildConfig(namespace))
+		if err != nil {
+			return err
+		}
+		fmt.Print(string(data))
+		return nil
+	}
+
+	kubeClient, namespace, err := getKubeClient(c)
+	if err != nil {
+		return err
+	}
+	err = kubeClient.Create(c.Context, blueprint.ToOSBuildConfig(namespace))
+	if err != nil {
+		return fmt.Errorf("cannot create the OSBuildConfig %s: %w", blueprint.Name, err)
+	}
+	InfoLogger.Printf("OSBuildConfig %s created in namespace %s", blueprint.Name, namespace)
+	return nil
+}
+
+func trigger(c *cli.Context) error {
+	name, err := getArg(c, 0, "osbuildconfig")
+	if err != nil {
+		return err
+	}
+	overrides, err := parseBuildOverrides(c.StringSlice(packageFlag), c.StringSlice(paramFlag))
+	if err != nil {
+		return err
+	}
+
+	var osBuildName string
+	if apiURL := c.String(apiURLFlag); apiURL != "" {
+		if c.String(secretFlag) == "" && c.String(tokenFlag) == "" {
+			return fmt.Errorf("the secret or the token is required to call the HTTP API")
+		}
+		namespace, err := getNamespace(c)
+		if err != nil {
+			return err
+		}
+		apiClient, err := restapi.NewClientWithResponses(apiURL)
+		if err != nil {
+			return err
+		}
+		credentials := TriggerCredentials{Secret: c.String(secretFlag), Token: c.String(tokenFlag)}
+		osBuildName, err = TriggerWithAPI(c.Context, apiClient, namespace, name, credentials, overrides)
+		if err != nil {
+			return err
+		}
+	} else {
+		kubeClient, namespace, err := getKubeClient(c)
+		if err != nil {
+			return err
+		}
+		triggerID, err := TriggerWithAnnotation(c.Context, kubeClient, namespace, name, overrides)
+		if err != nil {
+			return err
+		}
+		InfoLogger.Printf("OSBuildConfig %s triggered with the trigger ID %s, waiting for its OSBuild", name, triggerID)
+		ctx, cancel := context.WithTimeout(c.Context, triggeredOSBuildTimeout)
+		defer cancel()
+		osBuildName, err = FindTriggeredOSBuild(ctx, kubeClient, namespace, triggerID, c.Duration(intervalFlag))
+		if err != nil {
+			return err
+		}
+	}
+	InfoLogger.Printf("OSBuildConfig %s triggered, building OSBuild %s", name, osBuildName)
+	fmt.Println(osBuildName)
+
+	if !c.Bool(followFlag) {
+		return nil
+	}
+	return followOSBuild(c, osBuildName)
+}
+
+func follow(c *cli.Context) error {
+	name, err := getArg(c, 0, "osbuild")
+	if err != nil {
+		return err
+	}
+	return followOSBuild(c, name)
+}
+
+func followOSBuild(c *cli.Context, name string) error {
+	kubeClient, namespace, err := getKubeClient(c)
+	if err != nil {
+		return err
+	}
+	osBuild, err := FollowOSBuild(c.Context, kubeClient, namespace, name, c.Duration(intervalFlag), os.Stdout)
+	if err != nil {
+		return err
+	}
+	if osBuild.Status.AccessUrl != "" {
+		fmt.Printf("Artifact: %s\n", osBuild.Status.AccessUrl)
+	}
+	return nil
+}
+
+func status(c *cli.Context) error {
+	name, err := getArg(c, 0, "osbuild")
+	if err != nil {
+		return err
+	}
+	kubeClient, namespace, err := getKubeClient(c)
+	if err != nil {
+		return err
+	}
+	osBuild, err := getOSBuild(c.Context, kubeClient, namespace, name)
+	if err != nil {
+		return err
+	}
+	PrintOSBuild(os.Stdout, osBuild)
+	return nil
+}
+
+func download(c *cli.Context) error {
+	name, err := getArg(c, 0, "osbuild")
+	if err != nil {
+		return err
+	}
+	kubeClient, namespace, err := getKubeClient(c)
+	if err != nil {
+		return err
+	}
+	osBuild, err := getOSBuild(c.Context, kubeClient, namespace, name)
+	if err != nil {
+		return err
+	}
+	if GetPhase(osBuild) != phaseReady || osBuild.Status.AccessUrl == "" {
+		return fmt.Errorf("the OSBuild %s is %s, it has no artifact to download", name, GetPhase(osBuild))
+	}
+
+	file := c.String(outputFlag)
+	if file == "" {
+		file = getArtifactFileName(osBuild.Status.AccessUrl, name)
+	}
+	written, err := DownloadArtifact(c.Context, http.DefaultClient, osBuild.Status.AccessUrl, file)
+	if err != nil {
+		return err
+	}
+	InfoLogger.Printf("downloaded %d bytes of the artifact of OSBuild %s to %s", written, name, file)
+	return nil
+}
+
+func diffVersions(c *cli.Context) error {
+	name, err := getArg(c, 0, "osbuildconfig")
+	if err != nil {
+		return err
+	}
+	fromVersion, err := getVersionArg(c, 1, "from-version")
+	if err != nil {
+		return err
+	}
+	toVersion, err := getVersionArg(c, 2, "to-version")
+	if err != nil {
+		return err
+	}
+	output := c.String(outputFlag)
+	if output != outputText && output != outputJSON {
+		return fmt.Errorf("the output format %s is not one of %s and %s", output, outputText, outputJSON)
+	}
+
+	kubeClient, namespace, err := getKubeClient(c)
+	if err != nil {
+		return err
+	}
+	report, err := DiffOSBuilds(c.Context, kubeClient, namespace, name, fromVersion, toVersion)
+	if err != nil {
+		return err
+	}
+
+	if output == outputJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(report)
+	}
+	PrintReport(os.Stdout, report)
+	return nil
+}
+
+func getArg(c *cli.Context, index int, name string) (string, error) {
+	if c.NArg() <= index || c.Args().Get(index) == "" {
+		return "", fmt.Errorf("the %s argument is required", name)
+	}
+	return c.Args().Get(index), nil
+}
+
+func getVersionArg(c *cli.Context, index int, name string) (int, error) {
+	arg, err := getArg(c, index, name)
+	if err != nil {
+		return 0, err
+	}
+	version, err := strconv.Atoi(arg)
+	if err != nil || version < 1 {
+		return 0, fmt.Errorf("the %s argument %s is not a version", name, arg)
+	}
+	return version, nil
+}
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	err := app.RunContext(ctx, os.Args)
+	if err != nil {
+		stop()
+		ErrorLogger.Fatal(err)
+	}
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/project-flotta/osbuild-operator/api/v1alpha1"
+)
+
+const (
+	phaseBuilding = "Building"
+	phaseReady    = "Ready"
+	phaseFailed   = "Failed"
+)
+
+// GetPhase returns Ready or Failed for the finished OSBuilds, Building otherwise
+func GetPhase(osBuild *v1alpha1.OSBuild) string {
+	if osBuild.IsReady() {
+		return phaseReady
+	}
+	if osBuild.IsFailed() {
+		return phaseFailed
+	}
+	return phaseBuilding
+}
+
+// GetFailureReasons returns the messages of the failure conditions of the OSBuild
+func GetFailureReasons(osBuild *v1alpha1.OSBuild) []string {
+	var reasons []string
+	for _, condition := range osBuild.Status.Conditions {
+		if condition.Status != metav1.ConditionTrue || !v1alpha1.IsFailureCondition(condition.Type) {
+			continue
+		}
+		reason := string(condition.Type)
+		if condition.Message != nil && *condition.Message != "" {
+			reason = fmt.Sprintf("%s: %s", condition.Type, *condition.Message)
+		}
+		reasons = append(reasons, reason)
+	}
+	return reasons
+}
+
+// PrintOSBuild writes the phase, the conditions, the artifacts and the summaries of the OSBuild
+func PrintOSBuild(out io.Writer, osBuild *v1alpha1.OSBuild) {
+	fmt.Fprintf(out, "OSBuild:    %s\n", osBuild.Name)
+	fmt.Fprintf(out, "Phase:      %s\n", GetPhase(osBuild))
+	if osBuild.Spec.TriggeredBy != "" {
+		fmt.Fprintf(out, "Triggered:  %s\n", osBuild.Spec.TriggeredBy)
+	}
+	if osBuild.Status.AccessUrl != "" {
+		fmt.Fprintf(out, "Artifact:   %s\n", osBuild.Status.AccessUrl)
+	}
+	if osBuild.Status.OSTreeCommit != "" {
+		fmt.Fprintf(out, "Commit:     %s\n", osBuild.Status.OSTreeCommit)
+	}
+	if diffSummary := osBuild.Status.Diff; diffSummary != nil && diffSummary.PreviousOSBuild != "" {
+		fmt.Fprintf(out, "Diff:       %d added, %d removed, %d upgraded, %d downgraded packages since %s\n",
+			diffSummary.AddedPackages, diffSummary.RemovedPackages, diffSummary.UpgradedPackages, diffSummary.DowngradedPackages, diffSummary.PreviousOSBuild)
+	}
+	if vulnerabilities := osBuild.Status.Vulnerabilities; vulnerabilities != nil {
+		if vulnerabilities.Error != "" {
+			fmt.Fprintf(out, "Vulns:      cannot scan: %s\n", vulnerabilities.Error)
+		} else {
+			fmt.Fprintf(out, "Vulns:      %d critical, %d high, %d medium, %d low, %d unknown\n",
+				vulnerabilities.Critical, vulnerabilities.High, vulnerabilities.Medium, vulnerabilities.Low, vulnerabilities.Unknown)
+		}
+	}
+
+	fmt.Fprintln(out, "Conditions:")
+	for _, condition := range osBuild.Status.Conditions {
+		fmt.Fprintf(out, "  %s\n", formatCondition(condition))
+	}
+	if reasons := GetFailureReasons(osBuild); len(reasons) > 0 {
+		fmt.Fprintln(out, "Failure reasons:")
+		for _, reason := range reasons {
+			fmt.Fprintf(out, "  %s\n", reason)
+		}
+	}
+}
+
+// FollowOSBuild checks the OSBuild every interval and writes its phase and condition changes until it is Ready or
+// Failed, the OSBuild of a trigger being waited for until it is created. The failure reasons are returned as an error
+// when it Failed.
+func FollowOSBuild(ctx context.Context, kubeClient client.Client, namespace string, name string, interval time.Duration, out io.Writer) (*v1alpha1.OSBuild, error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var lastConditions []v1alpha1.Condition
+	lastPhase := ""
+	for {
+		osBuild := &v1alpha1.OSBuild{}
+		err := kubeClient.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, osBuild)
+		if err != nil && !errors.IsNotFound(err) {
+			return nil, fmt.Errorf("cannot get the OSBuild %s: %w", name, err)
+		}
+
+		if err == nil {
+			phase := GetPhase(osBuild)
+			if phase != lastPhase {
+				fmt.Fprintf(out, "%s %s %s\n", time.Now().Format(time.RFC3339), name, phase)
+				lastPhase = phase
+			}
+			for _, condition := range osBuild.Status.Conditions {
+				if !containsCondition(lastConditions, condition) {
+					fmt.Fprintf(out, "  %s\n", formatCondition(condition))
+				}
+			}
+			lastConditions = osBuild.Status.Conditions
+
+			switch phase {
+			case phaseReady:
+				return osBuild, nil
+			case phaseFailed:
+				return osBuild, fmt.Errorf("the OSBuild %s failed: %s", name, strings.Join(GetFailureReasons(osBuild), "; "))
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("stopped following the OSBuild %s: %w", name, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+func getOSBuild(ctx context.Context, kubeClient client.Client, namespace string, name string) (*v1alpha1.OSBuild, error) {
+	osBuild := &v1alpha1.OSBuild{}
+	err := kubeClient.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, osBuild)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get the OSBuild %s: %w", name, err)
+	}
+	return osBuild, nil
+}
+
+func formatCondition(condition v1alpha1.Condition) string {
+	formatted := fmt.Sprintf("%s=%s", condition.Type, condition.Status)
+	if condition.LastTransitionTime != nil {
+		formatted = fmt.Sprintf("%s since %s", formatted, condition.LastTransitionTime.Format(time.RFC3339))
+	}
+	if condition.Message != nil && *condition.Message != "" {
+		formatted = fmt.Sprintf("%s: %s", formatted, *condition.Message)
+	}
+	return formatted
+}
+
+func containsCondition(conditions []v1alpha1.Condition, condition v1alpha1.Condition) bool {
+	for i := range conditions {
+		if reflect.DeepEqual(conditions[i], condition) {
+			return true
+		}
+	}
+	return false
+}
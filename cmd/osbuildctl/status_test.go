@@ -0,0 +1,152 @@
+package main_test
+
+import (
+	"bytes"
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/project-flotta/osbuild-operator/api/v1alpha1"
+	osbuildctl "github.com/project-flotta/osbuild-operator/cmd/osbuildctl"
+)
+
+// sequenceClient returns the next OSBuild of the sequence on each Get, nil standing for an OSBuild not created yet
+type sequenceClient struct {
+	client.Client
+	osBuilds []*v1alpha1.OSBuild
+}
+
+func (s *sequenceClient) Get(_ context.Context, key client.ObjectKey, obj client.Object, _ ...client.GetOption) error {
+	osBuild := s.osBuilds[0]
+	if len(s.osBuilds) > 1 {
+		s.osBuilds = s.osBuilds[1:]
+	}
+	if osBuild == nil {
+		return errors.NewNotFound(schema.GroupResource{Group: v1alpha1.GroupVersion.Group, Resource: "osbuilds"}, key.Name)
+	}
+	osBuild.DeepCopyInto(obj.(*v1alpha1.OSBuild))
+	return nil
+}
+
+var _ = Describe("Status", func() {
+	newOSBuild := func(conditions ...v1alpha1.Condition) *v1alpha1.OSBuild {
+		return &v1alpha1.OSBuild{
+			ObjectMeta: metav1.ObjectMeta{Name: "edge-1", Namespace: "edge"},
+			Status:     v1alpha1.OSBuildStatus{Conditions: conditions},
+		}
+	}
+	condition := func(conditionType v1alpha1.ConditionType, status metav1.ConditionStatus, message string) v1alpha1.Condition {
+		c := v1alpha1.Condition{Type: conditionType, Status: status}
+		if message != "" {
+			c.Message = pointer.String(message)
+		}
+		return c
+	}
+
+	DescribeTable("should return the phase of the OSBuild", func(conditions []v1alpha1.Condition, expectedPhase string) {
+		// when
+		phase := osbuildctl.GetPhase(newOSBuild(conditions...))
+
+		// then
+		Expect(phase).To(Equal(expectedPhase))
+	},
+		Entry("without conditions", nil, "Building"),
+		Entry("in progress", []v1alpha1.Condition{condition(v1alpha1.ConditionInProgress, metav1.ConditionTrue, "")}, "Building"),
+		Entry("ready", []v1alpha1.Condition{condition(v1alpha1.ConditionReady, metav1.ConditionTrue, "")}, "Ready"),
+		Entry("failed", []v1alpha1.Condition{condition(v1alpha1.ConditionFailed, metav1.ConditionTrue, "")}, "Failed"),
+		Entry("failing the validation", []v1alpha1.Condition{condition(v1alpha1.ConditionValidationFailed, metav1.ConditionTrue, "")}, "Failed"),
+		Entry("with vulnerabilities", []v1alpha1.Condition{condition(v1alpha1.ConditionVulnerabilitiesFound, metav1.ConditionTrue, "")}, "Failed"),
+	)
+
+	It("should print the conditions and the failure reasons", func() {
+		// given
+		osBuild := newOSBuild(
+			condition(v1alpha1.ConditionReady, metav1.ConditionFalse, ""),
+			condition(v1alpha1.ConditionValidationFailed, metav1.ConditionTrue, "package foo cannot be found"),
+		)
+		out := &bytes.Buffer{}
+
+		// when
+		osbuildctl.PrintOSBuild(out, osBuild)
+
+		// then
+		Expect(out.String()).To(Equal(`OSBuild:    edge-1
+Phase:      Failed
+Conditions:
+  Ready=False
+  ValidationFailed=True: package foo cannot be found
+Failure reasons:
+  ValidationFailed: package foo cannot be found
+`))
+	})
+
+	Context("following an OSBuild", func() {
+		var ctx context.Context
+
+		BeforeEach(func() {
+			ctx = context.Background()
+		})
+
+		It("should print the changes until the OSBuild is Ready", func() {
+			// given
+			inProgress := newOSBuild(
+				condition(v1alpha1.ConditionReady, metav1.ConditionFalse, ""),
+				condition(v1alpha1.ConditionInProgress, metav1.ConditionTrue, "compose started"),
+			)
+			ready := newOSBuild(
+				condition(v1alpha1.ConditionReady, metav1.ConditionTrue, "image built"),
+				condition(v1alpha1.ConditionInProgress, metav1.ConditionFalse, ""),
+			)
+			ready.Status.AccessUrl = "https://bucket.example.com/edge-1.tar"
+			kubeClient := &sequenceClient{osBuilds: []*v1alpha1.OSBuild{nil, inProgress, inProgress, ready}}
+			out := &bytes.Buffer{}
+
+			// when
+			osBuild, err := osbuildctl.FollowOSBuild(ctx, kubeClient, "edge", "edge-1", time.Millisecond, out)
+
+			// then
+			Expect(err).NotTo(HaveOccurred())
+			Expect(osBuild.Status.AccessUrl).To(Equal("https://bucket.example.com/edge-1.tar"))
+			lines := out.String()
+			Expect(lines).To(MatchRegexp(`(?m)^\S+ edge-1 Building
+  Ready=False
+  InProgress=True: compose started
+\S+ edge-1 Ready
+  Ready=True: image built
+  InProgress=False
+$`))
+		})
+
+		It("should return the failure reasons when the OSBuild Failed", func() {
+			// given
+			failed := newOSBuild(condition(v1alpha1.ConditionFailed, metav1.ConditionTrue, "compose failed"))
+			kubeClient := &sequenceClient{osBuilds: []*v1alpha1.OSBuild{failed}}
+
+			// when
+			_, err := osbuildctl.FollowOSBuild(ctx, kubeClient, "edge", "edge-1", time.Millisecond, &bytes.Buffer{})
+
+			// then
+			Expect(err).To(MatchError("the OSBuild edge-1 failed: Failed: compose failed"))
+		})
+
+		It("should stop when the context is done", func() {
+			// given
+			kubeClient := &sequenceClient{osBuilds: []*v1alpha1.OSBuild{newOSBuild()}}
+			ctx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+			defer cancel()
+
+			// when
+			_, err := osbuildctl.FollowOSBuild(ctx, kubeClient, "edge", "edge-1", time.Millisecond, &bytes.Buffer{})
+
+			// then
+			Expect(err).To(MatchError(context.DeadlineExceeded))
+		})
+	})
+})
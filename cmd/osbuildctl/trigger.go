@@ -0,0 +1,157 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/project-flotta/osbuild-operator/api/v1alpha1"
+	"github.com/project-flotta/osbuild-operator/restapi"
+)
+
+// TriggerCredentials authenticate the calls to the trigger API, either with the secret of the webhook trigger of the
+// OSBuildConfig or with a Kubernetes bearer token
+type TriggerCredentials struct {
+	Secret string
+	Token  string
+}
+
+// TriggerWithAnnotation requests a new build of the OSBuildConfig the way the trigger API does, by setting a new
+// trigger ID in its webhook annotation, and returns the trigger ID
+func TriggerWithAnnotation(ctx context.Context, kubeClient client.Client, namespace string, name string, overrides *v1alpha1.BuildOverrides) (string, error) {
+	osBuildConfig := &v1alpha1.OSBuildConfig{}
+	err := kubeClient.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, osBuildConfig)
+	if err != nil {
+		return "", fmt.Errorf("cannot get the OSBuildConfig %s: %w", name, err)
+	}
+
+	patch := client.MergeFrom(osBuildConfig.DeepCopy())
+	if osBuildConfig.Annotations == nil {
+		osBuildConfig.Annotations = map[string]string{}
+	}
+	osBuildConfig.Annotations[v1alpha1.WebHookTriggerAnnotationKey] = uuid.New().String()
+	if overrides == nil {
+		// the details of a previous call must not be recorded on this build
+		delete(osBuildConfig.Annotations, v1alpha1.WebHookTriggerDetailsAnnotationKey)
+	} else {
+		// the details are plain strings, marshaling them cannot fail
+		data, _ := json.Marshal(v1alpha1.WebHookTriggerDetails{TriggeredBy: v1alpha1.TriggeredByWebhook, Overrides: overrides})
+		osBuildConfig.Annotations[v1alpha1.WebHookTriggerDetailsAnnotationKey] = string(data)
+	}
+	err = kubeClient.Patch(ctx, osBuildConfig, patch)
+	if err != nil {
+		return "", fmt.Errorf("cannot annotate the OSBuildConfig %s: %w", name, err)
+	}
+
+	return osBuildConfig.Annotations[v1alpha1.WebHookTriggerAnnotationKey], nil
+}
+
+// FindTriggeredOSBuild returns the name of the OSBuild the OSBuildConfig controller created for the trigger ID, checked
+// every interval until it is created. The edge-installer OSBuild of the trigger follows its edge-container OSBuild, the
+// first one created is returned
+func FindTriggeredOSBuild(ctx context.Context, kubeClient client.Client, namespace string, triggerID string, interval time.Duration) (string, error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		osBuilds := &v1alpha1.OSBuildList{}
+		err := kubeClient.List(ctx, osBuilds, client.InNamespace(namespace))
+		if err != nil {
+			return "", fmt.Errorf("cannot list the OSBuilds: %w", err)
+		}
+		var triggered *v1alpha1.OSBuild
+		for i := range osBuilds.Items {
+			osBuild := &osBuilds.Items[i]
+			if osBuild.Annotations[v1alpha1.TriggerIDAnnotationKey] != triggerID {
+				continue
+			}
+			if triggered == nil || osBuild.CreationTimestamp.Before(&triggered.CreationTimestamp) {
+				triggered = osBuild
+			}
+		}
+		if triggered != nil {
+			return triggered.Name, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return "", fmt.Errorf("the OSBuild of the trigger %s was not created: %w", triggerID, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// TriggerWithAPI requests a new build of the OSBuildConfig through the trigger API of the operator, and returns the
+// name of the OSBuild to be built, or already being built for an identical trigger
+func TriggerWithAPI(ctx context.Context, apiClient restapi.ClientWithResponsesInterface, namespace string, name string, credentials TriggerCredentials, overrides *v1alpha1.BuildOverrides) (string, error) {
+	params := &restapi.TriggerBuildParams{}
+	if credentials.Token != "" {
+		authorization := "Bearer " + credentials.Token
+		params.Authorization = &authorization
+	} else {
+		params.Secret = &credentials.Secret
+	}
+
+	request := restapi.TriggerBuildJSONRequestBody{}
+	if overrides != nil {
+		if len(overrides.Packages) > 0 {
+			request.Packages = &overrides.Packages
+		}
+		if len(overrides.Parameters) > 0 {
+			parameters := make([]restapi.ParameterValue, len(overrides.Parameters))
+			for i, parameter := range overrides.Parameters {
+				parameters[i] = restapi.ParameterValue{Name: parameter.Name, Value: parameter.Value}
+			}
+			request.Parameters = &parameters
+		}
+	}
+
+	response, err := apiClient.TriggerBuildWithResponse(ctx, namespace, name, params, request)
+	if err != nil {
+		return "", fmt.Errorf("cannot call the trigger API: %w", err)
+	}
+	message := response.JSON200
+	if response.StatusCode() == http.StatusAlreadyReported {
+		message = response.JSON208
+	}
+	if message == nil {
+		return "", fmt.Errorf("the trigger API answered %s: %s", response.Status(), strings.TrimSpace(string(response.Body)))
+	}
+
+	var content struct {
+		OSBuild string `json:"osbuild"`
+	}
+	if message.Content != nil {
+		// the content was read from JSON, marshaling it back cannot fail
+		data, _ := json.Marshal(*message.Content)
+		_ = json.Unmarshal(data, &content)
+	}
+	if content.OSBuild == "" {
+		return "", fmt.Errorf("the trigger API did not return the OSBuild")
+	}
+	return content.OSBuild, nil
+}
+
+// parseBuildOverrides returns the overrides of the packages and of the name=value template parameters, nil when
+// there are none
+func parseBuildOverrides(packages []string, parameters []string) (*v1alpha1.BuildOverrides, error) {
+	if len(packages) == 0 && len(parameters) == 0 {
+		return nil, nil
+	}
+
+	overrides := &v1alpha1.BuildOverrides{Packages: packages}
+	for _, parameter := range parameters {
+		nameValue := strings.SplitN(parameter, "=", 2)
+		if len(nameValue) != 2 || nameValue[0] == "" {
+			return nil, fmt.Errorf("the parameter %s is not formatted as name=value", parameter)
+		}
+		overrides.Parameters = append(overrides.Parameters, v1alpha1.ParameterValue{Name: nameValue[0], Value: nameValue[1]})
+	}
+	return overrides, nil
+}
@@ -0,0 +1,221 @@
+package main_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/project-flotta/osbuild-operator/api/v1alpha1"
+	osbuildctl "github.com/project-flotta/osbuild-operator/cmd/osbuildctl"
+	"github.com/project-flotta/osbuild-operator/restapi"
+)
+
+var _ = Describe("Trigger", func() {
+	var ctx context.Context
+
+	BeforeEach(func() {
+		ctx = context.Background()
+	})
+
+	Context("with the annotation", func() {
+		var (
+			kubeClient    client.Client
+			osBuildConfig *v1alpha1.OSBuildConfig
+		)
+
+		BeforeEach(func() {
+			scheme := runtime.NewScheme()
+			Expect(v1alpha1.AddToScheme(scheme)).To(Succeed())
+			osBuildConfig = &v1alpha1.OSBuildConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "edge",
+					Namespace:   "edge",
+					Annotations: map[string]string{v1alpha1.WebHookTriggerDetailsAnnotationKey: `{"triggeredBy":"GitHub"}`},
+				},
+				Status: v1alpha1.OSBuildConfigStatus{LastVersion: pointer.Int(2)},
+			}
+			kubeClient = fake.NewClientBuilder().WithScheme(scheme).WithObjects(osBuildConfig).Build()
+		})
+
+		It("should set a new trigger ID and return it", func() {
+			// when
+			triggerID, err := osbuildctl.TriggerWithAnnotation(ctx, kubeClient, "edge", "edge", nil)
+
+			// then
+			Expect(err).NotTo(HaveOccurred())
+			Expect(triggerID).NotTo(BeEmpty())
+
+			updated := &v1alpha1.OSBuildConfig{}
+			Expect(kubeClient.Get(ctx, client.ObjectKeyFromObject(osBuildConfig), updated)).To(Succeed())
+			Expect(updated.Annotations).To(HaveKeyWithValue(v1alpha1.WebHookTriggerAnnotationKey, triggerID))
+			Expect(updated.Annotations).NotTo(HaveKey(v1alpha1.WebHookTriggerDetailsAnnotationKey))
+		})
+
+		It("should record the overrides of the build", func() {
+			// given
+			overrides := &v1alpha1.BuildOverrides{
+				Packages:   []string{"tmux"},
+				Parameters: []v1alpha1.ParameterValue{{Name: "release", Value: "9.1"}},
+			}
+
+			// when
+			_, err := osbuildctl.TriggerWithAnnotation(ctx, kubeClient, "edge", "edge", overrides)
+
+			// then
+			Expect(err).NotTo(HaveOccurred())
+			updated := &v1alpha1.OSBuildConfig{}
+			Expect(kubeClient.Get(ctx, client.ObjectKeyFromObject(osBuildConfig), updated)).To(Succeed())
+			details := v1alpha1.WebHookTriggerDetails{}
+			Expect(json.Unmarshal([]byte(updated.Annotations[v1alpha1.WebHookTriggerDetailsAnnotationKey]), &details)).To(Succeed())
+			Expect(details).To(Equal(v1alpha1.WebHookTriggerDetails{TriggeredBy: v1alpha1.TriggeredByWebhook, Overrides: overrides}))
+		})
+
+		It("should fail when the OSBuildConfig does not exist", func() {
+			// when
+			_, err := osbuildctl.TriggerWithAnnotation(ctx, kubeClient, "edge", "other", nil)
+
+			// then
+			Expect(err).To(MatchError(ContainSubstring("cannot get the OSBuildConfig other")))
+		})
+	})
+
+	Context("finding the OSBuild of a trigger", func() {
+		var scheme *runtime.Scheme
+
+		newOSBuild := func(name string, triggerID string, created time.Time) *v1alpha1.OSBuild {
+			return &v1alpha1.OSBuild{ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				Namespace:         "edge",
+				Annotations:       map[string]string{v1alpha1.TriggerIDAnnotationKey: triggerID},
+				CreationTimestamp: metav1.NewTime(created),
+			}}
+		}
+
+		BeforeEach(func() {
+			scheme = runtime.NewScheme()
+			Expect(v1alpha1.AddToScheme(scheme)).To(Succeed())
+		})
+
+		It("should return the first OSBuild created for the trigger ID", func() {
+			// given
+			now := time.Now().Truncate(time.Second)
+			kubeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+				newOSBuild("edge-3", "other", now.Add(-time.Hour)),
+				newOSBuild("edge-5", "trigger", now),
+				newOSBuild("edge-4", "trigger", now.Add(-time.Minute)),
+			).Build()
+
+			// when
+			osBuildName, err := osbuildctl.FindTriggeredOSBuild(ctx, kubeClient, "edge", "trigger", time.Millisecond)
+
+			// then
+			Expect(err).NotTo(HaveOccurred())
+			Expect(osBuildName).To(Equal("edge-4"))
+		})
+
+		It("should fail when the OSBuild is not created in time", func() {
+			// given
+			kubeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(newOSBuild("edge-3", "other", time.Now())).Build()
+			timeoutCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+			defer cancel()
+
+			// when
+			_, err := osbuildctl.FindTriggeredOSBuild(timeoutCtx, kubeClient, "edge", "trigger", time.Millisecond)
+
+			// then
+			Expect(err).To(MatchError(ContainSubstring("the OSBuild of the trigger trigger was not created")))
+		})
+	})
+
+	Context("with the HTTP API", func() {
+		var (
+			server     *httptest.Server
+			apiClient  restapi.ClientWithResponsesInterface
+			request    *http.Request
+			body       []byte
+			statusCode int
+			response   string
+		)
+
+		BeforeEach(func() {
+			statusCode = http.StatusOK
+			response = `{"directive":"build","message_id":"id","content":{"osbuild":"edge-3"}}`
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				request = r
+				body, _ = io.ReadAll(r.Body)
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(statusCode)
+				_, _ = w.Write([]byte(response))
+			}))
+			var err error
+			apiClient, err = restapi.NewClientWithResponses(server.URL)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			server.Close()
+		})
+
+		It("should call the trigger API with the secret and the overrides", func() {
+			// given
+			overrides := &v1alpha1.BuildOverrides{Packages: []string{"tmux"}}
+
+			// when
+			osBuildName, err := osbuildctl.TriggerWithAPI(ctx, apiClient, "edge", "edge", osbuildctl.TriggerCredentials{Secret: "secret"}, overrides)
+
+			// then
+			Expect(err).NotTo(HaveOccurred())
+			Expect(osBuildName).To(Equal("edge-3"))
+			Expect(request.URL.Path).To(Equal("/api/osbuild/v1/namespaces/edge/osbuildconfig/edge/webhooks"))
+			Expect(request.Header.Get("secret")).To(Equal("secret"))
+			Expect(request.Header.Get("Authorization")).To(BeEmpty())
+			Expect(body).To(MatchJSON(`{"packages":["tmux"]}`))
+		})
+
+		It("should call the trigger API with the bearer token", func() {
+			// when
+			_, err := osbuildctl.TriggerWithAPI(ctx, apiClient, "edge", "edge", osbuildctl.TriggerCredentials{Token: "token"}, nil)
+
+			// then
+			Expect(err).NotTo(HaveOccurred())
+			Expect(request.Header.Get("Authorization")).To(Equal("Bearer token"))
+			Expect(request.Header.Get("secret")).To(BeEmpty())
+		})
+
+		It("should return the OSBuild already being built for an identical trigger", func() {
+			// given
+			statusCode = http.StatusAlreadyReported
+			response = `{"directive":"in-progress","message_id":"id","content":{"osbuild":"edge-2"}}`
+
+			// when
+			osBuildName, err := osbuildctl.TriggerWithAPI(ctx, apiClient, "edge", "edge", osbuildctl.TriggerCredentials{Secret: "secret"}, nil)
+
+			// then
+			Expect(err).NotTo(HaveOccurred())
+			Expect(osBuildName).To(Equal("edge-2"))
+		})
+
+		It("should fail when the trigger API rejects the call", func() {
+			// given
+			statusCode = http.StatusForbidden
+			response = ""
+
+			// when
+			_, err := osbuildctl.TriggerWithAPI(ctx, apiClient, "edge", "edge", osbuildctl.TriggerCredentials{Secret: "wrong"}, nil)
+
+			// then
+			Expect(err).To(MatchError(ContainSubstring("403 Forbidden")))
+		})
+	})
+})
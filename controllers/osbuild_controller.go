@@ -22,22 +22,36 @@ package controllers
 import (
 	"context"
 	"encoding/json"
+	goerrors "errors"
 	"fmt"
 	"net/http"
+	"path"
+	"reflect"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/go-logr/logr"
+	"github.com/google/uuid"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
 	osbuildv1alpha1 "github.com/project-flotta/osbuild-operator/api/v1alpha1"
 	"github.com/project-flotta/osbuild-operator/internal/composer"
+	"github.com/project-flotta/osbuild-operator/internal/conf"
+	"github.com/project-flotta/osbuild-operator/internal/diff"
+	"github.com/project-flotta/osbuild-operator/internal/repository/configmap"
 	repositoryosbuild "github.com/project-flotta/osbuild-operator/internal/repository/osbuild"
+	"github.com/project-flotta/osbuild-operator/internal/repository/secret"
+	"github.com/project-flotta/osbuild-operator/internal/rpmmd"
+	"github.com/project-flotta/osbuild-operator/internal/vulnerability"
 )
 
 var (
@@ -51,12 +65,31 @@ var (
 const (
 	// Conditions Messages
 	failedToSendPostRequestMsg = "Failed to post a new composer build request"
+	failedToGetSubscriptionMsg = "Failed to read the subscription secret"
+	failedToGetFilesMsg        = "Failed to read the content of the embedded files"
+	failedToGetUsersMsg        = "Failed to read the credentials of the users"
 	buildJobFinishedMsg        = "Build job was finished successfully"
 	buildJobFailedMsg          = "Build job was failed"
 	buildJobStillRunningMsg    = "Build job is still running"
+	missingPackagesMsg         = "Packages were not found in the repositories: %s"
+	validationSkippedMsg       = "The packages were not validated: %s"
+	vulnerabilitiesFoundMsg    = "Vulnerabilities violating the policy were found: %s"
+	vulnerabilityScanFailedMsg = "The vulnerabilities could not be scanned: %s"
 
-	EmptyComposeID = ""
-	emptyURL       = ""
+	EmptyComposeID    = ""
+	emptyURL          = ""
+	emptyOSTreeCommit = ""
+
+	// Subscription secret keys and defaults
+	SubscriptionOrganizationKey  = "organization"
+	SubscriptionActivationKeyKey = "activation_key"
+	defaultSubscriptionServerUrl = "subscription.rhsm.redhat.com"
+	defaultSubscriptionBaseUrl   = "https://cdn.redhat.com/"
+
+	systemdUnitsDir = "/etc/systemd/system"
+
+	diffConfigMapNameFormat = "%s-diff"
+	diffReportKey           = "report.json"
 
 	RequeueForLongDuration  = time.Minute * 2
 	RequeueForShortDuration = time.Second * 10
@@ -64,14 +97,19 @@ const (
 
 // OSBuildReconciler reconciles a OSBuild object
 type OSBuildReconciler struct {
-	Scheme            *runtime.Scheme
-	OSBuildRepository repositoryosbuild.Repository
-	ComposerClient    composer.ClientWithResponsesInterface
+	Scheme              *runtime.Scheme
+	OSBuildRepository   repositoryosbuild.Repository
+	SecretRepository    secret.Repository
+	ConfigMapRepository configmap.Repository
+	ComposerClient      composer.ClientWithResponsesInterface
+	PackageResolver     rpmmd.PackageResolver
+	EventRecorder       record.EventRecorder
 }
 
 //+kubebuilder:rbac:groups=osbuilder.project-flotta.io,resources=osbuilds,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=osbuilder.project-flotta.io,resources=osbuilds/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=osbuilder.project-flotta.io,resources=osbuilds/finalizers,verbs=update
+//+kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -101,6 +139,11 @@ func (r *OSBuildReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 		return ctrl.Result{}, nil
 	}
 
+	if isOSBuildConditionTrue(osBuild, osbuildv1alpha1.ConditionValidationFailed) {
+		logger.Info("the packages of the OSBuild failed the validation")
+		return ctrl.Result{}, nil
+	}
+
 	if osBuild.Status.ComposeId == EmptyComposeID {
 		// if the image wasn't created yet - schedule a new build
 		logger.Info("create a new image")
@@ -142,6 +185,10 @@ func (r *OSBuildReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 		logger.Info(fmt.Sprintf("the job ID %s, Finished", osBuild.Status.ComposeId))
 		return ctrl.Result{}, nil
 
+	case osbuildv1alpha1.ConditionVulnerabilitiesFound:
+		logger.Info(fmt.Sprintf("the job ID %s, Finished with vulnerabilities violating the policy", osBuild.Status.ComposeId))
+		return ctrl.Result{}, nil
+
 	default:
 		logger.Error(fmt.Errorf("failed to parse condition status"), "")
 		return ctrl.Result{Requeue: true, RequeueAfter: RequeueForLongDuration}, nil
@@ -229,15 +276,31 @@ func (r *OSBuildReconciler) updateOSBuildConditionStatus(ctx context.Context, lo
 
 	if composeStatus == composer.ComposeStatusValueSuccess {
 		// TODO: in case the target image type is edge-installer - do nothing
-		return r.updateOSBuildStatus(ctx, logger, osBuild, buildJobFinishedMsg, osbuildv1alpha1.ConditionReady, EmptyComposeID, accessUrl)
+		metadata, err := r.getComposeMetadata(ctx, logger, osBuild.Status.ComposeId)
+		if err != nil {
+			return err
+		}
+		osTreeCommit := getOSTreeCommit(logger, osBuild, metadata)
+		r.reportDiff(ctx, logger, osBuild, metadata)
+
+		conditionType, msg := osbuildv1alpha1.ConditionReady, buildJobFinishedMsg
+		scan := osBuild.Spec.VulnerabilityScan
+		summary := r.scanVulnerabilities(ctx, logger, osBuild, metadata)
+		if vulnerability.IsPolicyViolated(scan, summary) && vulnerability.GetPolicyAction(scan.Policy) == osbuildv1alpha1.VulnerabilityPolicyFailBuild {
+			conditionType, msg = osbuildv1alpha1.ConditionVulnerabilitiesFound, fmt.Sprintf(vulnerabilitiesFoundMsg, strings.Join(summary.PolicyViolations, ", "))
+			if summary.Error != "" {
+				msg = fmt.Sprintf(vulnerabilityScanFailedMsg, summary.Error)
+			}
+		}
+		return r.updateOSBuildStatus(ctx, logger, osBuild, msg, conditionType, EmptyComposeID, accessUrl, osTreeCommit)
 	}
 
 	if composeStatus == composer.ComposeStatusValueFailure {
-		return r.updateOSBuildStatus(ctx, logger, osBuild, buildJobFailedMsg, osbuildv1alpha1.ConditionFailed, EmptyComposeID, accessUrl)
+		return r.updateOSBuildStatus(ctx, logger, osBuild, buildJobFailedMsg, osbuildv1alpha1.ConditionFailed, EmptyComposeID, accessUrl, emptyOSTreeCommit)
 	}
 
 	if composeStatus == composer.ComposeStatusValuePending {
-		return r.updateOSBuildStatus(ctx, logger, osBuild, buildJobStillRunningMsg, osbuildv1alpha1.ConditionInProgress, EmptyComposeID, accessUrl)
+		return r.updateOSBuildStatus(ctx, logger, osBuild, buildJobStillRunningMsg, osbuildv1alpha1.ConditionInProgress, EmptyComposeID, accessUrl, emptyOSTreeCommit)
 	}
 
 	return nil
@@ -245,6 +308,32 @@ func (r *OSBuildReconciler) updateOSBuildConditionStatus(ctx context.Context, lo
 
 func (r *OSBuildReconciler) postComposeNewImage(ctx context.Context, logger logr.Logger, osBuild *osbuildv1alpha1.OSBuild) (ctrl.Result, error) {
 	customizations := r.createCustomizations(osBuild.Spec.Details.Customizations)
+	customizations, failureMsg, err := r.addReferencedCustomizations(ctx, osBuild.Namespace, osBuild.Spec.Details.Customizations, customizations)
+	if err != nil {
+		logger.Error(err, "failed to read the resources referenced by the customizations")
+
+		errUpdating := r.updateOSBuildStatus(ctx, logger, osBuild, failureMsg, osbuildv1alpha1.ConditionFailed, EmptyComposeID, emptyURL, emptyOSTreeCommit)
+		if errUpdating != nil {
+			logger.Error(errUpdating, "failed to update OSBuild condition status")
+		}
+		return ctrl.Result{Requeue: true, RequeueAfter: RequeueForLongDuration}, nil
+	}
+
+	missingPackages, err := r.findMissingPackages(ctx, logger, osBuild)
+	if err != nil {
+		// requeue with the backoff of the controller
+		return ctrl.Result{}, err
+	}
+	if len(missingPackages) > 0 {
+		errUpdating := r.updateOSBuildStatus(ctx, logger, osBuild, fmt.Sprintf(missingPackagesMsg, strings.Join(missingPackages, ", ")),
+			osbuildv1alpha1.ConditionValidationFailed, EmptyComposeID, emptyURL, emptyOSTreeCommit)
+		if errUpdating != nil {
+			logger.Error(errUpdating, "failed to update OSBuild condition status")
+			return ctrl.Result{Requeue: true, RequeueAfter: RequeueForShortDuration}, nil
+		}
+		return ctrl.Result{}, nil
+	}
+
 	imageRequest, err := r.createImageRequest(osBuild, osBuild.Spec.Details.TargetImage.TargetImageType)
 	if err != nil {
 		logger.Error(err, "failed to create an image request")
@@ -262,7 +351,7 @@ func (r *OSBuildReconciler) postComposeNewImage(ctx context.Context, logger logr
 	if err != nil {
 		logger.Error(err, "failed to post a new request")
 
-		errUpdating := r.updateOSBuildStatus(ctx, logger, osBuild, failedToSendPostRequestMsg, osbuildv1alpha1.ConditionFailed, EmptyComposeID, emptyURL)
+		errUpdating := r.updateOSBuildStatus(ctx, logger, osBuild, failedToSendPostRequestMsg, osbuildv1alpha1.ConditionFailed, EmptyComposeID, emptyURL, emptyOSTreeCommit)
 		if errUpdating != nil {
 			logger.Error(errUpdating, "failed to update OSBuild condition status")
 		}
@@ -276,7 +365,7 @@ func (r *OSBuildReconciler) postComposeNewImage(ctx context.Context, logger logr
 		err = fmt.Errorf(errorMsg)
 		logger.Error(err, "postCompose request failed")
 
-		errUpdating := r.updateOSBuildStatus(ctx, logger, osBuild, errorMsg, osbuildv1alpha1.ConditionFailed, EmptyComposeID, emptyURL)
+		errUpdating := r.updateOSBuildStatus(ctx, logger, osBuild, errorMsg, osbuildv1alpha1.ConditionFailed, EmptyComposeID, emptyURL, emptyOSTreeCommit)
 		if errUpdating != nil {
 			logger.Error(errUpdating, "failed to update OSBuild condition status")
 		}
@@ -288,7 +377,7 @@ func (r *OSBuildReconciler) postComposeNewImage(ctx context.Context, logger logr
 	composeId := composerResponse.JSON201.Id.String()
 	logger.Info("postComposer request was sent and trigger a new compose ID ", "container compose ID: ", composeId)
 
-	err = r.updateOSBuildStatus(ctx, logger, osBuild, buildJobStillRunningMsg, osbuildv1alpha1.ConditionInProgress, composeId, emptyURL)
+	err = r.updateOSBuildStatus(ctx, logger, osBuild, buildJobStillRunningMsg, osbuildv1alpha1.ConditionInProgress, composeId, emptyURL, emptyOSTreeCommit)
 	if err != nil {
 		logger.Error(err, "failed to create an image")
 		return ctrl.Result{Requeue: true, RequeueAfter: RequeueForLongDuration}, nil
@@ -298,8 +387,37 @@ func (r *OSBuildReconciler) postComposeNewImage(ctx context.Context, logger logr
 	return ctrl.Result{Requeue: true, RequeueAfter: RequeueForLongDuration}, nil
 }
 
+// findMissingPackages resolves the packages of the customizations against the repositories of the build. When the
+// metadata of the repositories cannot be loaded, an error is returned so that the validation is retried, until the
+// OSBuild is older than the retry period. The validation is then skipped, as it is right away when the repositories
+// cannot be validated at all, and a warning event tells so
+func (r *OSBuildReconciler) findMissingPackages(ctx context.Context, logger logr.Logger, osBuild *osbuildv1alpha1.OSBuild) ([]string, error) {
+	customizations := osBuild.Spec.Details.Customizations
+	if r.PackageResolver == nil || customizations == nil || len(customizations.Packages) == 0 {
+		return nil, nil
+	}
+
+	var repositories []osbuildv1alpha1.Repository
+	if osBuild.Spec.Details.TargetImage.Repositories != nil {
+		repositories = append(repositories, *osBuild.Spec.Details.TargetImage.Repositories...)
+	}
+	repositories = append(repositories, customizations.PayloadRepositories...)
+
+	missingPackages, err := r.PackageResolver.FindMissingPackages(ctx, repositories, osBuild.Spec.Details.TargetImage.Architecture, customizations.Packages)
+	if err != nil {
+		if !goerrors.Is(err, rpmmd.ErrValidationNotSupported) && time.Since(osBuild.CreationTimestamp.Time) < conf.GlobalConf.PackageValidationRetryPeriod {
+			logger.Error(err, "failed to resolve the packages, retrying their validation")
+			return nil, err
+		}
+		logger.Error(err, "failed to resolve the packages, skipping their validation")
+		r.EventRecorder.Eventf(osBuild, corev1.EventTypeWarning, "ValidationSkipped", validationSkippedMsg, err.Error())
+		return nil, nil
+	}
+	return missingPackages, nil
+}
+
 func (r *OSBuildReconciler) updateOSBuildStatus(ctx context.Context, logger logr.Logger, osBuild *osbuildv1alpha1.OSBuild,
-	msg string, newConditionStatus osbuildv1alpha1.ConditionType, composeId string, accessUrl string) error {
+	msg string, newConditionStatus osbuildv1alpha1.ConditionType, composeId string, accessUrl string, osTreeCommit string) error {
 	patch := client.MergeFrom(osBuild.DeepCopy())
 	if composeId != EmptyComposeID {
 		osBuild.Status.ComposeId = composeId
@@ -309,13 +427,19 @@ func (r *OSBuildReconciler) updateOSBuildStatus(ctx context.Context, logger logr
 		osBuild.Status.AccessUrl = accessUrl
 	}
 
+	if osTreeCommit != emptyOSTreeCommit {
+		osBuild.Status.OSTreeCommit = osTreeCommit
+	}
+
 	if osBuild.Status.Conditions == nil {
 		r.initConditionArray(ctx, logger, osBuild)
 	}
 
 	conditionsArr := osBuild.Status.Conditions
+	found := false
 	for i := range conditionsArr {
 		if conditionsArr[i].Type == newConditionStatus {
+			found = true
 			if conditionsArr[i].Status != metav1.ConditionTrue {
 				conditionsArr[i].Status = metav1.ConditionTrue
 				conditionsArr[i].Message = &msg
@@ -327,6 +451,14 @@ func (r *OSBuildReconciler) updateOSBuildStatus(ctx context.Context, logger logr
 			conditionsArr[i].Status = metav1.ConditionFalse
 		}
 	}
+	if !found {
+		osBuild.Status.Conditions = append(conditionsArr, osbuildv1alpha1.Condition{
+			Type:               newConditionStatus,
+			Status:             metav1.ConditionTrue,
+			Message:            &msg,
+			LastTransitionTime: &metav1.Time{Time: time.Now()},
+		})
+	}
 
 	errPatch := r.OSBuildRepository.PatchStatus(ctx, osBuild, &patch)
 	if errPatch != nil {
@@ -352,6 +484,195 @@ func (r *OSBuildReconciler) getComposeIDStatus(ctx context.Context, logger logr.
 	return nil, fmt.Errorf("something went wrong with requesting the composeID %v", composerResponse.StatusCode())
 }
 
+// getComposeMetadata returns the metadata of a finished compose, i.e. its OSTree commit and its packages
+func (r *OSBuildReconciler) getComposeMetadata(ctx context.Context, logger logr.Logger, composeId string) (*composer.ComposeMetadata, error) {
+	composeID, err := uuid.Parse(composeId)
+	if err != nil {
+		logger.Error(err, fmt.Sprintf("failed to parse compose ID %s", composeId))
+		return nil, err
+	}
+
+	composerResponse, err := r.ComposerClient.GetComposeMetadataWithResponse(ctx, composeID)
+	if err != nil {
+		logger.Error(err, fmt.Sprintf("failed to get compose ID %s metadata", composeId))
+		return nil, err
+	}
+
+	if composerResponse.JSON200 == nil {
+		return nil, fmt.Errorf("something went wrong with requesting the metadata of composeID %v", composerResponse.StatusCode())
+	}
+	return composerResponse.JSON200, nil
+}
+
+// getOSTreeCommit returns the ID of the OSTree commit built by a finished compose of an edge-container
+func getOSTreeCommit(logger logr.Logger, osBuild *osbuildv1alpha1.OSBuild, metadata *composer.ComposeMetadata) string {
+	if osBuild.Spec.Details.TargetImage.TargetImageType != osbuildv1alpha1.EdgeContainerImageType {
+		return emptyOSTreeCommit
+	}
+
+	if metadata.OstreeCommit == nil {
+		logger.Info(fmt.Sprintf("the metadata of compose ID %s has no OSTree commit", osBuild.Status.ComposeId))
+		return emptyOSTreeCommit
+	}
+	return *metadata.OstreeCommit
+}
+
+// reportDiff compares the packages and the user configuration of a finished OSBuild with the previous Ready OSBuild
+// of its OSBuildConfig. The report is stored in a ConfigMap owned by the OSBuild and summarized in its status.
+// Failures are only logged, as the report must not hold the build back.
+func (r *OSBuildReconciler) reportDiff(ctx context.Context, logger logr.Logger, osBuild *osbuildv1alpha1.OSBuild, metadata *composer.ComposeMetadata) {
+	previousOSBuild, err := r.getPreviousOSBuild(ctx, osBuild)
+	if err != nil {
+		logger.Error(err, "failed to find the previous OSBuild, skipping the diff report")
+		return
+	}
+
+	var previousOSBuildName string
+	var previousPackages []composer.PackageMetadata
+	var previousUserConfiguration *osbuildv1alpha1.UserConfiguration
+	if previousOSBuild != nil {
+		previousMetadata, err := r.getComposeMetadata(ctx, logger, previousOSBuild.Status.ComposeId)
+		if err != nil {
+			logger.Error(err, "failed to get the metadata of the previous OSBuild, skipping the diff report", "previous", previousOSBuild.Name)
+			return
+		}
+		previousOSBuildName = previousOSBuild.Name
+		previousPackages = getPackages(previousMetadata)
+		previousUserConfiguration = previousOSBuild.Spec.UserConfiguration
+	}
+
+	report := diff.NewReport(osBuild.Name, previousOSBuildName, previousPackages, getPackages(metadata),
+		previousUserConfiguration, osBuild.Spec.UserConfiguration)
+	configMapName := fmt.Sprintf(diffConfigMapNameFormat, osBuild.Name)
+	err = r.createOrUpdateDiffConfigMap(ctx, osBuild, configMapName, report)
+	if err != nil {
+		logger.Error(err, "failed to store the diff report")
+		return
+	}
+
+	patch := client.MergeFrom(osBuild.DeepCopy())
+	osBuild.Status.Diff = report.Summary(configMapName)
+	err = r.OSBuildRepository.PatchStatus(ctx, osBuild, &patch)
+	if err != nil {
+		logger.Error(err, "failed to patch the diff summary of the OSBuild")
+	}
+}
+
+// scanVulnerabilities matches the packages of a finished OSBuild against the feed of its vulnerability scan and sets
+// the summary in its status. A feed that cannot be loaded is recorded as the error of the summary.
+func (r *OSBuildReconciler) scanVulnerabilities(ctx context.Context, logger logr.Logger, osBuild *osbuildv1alpha1.OSBuild, metadata *composer.ComposeMetadata) *osbuildv1alpha1.VulnerabilitySummary {
+	scan := osBuild.Spec.VulnerabilityScan
+	if scan == nil {
+		return nil
+	}
+
+	var summary *osbuildv1alpha1.VulnerabilitySummary
+	entries, err := vulnerability.LoadFeed(ctx, r.ConfigMapRepository, osBuild.Namespace, scan.Feed, conf.GlobalConf.VulnerabilityFeedsDir)
+	if err != nil {
+		logger.Error(err, "failed to load the vulnerability feed")
+		summary = &osbuildv1alpha1.VulnerabilitySummary{Error: err.Error()}
+	} else {
+		summary = vulnerability.Summarize(vulnerability.Scan(entries, getPackages(metadata)), scan.Policy)
+	}
+	summary.ScanTime = metav1.Now()
+
+	patch := client.MergeFrom(osBuild.DeepCopy())
+	osBuild.Status.Vulnerabilities = summary
+	err = r.OSBuildRepository.PatchStatus(ctx, osBuild, &patch)
+	if err != nil {
+		logger.Error(err, "failed to patch the vulnerabilities summary of the OSBuild")
+	}
+	return summary
+}
+
+// getPreviousOSBuild returns the Ready OSBuild with the highest version lower than the OSBuild's among the OSBuilds of
+// the same OSBuildConfig and target image type
+func (r *OSBuildReconciler) getPreviousOSBuild(ctx context.Context, osBuild *osbuildv1alpha1.OSBuild) (*osbuildv1alpha1.OSBuild, error) {
+	owner := metav1.GetControllerOf(osBuild)
+	if owner == nil || owner.Kind != "OSBuildConfig" {
+		return nil, nil
+	}
+	version, ok := getOSBuildVersion(owner.Name, osBuild.Name)
+	if !ok {
+		return nil, nil
+	}
+
+	osBuilds, err := r.OSBuildRepository.ListByOSBuildConfig(ctx, owner.Name, osBuild.Namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	var previousOSBuild *osbuildv1alpha1.OSBuild
+	previousVersion := 0
+	for i := range osBuilds {
+		candidate := &osBuilds[i]
+		candidateVersion, ok := getOSBuildVersion(owner.Name, candidate.Name)
+		if !ok || candidateVersion >= version || candidateVersion <= previousVersion {
+			continue
+		}
+		if candidate.Spec.Details == nil || candidate.Spec.Details.TargetImage.TargetImageType != osBuild.Spec.Details.TargetImage.TargetImageType ||
+			candidate.Status.ComposeId == EmptyComposeID || !candidate.IsReady() {
+			continue
+		}
+		previousOSBuild, previousVersion = candidate, candidateVersion
+	}
+	return previousOSBuild, nil
+}
+
+// getOSBuildVersion returns the version of an OSBuild named after its OSBuildConfig
+func getOSBuildVersion(osBuildConfigName string, osBuildName string) (int, bool) {
+	if !strings.HasPrefix(osBuildName, osBuildConfigName+"-") {
+		return 0, false
+	}
+	version, err := strconv.Atoi(strings.TrimPrefix(osBuildName, osBuildConfigName+"-"))
+	if err != nil {
+		return 0, false
+	}
+	return version, true
+}
+
+func getPackages(metadata *composer.ComposeMetadata) []composer.PackageMetadata {
+	if metadata.Packages == nil {
+		return nil
+	}
+	return *metadata.Packages
+}
+
+func (r *OSBuildReconciler) createOrUpdateDiffConfigMap(ctx context.Context, osBuild *osbuildv1alpha1.OSBuild, name string, report *diff.Report) error {
+	reportJson, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: osBuild.Namespace,
+		},
+		Data: map[string]string{
+			diffReportKey: string(reportJson),
+		},
+	}
+	err = controllerutil.SetControllerReference(osBuild, configMap, r.Scheme)
+	if err != nil {
+		return err
+	}
+
+	err = r.ConfigMapRepository.Create(ctx, configMap)
+	if !errors.IsAlreadyExists(err) {
+		return err
+	}
+
+	// the report of a previous attempt to finish the OSBuild
+	existingConfigMap, err := r.ConfigMapRepository.Read(ctx, name, osBuild.Namespace)
+	if err != nil {
+		return err
+	}
+	updatedConfigMap := existingConfigMap.DeepCopy()
+	updatedConfigMap.Data = configMap.Data
+	return r.ConfigMapRepository.Patch(ctx, existingConfigMap, updatedConfigMap)
+}
+
 func (r *OSBuildReconciler) createImageRequest(osBuild *osbuildv1alpha1.OSBuild, targetImageType osbuildv1alpha1.TargetImageType) (*composer.ImageRequest, error) {
 	uploadOptions, err := r.getUploadOptions(osBuild, targetImageType)
 	if err != nil {
@@ -404,14 +725,13 @@ func (r *OSBuildReconciler) createCustomizations(osbuildCustomizations *osbuildv
 
 	customizationIsEmpty := true
 	composerCustomizations := composer.Customizations{}
-	if osbuildCustomizations.Users != nil && len(osbuildCustomizations.Users) > 0 {
-		var users []composer.User
-		for _, cstmzUser := range osbuildCustomizations.Users {
-			user := cstmzUser.DeepCopy()
-			users = append(users, (composer.User)(*user))
+	if len(osbuildCustomizations.Groups) > 0 {
+		var groups []composer.Group
+		for _, group := range osbuildCustomizations.Groups {
+			groups = append(groups, composer.Group{Name: group.Name, Gid: group.GID})
 		}
 		customizationIsEmpty = false
-		composerCustomizations.Users = &users
+		composerCustomizations.Groups = &groups
 	}
 
 	if osbuildCustomizations.Services != nil {
@@ -436,6 +756,87 @@ func (r *OSBuildReconciler) createCustomizations(osbuildCustomizations *osbuildv
 		composerCustomizations.Packages = &osbuildCustomizations.Packages
 	}
 
+	if len(osbuildCustomizations.PayloadRepositories) > 0 {
+		var repos []composer.Repository
+		for _, osbuildRepo := range osbuildCustomizations.PayloadRepositories {
+			composerRepo := osbuildRepo.DeepCopy()
+			repos = append(repos, (composer.Repository)(*composerRepo))
+		}
+		customizationIsEmpty = false
+		composerCustomizations.PayloadRepositories = &repos
+	}
+
+	if osbuildCustomizations.Hostname != nil {
+		customizationIsEmpty = false
+		composerCustomizations.Hostname = osbuildCustomizations.Hostname
+	}
+
+	if osbuildCustomizations.Kernel != nil {
+		customizationIsEmpty = false
+		kernel := composer.Kernel{Name: osbuildCustomizations.Kernel.Name}
+		if len(osbuildCustomizations.Kernel.Append) > 0 {
+			kernelArgs := strings.Join(osbuildCustomizations.Kernel.Append, " ")
+			kernel.Append = &kernelArgs
+		}
+		composerCustomizations.Kernel = &kernel
+	}
+
+	if osbuildCustomizations.Timezone != nil {
+		customizationIsEmpty = false
+		timezone := composer.Timezone{Timezone: osbuildCustomizations.Timezone.Timezone}
+		if len(osbuildCustomizations.Timezone.NTPServers) > 0 {
+			timezone.Ntpservers = &osbuildCustomizations.Timezone.NTPServers
+		}
+		composerCustomizations.Timezone = &timezone
+	}
+
+	if osbuildCustomizations.Locale != nil {
+		customizationIsEmpty = false
+		locale := composer.Locale{Keyboard: osbuildCustomizations.Locale.Keyboard}
+		if len(osbuildCustomizations.Locale.Languages) > 0 {
+			locale.Languages = &osbuildCustomizations.Locale.Languages
+		}
+		composerCustomizations.Locale = &locale
+	}
+
+	if osbuildCustomizations.Firewall != nil {
+		customizationIsEmpty = false
+		firewall := composer.FirewallCustomization{}
+		if len(osbuildCustomizations.Firewall.Ports) > 0 {
+			firewall.Ports = &osbuildCustomizations.Firewall.Ports
+		}
+		if osbuildCustomizations.Firewall.Services != nil {
+			var services struct {
+				Disabled *[]string `json:"disabled,omitempty"`
+				Enabled  *[]string `json:"enabled,omitempty"`
+			}
+			if len(osbuildCustomizations.Firewall.Services.Enabled) > 0 {
+				services.Enabled = &osbuildCustomizations.Firewall.Services.Enabled
+			}
+			if len(osbuildCustomizations.Firewall.Services.Disabled) > 0 {
+				services.Disabled = &osbuildCustomizations.Firewall.Services.Disabled
+			}
+			firewall.Services = &services
+		}
+		composerCustomizations.Firewall = &firewall
+	}
+
+	if len(osbuildCustomizations.Directories) > 0 {
+		customizationIsEmpty = false
+		var directories []composer.Directory
+		for _, directory := range osbuildCustomizations.Directories {
+			ensureParents := true
+			directories = append(directories, composer.Directory{
+				Path:          directory.Path,
+				Mode:          directory.Mode,
+				User:          directory.User,
+				Group:         directory.Group,
+				EnsureParents: &ensureParents,
+			})
+		}
+		composerCustomizations.Directories = &directories
+	}
+
 	if customizationIsEmpty {
 		return nil
 	}
@@ -443,6 +844,207 @@ func (r *OSBuildReconciler) createCustomizations(osbuildCustomizations *osbuildv
 	return &composerCustomizations
 }
 
+// addReferencedCustomizations adds to the composer customizations the ones whose content is held by Secrets
+// and ConfigMaps. In case of failure it returns the message of the Failed condition to set.
+func (r *OSBuildReconciler) addReferencedCustomizations(ctx context.Context, namespace string, osbuildCustomizations *osbuildv1alpha1.Customizations,
+	composerCustomizations *composer.Customizations) (*composer.Customizations, string, error) {
+	if osbuildCustomizations == nil {
+		return composerCustomizations, "", nil
+	}
+	if composerCustomizations == nil {
+		composerCustomizations = &composer.Customizations{}
+	}
+
+	if len(osbuildCustomizations.Users) > 0 {
+		users, err := r.getUsers(ctx, namespace, osbuildCustomizations.Users)
+		if err != nil {
+			return nil, failedToGetUsersMsg, err
+		}
+		composerCustomizations.Users = &users
+	}
+
+	if osbuildCustomizations.Subscription != nil {
+		subscription, err := r.getSubscription(ctx, namespace, osbuildCustomizations.Subscription)
+		if err != nil {
+			return nil, failedToGetSubscriptionMsg, err
+		}
+		composerCustomizations.Subscription = subscription
+	}
+
+	if len(osbuildCustomizations.Files) > 0 {
+		files, units, err := r.getFiles(ctx, namespace, osbuildCustomizations.Files)
+		if err != nil {
+			return nil, failedToGetFilesMsg, err
+		}
+		composerCustomizations.Files = &files
+		if len(units) > 0 {
+			if composerCustomizations.Services == nil {
+				composerCustomizations.Services = &struct {
+					Disabled *[]string `json:"disabled,omitempty"`
+					Enabled  *[]string `json:"enabled,omitempty"`
+				}{}
+			}
+			var enabled []string
+			if composerCustomizations.Services.Enabled != nil {
+				enabled = append(enabled, *composerCustomizations.Services.Enabled...)
+			}
+			enabled = append(enabled, units...)
+			composerCustomizations.Services.Enabled = &enabled
+		}
+	}
+
+	if reflect.DeepEqual(*composerCustomizations, composer.Customizations{}) {
+		return nil, "", nil
+	}
+	return composerCustomizations, "", nil
+}
+
+// getUsers reads the password hashes and the SSH keys of the users from their Secrets
+func (r *OSBuildReconciler) getUsers(ctx context.Context, namespace string, users []osbuildv1alpha1.User) ([]composer.User, error) {
+	var composerUsers []composer.User
+	for _, user := range users {
+		composerUser := composer.User{
+			Name:        user.Name,
+			Description: user.Description,
+			Uid:         user.UID,
+			Gid:         user.GID,
+			Home:        user.Home,
+			Shell:       user.Shell,
+		}
+		if user.Groups != nil {
+			groups := append([]string{}, *user.Groups...)
+			composerUser.Groups = &groups
+		}
+
+		if user.PasswordSecretKeyRef != nil {
+			password, err := r.readSecretKey(ctx, namespace, user.PasswordSecretKeyRef)
+			if err != nil {
+				return nil, err
+			}
+			password = strings.TrimSpace(password)
+			if !strings.HasPrefix(password, "$") {
+				return nil, fmt.Errorf("password of user %s is not a password hash", user.Name)
+			}
+			composerUser.Password = &password
+		}
+
+		var keys []string
+		if user.Key != nil {
+			keys = append(keys, *user.Key)
+		}
+		for i := range user.SSHKeysSecretKeyRefs {
+			key, err := r.readSecretKey(ctx, namespace, &user.SSHKeysSecretKeyRefs[i])
+			if err != nil {
+				return nil, err
+			}
+			keys = append(keys, strings.TrimSpace(key))
+		}
+		if len(keys) > 0 {
+			authorizedKeys := strings.Join(keys, "\n")
+			composerUser.Key = &authorizedKeys
+		}
+
+		composerUsers = append(composerUsers, composerUser)
+	}
+	return composerUsers, nil
+}
+
+func (r *OSBuildReconciler) readSecretKey(ctx context.Context, namespace string, keyRef *corev1.SecretKeySelector) (string, error) {
+	keySecret, err := r.SecretRepository.Read(ctx, keyRef.Name, namespace)
+	if err != nil {
+		return "", err
+	}
+	value, ok := keySecret.Data[keyRef.Key]
+	if !ok {
+		return "", fmt.Errorf("secret %s is missing the %s key", keyRef.Name, keyRef.Key)
+	}
+	return string(value), nil
+}
+
+// getFiles reads the content of the files from their ConfigMaps and Secrets.
+// It returns the files and the names of the systemd units to enable.
+func (r *OSBuildReconciler) getFiles(ctx context.Context, namespace string, files []osbuildv1alpha1.File) ([]composer.File, []string, error) {
+	var composerFiles []composer.File
+	var units []string
+	for _, file := range files {
+		if !path.IsAbs(file.Path) {
+			return nil, nil, fmt.Errorf("path of file %s is not absolute", file.Path)
+		}
+
+		var data string
+		switch {
+		case file.ConfigMapKeyRef != nil && file.SecretKeyRef == nil:
+			cm, err := r.ConfigMapRepository.Read(ctx, file.ConfigMapKeyRef.Name, namespace)
+			if err != nil {
+				return nil, nil, err
+			}
+			var ok bool
+			if data, ok = cm.Data[file.ConfigMapKeyRef.Key]; !ok {
+				return nil, nil, fmt.Errorf("configmap %s is missing the %s key", file.ConfigMapKeyRef.Name, file.ConfigMapKeyRef.Key)
+			}
+		case file.SecretKeyRef != nil && file.ConfigMapKeyRef == nil:
+			var err error
+			if data, err = r.readSecretKey(ctx, namespace, file.SecretKeyRef); err != nil {
+				return nil, nil, err
+			}
+		default:
+			return nil, nil, fmt.Errorf("exactly one of configMapKeyRef and secretKeyRef must be set for file %s", file.Path)
+		}
+
+		if file.EnableSystemdUnit {
+			if path.Dir(file.Path) != systemdUnitsDir {
+				return nil, nil, fmt.Errorf("systemd unit %s is not under %s", file.Path, systemdUnitsDir)
+			}
+			units = append(units, path.Base(file.Path))
+		}
+
+		ensureParents := true
+		composerFiles = append(composerFiles, composer.File{
+			Path:          file.Path,
+			Data:          &data,
+			Mode:          file.Mode,
+			User:          file.User,
+			Group:         file.Group,
+			EnsureParents: &ensureParents,
+		})
+	}
+	return composerFiles, units, nil
+}
+
+// getSubscription resolves the organization ID and the activation key of the subscription from its secret.
+// The returned value must never be logged or stored in the status of any resource.
+func (r *OSBuildReconciler) getSubscription(ctx context.Context, namespace string, subscription *osbuildv1alpha1.Subscription) (*composer.Subscription, error) {
+	subscriptionSecret, err := r.SecretRepository.Read(ctx, subscription.SecretRef.Name, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	organization, ok := subscriptionSecret.Data[SubscriptionOrganizationKey]
+	if !ok || len(organization) == 0 {
+		return nil, fmt.Errorf("secret %s is missing the %s key", subscription.SecretRef.Name, SubscriptionOrganizationKey)
+	}
+	activationKey, ok := subscriptionSecret.Data[SubscriptionActivationKeyKey]
+	if !ok || len(activationKey) == 0 {
+		return nil, fmt.Errorf("secret %s is missing the %s key", subscription.SecretRef.Name, SubscriptionActivationKeyKey)
+	}
+
+	composerSubscription := composer.Subscription{
+		Organization:  string(organization),
+		ActivationKey: string(activationKey),
+		ServerUrl:     subscription.ServerUrl,
+		BaseUrl:       subscription.BaseUrl,
+		Insights:      subscription.Insights,
+	}
+	if composerSubscription.ServerUrl == "" {
+		composerSubscription.ServerUrl = defaultSubscriptionServerUrl
+	}
+	if composerSubscription.BaseUrl == "" {
+		composerSubscription.BaseUrl = defaultSubscriptionBaseUrl
+	}
+
+	return &composerSubscription, nil
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *OSBuildReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
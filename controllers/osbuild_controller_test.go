@@ -4,24 +4,31 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"os"
 	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/google/uuid"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 
 	osbuildv1alpha1 "github.com/project-flotta/osbuild-operator/api/v1alpha1"
 	"github.com/project-flotta/osbuild-operator/controllers"
 	"github.com/project-flotta/osbuild-operator/internal/composer"
+	"github.com/project-flotta/osbuild-operator/internal/conf"
+	"github.com/project-flotta/osbuild-operator/internal/repository/configmap"
 	"github.com/project-flotta/osbuild-operator/internal/repository/osbuild"
+	"github.com/project-flotta/osbuild-operator/internal/repository/secret"
+	"github.com/project-flotta/osbuild-operator/internal/rpmmd"
 )
 
 var _ = Describe("OSBuild Controller", func() {
@@ -35,18 +42,24 @@ var _ = Describe("OSBuild Controller", func() {
 
 		// Conditions Messages
 		failedToSendPostRequestMsg = "Failed to post a new composer build request"
+		failedToGetSubscriptionMsg = "Failed to read the subscription secret"
+		failedToGetFilesMsg        = "Failed to read the content of the embedded files"
+		failedToGetUsersMsg        = "Failed to read the credentials of the users"
 		buildJobFinishedMsg        = "Build job was finished successfully"
 		buildJobFailedMsg          = "Build job was failed"
 		buildJobStillRunningMsg    = "Build job is still running"
+		missingPackagesMsg         = "Packages were not found in the repositories: pkg2"
 	)
 	var (
-		mockCtrl          *gomock.Controller
-		scheme            *runtime.Scheme
-		osBuildRepository *osbuild.MockRepository
-		composerClient    *composer.MockClientWithResponsesInterface
-		reconciler        *controllers.OSBuildReconciler
-		requestContext    context.Context
-		osbuildInstance   *osbuildv1alpha1.OSBuild
+		mockCtrl            *gomock.Controller
+		scheme              *runtime.Scheme
+		osBuildRepository   *osbuild.MockRepository
+		secretRepository    *secret.MockRepository
+		configMapRepository *configmap.MockRepository
+		composerClient      *composer.MockClientWithResponsesInterface
+		reconciler          *controllers.OSBuildReconciler
+		requestContext      context.Context
+		osbuildInstance     *osbuildv1alpha1.OSBuild
 
 		request = ctrl.Request{
 			NamespacedName: types.NamespacedName{
@@ -61,6 +74,7 @@ var _ = Describe("OSBuild Controller", func() {
 		composerGetStatusDone               composer.GetComposeStatusResponse
 		composerGetStatusPending            composer.GetComposeStatusResponse
 		composerGetStatusResponseBadRequest composer.GetComposeStatusResponse
+		composerGetMetadataDone             composer.GetComposeMetadataResponse
 
 		resultShortRequeue = ctrl.Result{Requeue: true, RequeueAfter: controllers.RequeueForShortDuration}
 		resultLongRequeue  = ctrl.Result{Requeue: true, RequeueAfter: controllers.RequeueForLongDuration}
@@ -85,11 +99,14 @@ var _ = Describe("OSBuild Controller", func() {
 		disabledServices = []string{"s1", "s2"}
 		enabledServices  = []string{"s3", "s4"}
 		zeroUuid         = "00000000-0000-0000-0000-000000000000"
+		osTreeCommit     = "02604b2da6e954bd34b8b82a835e5a77d2b60ffa"
 		buildUrl         = "http://test/test"
 	)
 	BeforeEach(func() {
 		mockCtrl = gomock.NewController(GinkgoT())
 		osBuildRepository = osbuild.NewMockRepository(mockCtrl)
+		secretRepository = secret.NewMockRepository(mockCtrl)
+		configMapRepository = configmap.NewMockRepository(mockCtrl)
 		composerClient = composer.NewMockClientWithResponsesInterface(mockCtrl)
 
 		scheme = runtime.NewScheme()
@@ -99,9 +116,11 @@ var _ = Describe("OSBuild Controller", func() {
 		Expect(err).To(BeNil())
 
 		reconciler = &controllers.OSBuildReconciler{
-			Scheme:            scheme,
-			OSBuildRepository: osBuildRepository,
-			ComposerClient:    composerClient,
+			Scheme:              scheme,
+			OSBuildRepository:   osBuildRepository,
+			SecretRepository:    secretRepository,
+			ConfigMapRepository: configMapRepository,
+			ComposerClient:      composerClient,
 		}
 
 		requestContext = context.TODO()
@@ -171,6 +190,16 @@ var _ = Describe("OSBuild Controller", func() {
 			},
 		}
 
+		composerGetMetadataDone = composer.GetComposeMetadataResponse{
+			HTTPResponse: &http.Response{
+				StatusCode: http.StatusOK,
+			},
+			JSON200: &composer.ComposeMetadata{
+				Id:           zeroUuid,
+				OstreeCommit: &osTreeCommit,
+			},
+		}
+
 		composerGetStatusDone = composer.GetComposeStatusResponse{
 			HTTPResponse: &http.Response{
 				StatusCode: http.StatusOK,
@@ -316,6 +345,374 @@ var _ = Describe("OSBuild Controller", func() {
 			Entry("target image type is guest-image (qcow2)", osbuildv1alpha1.GuestImageImageType),
 		)
 
+		It("should send the payload repositories as customizations only", func() {
+			// given
+			payloadRepoUrl := "https://payload"
+			osbuildInstance.Spec.Details.Customizations.PayloadRepositories = []osbuildv1alpha1.Repository{{Baseurl: &payloadRepoUrl}}
+			defer func() { osbuildInstance.Spec.Details.Customizations.PayloadRepositories = nil }()
+			composerClient.EXPECT().PostComposeWithResponse(requestContext, gomock.Any()).DoAndReturn(
+				func(ctx context.Context, body composer.PostComposeJSONRequestBody, reqEditors ...interface{}) (*composer.PostComposeResponse, error) {
+					Expect(*body.Customizations.PayloadRepositories).To(Equal([]composer.Repository{{Baseurl: &payloadRepoUrl}}))
+					Expect(body.ImageRequest.Repositories).To(BeEmpty())
+					return &composerPostResponseCreated, nil
+				},
+			)
+			// when
+			result, err := reconciler.Reconcile(requestContext, request)
+			// then
+			Expect(err).To(BeNil())
+			Expect(result).To(Equal(resultLongRequeue))
+		})
+
+		It("should send the OS-level customizations", func() {
+			// given
+			hostname, timezone := "edge", "UTC"
+			osbuildInstance.Spec.Details.Customizations.Hostname = &hostname
+			osbuildInstance.Spec.Details.Customizations.Kernel = &osbuildv1alpha1.Kernel{Append: []string{"nosmt=force", "quiet"}}
+			osbuildInstance.Spec.Details.Customizations.Timezone = &osbuildv1alpha1.Timezone{Timezone: &timezone}
+			osbuildInstance.Spec.Details.Customizations.Firewall = &osbuildv1alpha1.Firewall{Ports: []string{"22:tcp"}}
+			defer func() {
+				osbuildInstance.Spec.Details.Customizations.Hostname = nil
+				osbuildInstance.Spec.Details.Customizations.Kernel = nil
+				osbuildInstance.Spec.Details.Customizations.Timezone = nil
+				osbuildInstance.Spec.Details.Customizations.Firewall = nil
+			}()
+			composerClient.EXPECT().PostComposeWithResponse(requestContext, gomock.Any()).DoAndReturn(
+				func(ctx context.Context, body composer.PostComposeJSONRequestBody, reqEditors ...interface{}) (*composer.PostComposeResponse, error) {
+					Expect(*body.Customizations.Hostname).To(Equal(hostname))
+					Expect(*body.Customizations.Kernel.Append).To(Equal("nosmt=force quiet"))
+					Expect(*body.Customizations.Timezone.Timezone).To(Equal(timezone))
+					Expect(body.Customizations.Timezone.Ntpservers).To(BeNil())
+					Expect(*body.Customizations.Firewall.Ports).To(Equal([]string{"22:tcp"}))
+					Expect(body.Customizations.Locale).To(BeNil())
+					return &composerPostResponseCreated, nil
+				},
+			)
+			// when
+			result, err := reconciler.Reconcile(requestContext, request)
+			// then
+			Expect(err).To(BeNil())
+			Expect(result).To(Equal(resultLongRequeue))
+		})
+
+		Context("with embedded files", func() {
+			const (
+				configMapName = "files"
+				secretName    = "secret-files"
+			)
+
+			AfterEach(func() {
+				osbuildInstance.Spec.Details.Customizations.Files = nil
+			})
+
+			It("should send the files content and enable the systemd units", func() {
+				// given
+				osbuildInstance.Spec.Details.Customizations.Files = []osbuildv1alpha1.File{
+					{
+						Path:              "/etc/systemd/system/agent.service",
+						ConfigMapKeyRef:   &corev1.ConfigMapKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: configMapName}, Key: "agent.service"},
+						EnableSystemdUnit: true,
+					},
+					{
+						Path:         "/etc/agent/token",
+						SecretKeyRef: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: secretName}, Key: "token"},
+					},
+				}
+				configMapRepository.EXPECT().Read(requestContext, configMapName, instanceNamespace).Return(&corev1.ConfigMap{
+					Data: map[string]string{"agent.service": "[Unit]"},
+				}, nil)
+				secretRepository.EXPECT().Read(requestContext, secretName, instanceNamespace).Return(&corev1.Secret{
+					Data: map[string][]byte{"token": []byte("abc")},
+				}, nil)
+				composerClient.EXPECT().PostComposeWithResponse(requestContext, gomock.Any()).DoAndReturn(
+					func(ctx context.Context, body composer.PostComposeJSONRequestBody, reqEditors ...interface{}) (*composer.PostComposeResponse, error) {
+						files := *body.Customizations.Files
+						Expect(files).To(HaveLen(2))
+						Expect(files[0].Path).To(Equal("/etc/systemd/system/agent.service"))
+						Expect(*files[0].Data).To(Equal("[Unit]"))
+						Expect(files[1].Path).To(Equal("/etc/agent/token"))
+						Expect(*files[1].Data).To(Equal("abc"))
+						Expect(*body.Customizations.Services.Enabled).To(Equal(append(enabledServices, "agent.service")))
+						return &composerPostResponseCreated, nil
+					},
+				)
+				// when
+				result, err := reconciler.Reconcile(requestContext, request)
+				// then
+				Expect(err).To(BeNil())
+				Expect(result).To(Equal(resultLongRequeue))
+			})
+
+			It("should fail without posting when a systemd unit is not under the systemd directory", func() {
+				// given
+				osbuildInstance.Spec.Details.Customizations.Files = []osbuildv1alpha1.File{
+					{
+						Path:              "/etc/agent.service",
+						ConfigMapKeyRef:   &corev1.ConfigMapKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: configMapName}, Key: "agent.service"},
+						EnableSystemdUnit: true,
+					},
+				}
+				configMapRepository.EXPECT().Read(requestContext, configMapName, instanceNamespace).Return(&corev1.ConfigMap{
+					Data: map[string]string{"agent.service": "[Unit]"},
+				}, nil)
+				// when
+				result, err := reconciler.Reconcile(requestContext, request)
+				// then
+				Expect(err).To(BeNil())
+				Expect(result).To(Equal(resultLongRequeue))
+				checkConditionArr(osbuildv1alpha1.ConditionFailed, failedToGetFilesMsg, osbuildInstance.Status.Conditions)
+			})
+
+			It("should fail without posting when the key is missing", func() {
+				// given
+				osbuildInstance.Spec.Details.Customizations.Files = []osbuildv1alpha1.File{
+					{
+						Path:            "/etc/agent/config",
+						ConfigMapKeyRef: &corev1.ConfigMapKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: configMapName}, Key: "config"},
+					},
+				}
+				configMapRepository.EXPECT().Read(requestContext, configMapName, instanceNamespace).Return(&corev1.ConfigMap{}, nil)
+				// when
+				result, err := reconciler.Reconcile(requestContext, request)
+				// then
+				Expect(err).To(BeNil())
+				Expect(result).To(Equal(resultLongRequeue))
+				checkConditionArr(osbuildv1alpha1.ConditionFailed, failedToGetFilesMsg, osbuildInstance.Status.Conditions)
+			})
+		})
+
+		Context("with users credentials in secrets", func() {
+			const credentialsSecretName = "credentials"
+			var uid int64 = 1042
+
+			BeforeEach(func() {
+				osbuildInstance.Spec.Details.Customizations.Users = []osbuildv1alpha1.User{{
+					Name:                 "usr3",
+					Key:                  &sshPublicKey,
+					UID:                  &uid,
+					PasswordSecretKeyRef: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: credentialsSecretName}, Key: "password"},
+					SSHKeysSecretKeyRefs: []corev1.SecretKeySelector{
+						{LocalObjectReference: corev1.LocalObjectReference{Name: credentialsSecretName}, Key: "ssh"},
+					},
+				}}
+				osbuildInstance.Spec.Details.Customizations.Groups = []osbuildv1alpha1.Group{{Name: "group3", GID: &uid}}
+			})
+
+			AfterEach(func() {
+				osbuildInstance.Spec.Details.Customizations.Users = []osbuildv1alpha1.User{usr1, usr2}
+				osbuildInstance.Spec.Details.Customizations.Groups = nil
+			})
+
+			DescribeTable("should send the password hash and all the SSH keys", func(password string) {
+				// given
+				secretRepository.EXPECT().Read(requestContext, credentialsSecretName, instanceNamespace).Return(&corev1.Secret{
+					Data: map[string][]byte{"password": []byte(password), "ssh": []byte("secretKey\n")},
+				}, nil).Times(2)
+				composerClient.EXPECT().PostComposeWithResponse(requestContext, gomock.Any()).DoAndReturn(
+					func(ctx context.Context, body composer.PostComposeJSONRequestBody, reqEditors ...interface{}) (*composer.PostComposeResponse, error) {
+						users := *body.Customizations.Users
+						Expect(users).To(HaveLen(1))
+						Expect(*users[0].Password).To(Equal("$6$hash"))
+						Expect(*users[0].Key).To(Equal(sshPublicKey + "\nsecretKey"))
+						Expect(*users[0].Uid).To(Equal(uid))
+						Expect(*body.Customizations.Groups).To(Equal([]composer.Group{{Name: "group3", Gid: &uid}}))
+						return &composerPostResponseCreated, nil
+					},
+				)
+				// when
+				result, err := reconciler.Reconcile(requestContext, request)
+				// then
+				Expect(err).To(BeNil())
+				Expect(result).To(Equal(resultLongRequeue))
+			},
+				Entry("the hash", "$6$hash"),
+				Entry("the hash with surrounding whitespace", " $6$hash\n"),
+			)
+
+			It("should fail without posting when the password is not hashed", func() {
+				// given
+				secretRepository.EXPECT().Read(requestContext, credentialsSecretName, instanceNamespace).Return(&corev1.Secret{
+					Data: map[string][]byte{"password": []byte("plain")},
+				}, nil)
+				// when
+				result, err := reconciler.Reconcile(requestContext, request)
+				// then
+				Expect(err).To(BeNil())
+				Expect(result).To(Equal(resultLongRequeue))
+				checkConditionArr(osbuildv1alpha1.ConditionFailed, failedToGetUsersMsg, osbuildInstance.Status.Conditions)
+			})
+		})
+
+		Context("with a subscription", func() {
+			const subscriptionSecretName = "rhsm"
+
+			BeforeEach(func() {
+				osbuildInstance.Spec.Details.Customizations.Subscription = &osbuildv1alpha1.Subscription{
+					SecretRef: osbuildv1alpha1.NameRef{Name: subscriptionSecretName},
+					Insights:  true,
+				}
+			})
+
+			AfterEach(func() {
+				osbuildInstance.Spec.Details.Customizations.Subscription = nil
+			})
+
+			It("should send the subscription read from the secret", func() {
+				// given
+				secretRepository.EXPECT().Read(requestContext, subscriptionSecretName, instanceNamespace).Return(&corev1.Secret{
+					Data: map[string][]byte{
+						controllers.SubscriptionOrganizationKey:  []byte("org"),
+						controllers.SubscriptionActivationKeyKey: []byte("key"),
+					},
+				}, nil)
+				composerClient.EXPECT().PostComposeWithResponse(requestContext, gomock.Any()).DoAndReturn(
+					func(ctx context.Context, body composer.PostComposeJSONRequestBody, reqEditors ...interface{}) (*composer.PostComposeResponse, error) {
+						Expect(body.Customizations.Subscription).To(Equal(&composer.Subscription{
+							Organization:  "org",
+							ActivationKey: "key",
+							ServerUrl:     "subscription.rhsm.redhat.com",
+							BaseUrl:       "https://cdn.redhat.com/",
+							Insights:      true,
+						}))
+						return &composerPostResponseCreated, nil
+					},
+				)
+				// when
+				result, err := reconciler.Reconcile(requestContext, request)
+				// then
+				Expect(err).To(BeNil())
+				Expect(result).To(Equal(resultLongRequeue))
+				checkConditionArr(osbuildv1alpha1.ConditionInProgress, buildJobStillRunningMsg, osbuildInstance.Status.Conditions)
+			})
+
+			It("should fail without posting when the secret is missing the activation key", func() {
+				// given
+				secretRepository.EXPECT().Read(requestContext, subscriptionSecretName, instanceNamespace).Return(&corev1.Secret{
+					Data: map[string][]byte{
+						controllers.SubscriptionOrganizationKey: []byte("org"),
+					},
+				}, nil)
+				// when
+				result, err := reconciler.Reconcile(requestContext, request)
+				// then
+				Expect(err).To(BeNil())
+				Expect(result).To(Equal(resultLongRequeue))
+				checkConditionArr(osbuildv1alpha1.ConditionFailed, failedToGetSubscriptionMsg, osbuildInstance.Status.Conditions)
+			})
+
+			It("should fail without posting when the secret cannot be read", func() {
+				// given
+				secretRepository.EXPECT().Read(requestContext, subscriptionSecretName, instanceNamespace).Return(nil, errNotFound)
+				// when
+				result, err := reconciler.Reconcile(requestContext, request)
+				// then
+				Expect(err).To(BeNil())
+				Expect(result).To(Equal(resultLongRequeue))
+				checkConditionArr(osbuildv1alpha1.ConditionFailed, failedToGetSubscriptionMsg, osbuildInstance.Status.Conditions)
+			})
+		})
+	})
+
+	Context("Validating the packages before posting a compose", func() {
+		var (
+			packageResolver *rpmmd.MockPackageResolver
+			eventRecorder   *record.FakeRecorder
+			repoUrl         = "https://repo"
+			payloadRepoUrl  = "https://payload"
+		)
+
+		BeforeEach(func() {
+			os.Setenv("WORKING_NAMESPACE", instanceNamespace)
+			os.Setenv("CA_ISSUER_NAME", "osbuild-issuer")
+			Expect(conf.Load()).To(Succeed())
+
+			packageResolver = rpmmd.NewMockPackageResolver(mockCtrl)
+			eventRecorder = record.NewFakeRecorder(1)
+			reconciler.PackageResolver = packageResolver
+			reconciler.EventRecorder = eventRecorder
+			osbuildInstance.Spec.Details.TargetImage.Repositories = &[]osbuildv1alpha1.Repository{{Baseurl: &repoUrl}}
+			osbuildInstance.Spec.Details.Customizations.PayloadRepositories = []osbuildv1alpha1.Repository{{Baseurl: &payloadRepoUrl}}
+			osBuildRepository.EXPECT().Read(requestContext, instanceName, instanceNamespace).Return(osbuildInstance, nil)
+		})
+
+		AfterEach(func() {
+			osbuildInstance.Spec.Details.TargetImage.Repositories = nil
+			osbuildInstance.Spec.Details.Customizations.PayloadRepositories = nil
+		})
+
+		It("should set ValidationFailed without posting when packages are missing", func() {
+			// given
+			packageResolver.EXPECT().FindMissingPackages(requestContext,
+				[]osbuildv1alpha1.Repository{{Baseurl: &repoUrl}, {Baseurl: &payloadRepoUrl}}, osbuildv1alpha1.Architecture(architecture), packages).
+				Return([]string{"pkg2"}, nil)
+			osBuildRepository.EXPECT().PatchStatus(requestContext, osbuildInstance, gomock.Any()).Return(nil)
+			// when
+			result, err := reconciler.Reconcile(requestContext, request)
+			// then
+			Expect(err).To(BeNil())
+			Expect(result).To(Equal(resultDone))
+			Expect(osbuildInstance.Status.ComposeId).To(BeEmpty())
+			Expect(osbuildInstance.Status.Conditions).To(HaveLen(4))
+			checkConditionArr(osbuildv1alpha1.ConditionValidationFailed, missingPackagesMsg, osbuildInstance.Status.Conditions)
+		})
+
+		It("should post the compose when all the packages are found", func() {
+			// given
+			packageResolver.EXPECT().FindMissingPackages(requestContext, gomock.Any(), gomock.Any(), packages).Return(nil, nil)
+			composerClient.EXPECT().PostComposeWithResponse(requestContext, gomock.Any()).Return(&composerPostResponseCreated, nil)
+			osBuildRepository.EXPECT().PatchStatus(requestContext, osbuildInstance, gomock.Any()).Return(nil)
+			// when
+			result, err := reconciler.Reconcile(requestContext, request)
+			// then
+			Expect(err).To(BeNil())
+			Expect(result).To(Equal(resultLongRequeue))
+			checkConditionArr(osbuildv1alpha1.ConditionInProgress, buildJobStillRunningMsg, osbuildInstance.Status.Conditions)
+		})
+
+		It("should retry the validation when the packages cannot be resolved", func() {
+			// given
+			osbuildInstance.CreationTimestamp = metav1.Now()
+			packageResolver.EXPECT().FindMissingPackages(requestContext, gomock.Any(), gomock.Any(), packages).Return(nil, fmt.Errorf("failed"))
+			// when
+			_, err := reconciler.Reconcile(requestContext, request)
+			// then
+			Expect(err).To(HaveOccurred())
+			Expect(osbuildInstance.Status.ComposeId).To(BeEmpty())
+			Expect(eventRecorder.Events).To(BeEmpty())
+		})
+
+		DescribeTable("should skip the validation and post the compose", func(creationTime time.Time, resolveErr error) {
+			// given
+			osbuildInstance.CreationTimestamp = metav1.NewTime(creationTime)
+			packageResolver.EXPECT().FindMissingPackages(requestContext, gomock.Any(), gomock.Any(), packages).Return(nil, resolveErr)
+			composerClient.EXPECT().PostComposeWithResponse(requestContext, gomock.Any()).Return(&composerPostResponseCreated, nil)
+			osBuildRepository.EXPECT().PatchStatus(requestContext, osbuildInstance, gomock.Any()).Return(nil)
+			// when
+			result, err := reconciler.Reconcile(requestContext, request)
+			// then
+			Expect(err).To(BeNil())
+			Expect(result).To(Equal(resultLongRequeue))
+			checkConditionArr(osbuildv1alpha1.ConditionInProgress, buildJobStillRunningMsg, osbuildInstance.Status.Conditions)
+			Expect(eventRecorder.Events).To(Receive(HavePrefix("Warning ValidationSkipped The packages were not validated: " + resolveErr.Error())))
+		},
+			Entry("when the packages cannot be resolved for longer than the retry period", time.Now().Add(-time.Hour), fmt.Errorf("failed")),
+			Entry("when the repositories cannot be validated", time.Now(), rpmmd.ErrValidationNotSupported),
+		)
+
+		It("should return Done when the validation has already failed", func() {
+			// given
+			msg := missingPackagesMsg
+			osbuildInstance.Status.Conditions = []osbuildv1alpha1.Condition{{
+				Type:    osbuildv1alpha1.ConditionValidationFailed,
+				Status:  metav1.ConditionTrue,
+				Message: &msg,
+			}}
+			// when
+			result, err := reconciler.Reconcile(requestContext, request)
+			// then
+			Expect(err).To(BeNil())
+			Expect(result).To(Equal(resultDone))
+		})
 	})
 
 	Context("Last Build Status is InProgress", func() {
@@ -381,7 +778,9 @@ var _ = Describe("OSBuild Controller", func() {
 		It("should requeue if job status was changed from InProgress to success", func() {
 			// given
 			composerClient.EXPECT().GetComposeStatusWithResponse(requestContext, zeroUuid).Return(&composerGetStatusDone, nil)
-			osBuildRepository.EXPECT().PatchStatus(requestContext, osbuildInstance, gomock.Any()).Return(nil)
+			composerClient.EXPECT().GetComposeMetadataWithResponse(requestContext, uuid.MustParse(zeroUuid)).Return(&composerGetMetadataDone, nil)
+			configMapRepository.EXPECT().Create(requestContext, gomock.Any()).Return(nil)
+			osBuildRepository.EXPECT().PatchStatus(requestContext, osbuildInstance, gomock.Any()).Return(nil).Times(2)
 
 			// when
 			result, err := reconciler.Reconcile(requestContext, request)
@@ -390,6 +789,7 @@ var _ = Describe("OSBuild Controller", func() {
 			Expect(result).To(Equal(resultRequeue))
 			Expect(osbuildInstance.Status.AccessUrl).To(Equal(buildUrl))
 			Expect(osbuildInstance.Status.ComposeId).To(Equal(zeroUuid))
+			Expect(osbuildInstance.Status.OSTreeCommit).To(Equal(osTreeCommit))
 
 			checkConditionArr(osbuildv1alpha1.ConditionReady, buildJobFinishedMsg, osbuildInstance.Status.Conditions)
 		})
@@ -408,10 +808,121 @@ var _ = Describe("OSBuild Controller", func() {
 
 		})
 
+		It("should not record an OSTree commit for a guest-image", func() {
+			// given
+			osbuildInstance.Spec.Details.TargetImage.TargetImageType = osbuildv1alpha1.GuestImageImageType
+			composerClient.EXPECT().GetComposeStatusWithResponse(requestContext, zeroUuid).Return(&composerGetStatusDone, nil)
+			composerClient.EXPECT().GetComposeMetadataWithResponse(requestContext, uuid.MustParse(zeroUuid)).Return(&composerGetMetadataDone, nil)
+			configMapRepository.EXPECT().Create(requestContext, gomock.Any()).Return(nil)
+			osBuildRepository.EXPECT().PatchStatus(requestContext, osbuildInstance, gomock.Any()).Return(nil).Times(2)
+
+			// when
+			result, err := reconciler.Reconcile(requestContext, request)
+			// then
+			Expect(err).To(BeNil())
+			Expect(result).To(Equal(resultRequeue))
+			Expect(osbuildInstance.Status.OSTreeCommit).To(BeEmpty())
+			checkConditionArr(osbuildv1alpha1.ConditionReady, buildJobFinishedMsg, osbuildInstance.Status.Conditions)
+		})
+
+		Context("with a vulnerability scan", func() {
+			const feed = `[{"id": "CVE-2022-0001", "affected": [{"package": {"name": "bash"}, "versions": ["5.1.8-4.el9"]}],
+				"database_specific": {"severity": "Critical"}}]`
+			var metadataWithBash composer.GetComposeMetadataResponse
+
+			BeforeEach(func() {
+				os.Setenv("WORKING_NAMESPACE", instanceNamespace)
+				os.Setenv("CA_ISSUER_NAME", "osbuild-issuer")
+				Expect(conf.Load()).To(Succeed())
+
+				metadataWithBash = composerGetMetadataDone
+				metadataWithBash.JSON200 = &composer.ComposeMetadata{
+					Id:           zeroUuid,
+					OstreeCommit: &osTreeCommit,
+					Packages:     &[]composer.PackageMetadata{{Name: "bash", Arch: architecture, Version: "5.1.8", Release: "4.el9"}},
+				}
+				osbuildInstance.Spec.VulnerabilityScan = &osbuildv1alpha1.VulnerabilityScan{
+					Feed: osbuildv1alpha1.VulnerabilityFeed{ConfigMapKeyRef: &corev1.ConfigMapKeySelector{
+						LocalObjectReference: corev1.LocalObjectReference{Name: "feeds"},
+						Key:                  "osv.json",
+					}},
+				}
+
+				composerClient.EXPECT().GetComposeStatusWithResponse(requestContext, zeroUuid).Return(&composerGetStatusDone, nil)
+				composerClient.EXPECT().GetComposeMetadataWithResponse(requestContext, uuid.MustParse(zeroUuid)).Return(&metadataWithBash, nil)
+				configMapRepository.EXPECT().Create(requestContext, gomock.Any()).Return(nil)
+			})
+
+			It("should fail the build when the policy says so", func() {
+				// given
+				osbuildInstance.Spec.VulnerabilityScan.Policy = &osbuildv1alpha1.VulnerabilityPolicy{Action: osbuildv1alpha1.VulnerabilityPolicyFailBuild}
+				configMapRepository.EXPECT().Read(requestContext, "feeds", instanceNamespace).Return(&corev1.ConfigMap{Data: map[string]string{"osv.json": feed}}, nil)
+				osBuildRepository.EXPECT().PatchStatus(requestContext, osbuildInstance, gomock.Any()).Return(nil).Times(3)
+
+				// when
+				result, err := reconciler.Reconcile(requestContext, request)
+
+				// then
+				Expect(err).To(BeNil())
+				Expect(result).To(Equal(resultRequeue))
+				Expect(osbuildInstance.Status.Vulnerabilities.Critical).To(Equal(1))
+				Expect(osbuildInstance.Status.Vulnerabilities.PolicyViolations).To(Equal([]string{"CVE-2022-0001"}))
+				checkConditionArr(osbuildv1alpha1.ConditionVulnerabilitiesFound, "Vulnerabilities violating the policy were found: CVE-2022-0001",
+					osbuildInstance.Status.Conditions)
+			})
+
+			It("should keep the build Ready when the policy blocks its release", func() {
+				// given
+				osbuildInstance.Spec.VulnerabilityScan.Policy = &osbuildv1alpha1.VulnerabilityPolicy{Action: osbuildv1alpha1.VulnerabilityPolicyBlockRelease}
+				configMapRepository.EXPECT().Read(requestContext, "feeds", instanceNamespace).Return(&corev1.ConfigMap{Data: map[string]string{"osv.json": feed}}, nil)
+				osBuildRepository.EXPECT().PatchStatus(requestContext, osbuildInstance, gomock.Any()).Return(nil).Times(3)
+
+				// when
+				result, err := reconciler.Reconcile(requestContext, request)
+
+				// then
+				Expect(err).To(BeNil())
+				Expect(result).To(Equal(resultRequeue))
+				Expect(osbuildInstance.Status.Vulnerabilities.PolicyViolations).To(Equal([]string{"CVE-2022-0001"}))
+				checkConditionArr(osbuildv1alpha1.ConditionReady, buildJobFinishedMsg, osbuildInstance.Status.Conditions)
+			})
+
+			It("should fail the build when the feed cannot be read and the policy says so", func() {
+				// given
+				osbuildInstance.Spec.VulnerabilityScan.Policy = &osbuildv1alpha1.VulnerabilityPolicy{Action: osbuildv1alpha1.VulnerabilityPolicyFailBuild}
+				configMapRepository.EXPECT().Read(requestContext, "feeds", instanceNamespace).Return(nil, errNotFound)
+				osBuildRepository.EXPECT().PatchStatus(requestContext, osbuildInstance, gomock.Any()).Return(nil).Times(3)
+
+				// when
+				result, err := reconciler.Reconcile(requestContext, request)
+
+				// then
+				Expect(err).To(BeNil())
+				Expect(result).To(Equal(resultRequeue))
+				Expect(osbuildInstance.Status.Vulnerabilities.Error).NotTo(BeEmpty())
+				checkConditionArr(osbuildv1alpha1.ConditionVulnerabilitiesFound, "", osbuildInstance.Status.Conditions)
+			})
+		})
+
+		It("should requeue for short duration if job status was changed from InProgress to success but failed to get the metadata", func() {
+			// given
+			composerClient.EXPECT().GetComposeStatusWithResponse(requestContext, zeroUuid).Return(&composerGetStatusDone, nil)
+			composerClient.EXPECT().GetComposeMetadataWithResponse(requestContext, uuid.MustParse(zeroUuid)).Return(nil, errFailed)
+
+			// when
+			result, err := reconciler.Reconcile(requestContext, request)
+			// then
+			Expect(err).To(BeNil())
+			Expect(result).To(Equal(resultShortRequeue))
+			checkConditionArr(osbuildv1alpha1.ConditionInProgress, buildJobStillRunningMsg, osbuildInstance.Status.Conditions)
+		})
+
 		It("should requeue if job status was changed from InProgress to success but fail on patch status", func() {
 			// given
 			composerClient.EXPECT().GetComposeStatusWithResponse(requestContext, zeroUuid).Return(&composerGetStatusDone, nil)
-			osBuildRepository.EXPECT().PatchStatus(requestContext, osbuildInstance, gomock.Any()).Return(errFailed)
+			composerClient.EXPECT().GetComposeMetadataWithResponse(requestContext, uuid.MustParse(zeroUuid)).Return(&composerGetMetadataDone, nil)
+			configMapRepository.EXPECT().Create(requestContext, gomock.Any()).Return(nil)
+			osBuildRepository.EXPECT().PatchStatus(requestContext, osbuildInstance, gomock.Any()).Return(errFailed).Times(2)
 
 			// when
 			result, err := reconciler.Reconcile(requestContext, request)
@@ -421,6 +932,165 @@ var _ = Describe("OSBuild Controller", func() {
 		})
 	})
 
+	Context("Reporting the diff to the previous build", func() {
+		const (
+			osBuildConfigName = "config"
+			osBuildName       = "config-3"
+			previousComposeId = "11111111-1111-1111-1111-111111111111"
+		)
+		var (
+			diffRequest         = ctrl.Request{NamespacedName: types.NamespacedName{Name: osBuildName, Namespace: instanceNamespace}}
+			metadataWithPackage = func(composeId string, release string) *composer.GetComposeMetadataResponse {
+				return &composer.GetComposeMetadataResponse{
+					HTTPResponse: &http.Response{StatusCode: http.StatusOK},
+					JSON200: &composer.ComposeMetadata{
+						Id:           composeId,
+						OstreeCommit: &osTreeCommit,
+						Packages:     &[]composer.PackageMetadata{{Name: "bash", Arch: architecture, Version: "5.1.8", Release: release}},
+					},
+				}
+			}
+			newOSBuild = func(name string, targetImageType osbuildv1alpha1.TargetImageType, ready bool) osbuildv1alpha1.OSBuild {
+				status := metav1.ConditionFalse
+				if ready {
+					status = metav1.ConditionTrue
+				}
+				return osbuildv1alpha1.OSBuild{
+					ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: instanceNamespace},
+					Spec: osbuildv1alpha1.OSBuildSpec{
+						Details: &osbuildv1alpha1.BuildDetails{TargetImage: osbuildv1alpha1.TargetImage{TargetImageType: targetImageType}},
+						UserConfiguration: &osbuildv1alpha1.UserConfiguration{
+							Customizations: &osbuildv1alpha1.Customizations{Packages: []string{"pkg1"}},
+						},
+					},
+					Status: osbuildv1alpha1.OSBuildStatus{
+						ComposeId:  previousComposeId,
+						Conditions: []osbuildv1alpha1.Condition{{Type: osbuildv1alpha1.ConditionReady, Status: status}},
+					},
+				}
+			}
+		)
+
+		BeforeEach(func() {
+			msg := buildJobStillRunningMsg
+			controller := true
+			osbuildInstance.Name = osBuildName
+			osbuildInstance.OwnerReferences = []metav1.OwnerReference{{
+				APIVersion: osbuildv1alpha1.GroupVersion.String(),
+				Kind:       "OSBuildConfig",
+				Name:       osBuildConfigName,
+				Controller: &controller,
+			}}
+			osbuildInstance.Spec.UserConfiguration = &osbuildv1alpha1.UserConfiguration{
+				Customizations: &osbuildv1alpha1.Customizations{Packages: packages},
+			}
+			osbuildInstance.Status.ComposeId = zeroUuid
+			osbuildInstance.Status.Conditions = []osbuildv1alpha1.Condition{{
+				Type:    osbuildv1alpha1.ConditionInProgress,
+				Status:  metav1.ConditionTrue,
+				Message: &msg,
+			}}
+
+			osBuildRepository.EXPECT().Read(requestContext, osBuildName, instanceNamespace).Return(osbuildInstance, nil)
+			composerClient.EXPECT().GetComposeStatusWithResponse(requestContext, zeroUuid).Return(&composerGetStatusDone, nil)
+			composerClient.EXPECT().GetComposeMetadataWithResponse(requestContext, uuid.MustParse(zeroUuid)).Return(metadataWithPackage(zeroUuid, "5.el9"), nil)
+		})
+
+		It("should compare with the previous Ready OSBuild of the same target image type", func() {
+			// given
+			osBuilds := []osbuildv1alpha1.OSBuild{
+				newOSBuild("config-1", osbuildv1alpha1.EdgeContainerImageType, true),
+				newOSBuild("config-2", osbuildv1alpha1.EdgeContainerImageType, true),
+				newOSBuild("config-4", osbuildv1alpha1.EdgeContainerImageType, true),
+				newOSBuild("config-5", osbuildv1alpha1.EdgeInstallerImageType, true),
+				newOSBuild("config-6", osbuildv1alpha1.EdgeContainerImageType, false),
+				*osbuildInstance,
+			}
+			osBuildRepository.EXPECT().ListByOSBuildConfig(requestContext, osBuildConfigName, instanceNamespace).Return(osBuilds, nil)
+			composerClient.EXPECT().GetComposeMetadataWithResponse(requestContext, uuid.MustParse(previousComposeId)).Return(metadataWithPackage(previousComposeId, "4.el9"), nil)
+
+			var configMap *corev1.ConfigMap
+			configMapRepository.EXPECT().Create(requestContext, gomock.Any()).DoAndReturn(func(ctx context.Context, cm *corev1.ConfigMap) error {
+				configMap = cm
+				return nil
+			})
+			osBuildRepository.EXPECT().PatchStatus(requestContext, osbuildInstance, gomock.Any()).Return(nil).Times(2)
+
+			// when
+			result, err := reconciler.Reconcile(requestContext, diffRequest)
+
+			// then
+			Expect(err).To(BeNil())
+			Expect(result).To(Equal(resultRequeue))
+			Expect(configMap.Name).To(Equal("config-3-diff"))
+			Expect(configMap.OwnerReferences).To(HaveLen(1))
+			Expect(configMap.OwnerReferences[0].Name).To(Equal(osBuildName))
+			Expect(configMap.Data["report.json"]).To(ContainSubstring(`"oldVersion": "5.1.8-4.el9"`))
+			Expect(configMap.Data["report.json"]).To(ContainSubstring(`"newVersion": "5.1.8-5.el9"`))
+			Expect(*osbuildInstance.Status.Diff).To(Equal(osbuildv1alpha1.DiffSummary{
+				PreviousOSBuild:       "config-2",
+				UpgradedPackages:      1,
+				ChangedCustomizations: []string{"customizations.packages"},
+				ReportConfigMap:       "config-3-diff",
+			}))
+			checkConditionArr(osbuildv1alpha1.ConditionReady, buildJobFinishedMsg, osbuildInstance.Status.Conditions)
+		})
+
+		It("should report all the packages as added for the first build", func() {
+			// given
+			osBuildRepository.EXPECT().ListByOSBuildConfig(requestContext, osBuildConfigName, instanceNamespace).Return([]osbuildv1alpha1.OSBuild{*osbuildInstance}, nil)
+			configMapRepository.EXPECT().Create(requestContext, gomock.Any()).Return(nil)
+			osBuildRepository.EXPECT().PatchStatus(requestContext, osbuildInstance, gomock.Any()).Return(nil).Times(2)
+
+			// when
+			result, err := reconciler.Reconcile(requestContext, diffRequest)
+
+			// then
+			Expect(err).To(BeNil())
+			Expect(result).To(Equal(resultRequeue))
+			Expect(*osbuildInstance.Status.Diff).To(Equal(osbuildv1alpha1.DiffSummary{
+				AddedPackages:   1,
+				ReportConfigMap: "config-3-diff",
+			}))
+		})
+
+		It("should update the report left by a previous attempt", func() {
+			// given
+			existingConfigMap := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "config-3-diff", Namespace: instanceNamespace}}
+			osBuildRepository.EXPECT().ListByOSBuildConfig(requestContext, osBuildConfigName, instanceNamespace).Return(nil, nil)
+			configMapRepository.EXPECT().Create(requestContext, gomock.Any()).Return(errors.NewAlreadyExists(schema.GroupResource{}, "config-3-diff"))
+			configMapRepository.EXPECT().Read(requestContext, "config-3-diff", instanceNamespace).Return(existingConfigMap, nil)
+			configMapRepository.EXPECT().Patch(requestContext, existingConfigMap, gomock.Any()).DoAndReturn(func(ctx context.Context, old, new *corev1.ConfigMap) error {
+				Expect(new.Data).To(HaveKey("report.json"))
+				return nil
+			})
+			osBuildRepository.EXPECT().PatchStatus(requestContext, osbuildInstance, gomock.Any()).Return(nil).Times(2)
+
+			// when
+			result, err := reconciler.Reconcile(requestContext, diffRequest)
+
+			// then
+			Expect(err).To(BeNil())
+			Expect(result).To(Equal(resultRequeue))
+			Expect(osbuildInstance.Status.Diff).NotTo(BeNil())
+		})
+
+		It("should set the OSBuild Ready without a report when the OSBuilds cannot be listed", func() {
+			// given
+			osBuildRepository.EXPECT().ListByOSBuildConfig(requestContext, osBuildConfigName, instanceNamespace).Return(nil, errFailed)
+			osBuildRepository.EXPECT().PatchStatus(requestContext, osbuildInstance, gomock.Any()).Return(nil)
+
+			// when
+			result, err := reconciler.Reconcile(requestContext, diffRequest)
+
+			// then
+			Expect(err).To(BeNil())
+			Expect(result).To(Equal(resultRequeue))
+			Expect(osbuildInstance.Status.Diff).To(BeNil())
+			checkConditionArr(osbuildv1alpha1.ConditionReady, buildJobFinishedMsg, osbuildInstance.Status.Conditions)
+		})
+	})
+
 	Context("Failed to build an image", func() {
 		It("should done", func() {
 			// given
@@ -18,8 +18,12 @@ package controllers
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/go-logr/logr"
 	"github.com/google/go-cmp/cmp"
@@ -30,17 +34,26 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
 	osbuilderv1alpha1 "github.com/project-flotta/osbuild-operator/api/v1alpha1"
+	"github.com/project-flotta/osbuild-operator/internal/composer"
+	"github.com/project-flotta/osbuild-operator/internal/cron"
+	"github.com/project-flotta/osbuild-operator/internal/customizations"
 	"github.com/project-flotta/osbuild-operator/internal/manifests"
 	"github.com/project-flotta/osbuild-operator/internal/predicates"
 	"github.com/project-flotta/osbuild-operator/internal/repository/osbuild"
 	"github.com/project-flotta/osbuild-operator/internal/repository/osbuildconfig"
+	"github.com/project-flotta/osbuild-operator/internal/repository/osbuildrelease"
+	"github.com/project-flotta/osbuild-operator/internal/rpmmd"
+	"github.com/project-flotta/osbuild-operator/internal/s3"
 )
 
 // OSBuildConfigReconciler reconciles a OSBuildConfig object
 type OSBuildConfigReconciler struct {
-	OSBuildConfigRepository osbuildconfig.Repository
-	OSBuildRepository       osbuild.Repository
-	OSBuildCRCreator        manifests.OSBuildCRCreator
+	OSBuildConfigRepository  osbuildconfig.Repository
+	OSBuildRepository        osbuild.Repository
+	OSBuildCRCreator         manifests.OSBuildCRCreator
+	S3ObjectStore            s3.ObjectStore
+	OSBuildReleaseRepository osbuildrelease.Repository
+	RevisionReader           rpmmd.RevisionReader
 }
 
 const (
@@ -48,7 +61,22 @@ const (
 	FailedPatchLastTargetType = "Failed to set the OSBuildConfig lastTargetType"
 
 	// Annotations
-	webHookAnnotationKey = "last_webhook_trigger_ts"
+	webHookAnnotationKey = osbuilderv1alpha1.WebHookTriggerAnnotationKey
+
+	// Rollback failures
+	rollbackInvalidVersionMsg      = "Invalid version %q"
+	rollbackOSBuildNotFoundMsg     = "OSBuild %s not found"
+	rollbackNoUserConfigurationMsg = "OSBuild %s does not record the user configuration that produced it"
+
+	// Schedule failures
+	scheduleInvalidTimeZoneMsg = "Invalid time zone %q: %s"
+	scheduleInvalidCronMsg     = "Invalid cron expression: %s"
+	scheduleNeverMatchesMsg    = "The cron expression %q never matches"
+
+	// Repository change trigger
+	updatesAvailableMsg            = "The revisions of the repositories changed since the last OSBuild: %s"
+	defaultRepositoryCheckInterval = time.Hour
+	minRepositoryCheckInterval     = time.Minute
 )
 
 //+kubebuilder:rbac:groups=osbuilder.project-flotta.io,resources=osbuildconfigs,verbs=get;list;watch;create;update;patch;delete
@@ -83,11 +111,33 @@ func (r *OSBuildConfigReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 		return ctrl.Result{}, nil
 	}
 
-	newOSBuildInstanceIsNeeded, err := r.checkIfNewOSBuildInstanceIsNeeded(ctx, osBuildConfig, logger)
+	if version, ok := osBuildConfig.Annotations[osbuilderv1alpha1.RollbackAnnotationKey]; ok {
+		result, err := r.rollback(ctx, logger, osBuildConfig, version)
+		return requeueForTriggers(osBuildConfig, result), err
+	}
+
+	newOSBuildInstanceIsNeeded, trigger, err := r.checkIfNewOSBuildInstanceIsNeeded(ctx, osBuildConfig, logger)
 	if err != nil {
 		return ctrl.Result{Requeue: true, RequeueAfter: RequeueForShortDuration}, nil
 	}
 
+	if osBuildConfig.Spec.Triggers.Schedule != nil {
+		scheduledRunIsDue, err := r.checkSchedule(ctx, logger, osBuildConfig, newOSBuildInstanceIsNeeded)
+		if err != nil {
+			return ctrl.Result{Requeue: true, RequeueAfter: RequeueForShortDuration}, nil
+		}
+		newOSBuildInstanceIsNeeded = newOSBuildInstanceIsNeeded || scheduledRunIsDue
+	}
+
+	if trigger := osBuildConfig.Spec.Triggers.RepositoryChange; trigger != nil {
+		updatesAvailable, err := r.checkRepositories(ctx, logger, osBuildConfig)
+		if err != nil {
+			return ctrl.Result{Requeue: true, RequeueAfter: RequeueForShortDuration}, nil
+		}
+		newOSBuildInstanceIsNeeded = newOSBuildInstanceIsNeeded || updatesAvailable && trigger.AutomaticBuild != nil && *trigger.AutomaticBuild
+	}
+
+	var result ctrl.Result
 	if newOSBuildInstanceIsNeeded {
 		logger.Info("new OSBuild instance need to be created for OSBuildConfig", "OSBuildConfig", osBuildConfig.Name)
 		// create new OSBuild instance
@@ -96,16 +146,412 @@ func (r *OSBuildConfigReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 			// start with edge-container OSBuild instance
 			targetBuild = osbuilderv1alpha1.EdgeContainerImageType
 		}
-		return r.createOSBuildInstance(ctx, logger, osBuildConfig, targetBuild)
+		result, err = r.createOSBuildInstance(ctx, logger, osBuildConfig, targetBuild, trigger)
 	} else {
 		logger.Info("update OSBuildConfig current status")
-		return r.updateOSBuildConfigCurrentStatus(ctx, logger, osBuildConfig)
+		result, err = r.updateOSBuildConfigCurrentStatus(ctx, logger, osBuildConfig)
+	}
+	return requeueForTriggers(osBuildConfig, result), err
+}
+
+// checkSchedule returns whether a scheduled run is due and records the next scheduled run in the status. The runs
+// missed while the operator was not running are run once. When the OSBuildConfig is configured so, a due run is
+// skipped if neither the OSBuildConfig, its template nor the revisions of the repositories changed since the last
+// OSBuild, unless a new OSBuild is needed anyway. A due run that is not skipped is only recorded once its OSBuild is
+// created, so that it is run again when the creation fails
+func (r *OSBuildConfigReconciler) checkSchedule(ctx context.Context, logger logr.Logger, osBuildConfig *osbuilderv1alpha1.OSBuildConfig, newOSBuildInstanceIsNeeded bool) (bool, error) {
+	trigger := osBuildConfig.Spec.Triggers.Schedule
+	now := time.Now()
+	patch := client.MergeFrom(osBuildConfig.DeepCopy())
+	scheduleStatus := &osbuilderv1alpha1.ScheduleStatus{}
+	if osBuildConfig.Status.Schedule != nil {
+		scheduleStatus = osBuildConfig.Status.Schedule.DeepCopy()
+	}
+
+	schedule, msg := parseSchedule(trigger)
+	if schedule != nil {
+		if scheduleStatus.NextScheduleTime != nil && !now.Before(scheduleStatus.NextScheduleTime.Time) {
+			skipped := !newOSBuildInstanceIsNeeded && trigger.SkipIfUnchanged != nil && *trigger.SkipIfUnchanged &&
+				isUnchangedSinceLastBuild(osBuildConfig) && r.areRepositoriesUnchanged(ctx, logger, osBuildConfig)
+			logger.Info("Scheduled run is due", "skipped", skipped)
+			if !skipped {
+				return true, nil
+			}
+			scheduleStatus.LastScheduleTime = scheduleStatus.NextScheduleTime
+			scheduleStatus.LastRunSkipped = true
+		}
+
+		msg = setNextScheduleTime(scheduleStatus, schedule, trigger, now)
+	} else {
+		scheduleStatus.NextScheduleTime = nil
+	}
+	scheduleStatus.Message = msg
+
+	if msg != "" {
+		logger.Info("Cannot schedule the next run", "reason", msg)
+	}
+
+	if !cmp.Equal(osBuildConfig.Status.Schedule, scheduleStatus) {
+		osBuildConfig.Status.Schedule = scheduleStatus
+		err := r.OSBuildConfigRepository.PatchStatus(ctx, osBuildConfig, &patch)
+		if err != nil {
+			logger.Error(err, "Failed to patch OSBuildConfig status")
+			return false, err
+		}
+	}
+
+	return false, nil
+}
+
+// recordScheduledRun records the due scheduled run once its OSBuild is created and schedules the next run
+func (r *OSBuildConfigReconciler) recordScheduledRun(ctx context.Context, osBuildConfig *osbuilderv1alpha1.OSBuildConfig) error {
+	trigger := osBuildConfig.Spec.Triggers.Schedule
+	now := time.Now()
+	if trigger == nil || osBuildConfig.Status.Schedule == nil || osBuildConfig.Status.Schedule.NextScheduleTime == nil ||
+		now.Before(osBuildConfig.Status.Schedule.NextScheduleTime.Time) {
+		return nil
+	}
+	schedule, _ := parseSchedule(trigger)
+	if schedule == nil {
+		return nil
+	}
+
+	patch := client.MergeFrom(osBuildConfig.DeepCopy())
+	scheduleStatus := osBuildConfig.Status.Schedule
+	scheduleStatus.LastScheduleTime = scheduleStatus.NextScheduleTime
+	scheduleStatus.LastRunSkipped = false
+	scheduleStatus.Message = setNextScheduleTime(scheduleStatus, schedule, trigger, now)
+	return r.OSBuildConfigRepository.PatchStatus(ctx, osBuildConfig, &patch)
+}
+
+// setNextScheduleTime sets the next scheduled run after now, or returns why there is none
+func setNextScheduleTime(scheduleStatus *osbuilderv1alpha1.ScheduleStatus, schedule *cron.Schedule, trigger *osbuilderv1alpha1.ScheduleTrigger, now time.Time) string {
+	scheduleStatus.NextScheduleTime = nil
+	next := schedule.Next(now)
+	if next.IsZero() {
+		return fmt.Sprintf(scheduleNeverMatchesMsg, trigger.Cron)
+	}
+	nextScheduleTime := metav1.NewTime(next)
+	scheduleStatus.NextScheduleTime = &nextScheduleTime
+	return ""
+}
+
+// parseSchedule parses the cron expression of the trigger in its time zone, or returns why it cannot
+func parseSchedule(trigger *osbuilderv1alpha1.ScheduleTrigger) (*cron.Schedule, string) {
+	location := time.UTC
+	if trigger.TimeZone != nil && *trigger.TimeZone != "" {
+		var err error
+		location, err = time.LoadLocation(*trigger.TimeZone)
+		if err != nil {
+			return nil, fmt.Sprintf(scheduleInvalidTimeZoneMsg, *trigger.TimeZone, err.Error())
+		}
+	}
+
+	schedule, err := cron.Parse(trigger.Cron, location)
+	if err != nil {
+		return nil, fmt.Sprintf(scheduleInvalidCronMsg, err.Error())
+	}
+	return schedule, ""
+}
+
+// requeueForTriggers requeues the OSBuildConfig at its next scheduled run or repository check at the latest, as its
+// status changes do not trigger any reconciliation
+func requeueForTriggers(osBuildConfig *osbuilderv1alpha1.OSBuildConfig, result ctrl.Result) ctrl.Result {
+	if result.Requeue && result.RequeueAfter == 0 {
+		return result
+	}
+
+	var nextRuns []time.Time
+	if osBuildConfig.Spec.Triggers.Schedule != nil && osBuildConfig.Status.Schedule != nil && osBuildConfig.Status.Schedule.NextScheduleTime != nil {
+		nextRuns = append(nextRuns, osBuildConfig.Status.Schedule.NextScheduleTime.Time)
+	}
+	if trigger := osBuildConfig.Spec.Triggers.RepositoryChange; trigger != nil && osBuildConfig.Status.RepositoryCheck != nil &&
+		osBuildConfig.Status.RepositoryCheck.LastCheckTime != nil {
+		nextRuns = append(nextRuns, osBuildConfig.Status.RepositoryCheck.LastCheckTime.Add(getRepositoryCheckInterval(trigger)))
+	}
+
+	for _, nextRun := range nextRuns {
+		untilNextRun := time.Until(nextRun)
+		if untilNextRun <= 0 {
+			if result.RequeueAfter > 0 {
+				// the run failed and is retried with the requeue of the result
+				continue
+			}
+			untilNextRun = time.Second
+		}
+		if result.RequeueAfter == 0 || untilNextRun < result.RequeueAfter {
+			result.RequeueAfter = untilNextRun
+		}
+	}
+	return result
+}
+
+// checkRepositories reads the revisions of the repositories once per interval and sets the UpdatesAvailable condition
+// when they differ from the ones recorded when the last OSBuild was created. It returns whether they differ. Without
+// any recorded revision, the ones read become the reference until the next OSBuild
+func (r *OSBuildConfigReconciler) checkRepositories(ctx context.Context, logger logr.Logger, osBuildConfig *osbuilderv1alpha1.OSBuildConfig) (bool, error) {
+	now := time.Now()
+	lastCheck := osBuildConfig.Status.RepositoryCheck
+	if lastCheck != nil && lastCheck.LastCheckTime != nil &&
+		now.Before(lastCheck.LastCheckTime.Add(getRepositoryCheckInterval(osBuildConfig.Spec.Triggers.RepositoryChange))) {
+		return false, nil
+	}
+
+	patch := client.MergeFrom(osBuildConfig.DeepCopy())
+	checkTime := metav1.NewTime(now)
+	check := &osbuilderv1alpha1.RepositoryCheck{LastCheckTime: &checkTime}
+	updatesAvailable := false
+	revisions, err := r.getRepositoryRevisions(ctx, osBuildConfig)
+	if err != nil {
+		logger.Error(err, "failed to read the revisions of the repositories")
+		check.Message = err.Error()
+		if lastCheck != nil {
+			check.Revisions = lastCheck.Revisions
+		}
+	} else {
+		check.Revisions = revisions
+		if osBuildConfig.Status.RepositoryRevisions == nil {
+			osBuildConfig.Status.RepositoryRevisions = revisions
+		}
+
+		changedRepositories := getChangedRepositories(osBuildConfig.Status.RepositoryRevisions, revisions)
+		if len(changedRepositories) > 0 {
+			logger.Info("The revisions of the repositories changed", "repositories", changedRepositories)
+			updatesAvailable = true
+			setOSBuildConfigCondition(osBuildConfig, osbuilderv1alpha1.ConditionUpdatesAvailable, metav1.ConditionTrue,
+				fmt.Sprintf(updatesAvailableMsg, strings.Join(changedRepositories, ", ")))
+		} else {
+			setOSBuildConfigCondition(osBuildConfig, osbuilderv1alpha1.ConditionUpdatesAvailable, metav1.ConditionFalse, "")
+		}
+	}
+	osBuildConfig.Status.RepositoryCheck = check
+
+	err = r.OSBuildConfigRepository.PatchStatus(ctx, osBuildConfig, &patch)
+	if err != nil {
+		logger.Error(err, "Failed to patch OSBuildConfig status")
+		return false, err
+	}
+	return updatesAvailable, nil
+}
+
+func getRepositoryCheckInterval(trigger *osbuilderv1alpha1.RepositoryChangeTrigger) time.Duration {
+	if trigger.Interval == nil {
+		return defaultRepositoryCheckInterval
+	}
+	if trigger.Interval.Duration < minRepositoryCheckInterval {
+		return minRepositoryCheckInterval
+	}
+	return trigger.Interval.Duration
+}
+
+// getChangedRepositories returns the base URLs of the repositories whose revision changed, or that were added or
+// removed
+func getChangedRepositories(previous []osbuilderv1alpha1.RepositoryRevision, current []osbuilderv1alpha1.RepositoryRevision) []string {
+	previousRevisions := make(map[string]string, len(previous))
+	for _, revision := range previous {
+		previousRevisions[revision.Baseurl] = revision.Revision
+	}
+
+	var changed []string
+	for _, revision := range current {
+		previousRevision, ok := previousRevisions[revision.Baseurl]
+		if !ok || previousRevision != revision.Revision {
+			changed = append(changed, revision.Baseurl)
+		}
+		delete(previousRevisions, revision.Baseurl)
+	}
+	for baseUrl := range previousRevisions {
+		changed = append(changed, baseUrl)
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+// setOSBuildConfigCondition sets the status and the message of the condition, its transition time changing only with
+// its status
+func setOSBuildConfigCondition(osBuildConfig *osbuilderv1alpha1.OSBuildConfig, conditionType osbuilderv1alpha1.ConditionType,
+	status metav1.ConditionStatus, msg string) {
+	var message *string
+	if msg != "" {
+		message = &msg
+	}
+
+	for i := range osBuildConfig.Status.Conditions {
+		condition := &osBuildConfig.Status.Conditions[i]
+		if condition.Type != conditionType {
+			continue
+		}
+		if condition.Status != status {
+			condition.LastTransitionTime = &metav1.Time{Time: time.Now()}
+		}
+		condition.Status = status
+		condition.Message = message
+		return
+	}
+
+	osBuildConfig.Status.Conditions = append(osBuildConfig.Status.Conditions, osbuilderv1alpha1.Condition{
+		Type:               conditionType,
+		Status:             status,
+		Message:            message,
+		LastTransitionTime: &metav1.Time{Time: time.Now()},
+	})
+}
+
+// areRepositoriesUnchanged returns whether the revisions of the repositories are the ones recorded when the last
+// OSBuild was created. The repositories are considered changed when their revisions cannot be read
+func (r *OSBuildConfigReconciler) areRepositoriesUnchanged(ctx context.Context, logger logr.Logger, osBuildConfig *osbuilderv1alpha1.OSBuildConfig) bool {
+	if osBuildConfig.Status.RepositoryRevisions == nil {
+		return false
+	}
+	revisions, err := r.getRepositoryRevisions(ctx, osBuildConfig)
+	if err != nil {
+		logger.Error(err, "failed to read the revisions of the repositories")
+		return false
+	}
+	return cmp.Equal(osBuildConfig.Status.RepositoryRevisions, revisions)
+}
+
+func (r *OSBuildConfigReconciler) getRepositoryRevisions(ctx context.Context, osBuildConfig *osbuilderv1alpha1.OSBuildConfig) ([]osbuilderv1alpha1.RepositoryRevision, error) {
+	if r.RevisionReader == nil {
+		return nil, fmt.Errorf("the revisions of the repositories cannot be read")
+	}
+	repositories, err := manifests.GetRepositories(&osBuildConfig.Spec.Details)
+	if err != nil {
+		return nil, err
+	}
+	return r.RevisionReader.GetRevisions(ctx, repositories)
+}
+
+// recordRepositoryRevisions records the revisions of the repositories of the new OSBuild when the scheduled builds
+// are skipped if the repositories did not change, or when the repository change trigger is set. No revision is
+// recorded when they cannot be read, so that the next scheduled run is not skipped and the next repository check
+// takes the revisions it reads as the reference
+func (r *OSBuildConfigReconciler) recordRepositoryRevisions(ctx context.Context, logger logr.Logger, osBuildConfig *osbuilderv1alpha1.OSBuildConfig) error {
+	schedule := osBuildConfig.Spec.Triggers.Schedule
+	if (schedule == nil || schedule.SkipIfUnchanged == nil || !*schedule.SkipIfUnchanged) && osBuildConfig.Spec.Triggers.RepositoryChange == nil {
+		return nil
+	}
+
+	revisions, err := r.getRepositoryRevisions(ctx, osBuildConfig)
+	if err != nil {
+		logger.Error(err, "failed to read the revisions of the repositories")
+		revisions = nil
+	}
+
+	patch := client.MergeFrom(osBuildConfig.DeepCopy())
+	osBuildConfig.Status.RepositoryRevisions = revisions
+	if osBuildConfig.Spec.Triggers.RepositoryChange != nil {
+		setOSBuildConfigCondition(osBuildConfig, osbuilderv1alpha1.ConditionUpdatesAvailable, metav1.ConditionFalse, "")
+	}
+	return r.OSBuildConfigRepository.PatchStatus(ctx, osBuildConfig, &patch)
+}
+
+// isUnchangedSinceLastBuild returns whether the spec of the OSBuildConfig and its template are the ones of the last
+// OSBuild. The OSBuildConfig is considered changed when the generation of the last OSBuild was not recorded
+func isUnchangedSinceLastBuild(osBuildConfig *osbuilderv1alpha1.OSBuildConfig) bool {
+	if osBuildConfig.Status.Schedule == nil || osBuildConfig.Status.Schedule.LastBuildGeneration == nil ||
+		*osBuildConfig.Status.Schedule.LastBuildGeneration != osBuildConfig.Generation {
+		return false
+	}
+	lastTemplateVersion := osBuildConfig.Status.LastTemplateResourceVersion
+	currentTemplateVersion := osBuildConfig.Status.CurrentTemplateResourceVersion
+	return lastTemplateVersion == nil || currentTemplateVersion == nil || *lastTemplateVersion == *currentTemplateVersion
+}
+
+// recordLastBuildGeneration records the generation of the OSBuildConfig of the new OSBuild when the scheduled builds
+// are skipped if nothing changed
+func (r *OSBuildConfigReconciler) recordLastBuildGeneration(ctx context.Context, osBuildConfig *osbuilderv1alpha1.OSBuildConfig) error {
+	trigger := osBuildConfig.Spec.Triggers.Schedule
+	if trigger == nil || trigger.SkipIfUnchanged == nil || !*trigger.SkipIfUnchanged {
+		return nil
+	}
+
+	patch := client.MergeFrom(osBuildConfig.DeepCopy())
+	if osBuildConfig.Status.Schedule == nil {
+		osBuildConfig.Status.Schedule = &osbuilderv1alpha1.ScheduleStatus{}
+	}
+	generation := osBuildConfig.Generation
+	osBuildConfig.Status.Schedule.LastBuildGeneration = &generation
+	return r.OSBuildConfigRepository.PatchStatus(ctx, osBuildConfig, &patch)
+}
+
+// rollback restores the user configuration of the OSBuild of the requested version and removes the rollback
+// annotation. The last known user configuration is reset so that a new OSBuild is built even when the restored user
+// configuration matches the current one
+func (r *OSBuildConfigReconciler) rollback(ctx context.Context, logger logr.Logger, osBuildConfig *osbuilderv1alpha1.OSBuildConfig, version string) (ctrl.Result, error) {
+	userConfiguration, msg, err := r.getRollbackUserConfiguration(ctx, osBuildConfig, version)
+	if err != nil {
+		logger.Error(err, "failed to get the OSBuild to roll back to", "version", version)
+		return ctrl.Result{Requeue: true, RequeueAfter: RequeueForShortDuration}, nil
+	}
+
+	osBuildConfigOld := osBuildConfig.DeepCopy()
+	patch := client.MergeFrom(osBuildConfigOld)
+	osBuildConfig.Status.LastRollback = &osbuilderv1alpha1.Rollback{
+		Version:   version,
+		Succeeded: userConfiguration != nil,
+		Message:   msg,
+		Time:      metav1.Now(),
+	}
+	if userConfiguration != nil {
+		osBuildConfig.Status.LastKnownUserConfiguration = nil
+	}
+	err = r.OSBuildConfigRepository.PatchStatus(ctx, osBuildConfig, &patch)
+	if err != nil {
+		logger.Error(err, "Failed to patch OSBuildConfig status")
+		return ctrl.Result{Requeue: true, RequeueAfter: RequeueForShortDuration}, nil
+	}
+
+	delete(osBuildConfig.Annotations, osbuilderv1alpha1.RollbackAnnotationKey)
+	if userConfiguration != nil {
+		osBuildConfig.Spec.Details.Customizations = userConfiguration.Customizations.DeepCopy()
+		osBuildConfig.Spec.Template = userConfiguration.Template.DeepCopy()
+	}
+	err = r.OSBuildConfigRepository.Patch(ctx, osBuildConfigOld, osBuildConfig)
+	if err != nil {
+		logger.Error(err, "Failed to roll back OSBuildConfig", "version", version)
+		return ctrl.Result{Requeue: true, RequeueAfter: RequeueForShortDuration}, nil
+	}
+
+	if userConfiguration == nil {
+		logger.Info("Cannot roll back OSBuildConfig", "version", version, "reason", msg)
+		return ctrl.Result{}, nil
+	}
+
+	logger.Info("Rolled back OSBuildConfig", "version", version)
+	return resultQuickRequeue, nil
+}
+
+// getRollbackUserConfiguration returns the user configuration recorded by the OSBuild of the version, or the reason
+// why the OSBuildConfig cannot be rolled back to it
+func (r *OSBuildConfigReconciler) getRollbackUserConfiguration(ctx context.Context, osBuildConfig *osbuilderv1alpha1.OSBuildConfig, version string) (*osbuilderv1alpha1.UserConfiguration, string, error) {
+	versionNumber, err := strconv.Atoi(version)
+	if err != nil || versionNumber < 1 {
+		return nil, fmt.Sprintf(rollbackInvalidVersionMsg, version), nil
+	}
+
+	osBuildName := fmt.Sprintf("%s-%d", osBuildConfig.Name, versionNumber)
+	osBuild, err := r.OSBuildRepository.Read(ctx, osBuildName, osBuildConfig.Namespace)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, fmt.Sprintf(rollbackOSBuildNotFoundMsg, osBuildName), nil
+		}
+		return nil, "", err
+	}
+
+	if osBuild.Spec.UserConfiguration == nil {
+		return nil, fmt.Sprintf(rollbackNoUserConfigurationMsg, osBuildName), nil
 	}
+	return osBuild.Spec.UserConfiguration, "", nil
 }
 
-func (r *OSBuildConfigReconciler) checkIfNewOSBuildInstanceIsNeeded(ctx context.Context, osBuildConfig *osbuilderv1alpha1.OSBuildConfig, logger logr.Logger) (bool, error) {
+// checkIfNewOSBuildInstanceIsNeeded returns whether the user configuration changed or a webhook was called since the
+// last OSBuild, along with the details of the webhook call
+func (r *OSBuildConfigReconciler) checkIfNewOSBuildInstanceIsNeeded(ctx context.Context, osBuildConfig *osbuilderv1alpha1.OSBuildConfig,
+	logger logr.Logger) (bool, *osbuilderv1alpha1.WebHookTriggerDetails, error) {
 	userConfiguration := r.getSortedUserConfiguration(osBuildConfig)
 	userConfigOrWebhookAnnotationWereChanged := false
+	var trigger *osbuilderv1alpha1.WebHookTriggerDetails
 	patch := client.MergeFrom(osBuildConfig.DeepCopy())
 
 	if osBuildConfig.Status.LastKnownUserConfiguration == nil || !cmp.Equal(osBuildConfig.Status.LastKnownUserConfiguration, &userConfiguration) {
@@ -121,6 +567,7 @@ func (r *OSBuildConfigReconciler) checkIfNewOSBuildInstanceIsNeeded(ctx context.
 				logger.Info("LastWebhookTriggerTS OR LastWebhookTriggerTS were changed")
 				osBuildConfig.Status.LastWebhookTriggerTS = webhookTriggerTS
 				userConfigOrWebhookAnnotationWereChanged = true
+				trigger = getWebHookTriggerDetails(logger, osBuildConfig)
 			}
 		}
 	}
@@ -129,11 +576,51 @@ func (r *OSBuildConfigReconciler) checkIfNewOSBuildInstanceIsNeeded(ctx context.
 		errPatch := r.OSBuildConfigRepository.PatchStatus(ctx, osBuildConfig, &patch)
 		if errPatch != nil {
 			logger.Error(errPatch, "Failed to patch OSBuildConfig status")
-			return false, errPatch
+			return false, nil, errPatch
 		}
 	}
 
-	return userConfigOrWebhookAnnotationWereChanged, nil
+	return userConfigOrWebhookAnnotationWereChanged, trigger, nil
+}
+
+// getWebHookTriggerDetails returns the details the trigger API recorded about the last webhook call. Without any, the
+// generic webhook was called
+func getWebHookTriggerDetails(logger logr.Logger, osBuildConfig *osbuilderv1alpha1.OSBuildConfig) *osbuilderv1alpha1.WebHookTriggerDetails {
+	triggerID := osBuildConfig.Annotations[webHookAnnotationKey]
+	trigger := &osbuilderv1alpha1.WebHookTriggerDetails{ID: triggerID, TriggeredBy: osbuilderv1alpha1.TriggeredByWebhook}
+	details, ok := osBuildConfig.Annotations[osbuilderv1alpha1.WebHookTriggerDetailsAnnotationKey]
+	if !ok {
+		return trigger
+	}
+	err := json.Unmarshal([]byte(details), trigger)
+	if err != nil || trigger.TriggeredBy == "" {
+		logger.Error(err, "invalid webhook trigger details, ignoring them")
+		return &osbuilderv1alpha1.WebHookTriggerDetails{ID: triggerID, TriggeredBy: osbuilderv1alpha1.TriggeredByWebhook}
+	}
+	trigger.ID = triggerID
+	return trigger
+}
+
+// getOSBuildTrigger returns the webhook call recorded on the OSBuild, if any, so that the edge-installer OSBuild
+// records the same one, and is built with the same overrides, as the edge-container OSBuild it follows
+func getOSBuildTrigger(osBuild *osbuilderv1alpha1.OSBuild) *osbuilderv1alpha1.WebHookTriggerDetails {
+	if osBuild.Spec.TriggeredBy == "" || osBuild.Spec.TriggeredBy == osbuilderv1alpha1.TriggeredByUpdateCR {
+		return nil
+	}
+	trigger := &osbuilderv1alpha1.WebHookTriggerDetails{
+		ID:          osBuild.Annotations[osbuilderv1alpha1.TriggerIDAnnotationKey],
+		TriggeredBy: osBuild.Spec.TriggeredBy,
+		Commit:      osBuild.Annotations[osbuilderv1alpha1.CommitAnnotationKey],
+		Author:      osBuild.Annotations[osbuilderv1alpha1.CommitAuthorAnnotationKey],
+		Ref:         osBuild.Annotations[osbuilderv1alpha1.RefAnnotationKey],
+	}
+	if overrides, ok := osBuild.Annotations[osbuilderv1alpha1.BuildOverridesAnnotationKey]; ok {
+		trigger.Overrides = &osbuilderv1alpha1.BuildOverrides{}
+		if err := json.Unmarshal([]byte(overrides), trigger.Overrides); err != nil {
+			trigger.Overrides = nil
+		}
+	}
+	return trigger
 }
 
 func (r *OSBuildConfigReconciler) getSortedUserConfiguration(osBuildConfig *osbuilderv1alpha1.OSBuildConfig) osbuilderv1alpha1.UserConfiguration {
@@ -148,12 +635,49 @@ func (r *OSBuildConfigReconciler) getSortedUserConfiguration(osBuildConfig *osbu
 			})
 		}
 
+		if userConfiguration.Customizations.Groups != nil {
+			sort.SliceStable(userConfiguration.Customizations.Groups, func(i, j int) bool {
+				return userConfiguration.Customizations.Groups[i].Name < userConfiguration.Customizations.Groups[j].Name
+			})
+		}
+
 		if userConfiguration.Customizations.Services != nil {
 			sort.Strings(userConfiguration.Customizations.Services.Disabled)
 			sort.Strings(userConfiguration.Customizations.Services.Enabled)
 
 		}
 
+		if userConfiguration.Customizations.Timezone != nil {
+			sort.Strings(userConfiguration.Customizations.Timezone.NTPServers)
+		}
+
+		if userConfiguration.Customizations.Firewall != nil {
+			sort.Strings(userConfiguration.Customizations.Firewall.Ports)
+			if userConfiguration.Customizations.Firewall.Services != nil {
+				sort.Strings(userConfiguration.Customizations.Firewall.Services.Disabled)
+				sort.Strings(userConfiguration.Customizations.Firewall.Services.Enabled)
+			}
+		}
+
+		if userConfiguration.Customizations.Directories != nil {
+			sort.SliceStable(userConfiguration.Customizations.Directories, func(i, j int) bool {
+				return userConfiguration.Customizations.Directories[i].Path < userConfiguration.Customizations.Directories[j].Path
+			})
+		}
+
+		if userConfiguration.Customizations.Files != nil {
+			sort.SliceStable(userConfiguration.Customizations.Files, func(i, j int) bool {
+				return userConfiguration.Customizations.Files[i].Path < userConfiguration.Customizations.Files[j].Path
+			})
+		}
+
+		if userConfiguration.Customizations.PayloadRepositories != nil {
+			sort.SliceStable(userConfiguration.Customizations.PayloadRepositories, func(i, j int) bool {
+				return customizations.RepositoryURL(userConfiguration.Customizations.PayloadRepositories[i]) <
+					customizations.RepositoryURL(userConfiguration.Customizations.PayloadRepositories[j])
+			})
+		}
+
 	}
 	if osBuildConfig.Spec.Template != nil {
 		userConfiguration.Template = osBuildConfig.Spec.Template
@@ -176,28 +700,160 @@ func (r *OSBuildConfigReconciler) updateOSBuildConfigCurrentStatus(ctx context.C
 
 	osBuildStatus := getCondition(osBuild.Status.Conditions)
 
-	switch osBuildStatus {
-	case osbuilderv1alpha1.ConditionFailed:
+	switch {
+	case osbuilderv1alpha1.IsFailureCondition(osBuildStatus):
 		logger.Info("Last OSBuild instance has failed")
-		return ctrl.Result{}, nil
+		return r.collectGarbage(ctx, logger, osBuildConfig)
 
-	case osbuilderv1alpha1.ConditionInProgress:
+	case osBuildStatus == osbuilderv1alpha1.ConditionInProgress:
 		logger.Info("Last OSBuild instance still in progress")
 		return ctrl.Result{Requeue: true, RequeueAfter: RequeueForLongDuration}, nil
 
-	case osbuilderv1alpha1.ConditionReady:
+	case osBuildStatus == osbuilderv1alpha1.ConditionReady:
 		if osBuildConfig.Spec.Details.TargetImage.TargetImageType != osbuilderv1alpha1.EdgeInstallerImageType || *osBuildConfig.Status.LastBuildType == osbuilderv1alpha1.EdgeInstallerImageType {
-			return ctrl.Result{}, nil
+			return r.collectGarbage(ctx, logger, osBuildConfig)
 		}
 
 		// last build was edge-container - now need to create OSBuild instance for edge-installer
-		return r.createOSBuildInstance(ctx, logger, osBuildConfig, osbuilderv1alpha1.EdgeInstallerImageType)
+		return r.createOSBuildInstance(ctx, logger, osBuildConfig, osbuilderv1alpha1.EdgeInstallerImageType, getOSBuildTrigger(osBuild))
 
 	default:
 		return ctrl.Result{Requeue: true, RequeueAfter: RequeueForShortDuration}, nil
 	}
 }
 
+// collectGarbage deletes the oldest finished OSBuilds of the OSBuildConfig beyond its history limits, along with their
+// artifacts. The OSBuilds referenced by an OSBuildRelease are kept
+func (r *OSBuildConfigReconciler) collectGarbage(ctx context.Context, logger logr.Logger, osBuildConfig *osbuilderv1alpha1.OSBuildConfig) (ctrl.Result, error) {
+	if osBuildConfig.Spec.HistoryLimits == nil {
+		return ctrl.Result{}, nil
+	}
+
+	osBuilds, err := r.OSBuildRepository.ListByOSBuildConfig(ctx, osBuildConfig.Name, osBuildConfig.Namespace)
+	if err != nil {
+		logger.Error(err, "failed to list the OSBuilds of the OSBuildConfig")
+		return ctrl.Result{Requeue: true, RequeueAfter: RequeueForShortDuration}, nil
+	}
+
+	osBuildReleases, err := r.OSBuildReleaseRepository.List(ctx, osBuildConfig.Namespace)
+	if err != nil {
+		logger.Error(err, "failed to list the OSBuildReleases")
+		return ctrl.Result{Requeue: true, RequeueAfter: RequeueForShortDuration}, nil
+	}
+
+	for _, osBuild := range getExpiredOSBuilds(osBuildConfig, osBuilds, getReleasedOSBuildNames(osBuildReleases)) {
+		err = r.deleteOSBuild(ctx, osBuild)
+		if err != nil {
+			logger.Error(err, "failed to delete the OSBuild beyond the history limits", "OSBuild", osBuild.Name)
+			return ctrl.Result{Requeue: true, RequeueAfter: RequeueForShortDuration}, nil
+		}
+		logger.Info("Deleted the OSBuild beyond the history limits", "OSBuild", osBuild.Name)
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// deleteOSBuild deletes the OSBuild after the images it uploaded to S3. The kickstart ConfigMap and the jobs of the
+// OSBuild are deleted thanks to their owner reference
+func (r *OSBuildConfigReconciler) deleteOSBuild(ctx context.Context, osBuild *osbuilderv1alpha1.OSBuild) error {
+	var objectUrls []string
+	if osBuild.Spec.Details != nil && uploadTypeForTargetImageType[osBuild.Spec.Details.TargetImage.TargetImageType] == composer.UploadTypesAwsS3 &&
+		osBuild.Status.AccessUrl != emptyURL {
+		objectUrls = append(objectUrls, osBuild.Status.AccessUrl)
+	}
+	if osBuild.Status.ComposerIso != emptyURL {
+		objectUrls = append(objectUrls, osBuild.Status.ComposerIso)
+	}
+
+	for _, objectUrl := range objectUrls {
+		err := r.S3ObjectStore.RemoveObject(ctx, objectUrl)
+		if err != nil {
+			return err
+		}
+	}
+
+	err := r.OSBuildRepository.Delete(ctx, osBuild)
+	if errors.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+// getExpiredOSBuilds returns the Ready and Failed OSBuilds beyond the history limits, newest first. The last OSBuild,
+// the released OSBuilds and the OSTree parents of the kept OSBuilds are never returned
+func getExpiredOSBuilds(osBuildConfig *osbuilderv1alpha1.OSBuildConfig, osBuilds []osbuilderv1alpha1.OSBuild, released map[string]bool) []*osbuilderv1alpha1.OSBuild {
+	sorted := make([]*osbuilderv1alpha1.OSBuild, 0, len(osBuilds))
+	for i := range osBuilds {
+		sorted = append(sorted, &osBuilds[i])
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if !sorted[i].CreationTimestamp.Equal(&sorted[j].CreationTimestamp) {
+			return sorted[j].CreationTimestamp.Before(&sorted[i].CreationTimestamp)
+		}
+		return sorted[i].Name > sorted[j].Name
+	})
+
+	var lastOSBuildName string
+	if osBuildConfig.Status.LastVersion != nil {
+		lastOSBuildName = fmt.Sprintf("%s-%d", osBuildConfig.Name, *osBuildConfig.Status.LastVersion)
+	}
+
+	historyLimits := osBuildConfig.Spec.HistoryLimits
+	var successful, failed int32
+	var kept, candidates []*osbuilderv1alpha1.OSBuild
+	for _, osBuild := range sorted {
+		var limit *int32
+		var count *int32
+		switch {
+		case osBuild.IsReady():
+			limit, count = historyLimits.SuccessfulBuildsHistoryLimit, &successful
+		case osBuild.IsFailed():
+			limit, count = historyLimits.FailedBuildsHistoryLimit, &failed
+		}
+
+		if released[osBuild.Name] {
+			kept = append(kept, osBuild)
+			continue
+		}
+		if osBuild.Name == lastOSBuildName || limit == nil || *count < *limit {
+			kept = append(kept, osBuild)
+			if count != nil {
+				*count++
+			}
+			continue
+		}
+		candidates = append(candidates, osBuild)
+	}
+
+	parents := make(map[string]bool)
+	for _, osBuild := range kept {
+		if osBuild.Spec.Details != nil && osBuild.Spec.Details.TargetImage.OSTree != nil && osBuild.Spec.Details.TargetImage.OSTree.Parent != nil {
+			parents[*osBuild.Spec.Details.TargetImage.OSTree.Parent] = true
+		}
+	}
+
+	var expired []*osbuilderv1alpha1.OSBuild
+	for _, osBuild := range candidates {
+		if osBuild.Status.OSTreeCommit != emptyOSTreeCommit && parents[osBuild.Status.OSTreeCommit] {
+			continue
+		}
+		expired = append(expired, osBuild)
+	}
+	return expired
+}
+
+// getReleasedOSBuildNames returns the names of the OSBuilds the releases are promoting or currently point at
+func getReleasedOSBuildNames(osBuildReleases []osbuilderv1alpha1.OSBuildRelease) map[string]bool {
+	released := make(map[string]bool)
+	for _, osBuildRelease := range osBuildReleases {
+		released[osBuildRelease.Spec.OSBuildRef] = true
+		if osBuildRelease.Status.Current != nil {
+			released[osBuildRelease.Status.Current.OSBuildName] = true
+		}
+	}
+	return released
+}
+
 func getCondition(conditions []osbuilderv1alpha1.Condition) osbuilderv1alpha1.ConditionType {
 	for _, c := range conditions {
 		if c.Status == metav1.ConditionTrue {
@@ -217,9 +873,10 @@ func (r *OSBuildConfigReconciler) setOSBuildConfigLastBuildTargetType(ctx contex
 	return nil
 }
 
-func (r *OSBuildConfigReconciler) createOSBuildInstance(ctx context.Context, logger logr.Logger, osBuildConfig *osbuilderv1alpha1.OSBuildConfig, targetImageType osbuilderv1alpha1.TargetImageType) (ctrl.Result, error) {
+func (r *OSBuildConfigReconciler) createOSBuildInstance(ctx context.Context, logger logr.Logger, osBuildConfig *osbuilderv1alpha1.OSBuildConfig,
+	targetImageType osbuilderv1alpha1.TargetImageType, trigger *osbuilderv1alpha1.WebHookTriggerDetails) (ctrl.Result, error) {
 	//Set status to InProgress order to avoid multiple OSBuild instances creation
-	err := r.OSBuildCRCreator.Create(ctx, osBuildConfig, targetImageType)
+	err := r.OSBuildCRCreator.Create(ctx, osBuildConfig, targetImageType, trigger)
 	if err != nil {
 		return ctrl.Result{Requeue: true, RequeueAfter: RequeueForShortDuration}, nil
 	}
@@ -229,6 +886,21 @@ func (r *OSBuildConfigReconciler) createOSBuildInstance(ctx context.Context, log
 		logger.Error(errPatch, FailedPatchLastTargetType)
 	}
 
+	if targetImageType != osbuilderv1alpha1.EdgeInstallerImageType {
+		errPatch = r.recordScheduledRun(ctx, osBuildConfig)
+		if errPatch != nil {
+			logger.Error(errPatch, "Failed to record the scheduled run")
+		}
+		errPatch = r.recordLastBuildGeneration(ctx, osBuildConfig)
+		if errPatch != nil {
+			logger.Error(errPatch, "Failed to record the generation of the last OSBuild")
+		}
+		errPatch = r.recordRepositoryRevisions(ctx, logger, osBuildConfig)
+		if errPatch != nil {
+			logger.Error(errPatch, "Failed to record the revisions of the repositories")
+		}
+	}
+
 	return ctrl.Result{Requeue: true, RequeueAfter: RequeueForLongDuration}, nil
 }
 
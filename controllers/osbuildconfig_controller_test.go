@@ -3,6 +3,7 @@ package controllers_test
 import (
 	"context"
 	"fmt"
+	"os"
 	"time"
 
 	"github.com/golang/mock/gomock"
@@ -13,12 +14,17 @@ import (
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	osbuildv1alpha1 "github.com/project-flotta/osbuild-operator/api/v1alpha1"
 	"github.com/project-flotta/osbuild-operator/controllers"
+	"github.com/project-flotta/osbuild-operator/internal/conf"
 	"github.com/project-flotta/osbuild-operator/internal/manifests"
 	"github.com/project-flotta/osbuild-operator/internal/repository/osbuild"
 	"github.com/project-flotta/osbuild-operator/internal/repository/osbuildconfig"
+	"github.com/project-flotta/osbuild-operator/internal/repository/osbuildrelease"
+	"github.com/project-flotta/osbuild-operator/internal/rpmmd"
+	"github.com/project-flotta/osbuild-operator/internal/s3"
 )
 
 var _ = Describe("OSBuildConfig Controller", func() {
@@ -31,15 +37,18 @@ var _ = Describe("OSBuildConfig Controller", func() {
 		architecture = "x86_64"
 	)
 	var (
-		mockCtrl                *gomock.Controller
-		osBuildRepository       *osbuild.MockRepository
-		osBuildConfigRepository *osbuildconfig.MockRepository
-		osBuildCRCreator        *manifests.MockOSBuildCRCreator
-		reconciler              *controllers.OSBuildConfigReconciler
-		requestContext          context.Context
-		osbuildConfigInstance   *osbuildv1alpha1.OSBuildConfig
-		customizations          *osbuildv1alpha1.Customizations
-		osbuildInstance         *osbuildv1alpha1.OSBuild
+		mockCtrl                 *gomock.Controller
+		osBuildRepository        *osbuild.MockRepository
+		osBuildConfigRepository  *osbuildconfig.MockRepository
+		osBuildCRCreator         *manifests.MockOSBuildCRCreator
+		s3ObjectStore            *s3.MockObjectStore
+		osBuildReleaseRepository *osbuildrelease.MockRepository
+		revisionReader           *rpmmd.MockRevisionReader
+		reconciler               *controllers.OSBuildConfigReconciler
+		requestContext           context.Context
+		osbuildConfigInstance    *osbuildv1alpha1.OSBuildConfig
+		customizations           *osbuildv1alpha1.Customizations
+		osbuildInstance          *osbuildv1alpha1.OSBuild
 
 		request = ctrl.Request{
 			NamespacedName: types.NamespacedName{
@@ -75,11 +84,17 @@ var _ = Describe("OSBuildConfig Controller", func() {
 		osBuildRepository = osbuild.NewMockRepository(mockCtrl)
 		osBuildConfigRepository = osbuildconfig.NewMockRepository(mockCtrl)
 		osBuildCRCreator = manifests.NewMockOSBuildCRCreator(mockCtrl)
+		s3ObjectStore = s3.NewMockObjectStore(mockCtrl)
+		osBuildReleaseRepository = osbuildrelease.NewMockRepository(mockCtrl)
+		revisionReader = rpmmd.NewMockRevisionReader(mockCtrl)
 
 		reconciler = &controllers.OSBuildConfigReconciler{
-			OSBuildConfigRepository: osBuildConfigRepository,
-			OSBuildRepository:       osBuildRepository,
-			OSBuildCRCreator:        osBuildCRCreator,
+			OSBuildConfigRepository:  osBuildConfigRepository,
+			OSBuildRepository:        osBuildRepository,
+			OSBuildCRCreator:         osBuildCRCreator,
+			S3ObjectStore:            s3ObjectStore,
+			OSBuildReleaseRepository: osBuildReleaseRepository,
+			RevisionReader:           revisionReader,
 		}
 
 		requestContext = context.TODO()
@@ -188,6 +203,103 @@ var _ = Describe("OSBuildConfig Controller", func() {
 		})
 	})
 
+	Context("Rollback", func() {
+		const rollbackOSBuildName = instanceName + "-2"
+
+		BeforeEach(func() {
+			lastVersion := 3
+			osbuildConfigInstance.Status.LastVersion = &lastVersion
+			osbuildConfigInstance.Status.LastKnownUserConfiguration = &osbuildv1alpha1.UserConfiguration{Customizations: customizations}
+			osbuildConfigInstance.Annotations = map[string]string{osbuildv1alpha1.RollbackAnnotationKey: "2"}
+			osBuildConfigRepository.EXPECT().Read(requestContext, instanceName, instanceNamespace).Return(osbuildConfigInstance, nil)
+		})
+
+		It("should restore the user configuration of the OSBuild and trigger a new build", func() {
+			// given
+			userConfiguration := &osbuildv1alpha1.UserConfiguration{
+				Customizations: &osbuildv1alpha1.Customizations{Packages: []string{"pkg1"}},
+				Template: &osbuildv1alpha1.Template{
+					OSBuildConfigTemplateRef: "template",
+					Parameters:               []osbuildv1alpha1.ParameterValue{{Name: "param", Value: "old"}},
+				},
+			}
+			rollbackOSBuild := osbuildInstance.DeepCopy()
+			rollbackOSBuild.Name = rollbackOSBuildName
+			rollbackOSBuild.Spec.UserConfiguration = userConfiguration
+			osBuildRepository.EXPECT().Read(requestContext, rollbackOSBuildName, instanceNamespace).Return(rollbackOSBuild, nil)
+			osBuildConfigRepository.EXPECT().PatchStatus(requestContext, osbuildConfigInstance, gomock.Any()).DoAndReturn(
+				func(ctx context.Context, osBuildConfig *osbuildv1alpha1.OSBuildConfig, patch *client.Patch) error {
+					Expect(osBuildConfig.Status.LastRollback.Version).To(Equal("2"))
+					Expect(osBuildConfig.Status.LastRollback.Succeeded).To(BeTrue())
+					Expect(osBuildConfig.Status.LastKnownUserConfiguration).To(BeNil())
+					return nil
+				})
+			osBuildConfigRepository.EXPECT().Patch(requestContext, gomock.Any(), osbuildConfigInstance).DoAndReturn(
+				func(ctx context.Context, old, new *osbuildv1alpha1.OSBuildConfig) error {
+					Expect(old.Annotations).To(HaveKey(osbuildv1alpha1.RollbackAnnotationKey))
+					Expect(new.Annotations).ToNot(HaveKey(osbuildv1alpha1.RollbackAnnotationKey))
+					Expect(new.Spec.Details.Customizations).To(Equal(userConfiguration.Customizations))
+					Expect(new.Spec.Template).To(Equal(userConfiguration.Template))
+					Expect(new.Spec.Details.Distribution).To(Equal(distribution))
+					return nil
+				})
+
+			// when
+			result, err := reconciler.Reconcile(requestContext, request)
+
+			// then
+			Expect(err).To(BeNil())
+			Expect(result).To(Equal(ctrl.Result{RequeueAfter: time.Second}))
+		})
+
+		DescribeTable("should record the failed rollback and keep the user configuration", func(version string, rollbackOSBuild *osbuildv1alpha1.OSBuild, expectedMsg string) {
+			// given
+			osbuildConfigInstance.Annotations[osbuildv1alpha1.RollbackAnnotationKey] = version
+			if rollbackOSBuild != nil {
+				osBuildRepository.EXPECT().Read(requestContext, rollbackOSBuildName, instanceNamespace).Return(rollbackOSBuild, nil)
+			} else if version == "2" {
+				osBuildRepository.EXPECT().Read(requestContext, rollbackOSBuildName, instanceNamespace).Return(nil, errNotFound)
+			}
+			osBuildConfigRepository.EXPECT().PatchStatus(requestContext, osbuildConfigInstance, gomock.Any()).DoAndReturn(
+				func(ctx context.Context, osBuildConfig *osbuildv1alpha1.OSBuildConfig, patch *client.Patch) error {
+					Expect(osBuildConfig.Status.LastRollback.Succeeded).To(BeFalse())
+					Expect(osBuildConfig.Status.LastRollback.Message).To(Equal(expectedMsg))
+					Expect(osBuildConfig.Status.LastKnownUserConfiguration).ToNot(BeNil())
+					return nil
+				})
+			osBuildConfigRepository.EXPECT().Patch(requestContext, gomock.Any(), osbuildConfigInstance).DoAndReturn(
+				func(ctx context.Context, old, new *osbuildv1alpha1.OSBuildConfig) error {
+					Expect(new.Annotations).ToNot(HaveKey(osbuildv1alpha1.RollbackAnnotationKey))
+					Expect(new.Spec).To(Equal(old.Spec))
+					return nil
+				})
+
+			// when
+			result, err := reconciler.Reconcile(requestContext, request)
+
+			// then
+			Expect(err).To(BeNil())
+			Expect(result).To(Equal(resultDone))
+		},
+			Entry("when the version is invalid", "two", nil, `Invalid version "two"`),
+			Entry("when the OSBuild is not found", "2", nil, "OSBuild osbuild_test-2 not found"),
+			Entry("when the OSBuild does not record its user configuration", "2", &osbuildv1alpha1.OSBuild{},
+				"OSBuild osbuild_test-2 does not record the user configuration that produced it"),
+		)
+
+		It("should requeue when failing to get the OSBuild", func() {
+			// given
+			osBuildRepository.EXPECT().Read(requestContext, rollbackOSBuildName, instanceNamespace).Return(nil, errFailed)
+
+			// when
+			result, err := reconciler.Reconcile(requestContext, request)
+
+			// then
+			Expect(err).To(BeNil())
+			Expect(result).To(Equal(resultShortRequeue))
+		})
+	})
+
 	Context("New OSBuild instance is needed", func() {
 		DescribeTable("should requeue when failing to patch update", func(lastKnownUserConfiguration *osbuildv1alpha1.UserConfiguration, annotation map[string]string) {
 			// given
@@ -217,7 +329,7 @@ var _ = Describe("OSBuildConfig Controller", func() {
 			osbuildConfigInstance.Annotations = annotation
 			osBuildConfigRepository.EXPECT().Read(requestContext, instanceName, instanceNamespace).Return(osbuildConfigInstance, nil)
 			osBuildConfigRepository.EXPECT().PatchStatus(requestContext, osbuildConfigInstance, gomock.Any()).Return(nil)
-			osBuildCRCreator.EXPECT().Create(requestContext, osbuildConfigInstance, osbuildv1alpha1.EdgeContainerImageType).Return(errFailed)
+			osBuildCRCreator.EXPECT().Create(requestContext, osbuildConfigInstance, osbuildv1alpha1.EdgeContainerImageType, gomock.Any()).Return(errFailed)
 
 			// when
 			result, err := reconciler.Reconcile(requestContext, request)
@@ -239,7 +351,7 @@ var _ = Describe("OSBuildConfig Controller", func() {
 			osbuildConfigInstance.Annotations = annotation
 			osBuildConfigRepository.EXPECT().Read(requestContext, instanceName, instanceNamespace).Return(osbuildConfigInstance, nil)
 			osBuildConfigRepository.EXPECT().PatchStatus(requestContext, osbuildConfigInstance, gomock.Any()).Return(nil).Times(2)
-			osBuildCRCreator.EXPECT().Create(requestContext, osbuildConfigInstance, osbuildv1alpha1.EdgeContainerImageType).Return(nil)
+			osBuildCRCreator.EXPECT().Create(requestContext, osbuildConfigInstance, osbuildv1alpha1.EdgeContainerImageType, gomock.Any()).Return(nil)
 
 			// when
 			result, err := reconciler.Reconcile(requestContext, request)
@@ -253,6 +365,39 @@ var _ = Describe("OSBuildConfig Controller", func() {
 			Entry("because of the webHookAnnotationKey was changed", &osbuildv1alpha1.UserConfiguration{Customizations: &osbuildv1alpha1.Customizations{Packages: []string{"pkg1", "pkg2"}}}, map[string]string{"last_webhook_trigger_ts": "1111"}),
 			Entry("because of the webHookAnnotationKey was changed and also the LastKnownUserConfiguration is different from the current userConfiguration", &osbuildv1alpha1.UserConfiguration{Customizations: &osbuildv1alpha1.Customizations{Packages: []string{"pkg1"}}}, map[string]string{"last_webhook_trigger_ts": "1111"}),
 		)
+		DescribeTable("should record the webhook call on the new build", func(annotation map[string]string, trigger *osbuildv1alpha1.WebHookTriggerDetails) {
+			// given
+			osbuildConfigInstance.Status.LastKnownUserConfiguration = &osbuildv1alpha1.UserConfiguration{
+				Customizations: osbuildConfigInstance.Spec.Details.Customizations.DeepCopy(),
+			}
+			osbuildConfigInstance.Annotations = annotation
+			osBuildConfigRepository.EXPECT().Read(requestContext, instanceName, instanceNamespace).Return(osbuildConfigInstance, nil)
+			osBuildConfigRepository.EXPECT().PatchStatus(requestContext, osbuildConfigInstance, gomock.Any()).Return(nil).Times(2)
+			osBuildCRCreator.EXPECT().Create(requestContext, osbuildConfigInstance, osbuildv1alpha1.EdgeContainerImageType, trigger).Return(nil)
+
+			// when
+			result, err := reconciler.Reconcile(requestContext, request)
+
+			// then
+			Expect(err).To(BeNil())
+			Expect(result).To(Equal(resultLongRequeue))
+		},
+			Entry("when the generic webhook was called",
+				map[string]string{osbuildv1alpha1.WebHookTriggerAnnotationKey: "1111"},
+				&osbuildv1alpha1.WebHookTriggerDetails{ID: "1111", TriggeredBy: osbuildv1alpha1.TriggeredByWebhook}),
+			Entry("when the GitHub webhook was called",
+				map[string]string{
+					osbuildv1alpha1.WebHookTriggerAnnotationKey:        "1111",
+					osbuildv1alpha1.WebHookTriggerDetailsAnnotationKey: `{"triggeredBy":"GitHub","commit":"5f6a7b8c","author":"Jane Doe","ref":"refs/heads/main"}`,
+				},
+				&osbuildv1alpha1.WebHookTriggerDetails{ID: "1111", TriggeredBy: osbuildv1alpha1.TriggeredByGitHub, Commit: "5f6a7b8c", Author: "Jane Doe", Ref: "refs/heads/main"}),
+			Entry("when the webhook call details are invalid",
+				map[string]string{
+					osbuildv1alpha1.WebHookTriggerAnnotationKey:        "1111",
+					osbuildv1alpha1.WebHookTriggerDetailsAnnotationKey: "{",
+				},
+				&osbuildv1alpha1.WebHookTriggerDetails{ID: "1111", TriggeredBy: osbuildv1alpha1.TriggeredByWebhook}),
+		)
 	})
 
 	Context("OSBuildConfig status need to be updated", func() {
@@ -510,7 +655,7 @@ var _ = Describe("OSBuildConfig Controller", func() {
 			})
 			It("should requeue for short duration if fail on creation", func() {
 				// given
-				osBuildCRCreator.EXPECT().Create(requestContext, osbuildConfigInstance, osbuildv1alpha1.EdgeInstallerImageType).Return(errFailed)
+				osBuildCRCreator.EXPECT().Create(requestContext, osbuildConfigInstance, osbuildv1alpha1.EdgeInstallerImageType, gomock.Any()).Return(errFailed)
 
 				// when
 				result, err := reconciler.Reconcile(requestContext, request)
@@ -523,7 +668,485 @@ var _ = Describe("OSBuildConfig Controller", func() {
 			It("should requeue for long duration if the new OSBuild instance was created", func() {
 				// given
 				osBuildConfigRepository.EXPECT().PatchStatus(requestContext, osbuildConfigInstance, gomock.Any()).Return(nil)
-				osBuildCRCreator.EXPECT().Create(requestContext, osbuildConfigInstance, osbuildv1alpha1.EdgeInstallerImageType).Return(nil)
+				osBuildCRCreator.EXPECT().Create(requestContext, osbuildConfigInstance, osbuildv1alpha1.EdgeInstallerImageType, gomock.Any()).Return(nil)
+
+				// when
+				result, err := reconciler.Reconcile(requestContext, request)
+
+				// then
+				Expect(err).To(BeNil())
+				Expect(result).To(Equal(resultLongRequeue))
+			})
+
+			It("should create it with the webhook call of the edge-container OSBuild", func() {
+				// given
+				osbuildInstance.Spec.TriggeredBy = osbuildv1alpha1.TriggeredByWebhook
+				osbuildInstance.Annotations = map[string]string{
+					osbuildv1alpha1.TriggerIDAnnotationKey:      "6b9f1c2e-5d4a-4b8e-9f3c-2a1d0e7b6c5f",
+					osbuildv1alpha1.BuildOverridesAnnotationKey: `{"parameters":[{"name":"version","value":"1.2.3"}],"packages":["flotta-agent"]}`,
+				}
+				osBuildConfigRepository.EXPECT().PatchStatus(requestContext, osbuildConfigInstance, gomock.Any()).Return(nil)
+				osBuildCRCreator.EXPECT().Create(requestContext, osbuildConfigInstance, osbuildv1alpha1.EdgeInstallerImageType, &osbuildv1alpha1.WebHookTriggerDetails{
+					ID:          "6b9f1c2e-5d4a-4b8e-9f3c-2a1d0e7b6c5f",
+					TriggeredBy: osbuildv1alpha1.TriggeredByWebhook,
+					Overrides: &osbuildv1alpha1.BuildOverrides{
+						Parameters: []osbuildv1alpha1.ParameterValue{{Name: "version", Value: "1.2.3"}},
+						Packages:   []string{"flotta-agent"},
+					},
+				}).Return(nil)
+
+				// when
+				result, err := reconciler.Reconcile(requestContext, request)
+
+				// then
+				Expect(err).To(BeNil())
+				Expect(result).To(Equal(resultLongRequeue))
+			})
+		})
+
+		Context("with history limits", func() {
+			const (
+				installerUrl = "https://minio.example.com/images/installer-1.iso?X-Amz-Signature=abc"
+				parentCommit = "02604b2da6e954bd34b8b82a835e5a77d2b60ffa"
+			)
+			var osBuilds []osbuildv1alpha1.OSBuild
+
+			newOSBuild := func(version int, conditionType osbuildv1alpha1.ConditionType) osbuildv1alpha1.OSBuild {
+				osBuild := osbuildInstance.DeepCopy()
+				osBuild.Name = fmt.Sprintf("%s-%d", instanceName, version)
+				osBuild.CreationTimestamp = metav1.NewTime(time.Unix(int64(version), 0))
+				osBuild.Status.Conditions = []osbuildv1alpha1.Condition{{Type: conditionType, Status: metav1.ConditionTrue}}
+				return *osBuild
+			}
+
+			BeforeEach(func() {
+				successfulBuildsHistoryLimit := int32(2)
+				failedBuildsHistoryLimit := int32(0)
+				osbuildConfigInstance.Spec.HistoryLimits = &osbuildv1alpha1.HistoryLimits{
+					SuccessfulBuildsHistoryLimit: &successfulBuildsHistoryLimit,
+					FailedBuildsHistoryLimit:     &failedBuildsHistoryLimit,
+				}
+
+				osBuilds = []osbuildv1alpha1.OSBuild{
+					newOSBuild(1, osbuildv1alpha1.ConditionReady),
+					newOSBuild(2, osbuildv1alpha1.ConditionReady),
+					newOSBuild(3, osbuildv1alpha1.ConditionVulnerabilitiesFound),
+					newOSBuild(4, osbuildv1alpha1.ConditionReady),
+					newOSBuild(5, osbuildv1alpha1.ConditionFailed),
+					newOSBuild(6, osbuildv1alpha1.ConditionInProgress),
+				}
+				osBuilds[0].Spec.Details.TargetImage.TargetImageType = osbuildv1alpha1.EdgeInstallerImageType
+				osBuilds[0].Status.AccessUrl = installerUrl
+				osBuilds[1].Status.OSTreeCommit = parentCommit
+				parent := parentCommit
+				osBuilds[3].Spec.Details.TargetImage.OSTree = &osbuildv1alpha1.OSTreeConfig{Parent: &parent}
+
+				osBuildRepository.EXPECT().Read(requestContext, osBuildName, instanceNamespace).Return(&osBuilds[4], nil)
+				osBuildRepository.EXPECT().ListByOSBuildConfig(requestContext, instanceName, instanceNamespace).Return(osBuilds, nil)
+			})
+
+			It("should delete the oldest OSBuilds and their artifacts", func() {
+				// given
+				osBuildReleaseRepository.EXPECT().List(requestContext, instanceNamespace).Return(nil, nil)
+				s3ObjectStore.EXPECT().RemoveObject(requestContext, installerUrl).Return(nil)
+				// the last OSBuild, the OSTree parent and the OSBuild in progress are kept
+				osBuildRepository.EXPECT().Delete(requestContext, &osBuilds[2]).Return(nil)
+				osBuildRepository.EXPECT().Delete(requestContext, &osBuilds[0]).Return(nil)
+
+				// when
+				result, err := reconciler.Reconcile(requestContext, request)
+
+				// then
+				Expect(err).To(BeNil())
+				Expect(result).To(Equal(resultDone))
+			})
+
+			It("should keep the released OSBuilds", func() {
+				// given
+				osBuildReleaseRepository.EXPECT().List(requestContext, instanceNamespace).Return([]osbuildv1alpha1.OSBuildRelease{{
+					Spec: osbuildv1alpha1.OSBuildReleaseSpec{OSBuildRef: osBuilds[2].Name},
+					Status: osbuildv1alpha1.OSBuildReleaseStatus{
+						Current: &osbuildv1alpha1.OSBuildReleaseRecord{OSBuildName: osBuilds[0].Name},
+					},
+				}}, nil)
+
+				// when
+				result, err := reconciler.Reconcile(requestContext, request)
+
+				// then
+				Expect(err).To(BeNil())
+				Expect(result).To(Equal(resultDone))
+			})
+
+			It("should delete no OSBuild when the releases cannot be listed", func() {
+				// given
+				osBuildReleaseRepository.EXPECT().List(requestContext, instanceNamespace).Return(nil, errFailed)
+
+				// when
+				result, err := reconciler.Reconcile(requestContext, request)
+
+				// then
+				Expect(err).To(BeNil())
+				Expect(result).To(Equal(resultShortRequeue))
+			})
+
+			It("should keep the OSBuild when its artifacts cannot be deleted", func() {
+				// given
+				osBuildReleaseRepository.EXPECT().List(requestContext, instanceNamespace).Return(nil, nil)
+				osBuildRepository.EXPECT().Delete(requestContext, &osBuilds[2]).Return(nil)
+				s3ObjectStore.EXPECT().RemoveObject(requestContext, installerUrl).Return(errFailed)
+
+				// when
+				result, err := reconciler.Reconcile(requestContext, request)
+
+				// then
+				Expect(err).To(BeNil())
+				Expect(result).To(Equal(resultShortRequeue))
+			})
+		})
+
+		Context("with a schedule trigger", func() {
+			var (
+				skipIfUnchanged     bool
+				lastBuildGeneration int64
+				revisions           []osbuildv1alpha1.RepositoryRevision
+			)
+
+			BeforeEach(func() {
+				os.Setenv("WORKING_NAMESPACE", instanceNamespace)
+				os.Setenv("CA_ISSUER_NAME", "osbuild-issuer")
+				Expect(conf.Load()).To(Succeed())
+
+				skipIfUnchanged = true
+				timeZone := "Europe/Paris"
+				osbuildConfigInstance.Spec.Triggers.Schedule = &osbuildv1alpha1.ScheduleTrigger{
+					Cron:            "0 2 * * *",
+					TimeZone:        &timeZone,
+					SkipIfUnchanged: &skipIfUnchanged,
+				}
+				osbuildConfigInstance.Generation = 2
+				lastBuildGeneration = 2
+				revisions = []osbuildv1alpha1.RepositoryRevision{{Baseurl: "https://repo.example.com/baseos", Revision: "1"}}
+				osbuildConfigInstance.Status.RepositoryRevisions = revisions
+				osbuildInstance.Status.Conditions = []osbuildv1alpha1.Condition{{Type: osbuildv1alpha1.ConditionReady, Status: metav1.ConditionTrue}}
+			})
+
+			setScheduleStatus := func(nextScheduleTime time.Time) {
+				next := metav1.NewTime(nextScheduleTime)
+				osbuildConfigInstance.Status.Schedule = &osbuildv1alpha1.ScheduleStatus{NextScheduleTime: &next, LastBuildGeneration: &lastBuildGeneration}
+			}
+
+			expectScheduledRun := func(before time.Time, result ctrl.Result) {
+				paris, err := time.LoadLocation("Europe/Paris")
+				Expect(err).ToNot(HaveOccurred())
+				next := osbuildConfigInstance.Status.Schedule.NextScheduleTime.In(paris)
+				Expect(next.After(before)).To(BeTrue())
+				Expect(next.Sub(before)).To(BeNumerically("<=", 24*time.Hour))
+				Expect(next.Hour()).To(Equal(2))
+				Expect(next.Minute()).To(Equal(0))
+				Expect(result.RequeueAfter).To(BeNumerically("<=", time.Until(next)+time.Second))
+			}
+
+			It("should schedule the first run without building", func() {
+				// given
+				before := time.Now()
+				osBuildConfigRepository.EXPECT().PatchStatus(requestContext, osbuildConfigInstance, gomock.Any()).Return(nil)
+				osBuildRepository.EXPECT().Read(requestContext, osBuildName, instanceNamespace).Return(osbuildInstance, nil)
+
+				// when
+				result, err := reconciler.Reconcile(requestContext, request)
+
+				// then
+				Expect(err).To(BeNil())
+				Expect(osbuildConfigInstance.Status.Schedule.LastScheduleTime).To(BeNil())
+				Expect(osbuildConfigInstance.Status.Schedule.Message).To(BeEmpty())
+				expectScheduledRun(before, result)
+				Expect(result.RequeueAfter).To(BeNumerically(">", 0))
+			})
+
+			It("should requeue at the next run when it is not due", func() {
+				// given
+				next := time.Now().Add(time.Hour).Truncate(time.Minute)
+				setScheduleStatus(next)
+				osbuildConfigInstance.Spec.Triggers.Schedule.Cron = fmt.Sprintf("%d %d * * *", next.UTC().Minute(), next.UTC().Hour())
+				osbuildConfigInstance.Spec.Triggers.Schedule.TimeZone = nil
+				osBuildRepository.EXPECT().Read(requestContext, osBuildName, instanceNamespace).Return(osbuildInstance, nil)
+
+				// when
+				result, err := reconciler.Reconcile(requestContext, request)
+
+				// then
+				Expect(err).To(BeNil())
+				Expect(result.RequeueAfter).To(BeNumerically("~", time.Until(next), time.Second))
+			})
+
+			It("should keep the requeue of the last OSBuild when it is sooner than the next run", func() {
+				// given
+				osbuildInstance.Status.Conditions = []osbuildv1alpha1.Condition{{Type: osbuildv1alpha1.ConditionInProgress, Status: metav1.ConditionTrue}}
+				osBuildConfigRepository.EXPECT().PatchStatus(requestContext, osbuildConfigInstance, gomock.Any()).Return(nil)
+				osBuildRepository.EXPECT().Read(requestContext, osBuildName, instanceNamespace).Return(osbuildInstance, nil)
+
+				// when
+				result, err := reconciler.Reconcile(requestContext, request)
+
+				// then
+				Expect(err).To(BeNil())
+				Expect(result).To(Equal(resultLongRequeue))
+			})
+
+			It("should build when the run is due and the OSBuildConfig changed", func() {
+				// given
+				lastScheduleTime := time.Now().Add(-time.Minute).Truncate(time.Second)
+				lastBuildGeneration = 1
+				setScheduleStatus(lastScheduleTime)
+				revisionReader.EXPECT().GetRevisions(requestContext, gomock.Any()).Return(revisions, nil)
+				osBuildConfigRepository.EXPECT().PatchStatus(requestContext, osbuildConfigInstance, gomock.Any()).Return(nil).Times(4)
+				osBuildCRCreator.EXPECT().Create(requestContext, osbuildConfigInstance, osbuildv1alpha1.EdgeContainerImageType, gomock.Any()).Return(nil)
+
+				// when
+				result, err := reconciler.Reconcile(requestContext, request)
+
+				// then
+				Expect(err).To(BeNil())
+				Expect(result).To(Equal(resultLongRequeue))
+				Expect(osbuildConfigInstance.Status.Schedule.LastScheduleTime.Time).To(BeTemporally("==", lastScheduleTime))
+				Expect(osbuildConfigInstance.Status.Schedule.LastRunSkipped).To(BeFalse())
+				Expect(*osbuildConfigInstance.Status.Schedule.LastBuildGeneration).To(Equal(int64(2)))
+			})
+
+			It("should build when the run is due and the template changed", func() {
+				// given
+				lastTemplateVersion := "1"
+				currentTemplateVersion := "2"
+				osbuildConfigInstance.Status.LastTemplateResourceVersion = &lastTemplateVersion
+				osbuildConfigInstance.Status.CurrentTemplateResourceVersion = &currentTemplateVersion
+				setScheduleStatus(time.Now().Add(-time.Minute))
+				revisionReader.EXPECT().GetRevisions(requestContext, gomock.Any()).Return(revisions, nil)
+				osBuildConfigRepository.EXPECT().PatchStatus(requestContext, osbuildConfigInstance, gomock.Any()).Return(nil).Times(4)
+				osBuildCRCreator.EXPECT().Create(requestContext, osbuildConfigInstance, osbuildv1alpha1.EdgeContainerImageType, gomock.Any()).Return(nil)
+
+				// when
+				result, err := reconciler.Reconcile(requestContext, request)
+
+				// then
+				Expect(err).To(BeNil())
+				Expect(result).To(Equal(resultLongRequeue))
+				Expect(osbuildConfigInstance.Status.Schedule.LastRunSkipped).To(BeFalse())
+			})
+
+			It("should skip the run when nothing changed", func() {
+				// given
+				before := time.Now()
+				setScheduleStatus(before.Add(-time.Minute))
+				revisionReader.EXPECT().GetRevisions(requestContext, gomock.Any()).Return(revisions, nil)
+				osBuildConfigRepository.EXPECT().PatchStatus(requestContext, osbuildConfigInstance, gomock.Any()).Return(nil)
+				osBuildRepository.EXPECT().Read(requestContext, osBuildName, instanceNamespace).Return(osbuildInstance, nil)
+
+				// when
+				result, err := reconciler.Reconcile(requestContext, request)
+
+				// then
+				Expect(err).To(BeNil())
+				Expect(osbuildConfigInstance.Status.Schedule.LastRunSkipped).To(BeTrue())
+				expectScheduledRun(before, result)
+			})
+
+			It("should build when the run is due and the repositories changed", func() {
+				// given
+				setScheduleStatus(time.Now().Add(-time.Minute))
+				osbuildConfigInstance.Status.RepositoryRevisions = []osbuildv1alpha1.RepositoryRevision{{Baseurl: "https://repo.example.com/baseos", Revision: "0"}}
+				revisionReader.EXPECT().GetRevisions(requestContext, gomock.Any()).Return(revisions, nil).Times(2)
+				osBuildConfigRepository.EXPECT().PatchStatus(requestContext, osbuildConfigInstance, gomock.Any()).Return(nil).Times(4)
+				osBuildCRCreator.EXPECT().Create(requestContext, osbuildConfigInstance, osbuildv1alpha1.EdgeContainerImageType, gomock.Any()).Return(nil)
+
+				// when
+				result, err := reconciler.Reconcile(requestContext, request)
+
+				// then
+				Expect(err).To(BeNil())
+				Expect(result).To(Equal(resultLongRequeue))
+				Expect(osbuildConfigInstance.Status.Schedule.LastRunSkipped).To(BeFalse())
+				Expect(osbuildConfigInstance.Status.RepositoryRevisions).To(Equal(revisions))
+			})
+
+			It("should build when the run is due and skipping is not configured", func() {
+				// given
+				skipIfUnchanged = false
+				setScheduleStatus(time.Now().Add(-time.Minute))
+				osBuildConfigRepository.EXPECT().PatchStatus(requestContext, osbuildConfigInstance, gomock.Any()).Return(nil).Times(2)
+				osBuildCRCreator.EXPECT().Create(requestContext, osbuildConfigInstance, osbuildv1alpha1.EdgeContainerImageType, gomock.Any()).Return(nil)
+
+				// when
+				result, err := reconciler.Reconcile(requestContext, request)
+
+				// then
+				Expect(err).To(BeNil())
+				Expect(result).To(Equal(resultLongRequeue))
+				Expect(osbuildConfigInstance.Status.Schedule.LastRunSkipped).To(BeFalse())
+			})
+
+			It("should keep the run due when failing to create the OSBuild", func() {
+				// given
+				skipIfUnchanged = false
+				lastScheduleTime := time.Now().Add(-time.Minute).Truncate(time.Second)
+				setScheduleStatus(lastScheduleTime)
+				osBuildCRCreator.EXPECT().Create(requestContext, osbuildConfigInstance, osbuildv1alpha1.EdgeContainerImageType, gomock.Any()).Return(errFailed)
+
+				// when
+				result, err := reconciler.Reconcile(requestContext, request)
+
+				// then
+				Expect(err).To(BeNil())
+				Expect(result).To(Equal(resultShortRequeue))
+				Expect(osbuildConfigInstance.Status.Schedule.LastScheduleTime).To(BeNil())
+				Expect(osbuildConfigInstance.Status.Schedule.NextScheduleTime.Time).To(BeTemporally("==", lastScheduleTime))
+			})
+
+			It("should build and record no revision when the revisions cannot be read", func() {
+				// given
+				setScheduleStatus(time.Now().Add(-time.Minute))
+				revisionReader.EXPECT().GetRevisions(requestContext, gomock.Any()).Return(nil, errFailed).Times(2)
+				osBuildConfigRepository.EXPECT().PatchStatus(requestContext, osbuildConfigInstance, gomock.Any()).Return(nil).Times(4)
+				osBuildCRCreator.EXPECT().Create(requestContext, osbuildConfigInstance, osbuildv1alpha1.EdgeContainerImageType, gomock.Any()).Return(nil)
+
+				// when
+				result, err := reconciler.Reconcile(requestContext, request)
+
+				// then
+				Expect(err).To(BeNil())
+				Expect(result).To(Equal(resultLongRequeue))
+				Expect(osbuildConfigInstance.Status.RepositoryRevisions).To(BeNil())
+			})
+
+			DescribeTable("should record why the schedule cannot be evaluated", func(cron string, timeZone string, expectedMsg string) {
+				// given
+				osbuildConfigInstance.Spec.Triggers.Schedule.Cron = cron
+				osbuildConfigInstance.Spec.Triggers.Schedule.TimeZone = &timeZone
+				osBuildConfigRepository.EXPECT().PatchStatus(requestContext, osbuildConfigInstance, gomock.Any()).Return(nil)
+				osBuildRepository.EXPECT().Read(requestContext, osBuildName, instanceNamespace).Return(osbuildInstance, nil)
+
+				// when
+				result, err := reconciler.Reconcile(requestContext, request)
+
+				// then
+				Expect(err).To(BeNil())
+				Expect(result).To(Equal(resultDone))
+				Expect(osbuildConfigInstance.Status.Schedule.NextScheduleTime).To(BeNil())
+				Expect(osbuildConfigInstance.Status.Schedule.Message).To(HavePrefix(expectedMsg))
+			},
+				Entry("invalid time zone", "0 2 * * *", "Mars/Olympus_Mons", `Invalid time zone "Mars/Olympus_Mons"`),
+				Entry("invalid cron expression", "0 25 * * *", "UTC", "Invalid cron expression"),
+				Entry("cron expression never matching", "0 0 30 2 *", "UTC", `The cron expression "0 0 30 2 *" never matches`),
+			)
+
+			It("should requeue when failing to patch the schedule status", func() {
+				// given
+				osBuildConfigRepository.EXPECT().PatchStatus(requestContext, osbuildConfigInstance, gomock.Any()).Return(errFailed)
+
+				// when
+				result, err := reconciler.Reconcile(requestContext, request)
+
+				// then
+				Expect(err).To(BeNil())
+				Expect(result).To(Equal(resultShortRequeue))
+			})
+		})
+
+		Context("with a repository change trigger", func() {
+			var (
+				automaticBuild bool
+				revisions      []osbuildv1alpha1.RepositoryRevision
+				newRevisions   []osbuildv1alpha1.RepositoryRevision
+			)
+
+			BeforeEach(func() {
+				os.Setenv("WORKING_NAMESPACE", instanceNamespace)
+				os.Setenv("CA_ISSUER_NAME", "osbuild-issuer")
+				Expect(conf.Load()).To(Succeed())
+
+				automaticBuild = false
+				osbuildConfigInstance.Spec.Triggers.RepositoryChange = &osbuildv1alpha1.RepositoryChangeTrigger{
+					Interval:       &metav1.Duration{Duration: time.Hour},
+					AutomaticBuild: &automaticBuild,
+				}
+				revisions = []osbuildv1alpha1.RepositoryRevision{
+					{Baseurl: "https://repo.example.com/appstream", Revision: "1"},
+					{Baseurl: "https://repo.example.com/baseos", Revision: "1"},
+				}
+				newRevisions = []osbuildv1alpha1.RepositoryRevision{
+					{Baseurl: "https://repo.example.com/appstream", Revision: "1"},
+					{Baseurl: "https://repo.example.com/baseos", Revision: "2"},
+				}
+				osbuildInstance.Status.Conditions = []osbuildv1alpha1.Condition{{Type: osbuildv1alpha1.ConditionReady, Status: metav1.ConditionTrue}}
+			})
+
+			getUpdatesAvailableCondition := func() *osbuildv1alpha1.Condition {
+				for i := range osbuildConfigInstance.Status.Conditions {
+					if osbuildConfigInstance.Status.Conditions[i].Type == osbuildv1alpha1.ConditionUpdatesAvailable {
+						return &osbuildConfigInstance.Status.Conditions[i]
+					}
+				}
+				return nil
+			}
+
+			It("should take the revisions of the first check as the reference", func() {
+				// given
+				revisionReader.EXPECT().GetRevisions(requestContext, gomock.Any()).Return(revisions, nil)
+				osBuildConfigRepository.EXPECT().PatchStatus(requestContext, osbuildConfigInstance, gomock.Any()).Return(nil)
+				osBuildRepository.EXPECT().Read(requestContext, osBuildName, instanceNamespace).Return(osbuildInstance, nil)
+
+				// when
+				result, err := reconciler.Reconcile(requestContext, request)
+
+				// then
+				Expect(err).To(BeNil())
+				Expect(result.RequeueAfter).To(BeNumerically("~", time.Hour, time.Second))
+				Expect(osbuildConfigInstance.Status.RepositoryRevisions).To(Equal(revisions))
+				Expect(osbuildConfigInstance.Status.RepositoryCheck.Revisions).To(Equal(revisions))
+				Expect(getUpdatesAvailableCondition().Status).To(Equal(metav1.ConditionFalse))
+			})
+
+			It("should not check the repositories before the interval elapsed", func() {
+				// given
+				lastCheckTime := metav1.NewTime(time.Now().Add(-10 * time.Minute))
+				osbuildConfigInstance.Status.RepositoryCheck = &osbuildv1alpha1.RepositoryCheck{LastCheckTime: &lastCheckTime}
+				osBuildRepository.EXPECT().Read(requestContext, osBuildName, instanceNamespace).Return(osbuildInstance, nil)
+
+				// when
+				result, err := reconciler.Reconcile(requestContext, request)
+
+				// then
+				Expect(err).To(BeNil())
+				Expect(result.RequeueAfter).To(BeNumerically("~", 50*time.Minute, time.Second))
+			})
+
+			It("should report the updates without building", func() {
+				// given
+				osbuildConfigInstance.Status.RepositoryRevisions = revisions
+				revisionReader.EXPECT().GetRevisions(requestContext, gomock.Any()).Return(newRevisions, nil)
+				osBuildConfigRepository.EXPECT().PatchStatus(requestContext, osbuildConfigInstance, gomock.Any()).Return(nil)
+				osBuildRepository.EXPECT().Read(requestContext, osBuildName, instanceNamespace).Return(osbuildInstance, nil)
+
+				// when
+				result, err := reconciler.Reconcile(requestContext, request)
+
+				// then
+				Expect(err).To(BeNil())
+				Expect(result.RequeueAfter).To(BeNumerically("~", time.Hour, time.Second))
+				Expect(osbuildConfigInstance.Status.RepositoryRevisions).To(Equal(revisions))
+				Expect(osbuildConfigInstance.Status.RepositoryCheck.Revisions).To(Equal(newRevisions))
+				condition := getUpdatesAvailableCondition()
+				Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+				Expect(*condition.Message).To(Equal("The revisions of the repositories changed since the last OSBuild: https://repo.example.com/baseos"))
+			})
+
+			It("should build when the repositories changed and the automatic build is enabled", func() {
+				// given
+				automaticBuild = true
+				osbuildConfigInstance.Status.RepositoryRevisions = revisions
+				revisionReader.EXPECT().GetRevisions(requestContext, gomock.Any()).Return(newRevisions, nil).Times(2)
+				osBuildConfigRepository.EXPECT().PatchStatus(requestContext, osbuildConfigInstance, gomock.Any()).Return(nil).Times(3)
+				osBuildCRCreator.EXPECT().Create(requestContext, osbuildConfigInstance, osbuildv1alpha1.EdgeContainerImageType, gomock.Any()).Return(nil)
 
 				// when
 				result, err := reconciler.Reconcile(requestContext, request)
@@ -531,6 +1154,42 @@ var _ = Describe("OSBuildConfig Controller", func() {
 				// then
 				Expect(err).To(BeNil())
 				Expect(result).To(Equal(resultLongRequeue))
+				Expect(osbuildConfigInstance.Status.RepositoryRevisions).To(Equal(newRevisions))
+				Expect(getUpdatesAvailableCondition().Status).To(Equal(metav1.ConditionFalse))
+			})
+
+			It("should keep the last revisions when they cannot be read", func() {
+				// given
+				automaticBuild = true
+				lastCheckTime := metav1.NewTime(time.Now().Add(-2 * time.Hour))
+				osbuildConfigInstance.Status.RepositoryRevisions = revisions
+				osbuildConfigInstance.Status.RepositoryCheck = &osbuildv1alpha1.RepositoryCheck{LastCheckTime: &lastCheckTime, Revisions: revisions}
+				revisionReader.EXPECT().GetRevisions(requestContext, gomock.Any()).Return(nil, errFailed)
+				osBuildConfigRepository.EXPECT().PatchStatus(requestContext, osbuildConfigInstance, gomock.Any()).Return(nil)
+				osBuildRepository.EXPECT().Read(requestContext, osBuildName, instanceNamespace).Return(osbuildInstance, nil)
+
+				// when
+				result, err := reconciler.Reconcile(requestContext, request)
+
+				// then
+				Expect(err).To(BeNil())
+				Expect(result.RequeueAfter).To(BeNumerically("~", time.Hour, time.Second))
+				Expect(osbuildConfigInstance.Status.RepositoryCheck.Revisions).To(Equal(revisions))
+				Expect(osbuildConfigInstance.Status.RepositoryCheck.Message).ToNot(BeEmpty())
+				Expect(osbuildConfigInstance.Status.RepositoryCheck.LastCheckTime.Time).To(BeTemporally(">", lastCheckTime.Time))
+			})
+
+			It("should requeue when failing to patch the check status", func() {
+				// given
+				revisionReader.EXPECT().GetRevisions(requestContext, gomock.Any()).Return(revisions, nil)
+				osBuildConfigRepository.EXPECT().PatchStatus(requestContext, osbuildConfigInstance, gomock.Any()).Return(errFailed)
+
+				// when
+				result, err := reconciler.Reconcile(requestContext, request)
+
+				// then
+				Expect(err).To(BeNil())
+				Expect(result).To(Equal(resultShortRequeue))
 			})
 		})
 	})
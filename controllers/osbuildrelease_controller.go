@@ -0,0 +1,479 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	batchv1 "k8s.io/api/batch/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	osbuildv1alpha1 "github.com/project-flotta/osbuild-operator/api/v1alpha1"
+	"github.com/project-flotta/osbuild-operator/internal/composer"
+	"github.com/project-flotta/osbuild-operator/internal/conf"
+	"github.com/project-flotta/osbuild-operator/internal/repository/job"
+	repositoryosbuild "github.com/project-flotta/osbuild-operator/internal/repository/osbuild"
+	"github.com/project-flotta/osbuild-operator/internal/repository/osbuildrelease"
+	"github.com/project-flotta/osbuild-operator/internal/repository/ostreerepository"
+	"github.com/project-flotta/osbuild-operator/internal/s3"
+	"github.com/project-flotta/osbuild-operator/internal/templates"
+	"github.com/project-flotta/osbuild-operator/internal/vulnerability"
+)
+
+const (
+	// The names of the release jobs include the generation of the OSBuildRelease, so that promoting an OSBuild released
+	// before runs new jobs instead of finding the completed ones
+	releaseRetagNameFormat = "%s-retag-%s-%d"
+	releaseRefNameFormat   = "%s-ref-%s-%d"
+
+	releaseRetagJobTemplateFile  = "release-retag-job.yaml"
+	ostreeRepoRefJobTemplateFile = "ostree-repo-ref-job.yaml"
+
+	releaseAuthDir = "/var/auth"
+
+	releaseReleasedMsg          = "OSBuild %s is released"
+	releaseOSBuildNotFoundMsg   = "OSBuild %s not found"
+	releaseOSBuildFailedMsg     = "OSBuild %s failed"
+	releaseOSBuildWaitingMsg    = "Waiting for OSBuild %s to be Ready"
+	releaseOSBuildBlockedMsg    = "OSBuild %s is blocked by its vulnerability policy"
+	releaseNotApplicableMsg     = "OSBuild %s of type %s has no %s to release"
+	releaseRetagInProgressMsg   = "Tagging the image of OSBuild %s"
+	releaseRetagFailedMsg       = "Failed to tag the image of OSBuild %s"
+	releaseRepoNotFoundMsg      = "OSTreeRepository %s not found"
+	releaseRepoWaitingMsg       = "Waiting for OSTreeRepository %s to import the commit of OSBuild %s"
+	releaseRefInProgressMsg     = "Moving the ref to the commit of OSBuild %s"
+	releaseRefFailedMsg         = "Failed to move the ref to the commit of OSBuild %s"
+	releaseInstallerFailedMsg   = "Failed to copy the image of OSBuild %s"
+	releaseInstallerMissingMsg  = "OSBuild %s has no image uploaded to S3"
+	releaseContainerMissingMsg  = "OSBuild %s has no container image"
+	releaseContainerImageTarget = "container image"
+	releaseOSTreeRefTarget      = "OSTree commit"
+	releaseInstallerTarget      = "S3 image"
+)
+
+type releaseRetagJobParameters struct {
+	Namespace        string
+	Name             string
+	ImageName        string
+	ImageTag         string
+	SourceImage      string
+	DestinationImage string
+	AuthSecretName   string
+	AuthDir          string
+}
+
+type ostreeRepoRefJobParameters struct {
+	Namespace        string
+	Name             string
+	RepoName         string
+	ImageName        string
+	ImageTag         string
+	Commit           string
+	Ref              string
+	StorageDir       string
+	RepoDir          string
+	GPGKeySecretName string
+	GPGKeyDir        string
+}
+
+// OSBuildReleaseReconciler reconciles a OSBuildRelease object
+type OSBuildReleaseReconciler struct {
+	Scheme                     *runtime.Scheme
+	OSBuildReleaseRepository   osbuildrelease.Repository
+	OSBuildRepository          repositoryosbuild.Repository
+	OSTreeRepositoryRepository ostreerepository.Repository
+	JobRepository              job.Repository
+	S3ObjectStore              s3.ObjectStore
+}
+
+//+kubebuilder:rbac:groups=osbuilder.project-flotta.io,resources=osbuildreleases,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=osbuilder.project-flotta.io,resources=osbuildreleases/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=osbuilder.project-flotta.io,resources=osbuildreleases/finalizers,verbs=update
+
+// Reconcile promotes the referenced OSBuild once it is Ready: the edge-container image is tagged with the release tag,
+// the release ref of the OSTree repository is moved to its commit and the image uploaded to S3 is copied to the
+// release key. Every promotion is recorded in the history of the release
+func (r *OSBuildReleaseReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx).WithValues("osbuildrelease", req.Name)
+
+	osBuildRelease, err := r.OSBuildReleaseRepository.Read(ctx, req.Name, req.Namespace)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		logger.Error(err, "failed to get the OSBuildRelease")
+		return ctrl.Result{Requeue: true, RequeueAfter: RequeueForShortDuration}, nil
+	}
+
+	if osBuildRelease.DeletionTimestamp != nil {
+		return ctrl.Result{}, nil
+	}
+
+	if current := osBuildRelease.Status.Current; current != nil && current.OSBuildName == osBuildRelease.Spec.OSBuildRef {
+		return ctrl.Result{}, nil
+	}
+
+	osBuildName := osBuildRelease.Spec.OSBuildRef
+	osBuild, err := r.OSBuildRepository.Read(ctx, osBuildName, osBuildRelease.Namespace)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return r.updateStatus(ctx, logger, osBuildRelease, osbuildv1alpha1.ConditionFailed, fmt.Sprintf(releaseOSBuildNotFoundMsg, osBuildName), ctrl.Result{})
+		}
+		logger.Error(err, "failed to get the OSBuild", "OSBuild", osBuildName)
+		return ctrl.Result{Requeue: true, RequeueAfter: RequeueForShortDuration}, nil
+	}
+
+	// the OSBuilds failing because of their vulnerabilities are reported as blocked, before the other failures
+	if isOSBuildConditionTrue(osBuild, osbuildv1alpha1.ConditionVulnerabilitiesFound) ||
+		(osBuild.IsReady() && vulnerability.IsPolicyViolated(osBuild.Spec.VulnerabilityScan, osBuild.Status.Vulnerabilities)) {
+		return r.updateStatus(ctx, logger, osBuildRelease, osbuildv1alpha1.ConditionFailed, fmt.Sprintf(releaseOSBuildBlockedMsg, osBuildName), ctrl.Result{})
+	}
+	if osBuild.IsFailed() {
+		return r.updateStatus(ctx, logger, osBuildRelease, osbuildv1alpha1.ConditionFailed, fmt.Sprintf(releaseOSBuildFailedMsg, osBuildName), ctrl.Result{})
+	}
+	if !osBuild.IsReady() || osBuild.Spec.Details == nil {
+		return r.updateStatus(ctx, logger, osBuildRelease, osbuildv1alpha1.ConditionInProgress, fmt.Sprintf(releaseOSBuildWaitingMsg, osBuildName), ctrl.Result{})
+	}
+
+	record := osbuildv1alpha1.OSBuildReleaseRecord{OSBuildName: osBuildName}
+	targetImageType := osBuild.Spec.Details.TargetImage.TargetImageType
+
+	if osBuildRelease.Spec.ContainerImageTag != nil {
+		if targetImageType != osbuildv1alpha1.EdgeContainerImageType {
+			return r.updateStatus(ctx, logger, osBuildRelease, osbuildv1alpha1.ConditionFailed,
+				fmt.Sprintf(releaseNotApplicableMsg, osBuildName, targetImageType, releaseContainerImageTarget), ctrl.Result{})
+		}
+		containerImage, result := r.releaseContainerImage(ctx, logger, osBuildRelease, osBuild)
+		if result != nil {
+			return *result, nil
+		}
+		record.ContainerImage = containerImage
+	}
+
+	if osBuildRelease.Spec.OSTreeRef != nil {
+		if targetImageType != osbuildv1alpha1.EdgeContainerImageType {
+			return r.updateStatus(ctx, logger, osBuildRelease, osbuildv1alpha1.ConditionFailed,
+				fmt.Sprintf(releaseNotApplicableMsg, osBuildName, targetImageType, releaseOSTreeRefTarget), ctrl.Result{})
+		}
+		result := r.releaseOSTreeCommit(ctx, logger, osBuildRelease, osBuild)
+		if result != nil {
+			return *result, nil
+		}
+		record.OSTreeCommit = osBuild.Status.OSTreeCommit
+	}
+
+	if osBuildRelease.Spec.InstallerKey != nil {
+		if uploadTypeForTargetImageType[targetImageType] != composer.UploadTypesAwsS3 {
+			return r.updateStatus(ctx, logger, osBuildRelease, osbuildv1alpha1.ConditionFailed,
+				fmt.Sprintf(releaseNotApplicableMsg, osBuildName, targetImageType, releaseInstallerTarget), ctrl.Result{})
+		}
+		installerUrl, result := r.releaseInstaller(ctx, logger, osBuildRelease, osBuild)
+		if result != nil {
+			return *result, nil
+		}
+		record.InstallerUrl = installerUrl
+	}
+
+	record.ReleaseTime = metav1.Now()
+	patch := client.MergeFrom(osBuildRelease.DeepCopy())
+	osBuildRelease.Status.Current = &record
+	osBuildRelease.Status.History = append(osBuildRelease.Status.History, record)
+	err = r.updateOSBuildReleaseStatus(ctx, osBuildRelease, patch, osbuildv1alpha1.ConditionReady, fmt.Sprintf(releaseReleasedMsg, osBuildName))
+	if err != nil {
+		logger.Error(err, "failed to update the OSBuildRelease status")
+		return ctrl.Result{Requeue: true, RequeueAfter: RequeueForShortDuration}, nil
+	}
+
+	logger.Info("Released the OSBuild", "OSBuild", osBuildName)
+	return ctrl.Result{}, nil
+}
+
+// releaseContainerImage tags the edge-container image of the OSBuild with the release tag. It returns the tagged image,
+// or the result to return from the reconciliation until the tagging job completes
+func (r *OSBuildReleaseReconciler) releaseContainerImage(ctx context.Context, logger logr.Logger, osBuildRelease *osbuildv1alpha1.OSBuildRelease,
+	osBuild *osbuildv1alpha1.OSBuild) (string, *ctrl.Result) {
+	if osBuild.Status.AccessUrl == emptyURL {
+		result, _ := r.updateStatus(ctx, logger, osBuildRelease, osbuildv1alpha1.ConditionFailed, fmt.Sprintf(releaseContainerMissingMsg, osBuild.Name), ctrl.Result{})
+		return "", &result
+	}
+
+	containerImage := getImageRepository(osBuild.Status.AccessUrl) + ":" + *osBuildRelease.Spec.ContainerImageTag
+	jobName := getJobName(releaseRetagNameFormat, osBuildRelease.Name, osBuild.Name, osBuildRelease.Generation)
+	result := r.runJob(ctx, logger, osBuildRelease, jobName,
+		func() (*batchv1.Job, error) {
+			return r.generateRetagJob(osBuildRelease, osBuild, jobName, containerImage)
+		},
+		fmt.Sprintf(releaseRetagInProgressMsg, osBuild.Name), fmt.Sprintf(releaseRetagFailedMsg, osBuild.Name))
+	return containerImage, result
+}
+
+// releaseOSTreeCommit moves the release ref of the OSTree repository to the commit of the OSBuild, once the repository
+// imported it. It returns the result to return from the reconciliation until the ref job completes
+func (r *OSBuildReleaseReconciler) releaseOSTreeCommit(ctx context.Context, logger logr.Logger, osBuildRelease *osbuildv1alpha1.OSBuildRelease,
+	osBuild *osbuildv1alpha1.OSBuild) *ctrl.Result {
+	repoName := osBuildRelease.Spec.OSTreeRef.OSTreeRepositoryRef
+	ostreeRepository, err := r.OSTreeRepositoryRepository.Read(ctx, repoName, osBuildRelease.Namespace)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			result, _ := r.updateStatus(ctx, logger, osBuildRelease, osbuildv1alpha1.ConditionFailed, fmt.Sprintf(releaseRepoNotFoundMsg, repoName),
+				ctrl.Result{Requeue: true, RequeueAfter: RequeueForLongDuration})
+			return &result
+		}
+		logger.Error(err, "failed to get the OSTreeRepository", "OSTreeRepository", repoName)
+		return &ctrl.Result{Requeue: true, RequeueAfter: RequeueForShortDuration}
+	}
+
+	if !isCommitImported(ostreeRepository, osBuild) {
+		result, _ := r.updateStatus(ctx, logger, osBuildRelease, osbuildv1alpha1.ConditionInProgress, fmt.Sprintf(releaseRepoWaitingMsg, repoName, osBuild.Name),
+			ctrl.Result{Requeue: true, RequeueAfter: RequeueForLongDuration})
+		return &result
+	}
+
+	jobName := getJobName(releaseRefNameFormat, osBuildRelease.Name, osBuild.Name, osBuildRelease.Generation)
+	return r.runJob(ctx, logger, osBuildRelease, jobName,
+		func() (*batchv1.Job, error) {
+			return r.generateRefJob(osBuildRelease, ostreeRepository, osBuild, jobName)
+		},
+		fmt.Sprintf(releaseRefInProgressMsg, osBuild.Name), fmt.Sprintf(releaseRefFailedMsg, osBuild.Name))
+}
+
+// releaseInstaller copies the image the OSBuild uploaded to S3 to the release key and returns the URL of the copy, or
+// the result to return from the reconciliation when the copy failed
+func (r *OSBuildReleaseReconciler) releaseInstaller(ctx context.Context, logger logr.Logger, osBuildRelease *osbuildv1alpha1.OSBuildRelease,
+	osBuild *osbuildv1alpha1.OSBuild) (string, *ctrl.Result) {
+	if osBuild.Status.AccessUrl == emptyURL {
+		result, _ := r.updateStatus(ctx, logger, osBuildRelease, osbuildv1alpha1.ConditionFailed, fmt.Sprintf(releaseInstallerMissingMsg, osBuild.Name), ctrl.Result{})
+		return "", &result
+	}
+
+	installerUrl, err := r.S3ObjectStore.CopyObject(ctx, osBuild.Status.AccessUrl, *osBuildRelease.Spec.InstallerKey)
+	if err != nil {
+		logger.Error(err, "failed to copy the image of the OSBuild", "OSBuild", osBuild.Name)
+		result, _ := r.updateStatus(ctx, logger, osBuildRelease, osbuildv1alpha1.ConditionFailed, fmt.Sprintf(releaseInstallerFailedMsg, osBuild.Name),
+			ctrl.Result{Requeue: true, RequeueAfter: RequeueForLongDuration})
+		return "", &result
+	}
+	return installerUrl, nil
+}
+
+// runJob creates the job when missing and returns nil once it completed, or the result to return from the
+// reconciliation while it is running or after it failed
+func (r *OSBuildReleaseReconciler) runJob(ctx context.Context, logger logr.Logger, osBuildRelease *osbuildv1alpha1.OSBuildRelease, jobName string,
+	generateJob func() (*batchv1.Job, error), inProgressMsg string, failedMsg string) *ctrl.Result {
+	releaseJob, err := r.JobRepository.Read(ctx, jobName, osBuildRelease.Namespace)
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			logger.Error(err, "failed to get the job", "job", jobName)
+			return &ctrl.Result{Requeue: true, RequeueAfter: RequeueForShortDuration}
+		}
+
+		releaseJob, err = generateJob()
+		if err != nil {
+			logger.Error(err, "failed to generate the job", "job", jobName)
+			return &ctrl.Result{Requeue: true, RequeueAfter: RequeueForShortDuration}
+		}
+
+		err = r.JobRepository.Create(ctx, releaseJob)
+		if err != nil {
+			logger.Error(err, "failed to create the job", "job", jobName)
+			return &ctrl.Result{Requeue: true, RequeueAfter: RequeueForShortDuration}
+		}
+
+		logger.Info("Generated release Job", "job", jobName)
+		result, _ := r.updateStatus(ctx, logger, osBuildRelease, osbuildv1alpha1.ConditionInProgress, inProgressMsg,
+			ctrl.Result{Requeue: true, RequeueAfter: RequeueForLongDuration})
+		return &result
+	}
+
+	switch {
+	case isJobConditionTrue(releaseJob, batchv1.JobComplete):
+		return nil
+	case isJobConditionTrue(releaseJob, batchv1.JobFailed):
+		logger.Info("the release job failed", "job", jobName)
+		result, _ := r.updateStatus(ctx, logger, osBuildRelease, osbuildv1alpha1.ConditionFailed, failedMsg, ctrl.Result{})
+		return &result
+	default:
+		logger.Info("the release job is still running", "job", jobName)
+		return &ctrl.Result{Requeue: true, RequeueAfter: RequeueForLongDuration}
+	}
+}
+
+func (r *OSBuildReleaseReconciler) generateRetagJob(osBuildRelease *osbuildv1alpha1.OSBuildRelease, osBuild *osbuildv1alpha1.OSBuild,
+	jobName string, containerImage string) (*batchv1.Job, error) {
+	retagJobParams := releaseRetagJobParameters{
+		Namespace:        osBuildRelease.Namespace,
+		Name:             jobName,
+		ImageName:        conf.GlobalConf.SkopeoImageName,
+		ImageTag:         conf.GlobalConf.SkopeoImageTag,
+		SourceImage:      osBuild.Status.AccessUrl,
+		DestinationImage: containerImage,
+		AuthDir:          releaseAuthDir,
+	}
+	if osBuildRelease.Spec.ImagePullSecretRef != nil {
+		retagJobParams.AuthSecretName = osBuildRelease.Spec.ImagePullSecretRef.Name
+	}
+
+	return r.generateJob(osBuildRelease, releaseRetagJobTemplateFile, retagJobParams)
+}
+
+func (r *OSBuildReleaseReconciler) generateRefJob(osBuildRelease *osbuildv1alpha1.OSBuildRelease, ostreeRepository *osbuildv1alpha1.OSTreeRepository,
+	osBuild *osbuildv1alpha1.OSBuild, jobName string) (*batchv1.Job, error) {
+	refJobParams := ostreeRepoRefJobParameters{
+		Namespace:  osBuildRelease.Namespace,
+		Name:       jobName,
+		RepoName:   fmt.Sprintf(ostreeRepoNameFormat, ostreeRepository.Name),
+		ImageName:  conf.GlobalConf.OSTreeRepoImageName,
+		ImageTag:   conf.GlobalConf.OSTreeRepoImageTag,
+		Commit:     osBuild.Status.OSTreeCommit,
+		Ref:        osBuildRelease.Spec.OSTreeRef.Ref,
+		StorageDir: ostreeRepoStorageDir,
+		RepoDir:    ostreeRepoDir,
+		GPGKeyDir:  ostreeRepoGPGKeyDir,
+	}
+	if ostreeRepository.Spec.GPGKeySecretRef != nil {
+		refJobParams.GPGKeySecretName = ostreeRepository.Spec.GPGKeySecretRef.Name
+	}
+
+	return r.generateJob(osBuildRelease, ostreeRepoRefJobTemplateFile, refJobParams)
+}
+
+func (r *OSBuildReleaseReconciler) generateJob(osBuildRelease *osbuildv1alpha1.OSBuildRelease, templateFile string, params interface{}) (*batchv1.Job, error) {
+	buf, err := templates.LoadFromTemplateFile(templateFile, params)
+	if err != nil {
+		return nil, err
+	}
+
+	decode := scheme.Codecs.UniversalDeserializer().Decode
+	obj, _, err := decode(buf.Bytes(), nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	releaseJob, ok := obj.(*batchv1.Job)
+	if !ok {
+		return nil, fmt.Errorf("failed to deserialize the job object")
+	}
+
+	return releaseJob, controllerutil.SetControllerReference(osBuildRelease, releaseJob, r.Scheme)
+}
+
+// updateStatus sets the condition of the release and returns the result, or a short requeue when the status could not
+// be patched
+func (r *OSBuildReleaseReconciler) updateStatus(ctx context.Context, logger logr.Logger, osBuildRelease *osbuildv1alpha1.OSBuildRelease,
+	conditionType osbuildv1alpha1.ConditionType, msg string, result ctrl.Result) (ctrl.Result, error) {
+	patch := client.MergeFrom(osBuildRelease.DeepCopy())
+	err := r.updateOSBuildReleaseStatus(ctx, osBuildRelease, patch, conditionType, msg)
+	if err != nil {
+		logger.Error(err, "failed to update the OSBuildRelease status")
+		return ctrl.Result{Requeue: true, RequeueAfter: RequeueForShortDuration}, nil
+	}
+	return result, nil
+}
+
+// updateOSBuildReleaseStatus sets the condition and patches the status, including the changes made to the release
+// records since the patch was taken
+func (r *OSBuildReleaseReconciler) updateOSBuildReleaseStatus(ctx context.Context, osBuildRelease *osbuildv1alpha1.OSBuildRelease,
+	patch client.Patch, newConditionType osbuildv1alpha1.ConditionType, msg string) error {
+	found := false
+	for i := range osBuildRelease.Status.Conditions {
+		condition := &osBuildRelease.Status.Conditions[i]
+		if condition.Type == newConditionType {
+			found = true
+			if condition.Status != metav1.ConditionTrue || condition.Message == nil || *condition.Message != msg {
+				condition.Status = metav1.ConditionTrue
+				condition.Message = &msg
+				condition.LastTransitionTime = &metav1.Time{Time: time.Now()}
+			}
+		} else if condition.Status == metav1.ConditionTrue {
+			condition.Status = metav1.ConditionFalse
+			condition.Message = nil
+			condition.LastTransitionTime = &metav1.Time{Time: time.Now()}
+		}
+	}
+	if !found {
+		osBuildRelease.Status.Conditions = append(osBuildRelease.Status.Conditions, osbuildv1alpha1.Condition{
+			Type:               newConditionType,
+			Status:             metav1.ConditionTrue,
+			Message:            &msg,
+			LastTransitionTime: &metav1.Time{Time: time.Now()},
+		})
+	}
+
+	return r.OSBuildReleaseRepository.PatchStatus(ctx, osBuildRelease, &patch)
+}
+
+// isCommitImported returns whether the repository successfully imported the commit of the OSBuild
+func isCommitImported(ostreeRepository *osbuildv1alpha1.OSTreeRepository, osBuild *osbuildv1alpha1.OSBuild) bool {
+	for _, ostreeImport := range ostreeRepository.Status.Imports {
+		if ostreeImport.Succeeded && ostreeImport.OSBuildName == osBuild.Name {
+			return true
+		}
+	}
+	return false
+}
+
+// getImageRepository returns the image reference without its tag or digest
+func getImageRepository(image string) string {
+	if i := strings.Index(image, "@"); i >= 0 {
+		image = image[:i]
+	}
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		image = image[:i]
+	}
+	return image
+}
+
+// osBuildToOSBuildReleases maps an OSBuild to the OSBuildReleases referencing it
+func (r *OSBuildReleaseReconciler) osBuildToOSBuildReleases(obj client.Object) []reconcile.Request {
+	osBuildReleases, err := r.OSBuildReleaseRepository.ListByOSBuild(context.Background(), obj.GetName(), obj.GetNamespace())
+	if err != nil {
+		ctrl.Log.Error(err, "failed to list the OSBuildReleases of the OSBuild", "OSBuild", obj.GetName())
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, osBuildRelease := range osBuildReleases {
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{Name: osBuildRelease.Name, Namespace: osBuildRelease.Namespace},
+		})
+	}
+	return requests
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *OSBuildReleaseReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&osbuildv1alpha1.OSBuildRelease{}).
+		Owns(&batchv1.Job{}).
+		Watches(&source.Kind{Type: &osbuildv1alpha1.OSBuild{}}, handler.EnqueueRequestsFromMapFunc(r.osBuildToOSBuildReleases)).
+		Complete(r)
+}
@@ -0,0 +1,497 @@
+package controllers_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	osbuildv1alpha1 "github.com/project-flotta/osbuild-operator/api/v1alpha1"
+	"github.com/project-flotta/osbuild-operator/controllers"
+	"github.com/project-flotta/osbuild-operator/internal/conf"
+	"github.com/project-flotta/osbuild-operator/internal/repository/job"
+	"github.com/project-flotta/osbuild-operator/internal/repository/osbuild"
+	"github.com/project-flotta/osbuild-operator/internal/repository/osbuildrelease"
+	"github.com/project-flotta/osbuild-operator/internal/repository/ostreerepository"
+	"github.com/project-flotta/osbuild-operator/internal/s3"
+)
+
+var _ = Describe("OSBuildRelease Controller", func() {
+	const (
+		operatorNamespace = "osbuild"
+		caIssuerName      = "osbuild-issuer"
+		templatesDir      = "../resources/templates"
+
+		instanceNamespace = "edge"
+		instanceName      = "stable"
+		osBuildName       = "config-2"
+		repoName          = "repo"
+		retagJobName      = "stable-retag-config-2-1"
+		refJobName        = "stable-ref-config-2-1"
+		tag               = "stable"
+		ref               = "rhel/8/x86_64/edge/stable"
+		edgeContainerUrl  = "registry.example.com/edge/config:2"
+		commit            = "02604b2da6e954bd34b8b82a835e5a77d2b60ffa"
+		installerUrl      = "https://minio.example.com/images/installer-2.iso?X-Amz-Signature=abc"
+		installerKey      = "releases/stable.iso"
+	)
+
+	var (
+		mockCtrl *gomock.Controller
+
+		osBuildReleaseRepository   *osbuildrelease.MockRepository
+		osBuildRepository          *osbuild.MockRepository
+		ostreeRepositoryRepository *ostreerepository.MockRepository
+		jobRepository              *job.MockRepository
+		s3ObjectStore              *s3.MockObjectStore
+
+		reconciler     *controllers.OSBuildReleaseReconciler
+		requestContext context.Context
+
+		errNotFound error
+		errFailed   error
+
+		instance *osbuildv1alpha1.OSBuildRelease
+		osBuild  *osbuildv1alpha1.OSBuild
+
+		request = ctrl.Request{
+			NamespacedName: types.NamespacedName{
+				Name:      instanceName,
+				Namespace: instanceNamespace,
+			},
+		}
+
+		resultLongRequeue = ctrl.Result{Requeue: true, RequeueAfter: controllers.RequeueForLongDuration}
+		resultDone        = ctrl.Result{}
+	)
+
+	newJob := func(conditionType batchv1.JobConditionType) *batchv1.Job {
+		return &batchv1.Job{
+			Status: batchv1.JobStatus{
+				Conditions: []batchv1.JobCondition{{Type: conditionType, Status: corev1.ConditionTrue}},
+			},
+		}
+	}
+
+	expectCondition := func(conditionType osbuildv1alpha1.ConditionType, msg string) {
+		osBuildReleaseRepository.EXPECT().PatchStatus(requestContext, instance, gomock.Any()).DoAndReturn(
+			func(ctx context.Context, osBuildRelease *osbuildv1alpha1.OSBuildRelease, patch *client.Patch) error {
+				Expect(osBuildRelease.Status.Conditions).To(HaveLen(1))
+				Expect(osBuildRelease.Status.Conditions[0].Type).To(Equal(conditionType))
+				Expect(*osBuildRelease.Status.Conditions[0].Message).To(Equal(msg))
+				return nil
+			})
+	}
+
+	BeforeEach(func() {
+		os.Setenv("WORKING_NAMESPACE", operatorNamespace)
+		os.Setenv("CA_ISSUER_NAME", caIssuerName)
+		os.Setenv("TEMPLATES_DIR", templatesDir)
+		err := conf.Load()
+		Expect(err).To(BeNil())
+
+		mockCtrl = gomock.NewController(GinkgoT())
+		osBuildReleaseRepository = osbuildrelease.NewMockRepository(mockCtrl)
+		osBuildRepository = osbuild.NewMockRepository(mockCtrl)
+		ostreeRepositoryRepository = ostreerepository.NewMockRepository(mockCtrl)
+		jobRepository = job.NewMockRepository(mockCtrl)
+		s3ObjectStore = s3.NewMockObjectStore(mockCtrl)
+
+		scheme := runtime.NewScheme()
+		err = clientgoscheme.AddToScheme(scheme)
+		Expect(err).To(BeNil())
+		err = osbuildv1alpha1.AddToScheme(scheme)
+		Expect(err).To(BeNil())
+
+		reconciler = &controllers.OSBuildReleaseReconciler{
+			Scheme:                     scheme,
+			OSBuildReleaseRepository:   osBuildReleaseRepository,
+			OSBuildRepository:          osBuildRepository,
+			OSTreeRepositoryRepository: ostreeRepositoryRepository,
+			JobRepository:              jobRepository,
+			S3ObjectStore:              s3ObjectStore,
+		}
+
+		requestContext = context.TODO()
+
+		errNotFound = errors.NewNotFound(schema.GroupResource{}, "Requested resource was not found")
+		errFailed = errors.NewInternalError(fmt.Errorf("Server encounter and error"))
+
+		containerImageTag := tag
+		instance = &osbuildv1alpha1.OSBuildRelease{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:       instanceName,
+				Namespace:  instanceNamespace,
+				Generation: 1,
+			},
+			Spec: osbuildv1alpha1.OSBuildReleaseSpec{
+				OSBuildRef:         osBuildName,
+				ContainerImageTag:  &containerImageTag,
+				ImagePullSecretRef: &osbuildv1alpha1.NameRef{Name: "pull-secret"},
+				OSTreeRef: &osbuildv1alpha1.ReleaseOSTreeRef{
+					OSTreeRepositoryRef: repoName,
+					Ref:                 ref,
+				},
+			},
+		}
+
+		osBuild = &osbuildv1alpha1.OSBuild{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      osBuildName,
+				Namespace: instanceNamespace,
+			},
+			Spec: osbuildv1alpha1.OSBuildSpec{
+				Details: &osbuildv1alpha1.BuildDetails{
+					TargetImage: osbuildv1alpha1.TargetImage{
+						TargetImageType: osbuildv1alpha1.EdgeContainerImageType,
+					},
+				},
+			},
+			Status: osbuildv1alpha1.OSBuildStatus{
+				Conditions:   []osbuildv1alpha1.Condition{{Type: osbuildv1alpha1.ConditionReady, Status: metav1.ConditionTrue}},
+				AccessUrl:    edgeContainerUrl,
+				OSTreeCommit: commit,
+			},
+		}
+	})
+
+	AfterEach(func() {
+		os.Unsetenv("WORKING_NAMESPACE")
+		os.Unsetenv("CA_ISSUER_NAME")
+		os.Unsetenv("TEMPLATES_DIR")
+		mockCtrl.Finish()
+	})
+
+	It("should return Done when the instance is not found", func() {
+		// given
+		osBuildReleaseRepository.EXPECT().Read(requestContext, instanceName, instanceNamespace).Return(nil, errNotFound)
+		// when
+		result, err := reconciler.Reconcile(requestContext, request)
+		// then
+		Expect(err).To(BeNil())
+		Expect(result).To(Equal(resultDone))
+	})
+
+	Context("OSBuild", func() {
+		BeforeEach(func() {
+			osBuildReleaseRepository.EXPECT().Read(requestContext, instanceName, instanceNamespace).Return(instance, nil)
+		})
+
+		It("should do nothing when the OSBuild is already released", func() {
+			// given
+			instance.Status.Current = &osbuildv1alpha1.OSBuildReleaseRecord{OSBuildName: osBuildName}
+			// when
+			result, err := reconciler.Reconcile(requestContext, request)
+			// then
+			Expect(err).To(BeNil())
+			Expect(result).To(Equal(resultDone))
+		})
+
+		It("should fail when the OSBuild is not found", func() {
+			// given
+			osBuildRepository.EXPECT().Read(requestContext, osBuildName, instanceNamespace).Return(nil, errNotFound)
+			expectCondition(osbuildv1alpha1.ConditionFailed, "OSBuild config-2 not found")
+			// when
+			result, err := reconciler.Reconcile(requestContext, request)
+			// then
+			Expect(err).To(BeNil())
+			Expect(result).To(Equal(resultDone))
+		})
+
+		DescribeTable("should fail when the OSBuild failed", func(conditionType osbuildv1alpha1.ConditionType) {
+			// given
+			osBuild.Status.Conditions = []osbuildv1alpha1.Condition{{Type: conditionType, Status: metav1.ConditionTrue}}
+			osBuildRepository.EXPECT().Read(requestContext, osBuildName, instanceNamespace).Return(osBuild, nil)
+			expectCondition(osbuildv1alpha1.ConditionFailed, "OSBuild config-2 failed")
+			// when
+			result, err := reconciler.Reconcile(requestContext, request)
+			// then
+			Expect(err).To(BeNil())
+			Expect(result).To(Equal(resultDone))
+		},
+			Entry("the build failed", osbuildv1alpha1.ConditionFailed),
+			Entry("the validation failed", osbuildv1alpha1.ConditionValidationFailed),
+		)
+
+		It("should fail when the vulnerability policy of the OSBuild blocks its release", func() {
+			// given
+			osBuild.Spec.VulnerabilityScan = &osbuildv1alpha1.VulnerabilityScan{Policy: &osbuildv1alpha1.VulnerabilityPolicy{}}
+			osBuild.Status.Vulnerabilities = &osbuildv1alpha1.VulnerabilitySummary{Critical: 1, PolicyViolations: []string{"CVE-2022-0001"}}
+			osBuildRepository.EXPECT().Read(requestContext, osBuildName, instanceNamespace).Return(osBuild, nil)
+			expectCondition(osbuildv1alpha1.ConditionFailed, "OSBuild config-2 is blocked by its vulnerability policy")
+			// when
+			result, err := reconciler.Reconcile(requestContext, request)
+			// then
+			Expect(err).To(BeNil())
+			Expect(result).To(Equal(resultDone))
+		})
+
+		It("should fail when the OSBuild has vulnerabilities failing it", func() {
+			// given
+			osBuild.Status.Conditions = []osbuildv1alpha1.Condition{{Type: osbuildv1alpha1.ConditionVulnerabilitiesFound, Status: metav1.ConditionTrue}}
+			osBuildRepository.EXPECT().Read(requestContext, osBuildName, instanceNamespace).Return(osBuild, nil)
+			expectCondition(osbuildv1alpha1.ConditionFailed, "OSBuild config-2 is blocked by its vulnerability policy")
+			// when
+			result, err := reconciler.Reconcile(requestContext, request)
+			// then
+			Expect(err).To(BeNil())
+			Expect(result).To(Equal(resultDone))
+		})
+
+		It("should wait for the OSBuild to be Ready", func() {
+			// given
+			osBuild.Status.Conditions = []osbuildv1alpha1.Condition{{Type: osbuildv1alpha1.ConditionInProgress, Status: metav1.ConditionTrue}}
+			osBuildRepository.EXPECT().Read(requestContext, osBuildName, instanceNamespace).Return(osBuild, nil)
+			expectCondition(osbuildv1alpha1.ConditionInProgress, "Waiting for OSBuild config-2 to be Ready")
+			// when
+			result, err := reconciler.Reconcile(requestContext, request)
+			// then
+			Expect(err).To(BeNil())
+			Expect(result).To(Equal(resultDone))
+		})
+	})
+
+	Context("Edge container", func() {
+		BeforeEach(func() {
+			osBuildReleaseRepository.EXPECT().Read(requestContext, instanceName, instanceNamespace).Return(instance, nil)
+			osBuildRepository.EXPECT().Read(requestContext, osBuildName, instanceNamespace).Return(osBuild, nil)
+		})
+
+		DescribeTable("should create the job tagging the image", func(accessUrl string, expectedImage string) {
+			// given
+			osBuild.Status.AccessUrl = accessUrl
+			jobRepository.EXPECT().Read(requestContext, retagJobName, instanceNamespace).Return(nil, errNotFound)
+			jobRepository.EXPECT().Create(requestContext, gomock.Any()).DoAndReturn(
+				func(ctx context.Context, retagJob *batchv1.Job) error {
+					Expect(retagJob.Name).To(Equal(retagJobName))
+					Expect(retagJob.OwnerReferences).To(HaveLen(1))
+					container := retagJob.Spec.Template.Spec.Containers[0]
+					Expect(container.Command).To(ContainElements(
+						"--authfile=/var/auth/.dockerconfigjson",
+						"docker://"+accessUrl,
+						"docker://"+expectedImage,
+					))
+					Expect(retagJob.Spec.Template.Spec.Volumes[0].Secret.SecretName).To(Equal("pull-secret"))
+					return nil
+				})
+			expectCondition(osbuildv1alpha1.ConditionInProgress, "Tagging the image of OSBuild config-2")
+			// when
+			result, err := reconciler.Reconcile(requestContext, request)
+			// then
+			Expect(err).To(BeNil())
+			Expect(result).To(Equal(resultLongRequeue))
+		},
+			Entry("tag", edgeContainerUrl, "registry.example.com/edge/config:stable"),
+			Entry("registry port", "registry.example.com:5000/edge/config:2", "registry.example.com:5000/edge/config:stable"),
+			Entry("digest", "registry.example.com/edge/config@sha256:abc", "registry.example.com/edge/config:stable"),
+		)
+
+		It("should tag the image again when the OSBuild is promoted again", func() {
+			// given
+			instance.Generation = 3
+			instance.Status.History = []osbuildv1alpha1.OSBuildReleaseRecord{{OSBuildName: osBuildName}, {OSBuildName: "config-3"}}
+			instance.Status.Current = &instance.Status.History[1]
+			jobRepository.EXPECT().Read(requestContext, "stable-retag-config-2-3", instanceNamespace).Return(nil, errNotFound)
+			jobRepository.EXPECT().Create(requestContext, gomock.Any()).DoAndReturn(
+				func(ctx context.Context, retagJob *batchv1.Job) error {
+					Expect(retagJob.Name).To(Equal("stable-retag-config-2-3"))
+					return nil
+				})
+			expectCondition(osbuildv1alpha1.ConditionInProgress, "Tagging the image of OSBuild config-2")
+			// when
+			result, err := reconciler.Reconcile(requestContext, request)
+			// then
+			Expect(err).To(BeNil())
+			Expect(result).To(Equal(resultLongRequeue))
+		})
+
+		It("should shorten the name of the job tagging the image of long names", func() {
+			// given
+			instance.Name = strings.Repeat("stable", 10)
+			var jobName string
+			jobRepository.EXPECT().Read(requestContext, gomock.Any(), instanceNamespace).DoAndReturn(
+				func(ctx context.Context, name string, namespace string) (*batchv1.Job, error) {
+					jobName = name
+					return nil, errNotFound
+				})
+			jobRepository.EXPECT().Create(requestContext, gomock.Any()).DoAndReturn(
+				func(ctx context.Context, retagJob *batchv1.Job) error {
+					Expect(retagJob.Name).To(Equal(jobName))
+					return nil
+				})
+			expectCondition(osbuildv1alpha1.ConditionInProgress, "Tagging the image of OSBuild config-2")
+			// when
+			result, err := reconciler.Reconcile(requestContext, request)
+			// then
+			Expect(err).To(BeNil())
+			Expect(result).To(Equal(resultLongRequeue))
+			Expect(validation.IsDNS1123Label(jobName)).To(BeEmpty())
+			Expect(jobName).To(HavePrefix("stablestable"))
+		})
+
+		It("should fail when the image cannot be tagged", func() {
+			// given
+			jobRepository.EXPECT().Read(requestContext, retagJobName, instanceNamespace).Return(newJob(batchv1.JobFailed), nil)
+			expectCondition(osbuildv1alpha1.ConditionFailed, "Failed to tag the image of OSBuild config-2")
+			// when
+			result, err := reconciler.Reconcile(requestContext, request)
+			// then
+			Expect(err).To(BeNil())
+			Expect(result).To(Equal(resultDone))
+		})
+
+		It("should wait for the repository to import the commit", func() {
+			// given
+			jobRepository.EXPECT().Read(requestContext, retagJobName, instanceNamespace).Return(newJob(batchv1.JobComplete), nil)
+			ostreeRepositoryRepository.EXPECT().Read(requestContext, repoName, instanceNamespace).Return(&osbuildv1alpha1.OSTreeRepository{}, nil)
+			expectCondition(osbuildv1alpha1.ConditionInProgress, "Waiting for OSTreeRepository repo to import the commit of OSBuild config-2")
+			// when
+			result, err := reconciler.Reconcile(requestContext, request)
+			// then
+			Expect(err).To(BeNil())
+			Expect(result).To(Equal(resultLongRequeue))
+		})
+
+		Context("with the commit imported", func() {
+			var ostreeRepository *osbuildv1alpha1.OSTreeRepository
+
+			BeforeEach(func() {
+				ostreeRepository = &osbuildv1alpha1.OSTreeRepository{
+					ObjectMeta: metav1.ObjectMeta{Name: repoName, Namespace: instanceNamespace},
+					Spec: osbuildv1alpha1.OSTreeRepositorySpec{
+						GPGKeySecretRef: &osbuildv1alpha1.NameRef{Name: "gpg-key"},
+					},
+					Status: osbuildv1alpha1.OSTreeRepositoryStatus{
+						Imports: []osbuildv1alpha1.OSTreeRepositoryImport{{OSBuildName: osBuildName, Commit: commit, Succeeded: true}},
+					},
+				}
+				jobRepository.EXPECT().Read(requestContext, retagJobName, instanceNamespace).Return(newJob(batchv1.JobComplete), nil)
+				ostreeRepositoryRepository.EXPECT().Read(requestContext, repoName, instanceNamespace).Return(ostreeRepository, nil)
+			})
+
+			It("should create the job moving the ref", func() {
+				// given
+				jobRepository.EXPECT().Read(requestContext, refJobName, instanceNamespace).Return(nil, errNotFound)
+				jobRepository.EXPECT().Create(requestContext, gomock.Any()).DoAndReturn(
+					func(ctx context.Context, refJob *batchv1.Job) error {
+						Expect(refJob.Name).To(Equal(refJobName))
+						podSpec := refJob.Spec.Template.Spec
+						Expect(podSpec.Containers[0].Command[2]).To(ContainSubstring(fmt.Sprintf("refs --force --create=%s %s", ref, commit)))
+						Expect(podSpec.Containers[0].Command[2]).To(ContainSubstring("summary -u $SIGN_OPTS"))
+						Expect(podSpec.Volumes[0].PersistentVolumeClaim.ClaimName).To(Equal("repo-ostree-repo"))
+						Expect(podSpec.Volumes[1].Secret.SecretName).To(Equal("gpg-key"))
+						return nil
+					})
+				expectCondition(osbuildv1alpha1.ConditionInProgress, "Moving the ref to the commit of OSBuild config-2")
+				// when
+				result, err := reconciler.Reconcile(requestContext, request)
+				// then
+				Expect(err).To(BeNil())
+				Expect(result).To(Equal(resultLongRequeue))
+			})
+
+			It("should record the release once the ref is moved", func() {
+				// given
+				instance.Status.History = []osbuildv1alpha1.OSBuildReleaseRecord{{OSBuildName: "config-1"}}
+				jobRepository.EXPECT().Read(requestContext, refJobName, instanceNamespace).Return(newJob(batchv1.JobComplete), nil)
+				osBuildReleaseRepository.EXPECT().PatchStatus(requestContext, instance, gomock.Any()).DoAndReturn(
+					func(ctx context.Context, osBuildRelease *osbuildv1alpha1.OSBuildRelease, patch *client.Patch) error {
+						current := osBuildRelease.Status.Current
+						Expect(current.OSBuildName).To(Equal(osBuildName))
+						Expect(current.ContainerImage).To(Equal("registry.example.com/edge/config:stable"))
+						Expect(current.OSTreeCommit).To(Equal(commit))
+						Expect(current.ReleaseTime.IsZero()).To(BeFalse())
+						Expect(osBuildRelease.Status.History).To(HaveLen(2))
+						Expect(osBuildRelease.Status.History[1]).To(Equal(*current))
+						Expect(osBuildRelease.Status.Conditions[0].Type).To(Equal(osbuildv1alpha1.ConditionReady))
+						return nil
+					})
+				// when
+				result, err := reconciler.Reconcile(requestContext, request)
+				// then
+				Expect(err).To(BeNil())
+				Expect(result).To(Equal(resultDone))
+			})
+
+			It("should fail when the ref cannot be moved", func() {
+				// given
+				jobRepository.EXPECT().Read(requestContext, refJobName, instanceNamespace).Return(newJob(batchv1.JobFailed), nil)
+				expectCondition(osbuildv1alpha1.ConditionFailed, "Failed to move the ref to the commit of OSBuild config-2")
+				// when
+				result, err := reconciler.Reconcile(requestContext, request)
+				// then
+				Expect(err).To(BeNil())
+				Expect(result).To(Equal(resultDone))
+			})
+		})
+	})
+
+	Context("Installer", func() {
+		BeforeEach(func() {
+			key := installerKey
+			instance.Spec = osbuildv1alpha1.OSBuildReleaseSpec{
+				OSBuildRef:   osBuildName,
+				InstallerKey: &key,
+			}
+			osBuild.Spec.Details.TargetImage.TargetImageType = osbuildv1alpha1.EdgeInstallerImageType
+			osBuild.Status.AccessUrl = installerUrl
+			osBuild.Status.OSTreeCommit = ""
+			osBuildReleaseRepository.EXPECT().Read(requestContext, instanceName, instanceNamespace).Return(instance, nil)
+			osBuildRepository.EXPECT().Read(requestContext, osBuildName, instanceNamespace).Return(osBuild, nil)
+		})
+
+		It("should copy the installer to the release key", func() {
+			// given
+			s3ObjectStore.EXPECT().CopyObject(requestContext, installerUrl, installerKey).Return("s3://images/"+installerKey, nil)
+			osBuildReleaseRepository.EXPECT().PatchStatus(requestContext, instance, gomock.Any()).DoAndReturn(
+				func(ctx context.Context, osBuildRelease *osbuildv1alpha1.OSBuildRelease, patch *client.Patch) error {
+					Expect(osBuildRelease.Status.Current.InstallerUrl).To(Equal("s3://images/" + installerKey))
+					Expect(osBuildRelease.Status.History).To(HaveLen(1))
+					return nil
+				})
+			// when
+			result, err := reconciler.Reconcile(requestContext, request)
+			// then
+			Expect(err).To(BeNil())
+			Expect(result).To(Equal(resultDone))
+		})
+
+		It("should retry when the installer cannot be copied", func() {
+			// given
+			s3ObjectStore.EXPECT().CopyObject(requestContext, installerUrl, installerKey).Return("", errFailed)
+			expectCondition(osbuildv1alpha1.ConditionFailed, "Failed to copy the image of OSBuild config-2")
+			// when
+			result, err := reconciler.Reconcile(requestContext, request)
+			// then
+			Expect(err).To(BeNil())
+			Expect(result).To(Equal(resultLongRequeue))
+		})
+
+		It("should fail when a container image tag is requested", func() {
+			// given
+			containerImageTag := tag
+			instance.Spec.ContainerImageTag = &containerImageTag
+			expectCondition(osbuildv1alpha1.ConditionFailed, "OSBuild config-2 of type edge-installer has no container image to release")
+			// when
+			result, err := reconciler.Reconcile(requestContext, request)
+			// then
+			Expect(err).To(BeNil())
+			Expect(result).To(Equal(resultDone))
+		})
+	})
+})
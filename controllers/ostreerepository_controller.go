@@ -0,0 +1,984 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	routev1 "github.com/openshift/api/route/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	osbuildv1alpha1 "github.com/project-flotta/osbuild-operator/api/v1alpha1"
+	"github.com/project-flotta/osbuild-operator/internal/conf"
+	"github.com/project-flotta/osbuild-operator/internal/repository/deployment"
+	"github.com/project-flotta/osbuild-operator/internal/repository/job"
+	repositoryosbuild "github.com/project-flotta/osbuild-operator/internal/repository/osbuild"
+	"github.com/project-flotta/osbuild-operator/internal/repository/ostreerepository"
+	"github.com/project-flotta/osbuild-operator/internal/repository/persistentvolumeclaim"
+	"github.com/project-flotta/osbuild-operator/internal/repository/pod"
+	"github.com/project-flotta/osbuild-operator/internal/repository/route"
+	"github.com/project-flotta/osbuild-operator/internal/repository/service"
+	"github.com/project-flotta/osbuild-operator/internal/templates"
+)
+
+const (
+	ostreeRepoNameFormat       = "%s-ostree-repo"
+	ostreeRepoImportNameFormat = "%s-import-%s"
+	ostreeRepoDeltasNameFormat = "%s-deltas-%s"
+
+	ostreeRepoDeploymentTemplateFile = "ostree-repo-deployment.yaml"
+	ostreeRepoImportJobTemplateFile  = "ostree-repo-import-job.yaml"
+	ostreeRepoDeltasJobTemplateFile  = "ostree-repo-deltas-job.yaml"
+
+	ostreeRepoDeltasContainerName = "deltas"
+
+	// ostreeRepoImportMaxRetryDelay caps the backoff of the retries of a failed import
+	ostreeRepoImportMaxRetryDelay = time.Hour
+
+	// maxJobNameLength is the maximum length of the names of the jobs, so the job-name label of their pods is valid
+	maxJobNameLength = 63
+
+	ostreeRepoPortName = "http"
+	ostreeRepoPort     = 8080
+
+	ostreeRepoStorageDir = "/var/ostree"
+	ostreeRepoDir        = ostreeRepoStorageDir + "/repo"
+	ostreeRepoSourceDir  = "/var/source"
+	ostreeRepoGPGKeyDir  = "/var/gpg"
+
+	// ostreeRepoGPGKeyFile is where the public GPG key is published in the repository
+	ostreeRepoGPGKeyFile = "key.gpg"
+
+	// edgeContainerRepoDir is where the edge-container images serve their OSTree repository from
+	edgeContainerRepoDir = "/usr/share/nginx/html/repo"
+
+	ostreeRepoDefaultStorageSize = "10Gi"
+
+	ostreeRepoDefaultStaticDeltasFromCommits = 3
+
+	// ostreeRepoImportsLimit is the number of imports kept in the status, more are kept when the static deltas are
+	// generated from more previously imported commits
+	ostreeRepoImportsLimit = 10
+
+	ostreeRepoImportInProgressMsg = "Importing the commit of OSBuild %s"
+	ostreeRepoImportFailedMsg     = "Failed to import the commit of OSBuild %s"
+	ostreeRepoUpToDateMsg         = "The ref points at the commit of the last Ready OSBuild"
+	ostreeRepoDeltasInProgressMsg = "Generating the static deltas to the commit of OSBuild %s"
+	ostreeRepoDeltasFailedMsg     = "Failed to generate the static deltas to the commit of OSBuild %s"
+)
+
+type ostreeRepoDeploymentParameters struct {
+	Namespace  string
+	Name       string
+	ImageName  string
+	ImageTag   string
+	Port       int
+	StorageDir string
+	RepoDir    string
+}
+
+type ostreeRepoImportJobParameters struct {
+	Namespace            string
+	Name                 string
+	RepoName             string
+	ImageName            string
+	ImageTag             string
+	ImagePullSecretName  string
+	EdgeContainerImage   string
+	EdgeContainerRepoDir string
+	Commit               string
+	Ref                  string
+	StorageDir           string
+	RepoDir              string
+	SourceDir            string
+	GPGKeySecretName     string
+	GPGKeyDir            string
+	GPGKeyFile           string
+}
+
+type ostreeRepoDeltasJobParameters struct {
+	Namespace        string
+	Name             string
+	RepoName         string
+	ContainerName    string
+	ImageName        string
+	ImageTag         string
+	FromCommits      string
+	Ref              string
+	StorageDir       string
+	RepoDir          string
+	GPGKeySecretName string
+	GPGKeyDir        string
+}
+
+// OSTreeRepositoryReconciler reconciles a OSTreeRepository object
+type OSTreeRepositoryReconciler struct {
+	Scheme                          *runtime.Scheme
+	OSTreeRepositoryRepository      ostreerepository.Repository
+	OSBuildRepository               repositoryosbuild.Repository
+	PersistentVolumeClaimRepository persistentvolumeclaim.Repository
+	DeploymentRepository            deployment.Repository
+	ServiceRepository               service.Repository
+	RouteRepository                 route.Repository
+	JobRepository                   job.Repository
+	PodRepository                   pod.Repository
+}
+
+//+kubebuilder:rbac:groups=osbuilder.project-flotta.io,resources=ostreerepositories,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=osbuilder.project-flotta.io,resources=ostreerepositories/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=osbuilder.project-flotta.io,resources=ostreerepositories/finalizers,verbs=update
+// +kubebuilder:rbac:groups=core,resources=persistentvolumeclaims,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch
+
+// Reconcile keeps the repository server running and imports the commit of the last Ready edge-container OSBuild of
+// the referenced OSBuildConfig, moving the configured ref to it. When requested, the static deltas to every imported
+// commit are generated before the next import
+func (r *OSTreeRepositoryReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx).WithValues("ostreerepository", req.Name)
+
+	ostreeRepository, err := r.OSTreeRepositoryRepository.Read(ctx, req.Name, req.Namespace)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		logger.Error(err, "failed to get the OSTreeRepository")
+		return ctrl.Result{Requeue: true, RequeueAfter: RequeueForShortDuration}, nil
+	}
+
+	if ostreeRepository.DeletionTimestamp != nil {
+		// The repository resources are deleted thanks to their controller reference
+		return ctrl.Result{}, nil
+	}
+
+	created, err := r.ensureServerExists(ctx, logger, ostreeRepository)
+	if err != nil {
+		logger.Error(err, "failed to create the repository server")
+		return ctrl.Result{Requeue: true, RequeueAfter: RequeueForShortDuration}, nil
+	} else if created {
+		return resultQuickRequeue, nil
+	}
+
+	url, err := r.getRepositoryUrl(ctx, ostreeRepository)
+	if err != nil {
+		logger.Error(err, "failed to get the repository url")
+		return ctrl.Result{Requeue: true, RequeueAfter: RequeueForShortDuration}, nil
+	} else if url == nil {
+		return ctrl.Result{Requeue: true, RequeueAfter: RequeueForShortDuration}, nil
+	}
+
+	gpgKeyUrl := ""
+	if ostreeRepository.Spec.GPGKeySecretRef != nil {
+		gpgKeyUrl = *url + ostreeRepoGPGKeyFile
+	}
+
+	if ostreeRepository.Status.Url != *url || ostreeRepository.Status.GPGKeyUrl != gpgKeyUrl {
+		patch := client.MergeFrom(ostreeRepository.DeepCopy())
+		ostreeRepository.Status.Url = *url
+		ostreeRepository.Status.GPGKeyUrl = gpgKeyUrl
+		err = r.OSTreeRepositoryRepository.PatchStatus(ctx, ostreeRepository, &patch)
+		if err != nil {
+			logger.Error(err, "failed to patch the repository urls")
+			return ctrl.Result{Requeue: true, RequeueAfter: RequeueForShortDuration}, nil
+		}
+	}
+
+	if lastImport := getStaticDeltasPendingImport(ostreeRepository); lastImport != nil {
+		return r.generateStaticDeltas(ctx, logger, ostreeRepository, lastImport)
+	}
+
+	return r.importLastReadyOSBuild(ctx, logger, ostreeRepository)
+}
+
+func (r *OSTreeRepositoryReconciler) ensureServerExists(ctx context.Context, logger logr.Logger, ostreeRepository *osbuildv1alpha1.OSTreeRepository) (bool, error) {
+	created, err := r.ensurePersistentVolumeClaimExists(ctx, ostreeRepository)
+	if err != nil {
+		return false, err
+	} else if created {
+		logger.Info("Generated PersistentVolumeClaim for the repository")
+		return true, nil
+	}
+
+	created, err = r.ensureDeploymentExists(ctx, ostreeRepository)
+	if err != nil {
+		return false, err
+	} else if created {
+		logger.Info("Generated Deployment for the repository")
+		return true, nil
+	}
+
+	created, err = r.ensureServiceExists(ctx, ostreeRepository)
+	if err != nil {
+		return false, err
+	} else if created {
+		logger.Info("Generated Service for the repository")
+		return true, nil
+	}
+
+	created, err = r.ensureRouteExists(ctx, ostreeRepository)
+	if err != nil {
+		return false, err
+	} else if created {
+		logger.Info("Generated Route for the repository")
+		return true, nil
+	}
+
+	return false, nil
+}
+
+func (r *OSTreeRepositoryReconciler) ensurePersistentVolumeClaimExists(ctx context.Context, ostreeRepository *osbuildv1alpha1.OSTreeRepository) (bool, error) {
+	name := fmt.Sprintf(ostreeRepoNameFormat, ostreeRepository.Name)
+	_, err := r.PersistentVolumeClaimRepository.Read(ctx, name, ostreeRepository.Namespace)
+	if err == nil {
+		return false, nil
+	}
+
+	if errors.IsNotFound(err) {
+		persistentVolumeClaim, err := r.generatePersistentVolumeClaim(ostreeRepository)
+		if err != nil {
+			return false, err
+		}
+
+		err = r.PersistentVolumeClaimRepository.Create(ctx, persistentVolumeClaim)
+		if err != nil {
+			return false, err
+		}
+
+		return true, nil
+	}
+
+	return false, err
+}
+
+func (r *OSTreeRepositoryReconciler) generatePersistentVolumeClaim(ostreeRepository *osbuildv1alpha1.OSTreeRepository) (*corev1.PersistentVolumeClaim, error) {
+	size := resource.MustParse(ostreeRepoDefaultStorageSize)
+	var storageClassName *string
+	if storage := ostreeRepository.Spec.Storage; storage != nil {
+		if storage.Size != nil {
+			size = *storage.Size
+		}
+		storageClassName = storage.StorageClassName
+	}
+
+	persistentVolumeClaim := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf(ostreeRepoNameFormat, ostreeRepository.Name),
+			Namespace: ostreeRepository.Namespace,
+		},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+			Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{
+					corev1.ResourceStorage: size,
+				},
+			},
+			StorageClassName: storageClassName,
+		},
+	}
+
+	return persistentVolumeClaim, controllerutil.SetControllerReference(ostreeRepository, persistentVolumeClaim, r.Scheme)
+}
+
+func (r *OSTreeRepositoryReconciler) ensureDeploymentExists(ctx context.Context, ostreeRepository *osbuildv1alpha1.OSTreeRepository) (bool, error) {
+	name := fmt.Sprintf(ostreeRepoNameFormat, ostreeRepository.Name)
+	_, err := r.DeploymentRepository.Read(ctx, name, ostreeRepository.Namespace)
+	if err == nil {
+		return false, nil
+	}
+
+	if errors.IsNotFound(err) {
+		ostreeRepoDeployment, err := r.generateDeployment(ostreeRepository)
+		if err != nil {
+			return false, err
+		}
+
+		err = r.DeploymentRepository.Create(ctx, ostreeRepoDeployment)
+		if err != nil {
+			return false, err
+		}
+
+		return true, nil
+	}
+
+	return false, err
+}
+
+func (r *OSTreeRepositoryReconciler) generateDeployment(ostreeRepository *osbuildv1alpha1.OSTreeRepository) (*appsv1.Deployment, error) {
+	deploymentParams := ostreeRepoDeploymentParameters{
+		Namespace:  ostreeRepository.Namespace,
+		Name:       fmt.Sprintf(ostreeRepoNameFormat, ostreeRepository.Name),
+		ImageName:  conf.GlobalConf.OSTreeRepoImageName,
+		ImageTag:   conf.GlobalConf.OSTreeRepoImageTag,
+		Port:       ostreeRepoPort,
+		StorageDir: ostreeRepoStorageDir,
+		RepoDir:    ostreeRepoDir,
+	}
+
+	buf, err := templates.LoadFromTemplateFile(ostreeRepoDeploymentTemplateFile, deploymentParams)
+	if err != nil {
+		return nil, err
+	}
+
+	decode := scheme.Codecs.UniversalDeserializer().Decode
+	obj, _, err := decode(buf.Bytes(), nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	ostreeRepoDeployment, ok := obj.(*appsv1.Deployment)
+	if !ok {
+		return nil, fmt.Errorf("failed to deserialize the deployment object")
+	}
+
+	return ostreeRepoDeployment, controllerutil.SetControllerReference(ostreeRepository, ostreeRepoDeployment, r.Scheme)
+}
+
+func (r *OSTreeRepositoryReconciler) ensureServiceExists(ctx context.Context, ostreeRepository *osbuildv1alpha1.OSTreeRepository) (bool, error) {
+	name := fmt.Sprintf(ostreeRepoNameFormat, ostreeRepository.Name)
+	_, err := r.ServiceRepository.Read(ctx, name, ostreeRepository.Namespace)
+	if err == nil {
+		return false, nil
+	}
+
+	if errors.IsNotFound(err) {
+		ostreeRepoService, err := r.generateService(ostreeRepository)
+		if err != nil {
+			return false, err
+		}
+
+		err = r.ServiceRepository.Create(ctx, ostreeRepoService)
+		if err != nil {
+			return false, err
+		}
+
+		return true, nil
+	}
+
+	return false, err
+}
+
+func (r *OSTreeRepositoryReconciler) generateService(ostreeRepository *osbuildv1alpha1.OSTreeRepository) (*corev1.Service, error) {
+	name := fmt.Sprintf(ostreeRepoNameFormat, ostreeRepository.Name)
+	ostreeRepoService := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: ostreeRepository.Namespace,
+		},
+		Spec: corev1.ServiceSpec{
+			Type: corev1.ServiceTypeClusterIP,
+			Ports: []corev1.ServicePort{
+				{
+					Name:       ostreeRepoPortName,
+					Port:       int32(ostreeRepoPort),
+					Protocol:   "TCP",
+					TargetPort: intstr.FromInt(ostreeRepoPort),
+				},
+			},
+			Selector: map[string]string{"app": name},
+		},
+	}
+
+	return ostreeRepoService, controllerutil.SetControllerReference(ostreeRepository, ostreeRepoService, r.Scheme)
+}
+
+func (r *OSTreeRepositoryReconciler) ensureRouteExists(ctx context.Context, ostreeRepository *osbuildv1alpha1.OSTreeRepository) (bool, error) {
+	name := fmt.Sprintf(ostreeRepoNameFormat, ostreeRepository.Name)
+	_, err := r.RouteRepository.Read(ctx, name, ostreeRepository.Namespace)
+	if err == nil {
+		return false, nil
+	}
+
+	if errors.IsNotFound(err) {
+		ostreeRepoRoute, err := r.generateRoute(ostreeRepository)
+		if err != nil {
+			return false, err
+		}
+
+		err = r.RouteRepository.Create(ctx, ostreeRepoRoute)
+		if err != nil {
+			return false, err
+		}
+
+		return true, nil
+	}
+
+	return false, err
+}
+
+func (r *OSTreeRepositoryReconciler) generateRoute(ostreeRepository *osbuildv1alpha1.OSTreeRepository) (*routev1.Route, error) {
+	name := fmt.Sprintf(ostreeRepoNameFormat, ostreeRepository.Name)
+	ostreeRepoRoute := &routev1.Route{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: ostreeRepository.Namespace,
+		},
+		Spec: routev1.RouteSpec{
+			To: routev1.RouteTargetReference{
+				Kind: "Service",
+				Name: name,
+			},
+			Port: &routev1.RoutePort{
+				TargetPort: intstr.FromString(ostreeRepoPortName),
+			},
+			TLS: &routev1.TLSConfig{
+				Termination:                   routev1.TLSTerminationEdge,
+				InsecureEdgeTerminationPolicy: routev1.InsecureEdgeTerminationPolicyRedirect,
+			},
+		},
+	}
+	return ostreeRepoRoute, controllerutil.SetControllerReference(ostreeRepository, ostreeRepoRoute, r.Scheme)
+}
+
+// getRepositoryUrl returns the URL of the repository once its route is admitted
+func (r *OSTreeRepositoryReconciler) getRepositoryUrl(ctx context.Context, ostreeRepository *osbuildv1alpha1.OSTreeRepository) (*string, error) {
+	ostreeRepoRoute, err := r.RouteRepository.Read(ctx, fmt.Sprintf(ostreeRepoNameFormat, ostreeRepository.Name), ostreeRepository.Namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(ostreeRepoRoute.Status.Ingress) == 0 {
+		return nil, nil
+	}
+
+	ingress := &ostreeRepoRoute.Status.Ingress[0]
+	for _, condition := range ingress.Conditions {
+		if condition.Type == routev1.RouteAdmitted && condition.Status == corev1.ConditionTrue {
+			url := fmt.Sprintf("https://%s/", ingress.Host)
+			return &url, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// importLastReadyOSBuild imports the commit of the last Ready edge-container OSBuild of the OSBuildConfig, unless it
+// was already imported. Imports run one at a time since they all write to the same repository. A failed import is
+// retried with backoff, in a new job
+func (r *OSTreeRepositoryReconciler) importLastReadyOSBuild(ctx context.Context, logger logr.Logger, ostreeRepository *osbuildv1alpha1.OSTreeRepository) (ctrl.Result, error) {
+	osBuilds, err := r.OSBuildRepository.ListByOSBuildConfig(ctx, ostreeRepository.Spec.OSBuildConfigRef, ostreeRepository.Namespace)
+	if err != nil {
+		logger.Error(err, "failed to list the OSBuilds of the OSBuildConfig", "OSBuildConfig", ostreeRepository.Spec.OSBuildConfigRef)
+		return ctrl.Result{Requeue: true, RequeueAfter: RequeueForShortDuration}, nil
+	}
+
+	osBuild := getLastReadyEdgeContainerOSBuild(osBuilds)
+	if osBuild == nil || isOSBuildImported(ostreeRepository, osBuild.Name) {
+		return ctrl.Result{}, nil
+	}
+
+	failedImport := getFailedImport(ostreeRepository, osBuild.Name)
+	if failedImport != nil {
+		if untilRetry := time.Until(failedImport.ImportTime.Add(getImportRetryDelay(failedImport.Attempts))); untilRetry > 0 {
+			return ctrl.Result{Requeue: true, RequeueAfter: untilRetry}, nil
+		}
+	}
+
+	jobName := getJobName(ostreeRepoImportNameFormat, ostreeRepository.Name, osBuild.Name)
+	importJob, err := r.JobRepository.Read(ctx, jobName, ostreeRepository.Namespace)
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			logger.Error(err, "failed to get the import job", "job", jobName)
+			return ctrl.Result{Requeue: true, RequeueAfter: RequeueForShortDuration}, nil
+		}
+
+		importJob, err = r.generateImportJob(ostreeRepository, osBuild, jobName)
+		if err != nil {
+			logger.Error(err, "failed to generate the import job", "job", jobName)
+			return ctrl.Result{Requeue: true, RequeueAfter: RequeueForShortDuration}, nil
+		}
+
+		err = r.JobRepository.Create(ctx, importJob)
+		if err != nil {
+			logger.Error(err, "failed to create the import job", "job", jobName)
+			return ctrl.Result{Requeue: true, RequeueAfter: RequeueForShortDuration}, nil
+		}
+
+		logger.Info("Generated import Job", "OSBuild", osBuild.Name)
+		patch := client.MergeFrom(ostreeRepository.DeepCopy())
+		err = r.updateOSTreeRepositoryStatus(ctx, ostreeRepository, patch, osbuildv1alpha1.ConditionInProgress, fmt.Sprintf(ostreeRepoImportInProgressMsg, osBuild.Name))
+		if err != nil {
+			logger.Error(err, "failed to update the OSTreeRepository status")
+		}
+		return ctrl.Result{Requeue: true, RequeueAfter: RequeueForLongDuration}, nil
+	}
+
+	var newImport *osbuildv1alpha1.OSTreeRepositoryImport
+	var conditionType osbuildv1alpha1.ConditionType
+	var msg string
+	switch {
+	case isJobConditionTrue(importJob, batchv1.JobComplete):
+		newImport = &osbuildv1alpha1.OSTreeRepositoryImport{OSBuildName: osBuild.Name, Commit: osBuild.Status.OSTreeCommit, Succeeded: true}
+		conditionType, msg = osbuildv1alpha1.ConditionReady, ostreeRepoUpToDateMsg
+	case isJobConditionTrue(importJob, batchv1.JobFailed):
+		// the failed job is deleted for the retry to run in a new one
+		err = r.JobRepository.Delete(ctx, importJob)
+		if err != nil && !errors.IsNotFound(err) {
+			logger.Error(err, "failed to delete the failed import job", "job", jobName)
+			return ctrl.Result{Requeue: true, RequeueAfter: RequeueForShortDuration}, nil
+		}
+		newImport = &osbuildv1alpha1.OSTreeRepositoryImport{OSBuildName: osBuild.Name, Commit: osBuild.Status.OSTreeCommit, Succeeded: false, Attempts: 1}
+		conditionType, msg = osbuildv1alpha1.ConditionFailed, fmt.Sprintf(ostreeRepoImportFailedMsg, osBuild.Name)
+	default:
+		logger.Info("the import job is still running", "job", jobName)
+		return ctrl.Result{Requeue: true, RequeueAfter: RequeueForLongDuration}, nil
+	}
+
+	newImport.ImportTime = metav1.Now()
+	patch := client.MergeFrom(ostreeRepository.DeepCopy())
+	imports := ostreeRepository.Status.Imports
+	if failedImport != nil {
+		// the attempts of the same import are recorded once
+		imports = imports[:len(imports)-1]
+		if !newImport.Succeeded {
+			newImport.Attempts = failedImport.Attempts + 1
+		}
+	}
+	ostreeRepository.Status.Imports = trimImports(append(imports, *newImport), getImportsLimit(ostreeRepository))
+	err = r.updateOSTreeRepositoryStatus(ctx, ostreeRepository, patch, conditionType, msg)
+	if err != nil {
+		logger.Error(err, "failed to update the OSTreeRepository status")
+		return ctrl.Result{Requeue: true, RequeueAfter: RequeueForShortDuration}, nil
+	}
+
+	logger.Info("the import job finished", "job", jobName, "succeeded", newImport.Succeeded)
+	if !newImport.Succeeded {
+		return ctrl.Result{Requeue: true, RequeueAfter: getImportRetryDelay(newImport.Attempts)}, nil
+	}
+	return ctrl.Result{}, nil
+}
+
+// getFailedImport returns the last import when it is a failed import of the OSBuild
+func getFailedImport(ostreeRepository *osbuildv1alpha1.OSTreeRepository, osBuildName string) *osbuildv1alpha1.OSTreeRepositoryImport {
+	imports := ostreeRepository.Status.Imports
+	if len(imports) == 0 {
+		return nil
+	}
+	lastImport := &imports[len(imports)-1]
+	if lastImport.Succeeded || lastImport.OSBuildName != osBuildName {
+		return nil
+	}
+	return lastImport
+}
+
+// getImportRetryDelay returns the delay before retrying an import after its failed attempts, doubling from
+// RequeueForShortDuration up to ostreeRepoImportMaxRetryDelay
+func getImportRetryDelay(attempts int32) time.Duration {
+	delay := RequeueForShortDuration
+	for i := int32(1); i < attempts && delay < ostreeRepoImportMaxRetryDelay; i++ {
+		delay *= 2
+	}
+	if delay > ostreeRepoImportMaxRetryDelay {
+		delay = ostreeRepoImportMaxRetryDelay
+	}
+	return delay
+}
+
+func (r *OSTreeRepositoryReconciler) generateImportJob(ostreeRepository *osbuildv1alpha1.OSTreeRepository, osBuild *osbuildv1alpha1.OSBuild, jobName string) (*batchv1.Job, error) {
+	importJobParams := ostreeRepoImportJobParameters{
+		Namespace:            ostreeRepository.Namespace,
+		Name:                 jobName,
+		RepoName:             fmt.Sprintf(ostreeRepoNameFormat, ostreeRepository.Name),
+		ImageName:            conf.GlobalConf.OSTreeRepoImageName,
+		ImageTag:             conf.GlobalConf.OSTreeRepoImageTag,
+		EdgeContainerImage:   osBuild.Status.AccessUrl,
+		EdgeContainerRepoDir: edgeContainerRepoDir,
+		Commit:               osBuild.Status.OSTreeCommit,
+		Ref:                  ostreeRepository.Spec.Ref,
+		StorageDir:           ostreeRepoStorageDir,
+		RepoDir:              ostreeRepoDir,
+		SourceDir:            ostreeRepoSourceDir,
+		GPGKeyDir:            ostreeRepoGPGKeyDir,
+		GPGKeyFile:           ostreeRepoGPGKeyFile,
+	}
+	if ostreeRepository.Spec.ImagePullSecretRef != nil {
+		importJobParams.ImagePullSecretName = ostreeRepository.Spec.ImagePullSecretRef.Name
+	}
+	if ostreeRepository.Spec.GPGKeySecretRef != nil {
+		importJobParams.GPGKeySecretName = ostreeRepository.Spec.GPGKeySecretRef.Name
+	}
+
+	buf, err := templates.LoadFromTemplateFile(ostreeRepoImportJobTemplateFile, importJobParams)
+	if err != nil {
+		return nil, err
+	}
+
+	decode := scheme.Codecs.UniversalDeserializer().Decode
+	obj, _, err := decode(buf.Bytes(), nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	importJob, ok := obj.(*batchv1.Job)
+	if !ok {
+		return nil, fmt.Errorf("failed to deserialize the job object")
+	}
+
+	return importJob, controllerutil.SetControllerReference(ostreeRepository, importJob, r.Scheme)
+}
+
+// updateOSTreeRepositoryStatus sets the condition and patches the status, including the changes made to the imports
+// since the patch was taken
+func (r *OSTreeRepositoryReconciler) updateOSTreeRepositoryStatus(ctx context.Context, ostreeRepository *osbuildv1alpha1.OSTreeRepository,
+	patch client.Patch, newConditionType osbuildv1alpha1.ConditionType, msg string) error {
+	found := false
+	for i := range ostreeRepository.Status.Conditions {
+		condition := &ostreeRepository.Status.Conditions[i]
+		if condition.Type == newConditionType {
+			found = true
+			if condition.Status != metav1.ConditionTrue || condition.Message == nil || *condition.Message != msg {
+				condition.Status = metav1.ConditionTrue
+				condition.Message = &msg
+				condition.LastTransitionTime = &metav1.Time{Time: time.Now()}
+			}
+		} else if condition.Status == metav1.ConditionTrue {
+			condition.Status = metav1.ConditionFalse
+			condition.Message = nil
+			condition.LastTransitionTime = &metav1.Time{Time: time.Now()}
+		}
+	}
+	if !found {
+		ostreeRepository.Status.Conditions = append(ostreeRepository.Status.Conditions, osbuildv1alpha1.Condition{
+			Type:               newConditionType,
+			Status:             metav1.ConditionTrue,
+			Message:            &msg,
+			LastTransitionTime: &metav1.Time{Time: time.Now()},
+		})
+	}
+
+	return r.OSTreeRepositoryRepository.PatchStatus(ctx, ostreeRepository, &patch)
+}
+
+// generateStaticDeltas runs a job generating the static deltas from the commits previously imported to the commit of
+// the last import, and records the sizes of the deltas in the status of the imported OSBuild
+func (r *OSTreeRepositoryReconciler) generateStaticDeltas(ctx context.Context, logger logr.Logger, ostreeRepository *osbuildv1alpha1.OSTreeRepository,
+	lastImport *osbuildv1alpha1.OSTreeRepositoryImport) (ctrl.Result, error) {
+	jobName := getJobName(ostreeRepoDeltasNameFormat, ostreeRepository.Name, lastImport.OSBuildName)
+	deltasJob, err := r.JobRepository.Read(ctx, jobName, ostreeRepository.Namespace)
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			logger.Error(err, "failed to get the static deltas job", "job", jobName)
+			return ctrl.Result{Requeue: true, RequeueAfter: RequeueForShortDuration}, nil
+		}
+
+		deltasJob, err = r.generateDeltasJob(ostreeRepository, jobName)
+		if err != nil {
+			logger.Error(err, "failed to generate the static deltas job", "job", jobName)
+			return ctrl.Result{Requeue: true, RequeueAfter: RequeueForShortDuration}, nil
+		}
+
+		err = r.JobRepository.Create(ctx, deltasJob)
+		if err != nil {
+			logger.Error(err, "failed to create the static deltas job", "job", jobName)
+			return ctrl.Result{Requeue: true, RequeueAfter: RequeueForShortDuration}, nil
+		}
+
+		logger.Info("Generated static deltas Job", "OSBuild", lastImport.OSBuildName)
+		patch := client.MergeFrom(ostreeRepository.DeepCopy())
+		err = r.updateOSTreeRepositoryStatus(ctx, ostreeRepository, patch, osbuildv1alpha1.ConditionInProgress, fmt.Sprintf(ostreeRepoDeltasInProgressMsg, lastImport.OSBuildName))
+		if err != nil {
+			logger.Error(err, "failed to update the OSTreeRepository status")
+		}
+		return ctrl.Result{Requeue: true, RequeueAfter: RequeueForLongDuration}, nil
+	}
+
+	var generated bool
+	var conditionType osbuildv1alpha1.ConditionType
+	var msg string
+	switch {
+	case isJobConditionTrue(deltasJob, batchv1.JobComplete):
+		err = r.recordStaticDeltas(ctx, logger, ostreeRepository.Namespace, lastImport.OSBuildName, jobName)
+		if err != nil {
+			logger.Error(err, "failed to record the static deltas", "OSBuild", lastImport.OSBuildName)
+			return ctrl.Result{Requeue: true, RequeueAfter: RequeueForShortDuration}, nil
+		}
+		generated, conditionType, msg = true, osbuildv1alpha1.ConditionReady, ostreeRepoUpToDateMsg
+	case isJobConditionTrue(deltasJob, batchv1.JobFailed):
+		generated, conditionType, msg = false, osbuildv1alpha1.ConditionFailed, fmt.Sprintf(ostreeRepoDeltasFailedMsg, lastImport.OSBuildName)
+	default:
+		logger.Info("the static deltas job is still running", "job", jobName)
+		return ctrl.Result{Requeue: true, RequeueAfter: RequeueForLongDuration}, nil
+	}
+
+	patch := client.MergeFrom(ostreeRepository.DeepCopy())
+	lastImport.StaticDeltasGenerated = &generated
+	err = r.updateOSTreeRepositoryStatus(ctx, ostreeRepository, patch, conditionType, msg)
+	if err != nil {
+		logger.Error(err, "failed to update the OSTreeRepository status")
+		return ctrl.Result{Requeue: true, RequeueAfter: RequeueForShortDuration}, nil
+	}
+
+	logger.Info("the static deltas job finished", "job", jobName, "succeeded", generated)
+	return ctrl.Result{}, nil
+}
+
+func (r *OSTreeRepositoryReconciler) generateDeltasJob(ostreeRepository *osbuildv1alpha1.OSTreeRepository, jobName string) (*batchv1.Job, error) {
+	fromCommits := ostreeRepoDefaultStaticDeltasFromCommits
+	if ostreeRepository.Spec.StaticDeltas.FromCommits != nil {
+		fromCommits = *ostreeRepository.Spec.StaticDeltas.FromCommits
+	}
+
+	deltasJobParams := ostreeRepoDeltasJobParameters{
+		Namespace:     ostreeRepository.Namespace,
+		Name:          jobName,
+		RepoName:      fmt.Sprintf(ostreeRepoNameFormat, ostreeRepository.Name),
+		ContainerName: ostreeRepoDeltasContainerName,
+		ImageName:     conf.GlobalConf.OSTreeRepoImageName,
+		ImageTag:      conf.GlobalConf.OSTreeRepoImageTag,
+		FromCommits:   strings.Join(getPreviouslyImportedCommits(ostreeRepository, fromCommits), " "),
+		Ref:           ostreeRepository.Spec.Ref,
+		StorageDir:    ostreeRepoStorageDir,
+		RepoDir:       ostreeRepoDir,
+		GPGKeyDir:     ostreeRepoGPGKeyDir,
+	}
+	if ostreeRepository.Spec.GPGKeySecretRef != nil {
+		deltasJobParams.GPGKeySecretName = ostreeRepository.Spec.GPGKeySecretRef.Name
+	}
+
+	buf, err := templates.LoadFromTemplateFile(ostreeRepoDeltasJobTemplateFile, deltasJobParams)
+	if err != nil {
+		return nil, err
+	}
+
+	decode := scheme.Codecs.UniversalDeserializer().Decode
+	obj, _, err := decode(buf.Bytes(), nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	deltasJob, ok := obj.(*batchv1.Job)
+	if !ok {
+		return nil, fmt.Errorf("failed to deserialize the job object")
+	}
+
+	return deltasJob, controllerutil.SetControllerReference(ostreeRepository, deltasJob, r.Scheme)
+}
+
+// recordStaticDeltas copies the delta sizes reported by the job in its termination message to the OSBuild status
+func (r *OSTreeRepositoryReconciler) recordStaticDeltas(ctx context.Context, logger logr.Logger, namespace string, osBuildName string, jobName string) error {
+	pods, err := r.PodRepository.ListByJob(ctx, jobName, namespace)
+	if err != nil {
+		return err
+	}
+
+	terminationMessage := getSucceededContainerTerminationMessage(pods, ostreeRepoDeltasContainerName)
+	if terminationMessage == nil {
+		logger.Info("the sizes of the static deltas are not available, the pod of the job is gone", "job", jobName)
+		return nil
+	}
+
+	var staticDeltas []osbuildv1alpha1.StaticDelta
+	err = json.Unmarshal([]byte(*terminationMessage), &staticDeltas)
+	if err != nil {
+		logger.Error(err, "failed to parse the sizes of the static deltas", "job", jobName)
+		return nil
+	}
+
+	osBuild, err := r.OSBuildRepository.Read(ctx, osBuildName, namespace)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+
+	patch := client.MergeFrom(osBuild.DeepCopy())
+	osBuild.Status.StaticDeltas = staticDeltas
+	return r.OSBuildRepository.PatchStatus(ctx, osBuild, &patch)
+}
+
+func getSucceededContainerTerminationMessage(pods []corev1.Pod, containerName string) *string {
+	for _, jobPod := range pods {
+		if jobPod.Status.Phase != corev1.PodSucceeded {
+			continue
+		}
+		for _, containerStatus := range jobPod.Status.ContainerStatuses {
+			if containerStatus.Name == containerName && containerStatus.State.Terminated != nil {
+				return &containerStatus.State.Terminated.Message
+			}
+		}
+	}
+	return nil
+}
+
+// getStaticDeltasPendingImport returns the last import when it succeeded and its static deltas were not generated yet
+func getStaticDeltasPendingImport(ostreeRepository *osbuildv1alpha1.OSTreeRepository) *osbuildv1alpha1.OSTreeRepositoryImport {
+	imports := ostreeRepository.Status.Imports
+	if ostreeRepository.Spec.StaticDeltas == nil || len(imports) == 0 {
+		return nil
+	}
+
+	lastImport := &imports[len(imports)-1]
+	if !lastImport.Succeeded || lastImport.StaticDeltasGenerated != nil {
+		return nil
+	}
+	return lastImport
+}
+
+// getImportsLimit returns the number of imports to keep in the status, enough to find the commits the static deltas are
+// generated from
+func getImportsLimit(ostreeRepository *osbuildv1alpha1.OSTreeRepository) int {
+	limit := ostreeRepoImportsLimit
+	if ostreeRepository.Spec.StaticDeltas != nil && ostreeRepository.Spec.StaticDeltas.FromCommits != nil &&
+		*ostreeRepository.Spec.StaticDeltas.FromCommits+1 > limit {
+		limit = *ostreeRepository.Spec.StaticDeltas.FromCommits + 1
+	}
+	return limit
+}
+
+// trimImports returns the newest imports, up to limit
+func trimImports(imports []osbuildv1alpha1.OSTreeRepositoryImport, limit int) []osbuildv1alpha1.OSTreeRepositoryImport {
+	if len(imports) <= limit {
+		return imports
+	}
+	return append([]osbuildv1alpha1.OSTreeRepositoryImport{}, imports[len(imports)-limit:]...)
+}
+
+// getPreviouslyImportedCommits returns up to count commits successfully imported before the last import, newest first
+func getPreviouslyImportedCommits(ostreeRepository *osbuildv1alpha1.OSTreeRepository, count int) []string {
+	imports := ostreeRepository.Status.Imports
+	lastCommit := imports[len(imports)-1].Commit
+
+	var commits []string
+	for i := len(imports) - 2; i >= 0 && len(commits) < count; i-- {
+		commit := imports[i].Commit
+		if !imports[i].Succeeded || commit == "" || commit == lastCommit || containsString(commits, commit) {
+			continue
+		}
+		commits = append(commits, commit)
+	}
+	return commits
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func getLastReadyEdgeContainerOSBuild(osBuilds []osbuildv1alpha1.OSBuild) *osbuildv1alpha1.OSBuild {
+	var lastOSBuild *osbuildv1alpha1.OSBuild
+	for i := range osBuilds {
+		osBuild := &osBuilds[i]
+		if osBuild.Spec.Details == nil || osBuild.Spec.Details.TargetImage.TargetImageType != osbuildv1alpha1.EdgeContainerImageType {
+			continue
+		}
+		if osBuild.Status.AccessUrl == emptyURL || !osBuild.IsReady() {
+			continue
+		}
+		if lastOSBuild == nil || lastOSBuild.CreationTimestamp.Before(&osBuild.CreationTimestamp) {
+			lastOSBuild = osBuild
+		}
+	}
+	return lastOSBuild
+}
+
+func isOSBuildImported(ostreeRepository *osbuildv1alpha1.OSTreeRepository, osBuildName string) bool {
+	for _, ostreeImport := range ostreeRepository.Status.Imports {
+		if ostreeImport.OSBuildName == osBuildName && ostreeImport.Succeeded {
+			return true
+		}
+	}
+	return false
+}
+
+func isOSBuildConditionTrue(osBuild *osbuildv1alpha1.OSBuild, conditionType osbuildv1alpha1.ConditionType) bool {
+	for _, condition := range osBuild.Status.Conditions {
+		if condition.Type == conditionType {
+			return condition.Status == metav1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// getJobName formats the name of a job. Names longer than maxJobNameLength are truncated and suffixed with a hash of the
+// full name, so they stay unique
+func getJobName(format string, a ...interface{}) string {
+	name := fmt.Sprintf(format, a...)
+	if len(name) <= maxJobNameLength {
+		return name
+	}
+
+	hash := fnv.New32a()
+	_, _ = hash.Write([]byte(name))
+	suffix := fmt.Sprintf("-%08x", hash.Sum32())
+	return strings.TrimRight(name[:maxJobNameLength-len(suffix)], "-.") + suffix
+}
+
+func isJobConditionTrue(job *batchv1.Job, conditionType batchv1.JobConditionType) bool {
+	for _, condition := range job.Status.Conditions {
+		if condition.Type == conditionType {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// osBuildToOSTreeRepositories maps an OSBuild to the OSTreeRepositories importing the builds of its OSBuildConfig
+func (r *OSTreeRepositoryReconciler) osBuildToOSTreeRepositories(obj client.Object) []reconcile.Request {
+	owner := metav1.GetControllerOf(obj)
+	if owner == nil || owner.Kind != "OSBuildConfig" {
+		return nil
+	}
+
+	ostreeRepositories, err := r.OSTreeRepositoryRepository.ListByOSBuildConfig(context.Background(), owner.Name, obj.GetNamespace())
+	if err != nil {
+		ctrl.Log.Error(err, "failed to list the OSTreeRepositories of the OSBuildConfig", "OSBuildConfig", owner.Name)
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, ostreeRepository := range ostreeRepositories {
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{Name: ostreeRepository.Name, Namespace: ostreeRepository.Namespace},
+		})
+	}
+	return requests
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *OSTreeRepositoryReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&osbuildv1alpha1.OSTreeRepository{}).
+		Owns(&batchv1.Job{}).
+		Owns(&routev1.Route{}).
+		Watches(&source.Kind{Type: &osbuildv1alpha1.OSBuild{}}, handler.EnqueueRequestsFromMapFunc(r.osBuildToOSTreeRepositories)).
+		Complete(r)
+}
@@ -0,0 +1,689 @@
+package controllers_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	routev1 "github.com/openshift/api/route/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/pointer"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	osbuildv1alpha1 "github.com/project-flotta/osbuild-operator/api/v1alpha1"
+	"github.com/project-flotta/osbuild-operator/controllers"
+	"github.com/project-flotta/osbuild-operator/internal/conf"
+	"github.com/project-flotta/osbuild-operator/internal/repository/deployment"
+	"github.com/project-flotta/osbuild-operator/internal/repository/job"
+	"github.com/project-flotta/osbuild-operator/internal/repository/osbuild"
+	"github.com/project-flotta/osbuild-operator/internal/repository/ostreerepository"
+	"github.com/project-flotta/osbuild-operator/internal/repository/persistentvolumeclaim"
+	"github.com/project-flotta/osbuild-operator/internal/repository/pod"
+	"github.com/project-flotta/osbuild-operator/internal/repository/route"
+	"github.com/project-flotta/osbuild-operator/internal/repository/service"
+)
+
+var _ = Describe("OSTreeRepository Controller", func() {
+	const (
+		operatorNamespace = "osbuild"
+		caIssuerName      = "osbuild-issuer"
+		templatesDir      = "../resources/templates"
+
+		instanceNamespace = "edge"
+		instanceName      = "repo"
+		resourcesName     = "repo-ostree-repo"
+		osBuildConfigName = "config"
+		osBuildName       = "config-2"
+		importJobName     = "repo-import-config-2"
+		routeHost         = "repo-ostree-repo-edge.apps.example.com"
+		ref               = "rhel/8/x86_64/edge"
+		edgeContainerUrl  = "registry.example.com/edge/config:2"
+		commit            = "02604b2da6e954bd34b8b82a835e5a77d2b60ffa"
+	)
+
+	var (
+		mockCtrl *gomock.Controller
+
+		ostreeRepositoryRepository      *ostreerepository.MockRepository
+		osBuildRepository               *osbuild.MockRepository
+		persistentVolumeClaimRepository *persistentvolumeclaim.MockRepository
+		deploymentRepository            *deployment.MockRepository
+		serviceRepository               *service.MockRepository
+		routeRepository                 *route.MockRepository
+		jobRepository                   *job.MockRepository
+		podRepository                   *pod.MockRepository
+
+		reconciler     *controllers.OSTreeRepositoryReconciler
+		requestContext context.Context
+
+		errNotFound error
+		errFailed   error
+
+		instance *osbuildv1alpha1.OSTreeRepository
+		osBuilds []osbuildv1alpha1.OSBuild
+
+		admittedRoute = routev1.Route{
+			Status: routev1.RouteStatus{
+				Ingress: []routev1.RouteIngress{{
+					Host: routeHost,
+					Conditions: []routev1.RouteIngressCondition{{
+						Type:   routev1.RouteAdmitted,
+						Status: corev1.ConditionTrue,
+					}},
+				}},
+			},
+		}
+
+		request = ctrl.Request{
+			NamespacedName: types.NamespacedName{
+				Name:      instanceName,
+				Namespace: instanceNamespace,
+			},
+		}
+
+		resultLongRequeue  = ctrl.Result{Requeue: true, RequeueAfter: controllers.RequeueForLongDuration}
+		resultQuickRequeue = ctrl.Result{RequeueAfter: time.Second}
+		resultDone         = ctrl.Result{}
+	)
+
+	newOSBuild := func(name string, created int64, ready bool) osbuildv1alpha1.OSBuild {
+		status := metav1.ConditionFalse
+		if ready {
+			status = metav1.ConditionTrue
+		}
+		return osbuildv1alpha1.OSBuild{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				Namespace:         instanceNamespace,
+				CreationTimestamp: metav1.NewTime(time.Unix(created, 0)),
+			},
+			Spec: osbuildv1alpha1.OSBuildSpec{
+				Details: &osbuildv1alpha1.BuildDetails{
+					TargetImage: osbuildv1alpha1.TargetImage{
+						TargetImageType: osbuildv1alpha1.EdgeContainerImageType,
+					},
+				},
+			},
+			Status: osbuildv1alpha1.OSBuildStatus{
+				Conditions:   []osbuildv1alpha1.Condition{{Type: osbuildv1alpha1.ConditionReady, Status: status}},
+				AccessUrl:    edgeContainerUrl,
+				OSTreeCommit: commit,
+			},
+		}
+	}
+
+	expectServerExists := func() {
+		persistentVolumeClaimRepository.EXPECT().Read(requestContext, resourcesName, instanceNamespace).Return(&corev1.PersistentVolumeClaim{}, nil)
+		deploymentRepository.EXPECT().Read(requestContext, resourcesName, instanceNamespace).Return(&appsv1.Deployment{}, nil)
+		serviceRepository.EXPECT().Read(requestContext, resourcesName, instanceNamespace).Return(&corev1.Service{}, nil)
+		routeRepository.EXPECT().Read(requestContext, resourcesName, instanceNamespace).Return(&admittedRoute, nil).Times(2)
+	}
+
+	BeforeEach(func() {
+		os.Setenv("WORKING_NAMESPACE", operatorNamespace)
+		os.Setenv("CA_ISSUER_NAME", caIssuerName)
+		os.Setenv("TEMPLATES_DIR", templatesDir)
+		err := conf.Load()
+		Expect(err).To(BeNil())
+
+		mockCtrl = gomock.NewController(GinkgoT())
+		ostreeRepositoryRepository = ostreerepository.NewMockRepository(mockCtrl)
+		osBuildRepository = osbuild.NewMockRepository(mockCtrl)
+		persistentVolumeClaimRepository = persistentvolumeclaim.NewMockRepository(mockCtrl)
+		deploymentRepository = deployment.NewMockRepository(mockCtrl)
+		serviceRepository = service.NewMockRepository(mockCtrl)
+		routeRepository = route.NewMockRepository(mockCtrl)
+		jobRepository = job.NewMockRepository(mockCtrl)
+		podRepository = pod.NewMockRepository(mockCtrl)
+
+		scheme := runtime.NewScheme()
+		err = clientgoscheme.AddToScheme(scheme)
+		Expect(err).To(BeNil())
+		err = osbuildv1alpha1.AddToScheme(scheme)
+		Expect(err).To(BeNil())
+
+		reconciler = &controllers.OSTreeRepositoryReconciler{
+			Scheme:                          scheme,
+			OSTreeRepositoryRepository:      ostreeRepositoryRepository,
+			OSBuildRepository:               osBuildRepository,
+			PersistentVolumeClaimRepository: persistentVolumeClaimRepository,
+			DeploymentRepository:            deploymentRepository,
+			ServiceRepository:               serviceRepository,
+			RouteRepository:                 routeRepository,
+			JobRepository:                   jobRepository,
+			PodRepository:                   podRepository,
+		}
+
+		requestContext = context.TODO()
+
+		errNotFound = errors.NewNotFound(schema.GroupResource{}, "Requested resource was not found")
+		errFailed = errors.NewInternalError(fmt.Errorf("Server encounter and error"))
+
+		instance = &osbuildv1alpha1.OSTreeRepository{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      instanceName,
+				Namespace: instanceNamespace,
+			},
+			Spec: osbuildv1alpha1.OSTreeRepositorySpec{
+				OSBuildConfigRef:   osBuildConfigName,
+				Ref:                ref,
+				ImagePullSecretRef: &osbuildv1alpha1.NameRef{Name: "pull-secret"},
+			},
+			Status: osbuildv1alpha1.OSTreeRepositoryStatus{
+				Url: "https://" + routeHost + "/",
+			},
+		}
+
+		osBuilds = []osbuildv1alpha1.OSBuild{
+			newOSBuild("config-1", 1, true),
+			newOSBuild(osBuildName, 2, true),
+			newOSBuild("config-3", 3, false),
+		}
+	})
+
+	AfterEach(func() {
+		os.Unsetenv("WORKING_NAMESPACE")
+		os.Unsetenv("CA_ISSUER_NAME")
+		os.Unsetenv("TEMPLATES_DIR")
+		mockCtrl.Finish()
+	})
+
+	It("should return Done when the instance is not found", func() {
+		// given
+		ostreeRepositoryRepository.EXPECT().Read(requestContext, instanceName, instanceNamespace).Return(nil, errNotFound)
+		// when
+		result, err := reconciler.Reconcile(requestContext, request)
+		// then
+		Expect(err).To(BeNil())
+		Expect(result).To(Equal(resultDone))
+	})
+
+	Context("Repository server", func() {
+		BeforeEach(func() {
+			ostreeRepositoryRepository.EXPECT().Read(requestContext, instanceName, instanceNamespace).Return(instance, nil)
+		})
+
+		It("should create the persistent volume claim with the requested storage", func() {
+			// given
+			size := resource.MustParse("20Gi")
+			instance.Spec.Storage = &osbuildv1alpha1.OSTreeRepositoryStorage{Size: &size}
+			persistentVolumeClaimRepository.EXPECT().Read(requestContext, resourcesName, instanceNamespace).Return(nil, errNotFound)
+			persistentVolumeClaimRepository.EXPECT().Create(requestContext, gomock.Any()).DoAndReturn(
+				func(ctx context.Context, persistentVolumeClaim *corev1.PersistentVolumeClaim) error {
+					Expect(persistentVolumeClaim.Name).To(Equal(resourcesName))
+					Expect(persistentVolumeClaim.Spec.Resources.Requests[corev1.ResourceStorage]).To(Equal(size))
+					Expect(persistentVolumeClaim.OwnerReferences).To(HaveLen(1))
+					return nil
+				})
+			// when
+			result, err := reconciler.Reconcile(requestContext, request)
+			// then
+			Expect(err).To(BeNil())
+			Expect(result).To(Equal(resultQuickRequeue))
+		})
+
+		It("should create the deployment serving the repository", func() {
+			// given
+			persistentVolumeClaimRepository.EXPECT().Read(requestContext, resourcesName, instanceNamespace).Return(&corev1.PersistentVolumeClaim{}, nil)
+			deploymentRepository.EXPECT().Read(requestContext, resourcesName, instanceNamespace).Return(nil, errNotFound)
+			deploymentRepository.EXPECT().Create(requestContext, gomock.Any()).DoAndReturn(
+				func(ctx context.Context, deployment *appsv1.Deployment) error {
+					Expect(deployment.Name).To(Equal(resourcesName))
+					Expect(deployment.Namespace).To(Equal(instanceNamespace))
+					Expect(deployment.Spec.Template.Spec.Volumes[0].PersistentVolumeClaim.ClaimName).To(Equal(resourcesName))
+					return nil
+				})
+			// when
+			result, err := reconciler.Reconcile(requestContext, request)
+			// then
+			Expect(err).To(BeNil())
+			Expect(result).To(Equal(resultQuickRequeue))
+		})
+
+		It("should create the route of the repository", func() {
+			// given
+			persistentVolumeClaimRepository.EXPECT().Read(requestContext, resourcesName, instanceNamespace).Return(&corev1.PersistentVolumeClaim{}, nil)
+			deploymentRepository.EXPECT().Read(requestContext, resourcesName, instanceNamespace).Return(&appsv1.Deployment{}, nil)
+			serviceRepository.EXPECT().Read(requestContext, resourcesName, instanceNamespace).Return(&corev1.Service{}, nil)
+			routeRepository.EXPECT().Read(requestContext, resourcesName, instanceNamespace).Return(nil, errNotFound)
+			routeRepository.EXPECT().Create(requestContext, gomock.Any()).DoAndReturn(
+				func(ctx context.Context, route *routev1.Route) error {
+					Expect(route.Spec.To.Name).To(Equal(resourcesName))
+					Expect(route.Spec.TLS.Termination).To(Equal(routev1.TLSTerminationEdge))
+					return nil
+				})
+			// when
+			result, err := reconciler.Reconcile(requestContext, request)
+			// then
+			Expect(err).To(BeNil())
+			Expect(result).To(Equal(resultQuickRequeue))
+		})
+
+		It("should publish the url of the repository once the route is admitted", func() {
+			// given
+			instance.Status.Url = ""
+			expectServerExists()
+			ostreeRepositoryRepository.EXPECT().PatchStatus(requestContext, instance, gomock.Any()).Return(nil)
+			osBuildRepository.EXPECT().ListByOSBuildConfig(requestContext, osBuildConfigName, instanceNamespace).Return(nil, nil)
+			// when
+			result, err := reconciler.Reconcile(requestContext, request)
+			// then
+			Expect(err).To(BeNil())
+			Expect(result).To(Equal(resultDone))
+			Expect(instance.Status.Url).To(Equal("https://" + routeHost + "/"))
+		})
+
+		It("should publish the url of the public GPG key when signing", func() {
+			// given
+			instance.Spec.GPGKeySecretRef = &osbuildv1alpha1.NameRef{Name: "gpg-key"}
+			expectServerExists()
+			ostreeRepositoryRepository.EXPECT().PatchStatus(requestContext, instance, gomock.Any()).Return(nil)
+			osBuildRepository.EXPECT().ListByOSBuildConfig(requestContext, osBuildConfigName, instanceNamespace).Return(nil, nil)
+			// when
+			result, err := reconciler.Reconcile(requestContext, request)
+			// then
+			Expect(err).To(BeNil())
+			Expect(result).To(Equal(resultDone))
+			Expect(instance.Status.GPGKeyUrl).To(Equal("https://" + routeHost + "/key.gpg"))
+		})
+	})
+
+	Context("Imports", func() {
+		BeforeEach(func() {
+			ostreeRepositoryRepository.EXPECT().Read(requestContext, instanceName, instanceNamespace).Return(instance, nil)
+			expectServerExists()
+			osBuildRepository.EXPECT().ListByOSBuildConfig(requestContext, osBuildConfigName, instanceNamespace).Return(osBuilds, nil)
+		})
+
+		It("should create a job importing the last Ready OSBuild", func() {
+			// given
+			jobRepository.EXPECT().Read(requestContext, importJobName, instanceNamespace).Return(nil, errNotFound)
+			jobRepository.EXPECT().Create(requestContext, gomock.Any()).DoAndReturn(
+				func(ctx context.Context, job *batchv1.Job) error {
+					Expect(job.Name).To(Equal(importJobName))
+					Expect(job.Spec.Template.Spec.InitContainers[0].Image).To(Equal(edgeContainerUrl))
+					Expect(job.Spec.Template.Spec.Containers[0].Env).To(ContainElement(corev1.EnvVar{Name: "COMMIT", Value: commit}))
+					Expect(job.Spec.Template.Spec.Containers[0].Command[2]).To(ContainSubstring("--create=" + ref))
+					Expect(job.Spec.Template.Spec.ImagePullSecrets).To(ConsistOf(corev1.LocalObjectReference{Name: "pull-secret"}))
+					return nil
+				})
+			ostreeRepositoryRepository.EXPECT().PatchStatus(requestContext, instance, gomock.Any()).Return(nil)
+			// when
+			result, err := reconciler.Reconcile(requestContext, request)
+			// then
+			Expect(err).To(BeNil())
+			Expect(result).To(Equal(resultLongRequeue))
+			Expect(instance.Status.Conditions).To(HaveLen(1))
+			Expect(instance.Status.Conditions[0].Type).To(Equal(osbuildv1alpha1.ConditionInProgress))
+		})
+
+		It("should shorten the name of the import job of long names", func() {
+			// given
+			osBuilds[1].Name = strings.Repeat("config", 10) + "-2"
+			var jobName string
+			jobRepository.EXPECT().Read(requestContext, gomock.Any(), instanceNamespace).DoAndReturn(
+				func(ctx context.Context, name string, namespace string) (*batchv1.Job, error) {
+					jobName = name
+					return nil, errNotFound
+				})
+			jobRepository.EXPECT().Create(requestContext, gomock.Any()).DoAndReturn(
+				func(ctx context.Context, job *batchv1.Job) error {
+					Expect(job.Name).To(Equal(jobName))
+					return nil
+				})
+			ostreeRepositoryRepository.EXPECT().PatchStatus(requestContext, instance, gomock.Any()).Return(nil)
+			// when
+			result, err := reconciler.Reconcile(requestContext, request)
+			// then
+			Expect(err).To(BeNil())
+			Expect(result).To(Equal(resultLongRequeue))
+			Expect(validation.IsDNS1123Label(jobName)).To(BeEmpty())
+			Expect(jobName).To(HavePrefix("repo-import-configconfig"))
+		})
+
+		It("should sign the imported commit when a GPG key is provided", func() {
+			// given
+			instance.Spec.GPGKeySecretRef = &osbuildv1alpha1.NameRef{Name: "gpg-key"}
+			instance.Status.GPGKeyUrl = "https://" + routeHost + "/key.gpg"
+			jobRepository.EXPECT().Read(requestContext, importJobName, instanceNamespace).Return(nil, errNotFound)
+			jobRepository.EXPECT().Create(requestContext, gomock.Any()).DoAndReturn(
+				func(ctx context.Context, job *batchv1.Job) error {
+					Expect(job.Spec.Template.Spec.Volumes).To(ContainElement(corev1.Volume{
+						Name:         "gpg-key",
+						VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: "gpg-key"}},
+					}))
+					Expect(job.Spec.Template.Spec.Containers[0].Command[2]).To(ContainSubstring("gpg-sign --gpg-homedir=$GNUPGHOME $COMMIT $KEY_ID"))
+					Expect(job.Spec.Template.Spec.Containers[0].Command[2]).To(ContainSubstring("summary -u $SIGN_OPTS"))
+					return nil
+				})
+			ostreeRepositoryRepository.EXPECT().PatchStatus(requestContext, instance, gomock.Any()).Return(nil)
+			// when
+			result, err := reconciler.Reconcile(requestContext, request)
+			// then
+			Expect(err).To(BeNil())
+			Expect(result).To(Equal(resultLongRequeue))
+		})
+
+		It("should not sign the imported commit without a GPG key", func() {
+			// given
+			jobRepository.EXPECT().Read(requestContext, importJobName, instanceNamespace).Return(nil, errNotFound)
+			jobRepository.EXPECT().Create(requestContext, gomock.Any()).DoAndReturn(
+				func(ctx context.Context, job *batchv1.Job) error {
+					Expect(job.Spec.Template.Spec.Volumes).To(HaveLen(2))
+					Expect(job.Spec.Template.Spec.Containers[0].Command[2]).NotTo(ContainSubstring("gpg"))
+					return nil
+				})
+			ostreeRepositoryRepository.EXPECT().PatchStatus(requestContext, instance, gomock.Any()).Return(nil)
+			// when
+			result, err := reconciler.Reconcile(requestContext, request)
+			// then
+			Expect(err).To(BeNil())
+			Expect(result).To(Equal(resultLongRequeue))
+		})
+
+		It("should requeue while the import job is running", func() {
+			// given
+			jobRepository.EXPECT().Read(requestContext, importJobName, instanceNamespace).Return(&batchv1.Job{}, nil)
+			// when
+			result, err := reconciler.Reconcile(requestContext, request)
+			// then
+			Expect(err).To(BeNil())
+			Expect(result).To(Equal(resultLongRequeue))
+		})
+
+		DescribeTable("should record the import when the job finishes", func(jobCondition batchv1.JobConditionType, succeeded bool, conditionType osbuildv1alpha1.ConditionType, expectedResult ctrl.Result) {
+			// given
+			instance.Status.Conditions = []osbuildv1alpha1.Condition{{Type: osbuildv1alpha1.ConditionInProgress, Status: metav1.ConditionTrue}}
+			importJob := batchv1.Job{
+				Status: batchv1.JobStatus{
+					Conditions: []batchv1.JobCondition{{Type: jobCondition, Status: corev1.ConditionTrue}},
+				},
+			}
+			jobRepository.EXPECT().Read(requestContext, importJobName, instanceNamespace).Return(&importJob, nil)
+			if !succeeded {
+				jobRepository.EXPECT().Delete(requestContext, &importJob).Return(nil)
+			}
+			ostreeRepositoryRepository.EXPECT().PatchStatus(requestContext, instance, gomock.Any()).Return(nil)
+			// when
+			result, err := reconciler.Reconcile(requestContext, request)
+			// then
+			Expect(err).To(BeNil())
+			Expect(result).To(Equal(expectedResult))
+			Expect(instance.Status.Imports).To(HaveLen(1))
+			Expect(instance.Status.Imports[0].OSBuildName).To(Equal(osBuildName))
+			Expect(instance.Status.Imports[0].Commit).To(Equal(commit))
+			Expect(instance.Status.Imports[0].Succeeded).To(Equal(succeeded))
+			for _, condition := range instance.Status.Conditions {
+				Expect(condition.Status == metav1.ConditionTrue).To(Equal(condition.Type == conditionType))
+			}
+		},
+			Entry("job completed", batchv1.JobComplete, true, osbuildv1alpha1.ConditionReady, resultDone),
+			Entry("job failed", batchv1.JobFailed, false, osbuildv1alpha1.ConditionFailed,
+				ctrl.Result{Requeue: true, RequeueAfter: controllers.RequeueForShortDuration}),
+		)
+
+		It("should retry a failed import in a new job once the backoff elapsed", func() {
+			// given
+			instance.Status.Imports = []osbuildv1alpha1.OSTreeRepositoryImport{
+				{OSBuildName: osBuildName, Succeeded: false, Attempts: 1, ImportTime: metav1.NewTime(time.Now().Add(-time.Minute))},
+			}
+			jobRepository.EXPECT().Read(requestContext, importJobName, instanceNamespace).Return(nil, errNotFound)
+			jobRepository.EXPECT().Create(requestContext, gomock.Any()).Return(nil)
+			ostreeRepositoryRepository.EXPECT().PatchStatus(requestContext, instance, gomock.Any()).Return(nil)
+			// when
+			result, err := reconciler.Reconcile(requestContext, request)
+			// then
+			Expect(err).To(BeNil())
+			Expect(result).To(Equal(resultLongRequeue))
+		})
+
+		DescribeTable("should wait for the backoff before retrying a failed import", func(attempts int32, expectedDelay time.Duration) {
+			// given
+			instance.Status.Imports = []osbuildv1alpha1.OSTreeRepositoryImport{
+				{OSBuildName: osBuildName, Succeeded: false, Attempts: attempts, ImportTime: metav1.Now()},
+			}
+			// when
+			result, err := reconciler.Reconcile(requestContext, request)
+			// then
+			Expect(err).To(BeNil())
+			Expect(result.RequeueAfter).To(BeNumerically("~", expectedDelay, time.Second))
+		},
+			Entry("after the first attempt", int32(1), controllers.RequeueForShortDuration),
+			Entry("doubling after each attempt", int32(3), 4*controllers.RequeueForShortDuration),
+			Entry("up to the maximum delay", int32(20), time.Hour),
+		)
+
+		It("should record the attempts of a failed retry once", func() {
+			// given
+			instance.Status.Imports = []osbuildv1alpha1.OSTreeRepositoryImport{
+				{OSBuildName: "previous", Succeeded: true, StaticDeltasGenerated: pointer.Bool(true)},
+				{OSBuildName: osBuildName, Succeeded: false, Attempts: 2, ImportTime: metav1.NewTime(time.Now().Add(-time.Hour))},
+			}
+			importJob := batchv1.Job{
+				Status: batchv1.JobStatus{
+					Conditions: []batchv1.JobCondition{{Type: batchv1.JobFailed, Status: corev1.ConditionTrue}},
+				},
+			}
+			jobRepository.EXPECT().Read(requestContext, importJobName, instanceNamespace).Return(&importJob, nil)
+			jobRepository.EXPECT().Delete(requestContext, &importJob).Return(nil)
+			ostreeRepositoryRepository.EXPECT().PatchStatus(requestContext, instance, gomock.Any()).Return(nil)
+			// when
+			result, err := reconciler.Reconcile(requestContext, request)
+			// then
+			Expect(err).To(BeNil())
+			Expect(result).To(Equal(ctrl.Result{Requeue: true, RequeueAfter: 4 * controllers.RequeueForShortDuration}))
+			Expect(instance.Status.Imports).To(HaveLen(2))
+			Expect(instance.Status.Imports[1].Attempts).To(Equal(int32(3)))
+		})
+
+		It("should retry when the failed job cannot be deleted", func() {
+			// given
+			importJob := batchv1.Job{
+				Status: batchv1.JobStatus{
+					Conditions: []batchv1.JobCondition{{Type: batchv1.JobFailed, Status: corev1.ConditionTrue}},
+				},
+			}
+			jobRepository.EXPECT().Read(requestContext, importJobName, instanceNamespace).Return(&importJob, nil)
+			jobRepository.EXPECT().Delete(requestContext, &importJob).Return(errFailed)
+			// when
+			result, err := reconciler.Reconcile(requestContext, request)
+			// then
+			Expect(err).To(BeNil())
+			Expect(result).To(Equal(ctrl.Result{Requeue: true, RequeueAfter: controllers.RequeueForShortDuration}))
+			Expect(instance.Status.Imports).To(BeEmpty())
+		})
+
+		DescribeTable("should keep the newest imports only", func(fromCommits *int, expectedLen int) {
+			// given
+			instance.Spec.StaticDeltas = nil
+			if fromCommits != nil {
+				instance.Spec.StaticDeltas = &osbuildv1alpha1.OSTreeStaticDeltas{FromCommits: fromCommits}
+			}
+			instance.Status.Imports = nil
+			for i := 0; i < 20; i++ {
+				instance.Status.Imports = append(instance.Status.Imports, osbuildv1alpha1.OSTreeRepositoryImport{
+					OSBuildName: fmt.Sprintf("old-%d", i), Succeeded: true, StaticDeltasGenerated: pointer.Bool(true)})
+			}
+			importJob := batchv1.Job{
+				Status: batchv1.JobStatus{
+					Conditions: []batchv1.JobCondition{{Type: batchv1.JobComplete, Status: corev1.ConditionTrue}},
+				},
+			}
+			jobRepository.EXPECT().Read(requestContext, importJobName, instanceNamespace).Return(&importJob, nil)
+			ostreeRepositoryRepository.EXPECT().PatchStatus(requestContext, instance, gomock.Any()).Return(nil)
+			// when
+			result, err := reconciler.Reconcile(requestContext, request)
+			// then
+			Expect(err).To(BeNil())
+			Expect(result).To(Equal(resultDone))
+			Expect(instance.Status.Imports).To(HaveLen(expectedLen))
+			Expect(instance.Status.Imports[0].OSBuildName).To(Equal(fmt.Sprintf("old-%d", 21-expectedLen)))
+			Expect(instance.Status.Imports[expectedLen-1].OSBuildName).To(Equal(osBuildName))
+		},
+			Entry("without static deltas", nil, 10),
+			Entry("with static deltas from a few commits", pointer.Int(3), 10),
+			Entry("with static deltas from more commits than the limit", pointer.Int(14), 15),
+		)
+
+		It("should not import an OSBuild twice", func() {
+			// given
+			instance.Status.Imports = []osbuildv1alpha1.OSTreeRepositoryImport{{OSBuildName: osBuildName, Succeeded: true}}
+			// when
+			result, err := reconciler.Reconcile(requestContext, request)
+			// then
+			Expect(err).To(BeNil())
+			Expect(result).To(Equal(resultDone))
+		})
+
+		It("should retry when the job cannot be read", func() {
+			// given
+			jobRepository.EXPECT().Read(requestContext, importJobName, instanceNamespace).Return(nil, errFailed)
+			// when
+			result, err := reconciler.Reconcile(requestContext, request)
+			// then
+			Expect(err).To(BeNil())
+			Expect(result).To(Equal(ctrl.Result{Requeue: true, RequeueAfter: controllers.RequeueForShortDuration}))
+		})
+	})
+
+	Context("Static deltas", func() {
+		const deltasJobName = "repo-deltas-config-2"
+
+		BeforeEach(func() {
+			fromCommits := 2
+			instance.Spec.StaticDeltas = &osbuildv1alpha1.OSTreeStaticDeltas{FromCommits: &fromCommits}
+			instance.Status.Imports = []osbuildv1alpha1.OSTreeRepositoryImport{
+				{OSBuildName: "config-a", Commit: "a", Succeeded: true},
+				{OSBuildName: "config-b", Commit: "b", Succeeded: true},
+				{OSBuildName: "config-c", Commit: "c", Succeeded: false},
+				{OSBuildName: "config-d", Commit: "d", Succeeded: true},
+				{OSBuildName: osBuildName, Commit: commit, Succeeded: true},
+			}
+			ostreeRepositoryRepository.EXPECT().Read(requestContext, instanceName, instanceNamespace).Return(instance, nil)
+			expectServerExists()
+		})
+
+		It("should create a job generating the deltas from the previous successful imports", func() {
+			// given
+			jobRepository.EXPECT().Read(requestContext, deltasJobName, instanceNamespace).Return(nil, errNotFound)
+			jobRepository.EXPECT().Create(requestContext, gomock.Any()).DoAndReturn(
+				func(ctx context.Context, job *batchv1.Job) error {
+					Expect(job.Name).To(Equal(deltasJobName))
+					Expect(job.Spec.Template.Spec.Containers[0].Env).To(ContainElement(corev1.EnvVar{Name: "FROM_COMMITS", Value: "d b"}))
+					Expect(job.Spec.Template.Spec.Containers[0].Command[2]).To(ContainSubstring("rev-parse " + ref))
+					return nil
+				})
+			ostreeRepositoryRepository.EXPECT().PatchStatus(requestContext, instance, gomock.Any()).Return(nil)
+			// when
+			result, err := reconciler.Reconcile(requestContext, request)
+			// then
+			Expect(err).To(BeNil())
+			Expect(result).To(Equal(resultLongRequeue))
+			Expect(instance.Status.Conditions[0].Type).To(Equal(osbuildv1alpha1.ConditionInProgress))
+		})
+
+		It("should shorten the name of the deltas job of long names", func() {
+			// given
+			instance.Status.Imports[4].OSBuildName = strings.Repeat("config", 10) + "-2"
+			var jobName string
+			jobRepository.EXPECT().Read(requestContext, gomock.Any(), instanceNamespace).DoAndReturn(
+				func(ctx context.Context, name string, namespace string) (*batchv1.Job, error) {
+					jobName = name
+					return nil, errNotFound
+				})
+			jobRepository.EXPECT().Create(requestContext, gomock.Any()).DoAndReturn(
+				func(ctx context.Context, job *batchv1.Job) error {
+					Expect(job.Name).To(Equal(jobName))
+					return nil
+				})
+			ostreeRepositoryRepository.EXPECT().PatchStatus(requestContext, instance, gomock.Any()).Return(nil)
+			// when
+			result, err := reconciler.Reconcile(requestContext, request)
+			// then
+			Expect(err).To(BeNil())
+			Expect(result).To(Equal(resultLongRequeue))
+			Expect(validation.IsDNS1123Label(jobName)).To(BeEmpty())
+			Expect(jobName).To(HavePrefix("repo-deltas-configconfig"))
+		})
+
+		It("should record the sizes of the deltas in the OSBuild status", func() {
+			// given
+			deltasJob := batchv1.Job{
+				Status: batchv1.JobStatus{
+					Conditions: []batchv1.JobCondition{{Type: batchv1.JobComplete, Status: corev1.ConditionTrue}},
+				},
+			}
+			jobRepository.EXPECT().Read(requestContext, deltasJobName, instanceNamespace).Return(&deltasJob, nil)
+			pods := []corev1.Pod{
+				{Status: corev1.PodStatus{Phase: corev1.PodFailed}},
+				{Status: corev1.PodStatus{
+					Phase: corev1.PodSucceeded,
+					ContainerStatuses: []corev1.ContainerStatus{{
+						Name: "deltas",
+						State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{
+							Message: `[{"from":"d","size":1024},{"from":"b","size":4096}]`,
+						}},
+					}},
+				}},
+			}
+			podRepository.EXPECT().ListByJob(requestContext, deltasJobName, instanceNamespace).Return(pods, nil)
+			osBuild := newOSBuild(osBuildName, 2, true)
+			osBuildRepository.EXPECT().Read(requestContext, osBuildName, instanceNamespace).Return(&osBuild, nil)
+			osBuildRepository.EXPECT().PatchStatus(requestContext, &osBuild, gomock.Any()).Return(nil)
+			ostreeRepositoryRepository.EXPECT().PatchStatus(requestContext, instance, gomock.Any()).Return(nil)
+			// when
+			result, err := reconciler.Reconcile(requestContext, request)
+			// then
+			Expect(err).To(BeNil())
+			Expect(result).To(Equal(resultDone))
+			Expect(osBuild.Status.StaticDeltas).To(Equal([]osbuildv1alpha1.StaticDelta{{From: "d", Size: 1024}, {From: "b", Size: 4096}}))
+			Expect(*instance.Status.Imports[4].StaticDeltasGenerated).To(BeTrue())
+			Expect(instance.Status.Conditions[0].Type).To(Equal(osbuildv1alpha1.ConditionReady))
+		})
+
+		It("should not block the next imports when the deltas job fails", func() {
+			// given
+			deltasJob := batchv1.Job{
+				Status: batchv1.JobStatus{
+					Conditions: []batchv1.JobCondition{{Type: batchv1.JobFailed, Status: corev1.ConditionTrue}},
+				},
+			}
+			jobRepository.EXPECT().Read(requestContext, deltasJobName, instanceNamespace).Return(&deltasJob, nil)
+			ostreeRepositoryRepository.EXPECT().PatchStatus(requestContext, instance, gomock.Any()).Return(nil)
+			// when
+			result, err := reconciler.Reconcile(requestContext, request)
+			// then
+			Expect(err).To(BeNil())
+			Expect(result).To(Equal(resultDone))
+			Expect(*instance.Status.Imports[4].StaticDeltasGenerated).To(BeFalse())
+			Expect(instance.Status.Conditions[0].Type).To(Equal(osbuildv1alpha1.ConditionFailed))
+		})
+
+		It("should import the next OSBuild once the deltas are generated", func() {
+			// given
+			generated := true
+			instance.Status.Imports[4].StaticDeltasGenerated = &generated
+			osBuildRepository.EXPECT().ListByOSBuildConfig(requestContext, osBuildConfigName, instanceNamespace).Return(osBuilds, nil)
+			// when
+			result, err := reconciler.Reconcile(requestContext, request)
+			// then
+			Expect(err).To(BeNil())
+			Expect(result).To(Equal(resultDone))
+		})
+	})
+})
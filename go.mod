@@ -24,6 +24,7 @@ require (
 	github.com/openshift/api v3.9.0+incompatible
 	go.uber.org/zap v1.23.0
 	golang.org/x/crypto v0.0.0-20220924013350-4ba4fb4dd9e7
+	golang.org/x/time v0.0.0-20220609170525-579cf78fd858
 	k8s.io/api v0.25.3
 	k8s.io/utils v0.0.0-20220922133306-665eaaec4324
 )
@@ -90,7 +91,6 @@ require (
 	golang.org/x/sys v0.0.0-20220728004956-3c1f35247d10 // indirect
 	golang.org/x/term v0.0.0-20210927222741-03fcf44c2211 // indirect
 	golang.org/x/text v0.3.7 // indirect
-	golang.org/x/time v0.0.0-20220609170525-579cf78fd858 // indirect
 	gomodules.xyz/jsonpatch/v2 v2.2.0 // indirect
 	google.golang.org/appengine v1.6.7 // indirect
 	google.golang.org/protobuf v1.28.1 // indirect
@@ -223,9 +223,24 @@ type ContainerUploadStatus struct {
 
 // Customizations defines model for Customizations.
 type Customizations struct {
-	Containers *[]Container  `json:"containers,omitempty"`
-	Filesystem *[]Filesystem `json:"filesystem,omitempty"`
-	Packages   *[]string     `json:"packages,omitempty"`
+	Containers  *[]Container  `json:"containers,omitempty"`
+	Directories *[]Directory  `json:"directories,omitempty"`
+	Files       *[]File       `json:"files,omitempty"`
+	Filesystem  *[]Filesystem `json:"filesystem,omitempty"`
+
+	// Firewalld configuration
+	Firewall *FirewallCustomization `json:"firewall,omitempty"`
+
+	// List of groups to create
+	Groups *[]Group `json:"groups,omitempty"`
+
+	// Configures the hostname
+	Hostname *string `json:"hostname,omitempty"`
+	Kernel   *Kernel `json:"kernel,omitempty"`
+
+	// Locale configuration
+	Locale   *Locale   `json:"locale,omitempty"`
+	Packages *[]string `json:"packages,omitempty"`
 
 	// Extra repositories for packages specified in customizations. These
 	// repositories will only be used to depsolve and retrieve packages
@@ -241,7 +256,28 @@ type Customizations struct {
 		Enabled *[]string `json:"enabled,omitempty"`
 	} `json:"services,omitempty"`
 	Subscription *Subscription `json:"subscription,omitempty"`
-	Users        *[]User       `json:"users,omitempty"`
+
+	// Timezone configuration
+	Timezone *Timezone `json:"timezone,omitempty"`
+	Users    *[]User   `json:"users,omitempty"`
+}
+
+// A custom directory to create in the final artifact.
+type Directory struct {
+	// Ensure that the parent directories exist
+	EnsureParents *bool `json:"ensure_parents,omitempty"`
+
+	// Group of the directory as a group name
+	Group *string `json:"group,omitempty"`
+
+	// Permissions string for the directory in octal format
+	Mode *string `json:"mode,omitempty"`
+
+	// Path to the directory
+	Path string `json:"path"`
+
+	// Owner of the directory as a user name
+	User *string `json:"user,omitempty"`
 }
 
 // Error defines model for Error.
@@ -264,12 +300,48 @@ type ErrorList struct {
 	Total int     `json:"total"`
 }
 
+// A custom file to create in the final artifact.
+type File struct {
+	// Contents of the file as plain text
+	Data *string `json:"data,omitempty"`
+
+	// Ensure that the parent directories exist
+	EnsureParents *bool `json:"ensure_parents,omitempty"`
+
+	// Group of the file as a group name
+	Group *string `json:"group,omitempty"`
+
+	// Permissions string for the file in octal format
+	Mode *string `json:"mode,omitempty"`
+
+	// Path to the file
+	Path string `json:"path"`
+
+	// Owner of the file as a user name
+	User *string `json:"user,omitempty"`
+}
+
 // Filesystem defines model for Filesystem.
 type Filesystem struct {
 	MinSize    uint64 `json:"min_size"`
 	Mountpoint string `json:"mountpoint"`
 }
 
+// Firewalld configuration
+type FirewallCustomization struct {
+	// List of ports (or port ranges) and protocols to open
+	Ports *[]string `json:"ports,omitempty"`
+
+	// Firewalld services to enable or disable
+	Services *struct {
+		// List of services to disable
+		Disabled *[]string `json:"disabled,omitempty"`
+
+		// List of services to enable
+		Enabled *[]string `json:"enabled,omitempty"`
+	} `json:"services,omitempty"`
+}
+
 // GCPUploadOptions defines model for GCPUploadOptions.
 type GCPUploadOptions struct {
 	// Name of an existing STANDARD Storage class Bucket.
@@ -308,6 +380,15 @@ type GCPUploadStatus struct {
 	ProjectId string `json:"project_id"`
 }
 
+// Group defines model for Group.
+type Group struct {
+	// Group ID of the group to create (optional, auto-generated if not provided)
+	Gid *int64 `json:"gid,omitempty"`
+
+	// Name of the group to create
+	Name string `json:"name"`
+}
+
 // ImageRequest defines model for ImageRequest.
 type ImageRequest struct {
 	Architecture string       `json:"architecture"`
@@ -336,6 +417,15 @@ type ImageStatusValue string
 // ImageTypes defines model for ImageTypes.
 type ImageTypes string
 
+// Kernel defines model for Kernel.
+type Kernel struct {
+	// Appends arguments to the bootloader kernel command line
+	Append *string `json:"append,omitempty"`
+
+	// Name of the kernel to use
+	Name *string `json:"name,omitempty"`
+}
+
 // Koji defines model for Koji.
 type Koji struct {
 	Name    string `json:"name"`
@@ -364,6 +454,15 @@ type List struct {
 	Total int    `json:"total"`
 }
 
+// Locale configuration
+type Locale struct {
+	// Sets the keyboard layout
+	Keyboard *string `json:"keyboard,omitempty"`
+
+	// List of locales to be installed, the first one becomes primary, subsequent ones are secondary
+	Languages *[]string `json:"languages,omitempty"`
+}
+
 // OSTree defines model for OSTree.
 type OSTree struct {
 	// Can be either a commit (example: 02604b2da6e954bd34b8b82a835e5a77d2b60ffa), or a branch-like reference (example: rhel/8/x86_64/edge)
@@ -419,6 +518,15 @@ type Subscription struct {
 	ServerUrl     string `json:"server_url"`
 }
 
+// Timezone configuration
+type Timezone struct {
+	// List of ntp servers
+	Ntpservers *[]string `json:"ntpservers,omitempty"`
+
+	// Name of the timezone, defaults to UTC
+	Timezone *string `json:"timezone,omitempty"`
+}
+
 // This should really be oneOf but AWSS3UploadOptions is a subset of
 // AWSEC2UploadOptions. This means that all AWSEC2UploadOptions objects
 // are also valid AWSS3UploadOptionas objects which violates the oneOf
@@ -441,9 +549,30 @@ type UploadTypes string
 
 // User defines model for User.
 type User struct {
+	// GECOS field of the user
+	Description *string `json:"description,omitempty"`
+
+	// ID of the primary group of the user
+	Gid    *int64    `json:"gid,omitempty"`
 	Groups *[]string `json:"groups,omitempty"`
-	Key    *string   `json:"key,omitempty"`
-	Name   string    `json:"name"`
+
+	// Home directory of the user
+	Home *string `json:"home,omitempty"`
+
+	// ssh public keys, one per line
+	Key  *string `json:"key,omitempty"`
+	Name string  `json:"name"`
+
+	// If the password starts with $6$, $5$, or $2b$ it will be stored as
+	// an encrypted password. Otherwise it will be treated as a plain text
+	// password.
+	Password *string `json:"password,omitempty"`
+
+	// Login shell of the user
+	Shell *string `json:"shell,omitempty"`
+
+	// User ID
+	Uid *int64 `json:"uid,omitempty"`
 }
 
 // Page defines model for page.
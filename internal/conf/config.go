@@ -1,6 +1,8 @@
 package conf
 
 import (
+	"time"
+
 	"github.com/kelseyhightower/envconfig"
 )
 
@@ -62,6 +64,31 @@ type OperatorConfig struct {
 	// RepositoriesDir is the path to the directory where the repositories information files are stored
 	RepositoriesDir string `envconfig:"REPOSITORIES_DIR" default:"/etc/osbuild/repositories"`
 
+	// OSTreeRepoImageName is the name of the image serving the OSTree repositories and importing commits into them
+	OSTreeRepoImageName string `envconfig:"OSTREE_REPO_IMAGE_NAME" default:"quay.io/project-flotta/osbuild-operator-ostree-repo"`
+
+	// OSTreeRepoImageTag is the tag of the image serving the OSTree repositories and importing commits into them
+	OSTreeRepoImageTag string `envconfig:"OSTREE_REPO_IMAGE_TAG" default:"v0.1"`
+
+	// SkopeoImageName is the name of the image to use for retagging the released edge-container images
+	SkopeoImageName string `envconfig:"SKOPEO_IMAGE_NAME" default:"quay.io/skopeo/stable"`
+
+	// SkopeoImageTag is the tag of the image to use for retagging the released edge-container images
+	SkopeoImageTag string `envconfig:"SKOPEO_IMAGE_TAG" default:"v1"`
+
+	// EnablePackageValidation enables resolving the packages of the OSBuilds against the metadata of their repositories before composing
+	EnablePackageValidation bool `envconfig:"ENABLE_PACKAGE_VALIDATION" default:"true"`
+
+	// PackageValidationTimeout is the timeout of each request for the metadata of the repositories
+	PackageValidationTimeout time.Duration `envconfig:"PACKAGE_VALIDATION_TIMEOUT" default:"2m"`
+
+	// PackageValidationRetryPeriod is the period after the creation of an OSBuild during which the validation of its
+	// packages is retried when the metadata of the repositories cannot be loaded. The validation is skipped afterwards
+	PackageValidationRetryPeriod time.Duration `envconfig:"PACKAGE_VALIDATION_RETRY_PERIOD" default:"10m"`
+
+	// VulnerabilityFeedsDir is the path to the directory where the vulnerability feeds referenced by path are stored
+	VulnerabilityFeedsDir string `envconfig:"VULNERABILITY_FEEDS_DIR" default:"/vulnerability-feeds"`
+
 	// BaseISOContainerImage is the container image to run the iso-package job
 	BaseISOContainerImage string `envconfig:"BASE_ISO_CONTAINER_IMAGE" required:"true" default:"controller:latest"`
 }
@@ -0,0 +1,201 @@
+package cron
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	// Embed the time zone database, so that the schedules can be evaluated in any time zone whatever the image
+	_ "time/tzdata"
+)
+
+// maxSearchYears bounds the search of the next run, so that schedules that never match, e.g. on February 30th, end
+const maxSearchYears = 5
+
+var (
+	macros = map[string]string{
+		"@yearly":   "0 0 1 1 *",
+		"@annually": "0 0 1 1 *",
+		"@monthly":  "0 0 1 * *",
+		"@weekly":   "0 0 * * 0",
+		"@daily":    "0 0 * * *",
+		"@midnight": "0 0 * * *",
+		"@hourly":   "0 * * * *",
+	}
+
+	monthNames = map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}
+
+	dayOfWeekNames = map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}
+)
+
+type field struct {
+	name  string
+	min   int
+	max   int
+	names map[string]int
+}
+
+var (
+	minuteField     = field{name: "minute", min: 0, max: 59}
+	hourField       = field{name: "hour", min: 0, max: 23}
+	dayOfMonthField = field{name: "day of month", min: 1, max: 31}
+	monthField      = field{name: "month", min: 1, max: 12, names: monthNames}
+	// 7 is accepted for Sunday like most cron implementations do
+	dayOfWeekField = field{name: "day of week", min: 0, max: 7, names: dayOfWeekNames}
+)
+
+// Schedule is a parsed cron expression evaluated in a time zone
+type Schedule struct {
+	minutes     map[int]bool
+	hours       map[int]bool
+	daysOfMonth map[int]bool
+	months      map[int]bool
+	daysOfWeek  map[int]bool
+	// when both the days of the month and of the week are restricted, a day matching either of them matches
+	dayOfMonthRestricted bool
+	dayOfWeekRestricted  bool
+	location             *time.Location
+}
+
+// Parse parses a standard cron expression of 5 fields (minute, hour, day of month, month and day of week) or one of
+// the @yearly, @annually, @monthly, @weekly, @daily, @midnight and @hourly macros
+func Parse(expression string, location *time.Location) (*Schedule, error) {
+	expression = strings.TrimSpace(expression)
+	if macro, ok := macros[strings.ToLower(expression)]; ok {
+		expression = macro
+	}
+
+	fields := strings.Fields(expression)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("cron expression %q must have 5 fields, found %d", expression, len(fields))
+	}
+
+	schedule := &Schedule{location: location}
+	var err error
+	if schedule.minutes, err = parseField(fields[0], minuteField); err != nil {
+		return nil, err
+	}
+	if schedule.hours, err = parseField(fields[1], hourField); err != nil {
+		return nil, err
+	}
+	if schedule.daysOfMonth, err = parseField(fields[2], dayOfMonthField); err != nil {
+		return nil, err
+	}
+	if schedule.months, err = parseField(fields[3], monthField); err != nil {
+		return nil, err
+	}
+	if schedule.daysOfWeek, err = parseField(fields[4], dayOfWeekField); err != nil {
+		return nil, err
+	}
+	if schedule.daysOfWeek[7] {
+		schedule.daysOfWeek[0] = true
+	}
+	schedule.dayOfMonthRestricted = !isWildcard(fields[2])
+	schedule.dayOfWeekRestricted = !isWildcard(fields[4])
+	return schedule, nil
+}
+
+// Next returns the first time strictly after t matching the schedule, or the zero time when none is found within the
+// next years
+func (s *Schedule) Next(t time.Time) time.Time {
+	t = t.In(s.location)
+	limit := t.Year() + maxSearchYears
+	t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), 0, 0, s.location).Add(time.Minute)
+
+	for t.Year() <= limit {
+		if !s.months[int(t.Month())] {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, s.location)
+			continue
+		}
+		if !s.matchesDay(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, s.location)
+			continue
+		}
+		if !s.hours[t.Hour()] {
+			next := time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, s.location)
+			if !next.After(t) {
+				// the hour is repeated when the clocks are set back, skip it
+				next = t.Truncate(time.Hour).Add(time.Hour)
+			}
+			t = next
+			continue
+		}
+		if !s.minutes[t.Minute()] {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+func (s *Schedule) matchesDay(t time.Time) bool {
+	dayOfMonth := s.daysOfMonth[t.Day()]
+	dayOfWeek := s.daysOfWeek[int(t.Weekday())]
+	if s.dayOfMonthRestricted && s.dayOfWeekRestricted {
+		return dayOfMonth || dayOfWeek
+	}
+	return dayOfMonth && dayOfWeek
+}
+
+func isWildcard(value string) bool {
+	return value == "*" || value == "?"
+}
+
+// parseField parses a comma separated list of values, ranges and steps, e.g. 1,15-20,*/10
+func parseField(value string, f field) (map[int]bool, error) {
+	values := map[int]bool{}
+	for _, item := range strings.Split(value, ",") {
+		rangeValue, step := item, 1
+		if i := strings.Index(item, "/"); i >= 0 {
+			var err error
+			step, err = strconv.Atoi(item[i+1:])
+			if err != nil || step < 1 {
+				return nil, fmt.Errorf("invalid step %q of the %s field", item[i+1:], f.name)
+			}
+			rangeValue = item[:i]
+		}
+
+		start, end := f.min, f.max
+		if !isWildcard(rangeValue) {
+			bounds := strings.SplitN(rangeValue, "-", 2)
+			var err error
+			if start, err = parseValue(bounds[0], f); err != nil {
+				return nil, err
+			}
+			end = start
+			if len(bounds) == 2 {
+				if end, err = parseValue(bounds[1], f); err != nil {
+					return nil, err
+				}
+			} else if step > 1 {
+				// a step after a single value, e.g. 5/15, runs from the value to the maximum
+				end = f.max
+			}
+			if end < start {
+				return nil, fmt.Errorf("invalid range %q of the %s field", rangeValue, f.name)
+			}
+		}
+
+		for v := start; v <= end; v += step {
+			values[v] = true
+		}
+	}
+	return values, nil
+}
+
+func parseValue(value string, f field) (int, error) {
+	if v, ok := f.names[strings.ToLower(value)]; ok {
+		return v, nil
+	}
+	v, err := strconv.Atoi(value)
+	if err != nil || v < f.min || v > f.max {
+		return 0, fmt.Errorf("invalid value %q of the %s field, expected a value between %d and %d", value, f.name, f.min, f.max)
+	}
+	return v, nil
+}
@@ -0,0 +1,13 @@
+package cron_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestCron(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Cron Spec")
+}
@@ -0,0 +1,85 @@
+package cron_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/project-flotta/osbuild-operator/internal/cron"
+)
+
+var _ = Describe("Cron", func() {
+	newYork, _ := time.LoadLocation("America/New_York")
+
+	DescribeTable("should return the next run of the schedule", func(expression string, location *time.Location, from time.Time, expected time.Time) {
+		// given
+		schedule, err := cron.Parse(expression, location)
+		Expect(err).ToNot(HaveOccurred())
+
+		// when
+		next := schedule.Next(from)
+
+		// then
+		Expect(next.Equal(expected)).To(BeTrue(), "expected %s, got %s", expected, next)
+	},
+		Entry("every minute", "* * * * *", time.UTC,
+			time.Date(2022, 6, 1, 10, 20, 30, 0, time.UTC), time.Date(2022, 6, 1, 10, 21, 0, 0, time.UTC)),
+		Entry("strictly after the given time", "30 2 * * *", time.UTC,
+			time.Date(2022, 6, 1, 2, 30, 0, 0, time.UTC), time.Date(2022, 6, 2, 2, 30, 0, 0, time.UTC)),
+		Entry("nightly", "0 2 * * *", time.UTC,
+			time.Date(2022, 6, 1, 10, 0, 0, 0, time.UTC), time.Date(2022, 6, 2, 2, 0, 0, 0, time.UTC)),
+		Entry("steps", "*/15 * * * *", time.UTC,
+			time.Date(2022, 6, 1, 10, 16, 0, 0, time.UTC), time.Date(2022, 6, 1, 10, 30, 0, 0, time.UTC)),
+		Entry("lists and ranges", "0 8-10,18 * * *", time.UTC,
+			time.Date(2022, 6, 1, 10, 0, 0, 0, time.UTC), time.Date(2022, 6, 1, 18, 0, 0, 0, time.UTC)),
+		Entry("names of the days of the week", "0 0 * * sat,sun", time.UTC,
+			time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC), time.Date(2022, 6, 4, 0, 0, 0, 0, time.UTC)),
+		Entry("7 as Sunday", "0 0 * * 7", time.UTC,
+			time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC), time.Date(2022, 6, 5, 0, 0, 0, 0, time.UTC)),
+		Entry("names of the months", "0 0 1 jan,jul *", time.UTC,
+			time.Date(2022, 2, 1, 0, 0, 0, 0, time.UTC), time.Date(2022, 7, 1, 0, 0, 0, 0, time.UTC)),
+		Entry("either the day of the month or of the week", "0 0 13 * fri", time.UTC,
+			time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC), time.Date(2022, 6, 3, 0, 0, 0, 0, time.UTC)),
+		Entry("the last days of the month", "0 0 31 * *", time.UTC,
+			time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC), time.Date(2022, 7, 31, 0, 0, 0, 0, time.UTC)),
+		Entry("leap days", "0 0 29 2 *", time.UTC,
+			time.Date(2022, 3, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)),
+		Entry("macro", "@daily", time.UTC,
+			time.Date(2022, 6, 1, 10, 0, 0, 0, time.UTC), time.Date(2022, 6, 2, 0, 0, 0, 0, time.UTC)),
+		Entry("time zone", "0 2 * * *", newYork,
+			time.Date(2022, 6, 1, 10, 0, 0, 0, time.UTC), time.Date(2022, 6, 2, 6, 0, 0, 0, time.UTC)),
+		Entry("hour skipped when the clocks are set forward", "30 2 * * *", newYork,
+			time.Date(2022, 3, 13, 0, 0, 0, 0, newYork), time.Date(2022, 3, 14, 2, 30, 0, 0, newYork)),
+		Entry("hour repeated when the clocks are set back", "30 * * * *", newYork,
+			time.Date(2022, 11, 6, 5, 40, 0, 0, time.UTC), time.Date(2022, 11, 6, 6, 30, 0, 0, time.UTC)),
+	)
+
+	It("should return the zero time when the schedule never matches", func() {
+		// given
+		schedule, err := cron.Parse("0 0 30 2 *", time.UTC)
+		Expect(err).ToNot(HaveOccurred())
+
+		// when
+		next := schedule.Next(time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC))
+
+		// then
+		Expect(next.IsZero()).To(BeTrue())
+	})
+
+	DescribeTable("should fail to parse invalid expressions", func(expression string) {
+		// when
+		_, err := cron.Parse(expression, time.UTC)
+
+		// then
+		Expect(err).To(HaveOccurred())
+	},
+		Entry("too few fields", "0 2 * *"),
+		Entry("too many fields", "0 0 2 * * *"),
+		Entry("out of range value", "60 * * * *"),
+		Entry("unknown name", "0 0 * * someday"),
+		Entry("reversed range", "0 10-8 * * *"),
+		Entry("invalid step", "*/0 * * * *"),
+		Entry("unknown macro", "@sometimes"),
+	)
+})
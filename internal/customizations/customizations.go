@@ -20,6 +20,37 @@ func MergeCustomizations(templateCustomizations, configCustomizations *v1alpha1.
 			if configCustomizations.Users != nil {
 				customizations.Users = mergeUsers(templateCustomizations.Users, configCustomizations.Users)
 			}
+			if configCustomizations.Groups != nil {
+				customizations.Groups = mergeGroups(templateCustomizations.Groups, configCustomizations.Groups)
+			}
+			if configCustomizations.PayloadRepositories != nil {
+				customizations.PayloadRepositories = mergePayloadRepositories(templateCustomizations.PayloadRepositories, configCustomizations.PayloadRepositories)
+			}
+			if configCustomizations.Subscription != nil {
+				customizations.Subscription = configCustomizations.Subscription.DeepCopy()
+			}
+			if configCustomizations.Hostname != nil {
+				hostname := *configCustomizations.Hostname
+				customizations.Hostname = &hostname
+			}
+			if configCustomizations.Kernel != nil {
+				customizations.Kernel = mergeKernel(templateCustomizations.Kernel, configCustomizations.Kernel)
+			}
+			if configCustomizations.Timezone != nil {
+				customizations.Timezone = mergeTimezone(templateCustomizations.Timezone, configCustomizations.Timezone)
+			}
+			if configCustomizations.Locale != nil {
+				customizations.Locale = mergeLocale(templateCustomizations.Locale, configCustomizations.Locale)
+			}
+			if configCustomizations.Firewall != nil {
+				customizations.Firewall = mergeFirewall(templateCustomizations.Firewall, configCustomizations.Firewall)
+			}
+			if configCustomizations.Directories != nil {
+				customizations.Directories = mergeDirectories(templateCustomizations.Directories, configCustomizations.Directories)
+			}
+			if configCustomizations.Files != nil {
+				customizations.Files = mergeFiles(templateCustomizations.Files, configCustomizations.Files)
+			}
 		}
 	} else {
 		customizations = configCustomizations.DeepCopy()
@@ -68,6 +99,20 @@ func mergeServices(templateServices *v1alpha1.Services, configServices *v1alpha1
 	return &services
 }
 
+// mergeOrdered appends the config values missing from the template values, keeping the order of both
+func mergeOrdered(templateValues []string, configValues []string) []string {
+	var values []string
+	valuesSet := make(map[string]struct{})
+	for _, value := range append(append([]string{}, templateValues...), configValues...) {
+		if _, ok := valuesSet[value]; ok {
+			continue
+		}
+		valuesSet[value] = void
+		values = append(values, value)
+	}
+	return values
+}
+
 func mergePackages(templatePackages []string, configPackages []string) []string {
 	packagesSet := make(map[string]struct{})
 	for _, pkg := range templatePackages {
@@ -89,7 +134,11 @@ func mergeUsers(templateUsers []v1alpha1.User, configUsers []v1alpha1.User) []v1
 		userIndex[user.Name] = user
 	}
 	for _, user := range configUsers {
-		userIndex[user.Name] = user
+		if templateUser, ok := userIndex[user.Name]; ok {
+			userIndex[user.Name] = mergeUser(templateUser, user)
+		} else {
+			userIndex[user.Name] = user
+		}
 	}
 	var users []v1alpha1.User
 	for _, user := range userIndex {
@@ -97,3 +146,195 @@ func mergeUsers(templateUsers []v1alpha1.User, configUsers []v1alpha1.User) []v1
 	}
 	return users
 }
+
+// mergeUser overrides the fields of the template user that are set in the config user.
+// Groups and SSH keys of both users are kept.
+func mergeUser(templateUser v1alpha1.User, configUser v1alpha1.User) v1alpha1.User {
+	user := *templateUser.DeepCopy()
+	if configUser.Groups != nil {
+		var templateGroups []string
+		if templateUser.Groups != nil {
+			templateGroups = *templateUser.Groups
+		}
+		groups := mergeOrdered(templateGroups, *configUser.Groups)
+		user.Groups = &groups
+	}
+	if configUser.Key != nil {
+		user.Key = configUser.Key
+	}
+	if configUser.Description != nil {
+		user.Description = configUser.Description
+	}
+	if configUser.UID != nil {
+		user.UID = configUser.UID
+	}
+	if configUser.GID != nil {
+		user.GID = configUser.GID
+	}
+	if configUser.Home != nil {
+		user.Home = configUser.Home
+	}
+	if configUser.Shell != nil {
+		user.Shell = configUser.Shell
+	}
+	if configUser.PasswordSecretKeyRef != nil {
+		user.PasswordSecretKeyRef = configUser.PasswordSecretKeyRef.DeepCopy()
+	}
+	for _, keyRef := range configUser.SSHKeysSecretKeyRefs {
+		found := false
+		for _, existingKeyRef := range user.SSHKeysSecretKeyRefs {
+			if existingKeyRef.Name == keyRef.Name && existingKeyRef.Key == keyRef.Key {
+				found = true
+				break
+			}
+		}
+		if !found {
+			user.SSHKeysSecretKeyRefs = append(user.SSHKeysSecretKeyRefs, *keyRef.DeepCopy())
+		}
+	}
+	return user
+}
+
+func mergeGroups(templateGroups []v1alpha1.Group, configGroups []v1alpha1.Group) []v1alpha1.Group {
+	groupIndex := make(map[string]v1alpha1.Group)
+	for _, group := range templateGroups {
+		groupIndex[group.Name] = group
+	}
+	for _, group := range configGroups {
+		groupIndex[group.Name] = group
+	}
+	var groups []v1alpha1.Group
+	for _, group := range groupIndex {
+		groups = append(groups, group)
+	}
+	return groups
+}
+
+// RepositoryURL returns the URL identifying the repository: its base URL, metalink or mirrorlist
+func RepositoryURL(repository v1alpha1.Repository) string {
+	_, url := repositoryKindAndURL(repository)
+	return url
+}
+
+// repositoryKey returns the key the payload repositories are merged by: the kind of the URL identifying the
+// repository and the URL, so that a base URL and a metalink sharing the same URL stay apart
+func repositoryKey(repository v1alpha1.Repository) string {
+	kind, url := repositoryKindAndURL(repository)
+	if url == "" {
+		return ""
+	}
+	return kind + ":" + url
+}
+
+// repositoryKindAndURL returns the kind and the value of the first URL set on the repository, by order of precedence
+func repositoryKindAndURL(repository v1alpha1.Repository) (string, string) {
+	switch {
+	case repository.Baseurl != nil && *repository.Baseurl != "":
+		return "baseurl", *repository.Baseurl
+	case repository.Metalink != nil && *repository.Metalink != "":
+		return "metalink", *repository.Metalink
+	case repository.Mirrorlist != nil && *repository.Mirrorlist != "":
+		return "mirrorlist", *repository.Mirrorlist
+	}
+	return "", ""
+}
+
+// mergePayloadRepositories merges the repositories by URL, the ones of the config overriding the ones of the template.
+// The repositories without any URL cannot be matched and are all kept
+func mergePayloadRepositories(templateRepositories []v1alpha1.Repository, configRepositories []v1alpha1.Repository) []v1alpha1.Repository {
+	var repositories []v1alpha1.Repository
+	repositoryIndex := make(map[string]v1alpha1.Repository)
+	for _, repository := range append(append([]v1alpha1.Repository{}, templateRepositories...), configRepositories...) {
+		key := repositoryKey(repository)
+		if key == "" {
+			repositories = append(repositories, repository)
+			continue
+		}
+		repositoryIndex[key] = repository
+	}
+	for _, repository := range repositoryIndex {
+		repositories = append(repositories, repository)
+	}
+	return repositories
+}
+
+func mergeKernel(templateKernel *v1alpha1.Kernel, configKernel *v1alpha1.Kernel) *v1alpha1.Kernel {
+	if templateKernel == nil {
+		return configKernel.DeepCopy()
+	}
+	kernel := templateKernel.DeepCopy()
+	if configKernel.Name != nil {
+		kernel.Name = configKernel.Name
+	}
+	kernel.Append = mergeOrdered(templateKernel.Append, configKernel.Append)
+	return kernel
+}
+
+func mergeTimezone(templateTimezone *v1alpha1.Timezone, configTimezone *v1alpha1.Timezone) *v1alpha1.Timezone {
+	if templateTimezone == nil {
+		return configTimezone.DeepCopy()
+	}
+	timezone := templateTimezone.DeepCopy()
+	if configTimezone.Timezone != nil {
+		timezone.Timezone = configTimezone.Timezone
+	}
+	timezone.NTPServers = mergeOrdered(templateTimezone.NTPServers, configTimezone.NTPServers)
+	return timezone
+}
+
+func mergeLocale(templateLocale *v1alpha1.Locale, configLocale *v1alpha1.Locale) *v1alpha1.Locale {
+	if templateLocale == nil {
+		return configLocale.DeepCopy()
+	}
+	locale := templateLocale.DeepCopy()
+	if configLocale.Keyboard != nil {
+		locale.Keyboard = configLocale.Keyboard
+	}
+	// the first language is the primary one, hence the languages of the config replace the template's ones
+	if configLocale.Languages != nil {
+		locale.Languages = append([]string{}, configLocale.Languages...)
+	}
+	return locale
+}
+
+func mergeFirewall(templateFirewall *v1alpha1.Firewall, configFirewall *v1alpha1.Firewall) *v1alpha1.Firewall {
+	if templateFirewall == nil {
+		return configFirewall.DeepCopy()
+	}
+	firewall := templateFirewall.DeepCopy()
+	firewall.Ports = mergeOrdered(templateFirewall.Ports, configFirewall.Ports)
+	if configFirewall.Services != nil {
+		firewall.Services = mergeServices(templateFirewall.Services, configFirewall.Services)
+	}
+	return firewall
+}
+
+func mergeDirectories(templateDirectories []v1alpha1.Directory, configDirectories []v1alpha1.Directory) []v1alpha1.Directory {
+	directoryIndex := make(map[string]v1alpha1.Directory)
+	for _, directory := range templateDirectories {
+		directoryIndex[directory.Path] = directory
+	}
+	for _, directory := range configDirectories {
+		directoryIndex[directory.Path] = directory
+	}
+	var directories []v1alpha1.Directory
+	for _, directory := range directoryIndex {
+		directories = append(directories, directory)
+	}
+	return directories
+}
+
+func mergeFiles(templateFiles []v1alpha1.File, configFiles []v1alpha1.File) []v1alpha1.File {
+	fileIndex := make(map[string]v1alpha1.File)
+	for _, file := range templateFiles {
+		fileIndex[file.Path] = file
+	}
+	for _, file := range configFiles {
+		fileIndex[file.Path] = file
+	}
+	var files []v1alpha1.File
+	for _, file := range fileIndex {
+		files = append(files, file)
+	}
+	return files
+}
@@ -3,6 +3,7 @@ package customizations_test
 import (
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
 
 	"github.com/project-flotta/osbuild-operator/api/v1alpha1"
 	"github.com/project-flotta/osbuild-operator/internal/customizations"
@@ -25,6 +26,23 @@ var (
 
 	enabledServices3  = append(enabledServices1, enabledServices2[0])
 	disabledServices3 = append(disabledServices1, disabledServices2[0])
+
+	subscriptionA = v1alpha1.Subscription{SecretRef: v1alpha1.NameRef{Name: "a"}, Insights: true}
+	subscriptionB = v1alpha1.Subscription{SecretRef: v1alpha1.NameRef{Name: "b"}}
+
+	repoAUrl, repoBUrl, repoCUrl = "https://a", "https://b", "https://c"
+	checkGpg                     = true
+	repoA                        = v1alpha1.Repository{Baseurl: &repoAUrl}
+	repoB                        = v1alpha1.Repository{Baseurl: &repoBUrl}
+	repoBCheckGpg                = v1alpha1.Repository{Baseurl: &repoBUrl, CheckGpg: &checkGpg}
+	repoC                        = v1alpha1.Repository{Metalink: &repoCUrl}
+	emptyUrl                     = ""
+	repoCEmptyBaseurl            = v1alpha1.Repository{Baseurl: &emptyUrl, Metalink: &repoCUrl, CheckGpg: &checkGpg}
+	repoAMirrorlist              = v1alpha1.Repository{Mirrorlist: &repoAUrl}
+	repoBMirrorlist              = v1alpha1.Repository{Mirrorlist: &repoBUrl}
+	repoWithoutUrl               = v1alpha1.Repository{CheckGpg: &checkGpg}
+
+	noRepositories []v1alpha1.Repository
 )
 
 var _ = Describe("OSBuildConfig customizations", func() {
@@ -147,6 +165,185 @@ var _ = Describe("OSBuildConfig customizations", func() {
 			v1alpha1.Services{Enabled: []string{"b", "c"}, Disabled: []string{"a", "d"}}),
 	)
 
+	DescribeTable("payload repositories should be merged", func(templateRepositories, configRepositories, expectedRepositories []v1alpha1.Repository) {
+		// given
+		templateCustomizations := v1alpha1.Customizations{PayloadRepositories: templateRepositories}
+		configCustomizations := v1alpha1.Customizations{PayloadRepositories: configRepositories}
+
+		// when
+		merged := customizations.MergeCustomizations(&templateCustomizations, &configCustomizations)
+
+		// then
+		Expect(merged.PayloadRepositories).To(ConsistOf(expectedRepositories))
+		Expect(merged.Packages).To(BeNil())
+	},
+		Entry("no repositories anywhere", []v1alpha1.Repository{}, noRepositories, []v1alpha1.Repository{}),
+		Entry("repositories only in template", []v1alpha1.Repository{repoA, repoB}, noRepositories, []v1alpha1.Repository{repoA, repoB}),
+		Entry("repositories only in config", noRepositories, []v1alpha1.Repository{repoA, repoB}, []v1alpha1.Repository{repoA, repoB}),
+		Entry("repositories in both, disjoint config", []v1alpha1.Repository{repoA}, []v1alpha1.Repository{repoB, repoC}, []v1alpha1.Repository{repoA, repoB, repoC}),
+		Entry("repositories in both, config overrides the same URL", []v1alpha1.Repository{repoA, repoB}, []v1alpha1.Repository{repoBCheckGpg}, []v1alpha1.Repository{repoA, repoBCheckGpg}),
+		Entry("repositories in both, config overrides the same metalink with an empty base URL", []v1alpha1.Repository{repoC}, []v1alpha1.Repository{repoCEmptyBaseurl}, []v1alpha1.Repository{repoCEmptyBaseurl}),
+		Entry("mirrorlist repositories in both", []v1alpha1.Repository{repoAMirrorlist}, []v1alpha1.Repository{repoBMirrorlist}, []v1alpha1.Repository{repoAMirrorlist, repoBMirrorlist}),
+		Entry("base URL and mirrorlist sharing the same URL", []v1alpha1.Repository{repoA}, []v1alpha1.Repository{repoAMirrorlist}, []v1alpha1.Repository{repoA, repoAMirrorlist}),
+		Entry("repositories without URL in both", []v1alpha1.Repository{repoWithoutUrl}, []v1alpha1.Repository{repoWithoutUrl, repoA}, []v1alpha1.Repository{repoWithoutUrl, repoWithoutUrl, repoA}),
+	)
+
+	DescribeTable("subscription should be merged", func(templateSubscription, configSubscription, expectedSubscription *v1alpha1.Subscription) {
+		// given
+		templateCustomizations := v1alpha1.Customizations{Subscription: templateSubscription}
+		configCustomizations := v1alpha1.Customizations{Subscription: configSubscription}
+
+		// when
+		merged := customizations.MergeCustomizations(&templateCustomizations, &configCustomizations)
+
+		// then
+		Expect(merged.Subscription).To(Equal(expectedSubscription))
+	},
+		Entry("no subscription anywhere", nil, nil, nil),
+		Entry("subscription only in template", &subscriptionA, nil, &subscriptionA),
+		Entry("subscription only in config", nil, &subscriptionB, &subscriptionB),
+		Entry("subscription in both", &subscriptionA, &subscriptionB, &subscriptionB),
+	)
+
+	It("should override the hostname of the template", func() {
+		// given
+		templateHostname, configHostname := "template", "config"
+		templateCustomizations := v1alpha1.Customizations{Hostname: &templateHostname}
+		configCustomizations := v1alpha1.Customizations{Hostname: &configHostname}
+
+		// when
+		merged := customizations.MergeCustomizations(&templateCustomizations, &configCustomizations)
+
+		// then
+		Expect(*merged.Hostname).To(Equal(configHostname))
+	})
+
+	It("should merge kernel arguments keeping their order", func() {
+		// given
+		kernelName := "kernel-rt"
+		templateCustomizations := v1alpha1.Customizations{Kernel: &v1alpha1.Kernel{Append: []string{"a=1", "b"}}}
+		configCustomizations := v1alpha1.Customizations{Kernel: &v1alpha1.Kernel{Name: &kernelName, Append: []string{"c", "b"}}}
+
+		// when
+		merged := customizations.MergeCustomizations(&templateCustomizations, &configCustomizations)
+
+		// then
+		Expect(merged.Kernel.Append).To(Equal([]string{"a=1", "b", "c"}))
+		Expect(*merged.Kernel.Name).To(Equal(kernelName))
+	})
+
+	It("should merge timezone and NTP servers", func() {
+		// given
+		templateTimezone, configTimezone := "UTC", "US/Eastern"
+		templateCustomizations := v1alpha1.Customizations{Timezone: &v1alpha1.Timezone{Timezone: &templateTimezone, NTPServers: []string{"ntp1"}}}
+		configCustomizations := v1alpha1.Customizations{Timezone: &v1alpha1.Timezone{Timezone: &configTimezone, NTPServers: []string{"ntp2"}}}
+
+		// when
+		merged := customizations.MergeCustomizations(&templateCustomizations, &configCustomizations)
+
+		// then
+		Expect(*merged.Timezone.Timezone).To(Equal(configTimezone))
+		Expect(merged.Timezone.NTPServers).To(Equal([]string{"ntp1", "ntp2"}))
+	})
+
+	It("should replace the languages and keep the template keyboard", func() {
+		// given
+		keyboard := "us"
+		templateCustomizations := v1alpha1.Customizations{Locale: &v1alpha1.Locale{Languages: []string{"en_US.UTF-8"}, Keyboard: &keyboard}}
+		configCustomizations := v1alpha1.Customizations{Locale: &v1alpha1.Locale{Languages: []string{"de_DE.UTF-8", "en_US.UTF-8"}}}
+
+		// when
+		merged := customizations.MergeCustomizations(&templateCustomizations, &configCustomizations)
+
+		// then
+		Expect(merged.Locale.Languages).To(Equal([]string{"de_DE.UTF-8", "en_US.UTF-8"}))
+		Expect(*merged.Locale.Keyboard).To(Equal(keyboard))
+	})
+
+	It("should merge firewall ports and services", func() {
+		// given
+		templateCustomizations := v1alpha1.Customizations{Firewall: &v1alpha1.Firewall{
+			Ports:    []string{"22:tcp"},
+			Services: &v1alpha1.Services{Enabled: []string{"ssh", "http"}},
+		}}
+		configCustomizations := v1alpha1.Customizations{Firewall: &v1alpha1.Firewall{
+			Ports:    []string{"9100:tcp", "22:tcp"},
+			Services: &v1alpha1.Services{Disabled: []string{"http"}},
+		}}
+
+		// when
+		merged := customizations.MergeCustomizations(&templateCustomizations, &configCustomizations)
+
+		// then
+		Expect(merged.Firewall.Ports).To(Equal([]string{"22:tcp", "9100:tcp"}))
+		Expect(merged.Firewall.Services.Enabled).To(ConsistOf("ssh"))
+		Expect(merged.Firewall.Services.Disabled).To(ConsistOf("http"))
+	})
+
+	It("should merge users field by field", func() {
+		// given
+		shell, home := "/bin/zsh", "/var/home/A"
+		var uid int64 = 1000
+		keyRef1 := corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "keys"}, Key: "1"}
+		keyRef2 := corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "keys"}, Key: "2"}
+		templateCustomizations := v1alpha1.Customizations{Users: []v1alpha1.User{
+			{Name: "A", Groups: &[]string{"a"}, UID: &uid, Shell: &shell, SSHKeysSecretKeyRefs: []corev1.SecretKeySelector{keyRef1}},
+		}}
+		configCustomizations := v1alpha1.Customizations{Users: []v1alpha1.User{
+			{Name: "A", Groups: &[]string{"wheel"}, Home: &home, SSHKeysSecretKeyRefs: []corev1.SecretKeySelector{keyRef1, keyRef2}},
+		}}
+
+		// when
+		merged := customizations.MergeCustomizations(&templateCustomizations, &configCustomizations)
+
+		// then
+		Expect(merged.Users).To(ConsistOf(v1alpha1.User{
+			Name:                 "A",
+			Groups:               &[]string{"a", "wheel"},
+			UID:                  &uid,
+			Shell:                &shell,
+			Home:                 &home,
+			SSHKeysSecretKeyRefs: []corev1.SecretKeySelector{keyRef1, keyRef2},
+		}))
+	})
+
+	It("should merge groups by name", func() {
+		// given
+		var gid1, gid2 int64 = 1001, 1002
+		templateCustomizations := v1alpha1.Customizations{Groups: []v1alpha1.Group{{Name: "a", GID: &gid1}, {Name: "b"}}}
+		configCustomizations := v1alpha1.Customizations{Groups: []v1alpha1.Group{{Name: "a", GID: &gid2}}}
+
+		// when
+		merged := customizations.MergeCustomizations(&templateCustomizations, &configCustomizations)
+
+		// then
+		Expect(merged.Groups).To(ConsistOf(v1alpha1.Group{Name: "a", GID: &gid2}, v1alpha1.Group{Name: "b"}))
+	})
+
+	It("should merge files and directories per path", func() {
+		// given
+		templateMode, configMode := "0644", "0600"
+		templateCustomizations := v1alpha1.Customizations{
+			Directories: []v1alpha1.Directory{{Path: "/etc/a"}},
+			Files:       []v1alpha1.File{{Path: "/etc/a/1", Mode: &templateMode}, {Path: "/etc/a/2"}},
+		}
+		configCustomizations := v1alpha1.Customizations{
+			Directories: []v1alpha1.Directory{{Path: "/etc/b"}},
+			Files:       []v1alpha1.File{{Path: "/etc/a/1", Mode: &configMode}, {Path: "/etc/b/1"}},
+		}
+
+		// when
+		merged := customizations.MergeCustomizations(&templateCustomizations, &configCustomizations)
+
+		// then
+		Expect(merged.Directories).To(ConsistOf(v1alpha1.Directory{Path: "/etc/a"}, v1alpha1.Directory{Path: "/etc/b"}))
+		Expect(merged.Files).To(ConsistOf(
+			v1alpha1.File{Path: "/etc/a/1", Mode: &configMode},
+			v1alpha1.File{Path: "/etc/a/2"},
+			v1alpha1.File{Path: "/etc/b/1"},
+		))
+	})
+
 	It("should use config customizations when template customizations are nil", func() {
 		// given
 		configCustomizations := v1alpha1.Customizations{
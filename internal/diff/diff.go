@@ -0,0 +1,287 @@
+package diff
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/project-flotta/osbuild-operator/api/v1alpha1"
+	"github.com/project-flotta/osbuild-operator/internal/composer"
+	"github.com/project-flotta/osbuild-operator/internal/rpmmd"
+)
+
+const (
+	customizationsPrefix   = "customizations."
+	templateRefField       = "template.osBuildConfigTemplateRef"
+	templateParameterField = "template.parameters."
+)
+
+// Package is a package of a build, its version is formatted as [epoch:]version-release
+type Package struct {
+	Name    string `json:"name"`
+	Arch    string `json:"arch"`
+	Version string `json:"version"`
+}
+
+// PackageChange is a package whose version differs between two builds
+type PackageChange struct {
+	Name       string `json:"name"`
+	Arch       string `json:"arch"`
+	OldVersion string `json:"oldVersion"`
+	NewVersion string `json:"newVersion"`
+}
+
+// Report lists the changes of a build compared to the previous one
+type Report struct {
+	OSBuild               string          `json:"osBuild"`
+	PreviousOSBuild       string          `json:"previousOSBuild,omitempty"`
+	AddedPackages         []Package       `json:"addedPackages,omitempty"`
+	RemovedPackages       []Package       `json:"removedPackages,omitempty"`
+	UpgradedPackages      []PackageChange `json:"upgradedPackages,omitempty"`
+	DowngradedPackages    []PackageChange `json:"downgradedPackages,omitempty"`
+	ChangedCustomizations []string        `json:"changedCustomizations,omitempty"`
+}
+
+type packageVersion struct {
+	epoch   string
+	version string
+	release string
+}
+
+func (v packageVersion) String() string {
+	if v.epoch == "" || v.epoch == "0" {
+		return fmt.Sprintf("%s-%s", v.version, v.release)
+	}
+	return fmt.Sprintf("%s:%s-%s", v.epoch, v.version, v.release)
+}
+
+// NewReport compares the packages listed in the compose metadata and the user configurations of two builds. Without a
+// previous build, all the packages are reported as added.
+func NewReport(osBuildName string, previousOSBuildName string, previousPackages []composer.PackageMetadata, packages []composer.PackageMetadata,
+	previousConfiguration *v1alpha1.UserConfiguration, configuration *v1alpha1.UserConfiguration) *Report {
+	report := &Report{
+		OSBuild:         osBuildName,
+		PreviousOSBuild: previousOSBuildName,
+	}
+
+	previousVersions := getPackageVersions(previousPackages)
+	versions := getPackageVersions(packages)
+	for key, version := range versions {
+		previousVersion, ok := previousVersions[key]
+		if !ok {
+			report.AddedPackages = append(report.AddedPackages, Package{Name: key.name, Arch: key.arch, Version: version.String()})
+			continue
+		}
+
+		change := PackageChange{Name: key.name, Arch: key.arch, OldVersion: previousVersion.String(), NewVersion: version.String()}
+		switch rpmmd.CompareEVR(previousVersion.String(), version.String()) {
+		case -1:
+			report.UpgradedPackages = append(report.UpgradedPackages, change)
+		case 1:
+			report.DowngradedPackages = append(report.DowngradedPackages, change)
+		}
+	}
+	for key, previousVersion := range previousVersions {
+		if _, ok := versions[key]; !ok {
+			report.RemovedPackages = append(report.RemovedPackages, Package{Name: key.name, Arch: key.arch, Version: previousVersion.String()})
+		}
+	}
+
+	sortPackages(report.AddedPackages)
+	sortPackages(report.RemovedPackages)
+	sortPackageChanges(report.UpgradedPackages)
+	sortPackageChanges(report.DowngradedPackages)
+
+	if previousOSBuildName != "" {
+		report.ChangedCustomizations = getChangedCustomizations(previousConfiguration, configuration)
+	}
+	return report
+}
+
+// Summary returns the number of changes of the report, to be set in the status of the OSBuild
+func (r *Report) Summary(reportConfigMapName string) *v1alpha1.DiffSummary {
+	return &v1alpha1.DiffSummary{
+		PreviousOSBuild:       r.PreviousOSBuild,
+		AddedPackages:         len(r.AddedPackages),
+		RemovedPackages:       len(r.RemovedPackages),
+		UpgradedPackages:      len(r.UpgradedPackages),
+		DowngradedPackages:    len(r.DowngradedPackages),
+		ChangedCustomizations: r.ChangedCustomizations,
+		ReportConfigMap:       reportConfigMapName,
+	}
+}
+
+// Chain combines the reports of successive builds, from the oldest to the newest, into the report of the newest build
+// compared to the previous build of the oldest one. A package upgraded then downgraded back to its version is not
+// reported, and the changed customizations are the ones changed by any of the reports.
+func Chain(reports []*Report) *Report {
+	if len(reports) == 0 {
+		return &Report{}
+	}
+
+	// the versions of the changed packages before the first report and after the last one, empty when not installed
+	oldVersions := map[packageKey]string{}
+	newVersions := map[packageKey]string{}
+	change := func(name string, arch string, oldVersion string, newVersion string) {
+		key := packageKey{name: name, arch: arch}
+		if _, changed := oldVersions[key]; !changed {
+			oldVersions[key] = oldVersion
+		}
+		newVersions[key] = newVersion
+	}
+	changedCustomizations := map[string]bool{}
+	for _, report := range reports {
+		for _, pkg := range report.AddedPackages {
+			change(pkg.Name, pkg.Arch, "", pkg.Version)
+		}
+		for _, pkg := range report.RemovedPackages {
+			change(pkg.Name, pkg.Arch, pkg.Version, "")
+		}
+		for _, pkg := range report.UpgradedPackages {
+			change(pkg.Name, pkg.Arch, pkg.OldVersion, pkg.NewVersion)
+		}
+		for _, pkg := range report.DowngradedPackages {
+			change(pkg.Name, pkg.Arch, pkg.OldVersion, pkg.NewVersion)
+		}
+		for _, name := range report.ChangedCustomizations {
+			changedCustomizations[name] = true
+		}
+	}
+
+	chained := &Report{
+		OSBuild:         reports[len(reports)-1].OSBuild,
+		PreviousOSBuild: reports[0].PreviousOSBuild,
+	}
+	for key, oldVersion := range oldVersions {
+		newVersion := newVersions[key]
+		switch {
+		case oldVersion == newVersion:
+			// changed back to its version before the first report
+		case oldVersion == "":
+			chained.AddedPackages = append(chained.AddedPackages, Package{Name: key.name, Arch: key.arch, Version: newVersion})
+		case newVersion == "":
+			chained.RemovedPackages = append(chained.RemovedPackages, Package{Name: key.name, Arch: key.arch, Version: oldVersion})
+		case rpmmd.CompareEVR(oldVersion, newVersion) < 0:
+			chained.UpgradedPackages = append(chained.UpgradedPackages, PackageChange{Name: key.name, Arch: key.arch, OldVersion: oldVersion, NewVersion: newVersion})
+		default:
+			chained.DowngradedPackages = append(chained.DowngradedPackages, PackageChange{Name: key.name, Arch: key.arch, OldVersion: oldVersion, NewVersion: newVersion})
+		}
+	}
+	for name := range changedCustomizations {
+		chained.ChangedCustomizations = append(chained.ChangedCustomizations, name)
+	}
+
+	sortPackages(chained.AddedPackages)
+	sortPackages(chained.RemovedPackages)
+	sortPackageChanges(chained.UpgradedPackages)
+	sortPackageChanges(chained.DowngradedPackages)
+	sort.Strings(chained.ChangedCustomizations)
+	return chained
+}
+
+type packageKey struct {
+	name string
+	arch string
+}
+
+func getPackageVersions(packages []composer.PackageMetadata) map[packageKey]packageVersion {
+	versions := make(map[packageKey]packageVersion, len(packages))
+	for _, pkg := range packages {
+		version := packageVersion{version: pkg.Version, release: pkg.Release}
+		if pkg.Epoch != nil {
+			version.epoch = *pkg.Epoch
+		}
+		versions[packageKey{name: pkg.Name, arch: pkg.Arch}] = version
+	}
+	return versions
+}
+
+func sortPackages(packages []Package) {
+	sort.Slice(packages, func(i, j int) bool {
+		if packages[i].Name != packages[j].Name {
+			return packages[i].Name < packages[j].Name
+		}
+		return packages[i].Arch < packages[j].Arch
+	})
+}
+
+func sortPackageChanges(changes []PackageChange) {
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].Name != changes[j].Name {
+			return changes[i].Name < changes[j].Name
+		}
+		return changes[i].Arch < changes[j].Arch
+	})
+}
+
+// getChangedCustomizations returns the customizations fields and the template parameters that changed, without
+// their values as they may hold credentials
+func getChangedCustomizations(previous *v1alpha1.UserConfiguration, current *v1alpha1.UserConfiguration) []string {
+	if previous == nil {
+		previous = &v1alpha1.UserConfiguration{}
+	}
+	if current == nil {
+		current = &v1alpha1.UserConfiguration{}
+	}
+
+	var changed []string
+	previousFields := toFields(previous.Customizations)
+	fields := toFields(current.Customizations)
+	for name := range unionKeys(previousFields, fields) {
+		if !reflect.DeepEqual(previousFields[name], fields[name]) {
+			changed = append(changed, customizationsPrefix+name)
+		}
+	}
+
+	previousTemplate, template := previous.Template, current.Template
+	if previousTemplate == nil {
+		previousTemplate = &v1alpha1.Template{}
+	}
+	if template == nil {
+		template = &v1alpha1.Template{}
+	}
+	if previousTemplate.OSBuildConfigTemplateRef != template.OSBuildConfigTemplateRef {
+		changed = append(changed, templateRefField)
+	}
+	previousParameters := toParameters(previousTemplate.Parameters)
+	parameters := toParameters(template.Parameters)
+	for name := range unionKeys(previousParameters, parameters) {
+		if previousParameters[name] != parameters[name] {
+			changed = append(changed, templateParameterField+name)
+		}
+	}
+
+	sort.Strings(changed)
+	return changed
+}
+
+func toFields(customizations *v1alpha1.Customizations) map[string]interface{} {
+	fields := map[string]interface{}{}
+	if customizations == nil {
+		return fields
+	}
+	// the customizations are plain JSON-serializable API types, marshaling them cannot fail
+	data, _ := json.Marshal(customizations)
+	_ = json.Unmarshal(data, &fields)
+	return fields
+}
+
+func toParameters(parameters []v1alpha1.ParameterValue) map[string]interface{} {
+	values := map[string]interface{}{}
+	for _, parameter := range parameters {
+		values[parameter.Name] = parameter.Value
+	}
+	return values
+}
+
+func unionKeys(a map[string]interface{}, b map[string]interface{}) map[string]bool {
+	keys := map[string]bool{}
+	for key := range a {
+		keys[key] = true
+	}
+	for key := range b {
+		keys[key] = true
+	}
+	return keys
+}
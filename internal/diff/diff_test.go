@@ -0,0 +1,198 @@
+package diff_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/project-flotta/osbuild-operator/api/v1alpha1"
+	"github.com/project-flotta/osbuild-operator/internal/composer"
+	"github.com/project-flotta/osbuild-operator/internal/diff"
+)
+
+var _ = Describe("Diff", func() {
+	newPackage := func(name string, epoch string, version string, release string) composer.PackageMetadata {
+		pkg := composer.PackageMetadata{Name: name, Arch: "x86_64", Version: version, Release: release}
+		if epoch != "" {
+			pkg.Epoch = &epoch
+		}
+		return pkg
+	}
+
+	It("should report the added, removed, upgraded and downgraded packages", func() {
+		// given
+		previousPackages := []composer.PackageMetadata{
+			newPackage("bash", "", "5.1.8", "4.el9"),
+			newPackage("vim-minimal", "2", "8.2.2637", "16.el9"),
+			newPackage("nano", "", "5.6.1", "5.el9"),
+			newPackage("podman", "", "4.2.0", "1.el9"),
+		}
+		packages := []composer.PackageMetadata{
+			newPackage("bash", "", "5.1.8", "4.el9"),
+			newPackage("vim-minimal", "2", "8.2.2637", "20.el9"),
+			newPackage("podman", "", "4.1.1", "2.el9"),
+			newPackage("git", "", "2.31.1", "2.el9"),
+		}
+
+		// when
+		report := diff.NewReport("config-2", "config-1", previousPackages, packages, nil, nil)
+
+		// then
+		Expect(report.OSBuild).To(Equal("config-2"))
+		Expect(report.PreviousOSBuild).To(Equal("config-1"))
+		Expect(report.AddedPackages).To(Equal([]diff.Package{{Name: "git", Arch: "x86_64", Version: "2.31.1-2.el9"}}))
+		Expect(report.RemovedPackages).To(Equal([]diff.Package{{Name: "nano", Arch: "x86_64", Version: "5.6.1-5.el9"}}))
+		Expect(report.UpgradedPackages).To(Equal([]diff.PackageChange{
+			{Name: "vim-minimal", Arch: "x86_64", OldVersion: "2:8.2.2637-16.el9", NewVersion: "2:8.2.2637-20.el9"},
+		}))
+		Expect(report.DowngradedPackages).To(Equal([]diff.PackageChange{
+			{Name: "podman", Arch: "x86_64", OldVersion: "4.2.0-1.el9", NewVersion: "4.1.1-2.el9"},
+		}))
+
+		summary := report.Summary("config-2-diff")
+		Expect(*summary).To(Equal(v1alpha1.DiffSummary{
+			PreviousOSBuild:    "config-1",
+			AddedPackages:      1,
+			RemovedPackages:    1,
+			UpgradedPackages:   1,
+			DowngradedPackages: 1,
+			ReportConfigMap:    "config-2-diff",
+		}))
+	})
+
+	DescribeTable("should compare the versions the way RPM does", func(oldEpoch, oldVersion, oldRelease, newEpoch, newVersion, newRelease string, upgraded bool) {
+		// given
+		previousPackages := []composer.PackageMetadata{newPackage("pkg", oldEpoch, oldVersion, oldRelease)}
+		packages := []composer.PackageMetadata{newPackage("pkg", newEpoch, newVersion, newRelease)}
+
+		// when
+		report := diff.NewReport("config-2", "config-1", previousPackages, packages, nil, nil)
+
+		// then
+		if upgraded {
+			Expect(report.UpgradedPackages).To(HaveLen(1))
+			Expect(report.DowngradedPackages).To(BeEmpty())
+		} else {
+			Expect(report.UpgradedPackages).To(BeEmpty())
+			Expect(report.DowngradedPackages).To(HaveLen(1))
+		}
+	},
+		Entry("numeric segments", "", "1.9", "1", "", "1.10", "1", true),
+		Entry("leading zeros", "", "1.010", "1", "", "1.9", "1", false),
+		Entry("numeric newer than alphabetic", "", "1.a", "1", "", "1.1", "1", true),
+		Entry("longer version", "", "1.0", "1", "", "1.0.1", "1", true),
+		Entry("tilde before release", "", "1.0", "1", "", "1.0~rc1", "1", false),
+		Entry("caret after release", "", "1.0", "1", "", "1.0^git1", "1", true),
+		Entry("epoch", "1", "2.0", "1", "", "3.0", "1", false),
+		Entry("release", "", "1.0", "1.el9", "", "1.0", "2.el9", true),
+	)
+
+	It("should report all the packages as added without a previous OSBuild", func() {
+		// when
+		report := diff.NewReport("config-1", "", nil, []composer.PackageMetadata{newPackage("bash", "", "5.1.8", "4.el9")}, nil,
+			&v1alpha1.UserConfiguration{Customizations: &v1alpha1.Customizations{Packages: []string{"bash"}}})
+
+		// then
+		Expect(report.AddedPackages).To(HaveLen(1))
+		Expect(report.ChangedCustomizations).To(BeEmpty())
+	})
+
+	It("should report the changed customizations and template parameters", func() {
+		// given
+		previousConfiguration := &v1alpha1.UserConfiguration{
+			Customizations: &v1alpha1.Customizations{
+				Packages: []string{"bash"},
+				Services: &v1alpha1.Services{Enabled: []string{"sshd"}},
+			},
+			Template: &v1alpha1.Template{
+				OSBuildConfigTemplateRef: "template",
+				Parameters:               []v1alpha1.ParameterValue{{Name: "a", Value: "1"}, {Name: "b", Value: "1"}, {Name: "c", Value: "1"}},
+			},
+		}
+		configuration := &v1alpha1.UserConfiguration{
+			Customizations: &v1alpha1.Customizations{
+				Packages: []string{"bash", "git"},
+				Services: &v1alpha1.Services{Enabled: []string{"sshd"}},
+				Users:    []v1alpha1.User{{Name: "user"}},
+			},
+			Template: &v1alpha1.Template{
+				OSBuildConfigTemplateRef: "other-template",
+				Parameters:               []v1alpha1.ParameterValue{{Name: "a", Value: "1"}, {Name: "b", Value: "2"}, {Name: "d", Value: "1"}},
+			},
+		}
+
+		// when
+		report := diff.NewReport("config-2", "config-1", nil, nil, previousConfiguration, configuration)
+
+		// then
+		Expect(report.ChangedCustomizations).To(Equal([]string{
+			"customizations.packages",
+			"customizations.users",
+			"template.osBuildConfigTemplateRef",
+			"template.parameters.b",
+			"template.parameters.c",
+			"template.parameters.d",
+		}))
+	})
+
+	It("should chain the reports of successive builds", func() {
+		// given
+		reports := []*diff.Report{
+			{
+				OSBuild:         "config-2",
+				PreviousOSBuild: "config-1",
+				AddedPackages:   []diff.Package{{Name: "git", Arch: "x86_64", Version: "2.31.1-2.el9"}},
+				RemovedPackages: []diff.Package{{Name: "nano", Arch: "x86_64", Version: "5.6.1-5.el9"}},
+				UpgradedPackages: []diff.PackageChange{
+					{Name: "bash", Arch: "x86_64", OldVersion: "5.1.8-4.el9", NewVersion: "5.1.8-6.el9"},
+					{Name: "podman", Arch: "x86_64", OldVersion: "4.1.1-2.el9", NewVersion: "4.2.0-1.el9"},
+				},
+				ChangedCustomizations: []string{"customizations.packages"},
+			},
+			{
+				OSBuild:               "config-4",
+				PreviousOSBuild:       "config-2",
+				RemovedPackages:       []diff.Package{{Name: "git", Arch: "x86_64", Version: "2.31.1-2.el9"}},
+				AddedPackages:         []diff.Package{{Name: "nano", Arch: "x86_64", Version: "5.6.1-7.el9"}},
+				UpgradedPackages:      []diff.PackageChange{{Name: "vim-minimal", Arch: "x86_64", OldVersion: "2:8.2.2637-16.el9", NewVersion: "2:8.2.2637-20.el9"}},
+				DowngradedPackages:    []diff.PackageChange{{Name: "podman", Arch: "x86_64", OldVersion: "4.2.0-1.el9", NewVersion: "4.1.1-2.el9"}},
+				ChangedCustomizations: []string{"customizations.users", "customizations.packages"},
+			},
+			{
+				OSBuild:          "config-5",
+				PreviousOSBuild:  "config-4",
+				AddedPackages:    []diff.Package{{Name: "tmux", Arch: "x86_64", Version: "3.2a-4.el9"}},
+				UpgradedPackages: []diff.PackageChange{{Name: "bash", Arch: "x86_64", OldVersion: "5.1.8-6.el9", NewVersion: "5.1.8-7.el9"}},
+			},
+		}
+
+		// when
+		report := diff.Chain(reports)
+
+		// then
+		Expect(report.OSBuild).To(Equal("config-5"))
+		Expect(report.PreviousOSBuild).To(Equal("config-1"))
+		Expect(report.AddedPackages).To(Equal([]diff.Package{{Name: "tmux", Arch: "x86_64", Version: "3.2a-4.el9"}}))
+		Expect(report.RemovedPackages).To(BeEmpty())
+		Expect(report.UpgradedPackages).To(Equal([]diff.PackageChange{
+			{Name: "bash", Arch: "x86_64", OldVersion: "5.1.8-4.el9", NewVersion: "5.1.8-7.el9"},
+			{Name: "nano", Arch: "x86_64", OldVersion: "5.6.1-5.el9", NewVersion: "5.6.1-7.el9"},
+			{Name: "vim-minimal", Arch: "x86_64", OldVersion: "2:8.2.2637-16.el9", NewVersion: "2:8.2.2637-20.el9"},
+		}))
+		Expect(report.DowngradedPackages).To(BeEmpty())
+		Expect(report.ChangedCustomizations).To(Equal([]string{"customizations.packages", "customizations.users"}))
+	})
+
+	It("should return the report of a single build as is", func() {
+		// given
+		report := diff.NewReport("config-2", "config-1",
+			[]composer.PackageMetadata{newPackage("bash", "", "5.1.8", "4.el9"), newPackage("nano", "", "5.6.1", "5.el9")},
+			[]composer.PackageMetadata{newPackage("bash", "", "5.1.8", "6.el9"), newPackage("git", "", "2.31.1", "2.el9")},
+			nil, nil)
+
+		// when
+		chained := diff.Chain([]*diff.Report{report})
+
+		// then
+		Expect(chained).To(Equal(report))
+	})
+})
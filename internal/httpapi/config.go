@@ -1,6 +1,10 @@
 package httpapi
 
-import "github.com/kelseyhightower/envconfig"
+import (
+	"time"
+
+	"github.com/kelseyhightower/envconfig"
+)
 
 type HttpAPIConfig struct {
 	// The port of the HTTPs server
@@ -14,6 +18,38 @@ type HttpAPIConfig struct {
 
 	// Verbosity of the logger.
 	LogLevel string `envconfig:"LOG_LEVEL" default:"info"`
+
+	// The longest time a request waits for an OSBuild to finish
+	MaxWaitTimeout time.Duration `envconfig:"MAX_WAIT_TIMEOUT" default:"10m"`
+
+	// The period at which a waiting request checks whether the OSBuild finished
+	WaitPollInterval time.Duration `envconfig:"WAIT_POLL_INTERVAL" default:"5s"`
+
+	// The period at which the idle watch streams send a comment, keeping the proxies from closing them
+	WatchHeartbeatInterval time.Duration `envconfig:"WATCH_HEARTBEAT_INTERVAL" default:"30s"`
+
+	// The certificate and key files of the HTTPs server, e.g. mounted from the Secret of a cert-manager Certificate.
+	// They are reloaded when they change, and plain HTTP is served when they are not set
+	TLSCertFile string `envconfig:"TLS_CERT_FILE" default:""`
+	TLSKeyFile  string `envconfig:"TLS_KEY_FILE" default:""`
+
+	// The requests per second each client is allowed, in bursts of up to ClientRateBurst requests. 0 disables the limit
+	ClientRateLimit float64 `envconfig:"CLIENT_RATE_LIMIT" default:"10"`
+	ClientRateBurst int     `envconfig:"CLIENT_RATE_BURST" default:"20"`
+
+	// The header holding the address of the clients behind a proxy, e.g. X-Forwarded-For, of which the last address is
+	// used. It is only to be set behind a proxy that sets or appends to it. The clients are identified by the address
+	// of their connection when it is not set
+	ClientIPHeader string `envconfig:"CLIENT_IP_HEADER" default:""`
+
+	// The builds per second each OSBuildConfig can be triggered, in bursts of up to TriggerRateBurst builds. 0 disables
+	// the limit
+	TriggerRateLimit float64 `envconfig:"TRIGGER_RATE_LIMIT" default:"0.1"`
+	TriggerRateBurst int     `envconfig:"TRIGGER_RATE_BURST" default:"3"`
+
+	// How far the timestamps of the signed requests may be from the server time. Their nonces are remembered as long,
+	// and 0 disables the replay protection
+	ReplayWindow time.Duration `envconfig:"REPLAY_WINDOW" default:"5m"`
 }
 
 var GlobalHttpAPIConf *HttpAPIConfig
@@ -1,6 +1,7 @@
 package indexer
 
 import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/project-flotta/osbuild-operator/api/v1alpha1"
@@ -9,6 +10,15 @@ import (
 const (
 	// ConfigByConfigTemplate is the name of the indexer for OSBuildConfig by OSBuildConfigTemplate name
 	ConfigByConfigTemplate = "config-by-template"
+
+	// BuildByConfig is the name of the indexer for OSBuild by the name of the OSBuildConfig controlling it
+	BuildByConfig = "build-by-config"
+
+	// RepositoryByConfig is the name of the indexer for OSTreeRepository by OSBuildConfig name
+	RepositoryByConfig = "repository-by-config"
+
+	// ReleaseByBuild is the name of the indexer for OSBuildRelease by the name of the released OSBuild
+	ReleaseByBuild = "release-by-build"
 )
 
 func ConfigByTemplateIndexFunc(obj client.Object) []string {
@@ -25,3 +35,43 @@ func ConfigByTemplateIndexFunc(obj client.Object) []string {
 
 	return []string{config.Spec.Template.OSBuildConfigTemplateRef}
 }
+
+func BuildByConfigIndexFunc(obj client.Object) []string {
+	build, ok := obj.(*v1alpha1.OSBuild)
+	if !ok {
+		return []string{}
+	}
+	owner := metav1.GetControllerOf(build)
+	if owner == nil {
+		return []string{}
+	}
+	if owner.APIVersion != v1alpha1.GroupVersion.String() || owner.Kind != "OSBuildConfig" {
+		return []string{}
+	}
+
+	return []string{owner.Name}
+}
+
+func RepositoryByConfigIndexFunc(obj client.Object) []string {
+	repository, ok := obj.(*v1alpha1.OSTreeRepository)
+	if !ok {
+		return []string{}
+	}
+	if repository.Spec.OSBuildConfigRef == "" {
+		return []string{}
+	}
+
+	return []string{repository.Spec.OSBuildConfigRef}
+}
+
+func ReleaseByBuildIndexFunc(obj client.Object) []string {
+	release, ok := obj.(*v1alpha1.OSBuildRelease)
+	if !ok {
+		return []string{}
+	}
+	if release.Spec.OSBuildRef == "" {
+		return []string{}
+	}
+
+	return []string{release.Spec.OSBuildRef}
+}
@@ -3,6 +3,7 @@ package indexer_test
 import (
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/project-flotta/osbuild-operator/api/v1alpha1"
 	"github.com/project-flotta/osbuild-operator/internal/indexer"
@@ -60,4 +61,131 @@ var _ = Describe("Index functions", func() {
 		})
 	})
 
+	Context("Index func of os build", func() {
+		var controller = true
+
+		It("should create key", func() {
+			// given
+			build := v1alpha1.OSBuild{
+				ObjectMeta: metav1.ObjectMeta{
+					OwnerReferences: []metav1.OwnerReference{{
+						APIVersion: v1alpha1.GroupVersion.String(),
+						Kind:       "OSBuildConfig",
+						Name:       "config",
+						Controller: &controller,
+					}},
+				},
+			}
+
+			// when
+			keys := indexer.BuildByConfigIndexFunc(&build)
+
+			// then
+			Expect(keys).Should(ConsistOf("config"))
+		})
+
+		DescribeTable("should create no keys", func(ownerReferences []metav1.OwnerReference) {
+			// given
+			build := v1alpha1.OSBuild{
+				ObjectMeta: metav1.ObjectMeta{
+					OwnerReferences: ownerReferences,
+				},
+			}
+
+			// when
+			keys := indexer.BuildByConfigIndexFunc(&build)
+
+			// then
+			Expect(keys).To(BeEmpty())
+		},
+			Entry("no owner", nil),
+			Entry("owner is not the controller", []metav1.OwnerReference{{
+				APIVersion: v1alpha1.GroupVersion.String(),
+				Kind:       "OSBuildConfig",
+				Name:       "config",
+			}}),
+			Entry("controller is not an OSBuildConfig", []metav1.OwnerReference{{
+				APIVersion: "v1",
+				Kind:       "ConfigMap",
+				Name:       "config",
+				Controller: &controller,
+			}}))
+
+		It("should create no keys for wrong type", func() {
+			// given
+			notABuild := v1alpha1.OSBuildConfig{}
+
+			// when
+			keys := indexer.BuildByConfigIndexFunc(&notABuild)
+
+			// then
+			Expect(keys).To(BeEmpty())
+		})
+	})
+
+	Context("Index func of OSTree repository", func() {
+		It("should create key", func() {
+			// given
+			repository := v1alpha1.OSTreeRepository{
+				Spec: v1alpha1.OSTreeRepositorySpec{
+					OSBuildConfigRef: "config",
+				},
+			}
+
+			// when
+			keys := indexer.RepositoryByConfigIndexFunc(&repository)
+
+			// then
+			Expect(keys).Should(ConsistOf("config"))
+		})
+
+		It("should create no keys without config ref", func() {
+			// when
+			keys := indexer.RepositoryByConfigIndexFunc(&v1alpha1.OSTreeRepository{})
+
+			// then
+			Expect(keys).To(BeEmpty())
+		})
+
+		It("should create no keys for wrong type", func() {
+			// when
+			keys := indexer.RepositoryByConfigIndexFunc(&v1alpha1.OSBuild{})
+
+			// then
+			Expect(keys).To(BeEmpty())
+		})
+	})
+
+	Context("Index func of OSBuild release", func() {
+		It("should create key", func() {
+			// given
+			release := v1alpha1.OSBuildRelease{
+				Spec: v1alpha1.OSBuildReleaseSpec{
+					OSBuildRef: "build",
+				},
+			}
+
+			// when
+			keys := indexer.ReleaseByBuildIndexFunc(&release)
+
+			// then
+			Expect(keys).Should(ConsistOf("build"))
+		})
+
+		It("should create no keys without build ref", func() {
+			// when
+			keys := indexer.ReleaseByBuildIndexFunc(&v1alpha1.OSBuildRelease{})
+
+			// then
+			Expect(keys).To(BeEmpty())
+		})
+
+		It("should create no keys for wrong type", func() {
+			// when
+			keys := indexer.ReleaseByBuildIndexFunc(&v1alpha1.OSBuild{})
+
+			// then
+			Expect(keys).To(BeEmpty())
+		})
+	})
 })
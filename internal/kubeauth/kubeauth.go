@@ -0,0 +1,92 @@
+package kubeauth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	_ "github.com/golang/mock/mockgen/model"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/project-flotta/osbuild-operator/api/v1alpha1"
+)
+
+const (
+	// InstantiateSubresource is the virtual subresource of the OSBuildConfigs whose create verb allows to trigger
+	// their builds, like the buildconfigs/instantiate subresource of OpenShift
+	InstantiateSubresource = "instantiate"
+
+	OSBuildConfigResource = "osbuildconfigs"
+	OSBuildResource       = "osbuilds"
+)
+
+// ErrUnauthenticated is returned when the token is not valid
+var ErrUnauthenticated = errors.New("the token is not authenticated")
+
+//go:generate mockgen -package=kubeauth -destination=mock_kubeauth.go . Authorizer
+
+// Authorizer authorizes the requests bearing Kubernetes tokens, e.g. the tokens of service accounts
+type Authorizer interface {
+	// Authorize authenticates the token with a TokenReview and returns whether its user is allowed the attributes by a
+	// SubjectAccessReview. ErrUnauthenticated is returned when the token is not valid
+	Authorize(ctx context.Context, token string, attributes authorizationv1.ResourceAttributes) (bool, error)
+}
+
+// ReviewAuthorizer creates TokenReviews and SubjectAccessReviews with the client
+type ReviewAuthorizer struct {
+	client client.Client
+}
+
+func NewReviewAuthorizer(client client.Client) *ReviewAuthorizer {
+	return &ReviewAuthorizer{client: client}
+}
+
+func (a *ReviewAuthorizer) Authorize(ctx context.Context, token string, attributes authorizationv1.ResourceAttributes) (bool, error) {
+	tokenReview := &authenticationv1.TokenReview{
+		Spec: authenticationv1.TokenReviewSpec{Token: token},
+	}
+	err := a.client.Create(ctx, tokenReview)
+	if err != nil {
+		return false, fmt.Errorf("failed to review the token: %w", err)
+	}
+	if !tokenReview.Status.Authenticated {
+		if tokenReview.Status.Error != "" {
+			return false, fmt.Errorf("%w: %s", ErrUnauthenticated, tokenReview.Status.Error)
+		}
+		return false, ErrUnauthenticated
+	}
+
+	user := tokenReview.Status.User
+	extra := map[string]authorizationv1.ExtraValue{}
+	for key, value := range user.Extra {
+		extra[key] = authorizationv1.ExtraValue(value)
+	}
+	subjectAccessReview := &authorizationv1.SubjectAccessReview{
+		Spec: authorizationv1.SubjectAccessReviewSpec{
+			ResourceAttributes: &attributes,
+			User:               user.Username,
+			UID:                user.UID,
+			Groups:             user.Groups,
+			Extra:              extra,
+		},
+	}
+	err = a.client.Create(ctx, subjectAccessReview)
+	if err != nil {
+		return false, fmt.Errorf("failed to review the access of %s: %w", user.Username, err)
+	}
+	return subjectAccessReview.Status.Allowed, nil
+}
+
+// ResourceAttributes returns the attributes of the verb on the resource of the API group of the operator
+func ResourceAttributes(verb string, resource string, subresource string, namespace string, name string) authorizationv1.ResourceAttributes {
+	return authorizationv1.ResourceAttributes{
+		Namespace:   namespace,
+		Verb:        verb,
+		Group:       v1alpha1.GroupVersion.Group,
+		Resource:    resource,
+		Subresource: subresource,
+		Name:        name,
+	}
+}
@@ -0,0 +1,13 @@
+package kubeauth_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestKubeAuth(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "KubeAuth Spec")
+}
@@ -0,0 +1,119 @@
+package kubeauth_test
+
+import (
+	"context"
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/project-flotta/osbuild-operator/internal/kubeauth"
+)
+
+const validToken = "valid-token"
+
+// reviewClient answers the reviews like the API server would, allowing the subjects of allowed
+type reviewClient struct {
+	client.Client
+	allowed             map[string]bool
+	subjectAccessReview *authorizationv1.SubjectAccessReview
+	err                 error
+}
+
+func (c *reviewClient) Create(_ context.Context, obj client.Object, _ ...client.CreateOption) error {
+	if c.err != nil {
+		return c.err
+	}
+	switch review := obj.(type) {
+	case *authenticationv1.TokenReview:
+		if review.Spec.Token != validToken {
+			review.Status.Error = "invalid bearer token"
+			return nil
+		}
+		review.Status.Authenticated = true
+		review.Status.User = authenticationv1.UserInfo{
+			Username: "system:serviceaccount:ci:builder",
+			UID:      "8d2f0c3a-1b4e-4c5d-9e6f-7a8b9c0d1e2f",
+			Groups:   []string{"system:serviceaccounts", "system:serviceaccounts:ci"},
+			Extra:    map[string]authenticationv1.ExtraValue{"scopes": {"build"}},
+		}
+	case *authorizationv1.SubjectAccessReview:
+		c.subjectAccessReview = review
+		review.Status.Allowed = c.allowed[review.Spec.User]
+	}
+	return nil
+}
+
+var _ = Describe("ReviewAuthorizer", func() {
+	var (
+		c          *reviewClient
+		authorizer *kubeauth.ReviewAuthorizer
+		attributes authorizationv1.ResourceAttributes
+	)
+
+	BeforeEach(func() {
+		c = &reviewClient{allowed: map[string]bool{}}
+		authorizer = kubeauth.NewReviewAuthorizer(c)
+		attributes = kubeauth.ResourceAttributes("create", kubeauth.OSBuildConfigResource, kubeauth.InstantiateSubresource, "default", "edge-container")
+	})
+
+	It("should allow the user of the token allowed by the SubjectAccessReview", func() {
+		// given
+		c.allowed["system:serviceaccount:ci:builder"] = true
+
+		// when
+		allowed, err := authorizer.Authorize(context.TODO(), validToken, attributes)
+
+		// then
+		Expect(err).ToNot(HaveOccurred())
+		Expect(allowed).To(BeTrue())
+		Expect(c.subjectAccessReview.Spec).To(Equal(authorizationv1.SubjectAccessReviewSpec{
+			ResourceAttributes: &authorizationv1.ResourceAttributes{
+				Namespace:   "default",
+				Verb:        "create",
+				Group:       "osbuilder.project-flotta.io",
+				Resource:    "osbuildconfigs",
+				Subresource: "instantiate",
+				Name:        "edge-container",
+			},
+			User:   "system:serviceaccount:ci:builder",
+			UID:    "8d2f0c3a-1b4e-4c5d-9e6f-7a8b9c0d1e2f",
+			Groups: []string{"system:serviceaccounts", "system:serviceaccounts:ci"},
+			Extra:  map[string]authorizationv1.ExtraValue{"scopes": {"build"}},
+		}))
+	})
+
+	It("should not allow the user of the token denied by the SubjectAccessReview", func() {
+		// when
+		allowed, err := authorizer.Authorize(context.TODO(), validToken, attributes)
+
+		// then
+		Expect(err).ToNot(HaveOccurred())
+		Expect(allowed).To(BeFalse())
+	})
+
+	It("should fail to authenticate an invalid token", func() {
+		// when
+		allowed, err := authorizer.Authorize(context.TODO(), "invalid-token", attributes)
+
+		// then
+		Expect(err).To(MatchError(kubeauth.ErrUnauthenticated))
+		Expect(allowed).To(BeFalse())
+		Expect(c.subjectAccessReview).To(BeNil())
+	})
+
+	It("should fail when the token cannot be reviewed", func() {
+		// given
+		c.err = fmt.Errorf("connection refused")
+
+		// when
+		_, err := authorizer.Authorize(context.TODO(), validToken, attributes)
+
+		// then
+		Expect(err).To(HaveOccurred())
+		Expect(err).ToNot(MatchError(kubeauth.ErrUnauthenticated))
+	})
+})
@@ -25,12 +25,16 @@ import (
 	repositoryosbuild "github.com/project-flotta/osbuild-operator/internal/repository/osbuild"
 	repositoryosbuildconfig "github.com/project-flotta/osbuild-operator/internal/repository/osbuildconfig"
 	"github.com/project-flotta/osbuild-operator/internal/repository/osbuildconfigtemplate"
+	"github.com/project-flotta/osbuild-operator/internal/repository/ostreerepository"
 	"github.com/project-flotta/osbuild-operator/internal/templates"
 )
 
 //go:generate mockgen -package=manifests -source=osbuild.go -destination=mock_osbuildcrcreator.go
 type OSBuildCRCreator interface {
-	Create(ctx context.Context, osBuildConfig *osbuildv1alpha1.OSBuildConfig, targetImageType osbuildv1alpha1.TargetImageType) error
+	// Create creates the next OSBuild of the OSBuildConfig. The webhook call that triggered it, if any, is recorded on
+	// the OSBuild
+	Create(ctx context.Context, osBuildConfig *osbuildv1alpha1.OSBuildConfig, targetImageType osbuildv1alpha1.TargetImageType,
+		trigger *osbuildv1alpha1.WebHookTriggerDetails) error
 }
 
 type OSBuildCreator struct {
@@ -39,6 +43,7 @@ type OSBuildCreator struct {
 	OSBuildConfigTemplateRepository osbuildconfigtemplate.Repository
 	ConfigMapRepository             configmap.Repository
 	OSBuildConfigRepository         repositoryosbuildconfig.Repository
+	OSTreeRepositoryRepository      ostreerepository.Repository
 }
 
 var zero int
@@ -46,17 +51,20 @@ var zero int
 func NewOSBuildCRCreator(osBuildConfigRepository repositoryosbuildconfig.Repository,
 	osBuildRepository repositoryosbuild.Repository, scheme *runtime.Scheme,
 	osBuildConfigTemplateRepository osbuildconfigtemplate.Repository,
-	configMapRepository configmap.Repository) *OSBuildCreator {
+	configMapRepository configmap.Repository,
+	ostreeRepositoryRepository ostreerepository.Repository) *OSBuildCreator {
 	return &OSBuildCreator{
 		Scheme:                          scheme,
 		OSBuildRepository:               osBuildRepository,
 		OSBuildConfigTemplateRepository: osBuildConfigTemplateRepository,
 		ConfigMapRepository:             configMapRepository,
 		OSBuildConfigRepository:         osBuildConfigRepository,
+		OSTreeRepositoryRepository:      ostreeRepositoryRepository,
 	}
 }
 
-func (o *OSBuildCreator) Create(ctx context.Context, osBuildConfig *osbuildv1alpha1.OSBuildConfig, targetImageType osbuildv1alpha1.TargetImageType) error {
+func (o *OSBuildCreator) Create(ctx context.Context, osBuildConfig *osbuildv1alpha1.OSBuildConfig, targetImageType osbuildv1alpha1.TargetImageType,
+	trigger *osbuildv1alpha1.WebHookTriggerDetails) error {
 	logger := log.FromContext(ctx)
 
 	lastVersion := osBuildConfig.Status.LastVersion
@@ -72,11 +80,24 @@ func (o *OSBuildCreator) Create(ctx context.Context, osBuildConfig *osbuildv1alp
 			Namespace: osBuildConfig.Namespace,
 		},
 		Spec: osbuildv1alpha1.OSBuildSpec{
-			TriggeredBy: "UpdateCR",
+			TriggeredBy: osbuildv1alpha1.TriggeredByUpdateCR,
+			UserConfiguration: &osbuildv1alpha1.UserConfiguration{
+				Customizations: osBuildConfig.Spec.Details.Customizations.DeepCopy(),
+				Template:       osBuildConfig.Spec.Template.DeepCopy(),
+			},
+			VulnerabilityScan: osBuildConfig.Spec.VulnerabilityScan.DeepCopy(),
 		},
 	}
 
+	if trigger != nil {
+		setTrigger(osBuild, trigger)
+		if trigger.Overrides != nil {
+			applyOverrides(ctx, osBuild.Spec.UserConfiguration, trigger.Overrides)
+		}
+	}
+
 	osBuildConfigSpecDetails := osBuildConfig.Spec.Details.DeepCopy()
+	osBuildConfigSpecDetails.Customizations = osBuild.Spec.UserConfiguration.Customizations.DeepCopy()
 	err := mergeRepositories(osBuildConfigSpecDetails)
 	if err != nil {
 		logger.Error(err, "failed to merge repositories list")
@@ -89,13 +110,21 @@ func (o *OSBuildCreator) Create(ctx context.Context, osBuildConfig *osbuildv1alp
 		return err
 	}
 
+	if osBuildConfig.Spec.TrackOSTreeHistory != nil && *osBuildConfig.Spec.TrackOSTreeHistory && targetImageType == osbuildv1alpha1.EdgeContainerImageType {
+		err = o.setOSTreeParent(ctx, osBuildConfig, osBuildConfigSpecDetails)
+		if err != nil {
+			logger.Error(err, "cannot set the OSTree parent of osBuild")
+			return err
+		}
+	}
+
 	var kickstartConfigMap *corev1.ConfigMap
 
 	if targetImageType == osbuildv1alpha1.EdgeInstallerImageType {
 		//[ECOPROJECT-917] TODO: build it by two steps and use two OSBuild instances
 		osBuild.Spec.Details = osBuildConfigSpecDetails
 		if osConfigTemplate != nil {
-			kickstartConfigMap, err = o.createKickstartConfigMap(ctx, osBuildConfig, osConfigTemplate, osBuildName, osBuild.Namespace)
+			kickstartConfigMap, err = o.createKickstartConfigMap(ctx, osBuildConfig, osConfigTemplate, osBuild.Spec.UserConfiguration.Template.Parameters, osBuildName, osBuild.Namespace)
 			if err != nil {
 				return err
 			}
@@ -148,6 +177,84 @@ func (o *OSBuildCreator) Create(ctx context.Context, osBuildConfig *osbuildv1alp
 	return nil
 }
 
+// applyOverrides sets the parameters and adds the packages of the webhook call to the user configuration of the
+// OSBuild. The parameters are ignored without a template, as the trigger API only accepts them with one
+func applyOverrides(ctx context.Context, userConfiguration *osbuildv1alpha1.UserConfiguration, overrides *osbuildv1alpha1.BuildOverrides) {
+	logger := log.FromContext(ctx)
+
+	if len(overrides.Parameters) > 0 {
+		if userConfiguration.Template == nil {
+			logger.Info("the parameters of the webhook call are ignored without a template")
+		} else {
+			userConfiguration.Template.Parameters = overrideParameters(userConfiguration.Template.Parameters, overrides.Parameters)
+		}
+	}
+
+	if len(overrides.Packages) > 0 {
+		if userConfiguration.Customizations == nil {
+			userConfiguration.Customizations = &osbuildv1alpha1.Customizations{}
+		}
+		for _, pkg := range overrides.Packages {
+			if !containsString(userConfiguration.Customizations.Packages, pkg) {
+				userConfiguration.Customizations.Packages = append(userConfiguration.Customizations.Packages, pkg)
+			}
+		}
+	}
+}
+
+func overrideParameters(parameters []osbuildv1alpha1.ParameterValue, overrides []osbuildv1alpha1.ParameterValue) []osbuildv1alpha1.ParameterValue {
+	for _, override := range overrides {
+		overridden := false
+		for i := range parameters {
+			if parameters[i].Name == override.Name {
+				parameters[i].Value = override.Value
+				overridden = true
+			}
+		}
+		if !overridden {
+			parameters = append(parameters, override)
+		}
+	}
+	return parameters
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// setTrigger records the webhook call that triggered the OSBuild
+func setTrigger(osBuild *osbuildv1alpha1.OSBuild, trigger *osbuildv1alpha1.WebHookTriggerDetails) {
+	osBuild.Spec.TriggeredBy = trigger.TriggeredBy
+	annotations := map[string]string{
+		osbuildv1alpha1.TriggerIDAnnotationKey:    trigger.ID,
+		osbuildv1alpha1.CommitAnnotationKey:       trigger.Commit,
+		osbuildv1alpha1.CommitAuthorAnnotationKey: trigger.Author,
+		osbuildv1alpha1.RefAnnotationKey:          trigger.Ref,
+	}
+	for key, value := range annotations {
+		if value == "" {
+			continue
+		}
+		if osBuild.Annotations == nil {
+			osBuild.Annotations = map[string]string{}
+		}
+		osBuild.Annotations[key] = value
+	}
+	if trigger.Overrides != nil {
+		if osBuild.Annotations == nil {
+			osBuild.Annotations = map[string]string{}
+		}
+		// the overrides are plain strings, marshaling them cannot fail
+		data, _ := json.Marshal(trigger.Overrides)
+		osBuild.Annotations[osbuildv1alpha1.BuildOverridesAnnotationKey] = string(data)
+	}
+}
+
 func mergeRepositories(osBuildConfigSpecDetails *osbuildv1alpha1.BuildDetails) error {
 	repos, err := getDefaultRepositories(osBuildConfigSpecDetails.Distribution, osBuildConfigSpecDetails.TargetImage.Architecture)
 	if err != nil {
@@ -162,6 +269,23 @@ func mergeRepositories(osBuildConfigSpecDetails *osbuildv1alpha1.BuildDetails) e
 	return nil
 }
 
+// GetRepositories returns the repositories the OSBuilds of the build details use: the default repositories of the
+// distribution and architecture, the repositories of the target image and the payload repositories
+func GetRepositories(details *osbuildv1alpha1.BuildDetails) ([]osbuildv1alpha1.Repository, error) {
+	repos, err := getDefaultRepositories(details.Distribution, details.TargetImage.Architecture)
+	if err != nil {
+		return nil, err
+	}
+
+	if details.TargetImage.Repositories != nil {
+		repos = append(repos, *details.TargetImage.Repositories...)
+	}
+	if details.Customizations != nil {
+		repos = append(repos, details.Customizations.PayloadRepositories...)
+	}
+	return repos, nil
+}
+
 func getDefaultRepositories(distribution string, arch osbuildv1alpha1.Architecture) ([]osbuildv1alpha1.Repository, error) {
 	reposJsonPath := path.Join(conf.GlobalConf.RepositoriesDir, fmt.Sprintf("%s.json", distribution))
 
@@ -201,6 +325,70 @@ func (o *OSBuildCreator) applyTemplate(ctx context.Context, osBuildConfig *osbui
 	return osConfigTemplate, nil
 }
 
+// setOSTreeParent chains the new build to the last Ready edge-container OSBuild of the OSBuildConfig, using its commit
+// or, when the commit is unknown, its OSTree ref. A parent set by the user is kept
+func (o *OSBuildCreator) setOSTreeParent(ctx context.Context, osBuildConfig *osbuildv1alpha1.OSBuildConfig, osBuildConfigSpecDetails *osbuildv1alpha1.BuildDetails) error {
+	logger := log.FromContext(ctx)
+
+	osTree := osBuildConfigSpecDetails.TargetImage.OSTree
+	if osTree == nil || osTree.Url == nil {
+		logger.Info("cannot chain OSTree commits without the url of the OSTree repository")
+		return nil
+	}
+	if osTree.Parent != nil && *osTree.Parent != "" {
+		logger.Info("keeping the OSTree parent set by the user", "parent", *osTree.Parent)
+		return nil
+	}
+
+	osBuilds, err := o.OSBuildRepository.ListByOSBuildConfig(ctx, osBuildConfig.Name, osBuildConfig.Namespace)
+	if err != nil {
+		return err
+	}
+
+	lastReadyOSBuild := getLastReadyOSBuild(osBuilds, osbuildv1alpha1.EdgeContainerImageType)
+	if lastReadyOSBuild == nil {
+		logger.Info("no Ready edge-container OSBuild to use as OSTree parent")
+		return nil
+	}
+
+	parent := lastReadyOSBuild.Status.OSTreeCommit
+	if parent == "" {
+		lastOSTree := lastReadyOSBuild.Spec.Details.TargetImage.OSTree
+		if lastOSTree == nil || lastOSTree.Ref == nil {
+			logger.Info("the last Ready OSBuild has neither an OSTree commit nor a ref", "OSBuild", lastReadyOSBuild.Name)
+			return nil
+		}
+		parent = *lastOSTree.Ref
+	}
+
+	osTree.Parent = &parent
+	return nil
+}
+
+// getLastReadyOSBuild returns the last Ready OSBuild of the target image type
+func getLastReadyOSBuild(osBuilds []osbuildv1alpha1.OSBuild, targetImageType osbuildv1alpha1.TargetImageType) *osbuildv1alpha1.OSBuild {
+	var lastReadyOSBuild *osbuildv1alpha1.OSBuild
+	for i := range osBuilds {
+		if osBuilds[i].Spec.Details == nil || osBuilds[i].Spec.Details.TargetImage.TargetImageType != targetImageType ||
+			!isOSBuildReady(&osBuilds[i]) {
+			continue
+		}
+		if lastReadyOSBuild == nil || lastReadyOSBuild.CreationTimestamp.Before(&osBuilds[i].CreationTimestamp) {
+			lastReadyOSBuild = &osBuilds[i]
+		}
+	}
+	return lastReadyOSBuild
+}
+
+func isOSBuildReady(osBuild *osbuildv1alpha1.OSBuild) bool {
+	for _, condition := range osBuild.Status.Conditions {
+		if condition.Type == osbuildv1alpha1.ConditionReady {
+			return condition.Status == metav1.ConditionTrue
+		}
+	}
+	return false
+}
+
 func (o *OSBuildCreator) setKickstartConfigMapOwner(ctx context.Context, kickstartConfigMap *corev1.ConfigMap, osBuild *osbuildv1alpha1.OSBuild) error {
 	oldConfigMap := kickstartConfigMap.DeepCopy()
 	err := controllerutil.SetOwnerReference(osBuild, kickstartConfigMap, o.Scheme)
@@ -210,8 +398,9 @@ func (o *OSBuildCreator) setKickstartConfigMapOwner(ctx context.Context, kicksta
 	return o.ConfigMapRepository.Patch(ctx, oldConfigMap, kickstartConfigMap)
 }
 
-func (o *OSBuildCreator) createKickstartConfigMap(ctx context.Context, osBuildConfig *osbuildv1alpha1.OSBuildConfig, osConfigTemplate *osbuildv1alpha1.OSBuildConfigTemplate, name, namespace string) (*corev1.ConfigMap, error) {
-	kickstart, err := o.getKickstart(ctx, osConfigTemplate, osBuildConfig)
+func (o *OSBuildCreator) createKickstartConfigMap(ctx context.Context, osBuildConfig *osbuildv1alpha1.OSBuildConfig, osConfigTemplate *osbuildv1alpha1.OSBuildConfigTemplate,
+	parameters []osbuildv1alpha1.ParameterValue, name, namespace string) (*corev1.ConfigMap, error) {
+	kickstart, err := o.getKickstart(ctx, osConfigTemplate, osBuildConfig, parameters)
 	if err != nil {
 		return nil, err
 	}
@@ -246,7 +435,8 @@ func (o *OSBuildCreator) createKickstartConfigMap(ctx context.Context, osBuildCo
 	return cm, nil
 }
 
-func (o *OSBuildCreator) getKickstart(ctx context.Context, osConfigTemplate *osbuildv1alpha1.OSBuildConfigTemplate, osBuildConfig *osbuildv1alpha1.OSBuildConfig) (*string, error) {
+func (o *OSBuildCreator) getKickstart(ctx context.Context, osConfigTemplate *osbuildv1alpha1.OSBuildConfigTemplate, osBuildConfig *osbuildv1alpha1.OSBuildConfig,
+	parameters []osbuildv1alpha1.ParameterValue) (*string, error) {
 	if osConfigTemplate.Spec.Iso == nil || osConfigTemplate.Spec.Iso.Kickstart == nil {
 		return nil, nil
 	}
@@ -268,9 +458,44 @@ func (o *OSBuildCreator) getKickstart(ctx context.Context, osConfigTemplate *osb
 		}
 	}
 
-	finalKickstart, err := templates.ProcessOSBuildConfigTemplate(kickstartTemplate, osConfigTemplate.Spec.Parameters, osBuildConfig.Spec.Template.Parameters)
+	builtInValues, err := o.getOSTreeRepositoryValues(ctx, osBuildConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	finalKickstart, err := templates.ProcessOSBuildConfigTemplate(kickstartTemplate, osConfigTemplate.Spec.Parameters, parameters, builtInValues)
 	if err != nil {
 		return nil, err
 	}
 	return &finalKickstart, nil
 }
+
+// getOSTreeRepositoryValues returns the URLs of the OSTreeRepository hosting the commits of the OSBuildConfig, so the
+// kickstart can install from the repository and import its GPG key. The first repository by name is used when several
+// host the commits. The values are empty when no repository hosts them yet
+func (o *OSBuildCreator) getOSTreeRepositoryValues(ctx context.Context, osBuildConfig *osbuildv1alpha1.OSBuildConfig) (map[string]string, error) {
+	ostreeRepositories, err := o.OSTreeRepositoryRepository.ListByOSBuildConfig(ctx, osBuildConfig.Name, osBuildConfig.Namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	var ostreeRepository *osbuildv1alpha1.OSTreeRepository
+	for i := range ostreeRepositories {
+		if ostreeRepositories[i].Status.Url == "" {
+			continue
+		}
+		if ostreeRepository == nil || ostreeRepositories[i].Name < ostreeRepository.Name {
+			ostreeRepository = &ostreeRepositories[i]
+		}
+	}
+
+	values := map[string]string{
+		templates.OSTreeRepoUrlValue:   "",
+		templates.OSTreeGPGKeyUrlValue: "",
+	}
+	if ostreeRepository != nil {
+		values[templates.OSTreeRepoUrlValue] = ostreeRepository.Status.Url
+		values[templates.OSTreeGPGKeyUrlValue] = ostreeRepository.Status.GPGKeyUrl
+	}
+	return values, nil
+}
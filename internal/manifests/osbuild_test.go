@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	. "github.com/onsi/ginkgo/v2"
@@ -24,6 +25,7 @@ import (
 	"github.com/project-flotta/osbuild-operator/internal/repository/osbuild"
 	"github.com/project-flotta/osbuild-operator/internal/repository/osbuildconfig"
 	"github.com/project-flotta/osbuild-operator/internal/repository/osbuildconfigtemplate"
+	"github.com/project-flotta/osbuild-operator/internal/repository/ostreerepository"
 	"github.com/project-flotta/osbuild-operator/tests/matchers"
 )
 
@@ -43,6 +45,7 @@ var _ = Describe("OSBuild creation", func() {
 		mockCtrl                        *gomock.Controller
 		osBuildConfigRepository         *osbuildconfig.MockRepository
 		configMapRepository             *configmap.MockRepository
+		ostreeRepositoryRepository      *ostreerepository.MockRepository
 		osBuildConfigTemplateRepository *osbuildconfigtemplate.MockRepository
 		osBuildRepository               *osbuild.MockRepository
 		scheme                          *runtime.Scheme
@@ -97,6 +100,9 @@ var _ = Describe("OSBuild creation", func() {
 			Spec: v1alpha1.OSBuildSpec{
 				Details:     expectedOSBuildSpecDetails,
 				TriggeredBy: "UpdateCR",
+				UserConfiguration: &v1alpha1.UserConfiguration{
+					Customizations: osBuildConfig.Spec.Details.Customizations.DeepCopy(),
+				},
 			},
 		}
 		err = controllerutil.SetControllerReference(&osBuildConfig, &expectedOSBuild, scheme)
@@ -107,6 +113,7 @@ var _ = Describe("OSBuild creation", func() {
 		osBuildConfigTemplateRepository = osbuildconfigtemplate.NewMockRepository(mockCtrl)
 		osBuildConfigRepository = osbuildconfig.NewMockRepository(mockCtrl)
 		configMapRepository = configmap.NewMockRepository(mockCtrl)
+		ostreeRepositoryRepository = ostreerepository.NewMockRepository(mockCtrl)
 
 		creator = manifests.NewOSBuildCRCreator(
 			osBuildConfigRepository,
@@ -114,6 +121,7 @@ var _ = Describe("OSBuild creation", func() {
 			scheme,
 			osBuildConfigTemplateRepository,
 			configMapRepository,
+			ostreeRepositoryRepository,
 		)
 	})
 
@@ -132,7 +140,7 @@ var _ = Describe("OSBuild creation", func() {
 			osBuildRepository.EXPECT().Create(ctx, &expectedOSBuild)
 
 			// when
-			err := creator.Create(ctx, &osBuildConfig, v1alpha1.EdgeContainerImageType)
+			err := creator.Create(ctx, &osBuildConfig, v1alpha1.EdgeContainerImageType, nil)
 
 			//then
 			Expect(err).ToNot(HaveOccurred())
@@ -153,7 +161,36 @@ var _ = Describe("OSBuild creation", func() {
 			osBuildRepository.EXPECT().Create(ctx, &expectedOSBuild)
 
 			// when
-			err := creator.Create(ctx, &osBuildConfig, v1alpha1.EdgeContainerImageType)
+			err := creator.Create(ctx, &osBuildConfig, v1alpha1.EdgeContainerImageType, nil)
+
+			//then
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("should record the webhook call that triggered the OSBuild", func() {
+			// given
+			cp := osBuildConfig.DeepCopy()
+			one := 1
+			cp.Status.LastVersion = &one
+			osBuildConfigRepository.EXPECT().PatchStatus(ctx, cp, gomock.Any())
+
+			expectedOSBuild.Spec.TriggeredBy = v1alpha1.TriggeredByGitHub
+			expectedOSBuild.Annotations = map[string]string{
+				v1alpha1.TriggerIDAnnotationKey:    "6b9f1c2e-5d4a-4b8e-9f3c-2a1d0e7b6c5f",
+				v1alpha1.CommitAnnotationKey:       "5f6a7b8c",
+				v1alpha1.CommitAuthorAnnotationKey: "Jane Doe",
+				v1alpha1.RefAnnotationKey:          "refs/heads/main",
+			}
+			osBuildRepository.EXPECT().Create(ctx, &expectedOSBuild)
+
+			// when
+			err := creator.Create(ctx, &osBuildConfig, v1alpha1.EdgeContainerImageType, &v1alpha1.WebHookTriggerDetails{
+				ID:          "6b9f1c2e-5d4a-4b8e-9f3c-2a1d0e7b6c5f",
+				TriggeredBy: v1alpha1.TriggeredByGitHub,
+				Commit:      "5f6a7b8c",
+				Author:      "Jane Doe",
+				Ref:         "refs/heads/main",
+			})
 
 			//then
 			Expect(err).ToNot(HaveOccurred())
@@ -167,7 +204,7 @@ var _ = Describe("OSBuild creation", func() {
 			osBuildConfigRepository.EXPECT().PatchStatus(ctx, cp, gomock.Any()).Return(fmt.Errorf("boom"))
 
 			// when
-			err := creator.Create(ctx, &osBuildConfig, v1alpha1.EdgeContainerImageType)
+			err := creator.Create(ctx, &osBuildConfig, v1alpha1.EdgeContainerImageType, nil)
 
 			//then
 			Expect(err).To(HaveOccurred())
@@ -180,13 +217,145 @@ var _ = Describe("OSBuild creation", func() {
 			osBuildRepository.EXPECT().Create(ctx, &expectedOSBuild).Return(fmt.Errorf("boom"))
 
 			// when
-			err := creator.Create(ctx, &osBuildConfig, v1alpha1.EdgeContainerImageType)
+			err := creator.Create(ctx, &osBuildConfig, v1alpha1.EdgeContainerImageType, nil)
 
 			//then
 			Expect(err).To(HaveOccurred())
 		})
 	})
 
+	Context("with OSTree history tracking", func() {
+		var (
+			osTreeUrl = "http://ostree-repo/repo"
+			osTreeRef = "rhel/8/x86_64/edge"
+			commit    = "02604b2da6e954bd34b8b82a835e5a77d2b60ffa"
+			track     = true
+		)
+
+		previousOSBuild := func(version int, ready bool, osTreeCommit string) v1alpha1.OSBuild {
+			status := metav1.ConditionFalse
+			if ready {
+				status = metav1.ConditionTrue
+			}
+			return v1alpha1.OSBuild{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              configName(OSBuildConfigName, version),
+					CreationTimestamp: metav1.NewTime(time.Unix(int64(version), 0)),
+				},
+				Spec: v1alpha1.OSBuildSpec{
+					Details: &v1alpha1.BuildDetails{
+						TargetImage: v1alpha1.TargetImage{
+							TargetImageType: v1alpha1.EdgeContainerImageType,
+							OSTree:          &v1alpha1.OSTreeConfig{Ref: &osTreeRef},
+						},
+					},
+				},
+				Status: v1alpha1.OSBuildStatus{
+					Conditions:   []v1alpha1.Condition{{Type: v1alpha1.ConditionReady, Status: status}},
+					OSTreeCommit: osTreeCommit,
+				},
+			}
+		}
+
+		BeforeEach(func() {
+			osBuildConfig.Spec.TrackOSTreeHistory = &track
+			osBuildConfig.Spec.Details.TargetImage.OSTree = &v1alpha1.OSTreeConfig{Url: &osTreeUrl, Ref: &osTreeRef}
+			expectedOSBuild.Spec.Details.TargetImage.OSTree = osBuildConfig.Spec.Details.TargetImage.OSTree.DeepCopy()
+			osBuildConfigRepository.EXPECT().PatchStatus(ctx, gomock.Any(), gomock.Any())
+		})
+
+		It("should set the parent to the commit of the last Ready OSBuild", func() {
+			// given
+			osBuildRepository.EXPECT().ListByOSBuildConfig(ctx, OSBuildConfigName, osBuildConfig.Namespace).Return([]v1alpha1.OSBuild{
+				previousOSBuild(1, true, "old-commit"),
+				previousOSBuild(3, false, ""),
+				previousOSBuild(2, true, commit),
+			}, nil)
+			expectedOSBuild.Spec.Details.TargetImage.OSTree.Parent = &commit
+			osBuildRepository.EXPECT().Create(ctx, &expectedOSBuild)
+
+			// when
+			err := creator.Create(ctx, &osBuildConfig, v1alpha1.EdgeContainerImageType, nil)
+
+			//then
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("should ignore the Ready OSBuilds of other target image types", func() {
+			// given
+			guestImage := previousOSBuild(3, true, "")
+			guestImage.Spec.Details.TargetImage.TargetImageType = v1alpha1.GuestImageImageType
+			osBuildRepository.EXPECT().ListByOSBuildConfig(ctx, OSBuildConfigName, osBuildConfig.Namespace).Return([]v1alpha1.OSBuild{
+				previousOSBuild(2, true, commit),
+				guestImage,
+			}, nil)
+			expectedOSBuild.Spec.Details.TargetImage.OSTree.Parent = &commit
+			osBuildRepository.EXPECT().Create(ctx, &expectedOSBuild)
+
+			// when
+			err := creator.Create(ctx, &osBuildConfig, v1alpha1.EdgeContainerImageType, nil)
+
+			//then
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("should keep the parent set by the user", func() {
+			// given
+			userParent := "user-commit"
+			osBuildConfig.Spec.Details.TargetImage.OSTree.Parent = &userParent
+			expectedOSBuild.Spec.Details.TargetImage.OSTree.Parent = &userParent
+			osBuildRepository.EXPECT().Create(ctx, &expectedOSBuild)
+
+			// when
+			err := creator.Create(ctx, &osBuildConfig, v1alpha1.EdgeContainerImageType, nil)
+
+			//then
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("should set the parent to the ref of the last Ready OSBuild when its commit is unknown", func() {
+			// given
+			osBuildRepository.EXPECT().ListByOSBuildConfig(ctx, OSBuildConfigName, osBuildConfig.Namespace).Return([]v1alpha1.OSBuild{
+				previousOSBuild(1, true, ""),
+			}, nil)
+			expectedOSBuild.Spec.Details.TargetImage.OSTree.Parent = &osTreeRef
+			osBuildRepository.EXPECT().Create(ctx, &expectedOSBuild)
+
+			// when
+			err := creator.Create(ctx, &osBuildConfig, v1alpha1.EdgeContainerImageType, nil)
+
+			//then
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("should not set the parent when there is no Ready OSBuild", func() {
+			// given
+			osBuildRepository.EXPECT().ListByOSBuildConfig(ctx, OSBuildConfigName, osBuildConfig.Namespace).Return([]v1alpha1.OSBuild{
+				previousOSBuild(1, false, ""),
+			}, nil)
+			osBuildRepository.EXPECT().Create(ctx, &expectedOSBuild)
+
+			// when
+			err := creator.Create(ctx, &osBuildConfig, v1alpha1.EdgeContainerImageType, nil)
+
+			//then
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("should not set the parent without the url of the OSTree repository", func() {
+			// given
+			osBuildConfig.Spec.Details.TargetImage.OSTree.Url = nil
+			expectedOSBuild.Spec.Details.TargetImage.OSTree.Url = nil
+			osBuildRepository.EXPECT().Create(ctx, &expectedOSBuild)
+
+			// when
+			err := creator.Create(ctx, &osBuildConfig, v1alpha1.EdgeContainerImageType, nil)
+
+			//then
+			Expect(err).ToNot(HaveOccurred())
+		})
+	})
+
 	Context("with template", func() {
 		const (
 			templateName = "template-name"
@@ -228,6 +397,7 @@ var _ = Describe("OSBuild creation", func() {
 				Disabled: append(templateCustomizations.Services.Disabled, configCustomizations.Services.Disabled...),
 			}
 			expectedOSBuild.Spec.Details.Customizations = &expectedCustomizations
+			expectedOSBuild.Spec.UserConfiguration.Template = osBuildConfig.Spec.Template.DeepCopy()
 
 		})
 		It("should fail when template not found", func() {
@@ -236,7 +406,7 @@ var _ = Describe("OSBuild creation", func() {
 				Return(nil, errors.NewNotFound(schema.GroupResource{}, templateName))
 
 			// when
-			err := creator.Create(ctx, &osBuildConfig, v1alpha1.EdgeContainerImageType)
+			err := creator.Create(ctx, &osBuildConfig, v1alpha1.EdgeContainerImageType, nil)
 
 			//then
 			Expect(err).To(HaveOccurred())
@@ -244,10 +414,17 @@ var _ = Describe("OSBuild creation", func() {
 
 		Context("with edge-installer image type", func() {
 			var (
-				kickstartTxt = "kickstart-raw"
-				kickstartMap corev1.ConfigMap
+				kickstartTxt       = "kickstart-raw"
+				kickstartMap       corev1.ConfigMap
+				ostreeRepositories []v1alpha1.OSTreeRepository
 			)
 			BeforeEach(func() {
+				ostreeRepositories = nil
+				ostreeRepositoryRepository.EXPECT().ListByOSBuildConfig(ctx, OSBuildConfigName, osBuildConfig.Namespace).
+					DoAndReturn(func(context.Context, string, string) ([]v1alpha1.OSTreeRepository, error) {
+						return ostreeRepositories, nil
+					}).AnyTimes()
+
 				osBuildConfig.Spec.Details.TargetImage.TargetImageType = v1alpha1.EdgeInstallerImageType
 				expectedOSBuild.Spec.Details.TargetImage.TargetImageType = v1alpha1.EdgeInstallerImageType
 
@@ -274,7 +451,7 @@ var _ = Describe("OSBuild creation", func() {
 				osBuildRepository.EXPECT().Create(ctx, matchers.NewOSBuildMatcher(&expectedOSBuild))
 
 				// when
-				err := creator.Create(ctx, &osBuildConfig, v1alpha1.EdgeInstallerImageType)
+				err := creator.Create(ctx, &osBuildConfig, v1alpha1.EdgeInstallerImageType, nil)
 
 				//then
 				Expect(err).ToNot(HaveOccurred())
@@ -314,7 +491,7 @@ var _ = Describe("OSBuild creation", func() {
 				osBuildRepository.EXPECT().Create(ctx, matchers.NewOSBuildMatcher(&expectedOSBuild))
 
 				// when
-				err := creator.Create(ctx, &osBuildConfig, v1alpha1.EdgeInstallerImageType)
+				err := creator.Create(ctx, &osBuildConfig, v1alpha1.EdgeInstallerImageType, nil)
 
 				//then
 				Expect(err).ToNot(HaveOccurred())
@@ -349,10 +526,118 @@ var _ = Describe("OSBuild creation", func() {
 				osBuildRepository.EXPECT().Create(ctx, matchers.NewOSBuildMatcher(&expectedOSBuild))
 
 				// when
-				err := creator.Create(ctx, &osBuildConfig, v1alpha1.EdgeInstallerImageType)
+				err := creator.Create(ctx, &osBuildConfig, v1alpha1.EdgeInstallerImageType, nil)
+
+				//then
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			It("should create with the OSTree repository urls in the kickstart", func() {
+				// given
+				kickstartTmpl := "ostreesetup --url={{.OSTreeRepoUrl}}; curl {{.OSTreeGPGKeyUrl}}"
+				template.Spec.Iso = &v1alpha1.IsoConfiguration{
+					Kickstart: &v1alpha1.KickstartFile{
+						Raw: &kickstartTmpl,
+					},
+				}
+				osBuildConfigTemplateRepository.EXPECT().Read(ctx, templateName, osBuildConfig.Namespace).Return(&template, nil)
+				ostreeRepositories = []v1alpha1.OSTreeRepository{
+					{ObjectMeta: metav1.ObjectMeta{Name: "repo-b"}, Status: v1alpha1.OSTreeRepositoryStatus{Url: "https://repo-b/", GPGKeyUrl: "https://repo-b/key.gpg"}},
+					{ObjectMeta: metav1.ObjectMeta{Name: "repo-a"}, Status: v1alpha1.OSTreeRepositoryStatus{Url: "https://repo-a/", GPGKeyUrl: "https://repo-a/key.gpg"}},
+					{ObjectMeta: metav1.ObjectMeta{Name: "repo-0"}},
+				}
+
+				// Kickstart ConfigMap doesn't exist
+				configMapRepository.EXPECT().Read(ctx, kickstartMap.Name, osBuildConfig.Namespace).
+					Return(nil, errors.NewNotFound(schema.GroupResource{}, templateName))
+
+				kickstartMap.Data["kickstart"] = "ostreesetup --url=https://repo-a/; curl https://repo-a/key.gpg"
+				configMapRepository.EXPECT().Create(ctx, &kickstartMap)
+
+				configMapRepository.EXPECT().Patch(ctx, &kickstartMap, gomock.Any())
+
+				osBuildConfigRepository.EXPECT().PatchStatus(ctx, gomock.Any(), gomock.Any())
+				osBuildRepository.EXPECT().Create(ctx, matchers.NewOSBuildMatcher(&expectedOSBuild))
+
+				// when
+				err := creator.Create(ctx, &osBuildConfig, v1alpha1.EdgeInstallerImageType, nil)
+
+				//then
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			It("should create with empty OSTree repository urls in the kickstart when no repository hosts the commits", func() {
+				// given
+				kickstartTmpl := "ostreesetup --url={{.OSTreeRepoUrl}}; curl {{.OSTreeGPGKeyUrl}}"
+				template.Spec.Iso = &v1alpha1.IsoConfiguration{
+					Kickstart: &v1alpha1.KickstartFile{
+						Raw: &kickstartTmpl,
+					},
+				}
+				osBuildConfigTemplateRepository.EXPECT().Read(ctx, templateName, osBuildConfig.Namespace).Return(&template, nil)
+				ostreeRepositories = []v1alpha1.OSTreeRepository{{ObjectMeta: metav1.ObjectMeta{Name: "repo-0"}}}
+
+				configMapRepository.EXPECT().Read(ctx, kickstartMap.Name, osBuildConfig.Namespace).
+					Return(nil, errors.NewNotFound(schema.GroupResource{}, templateName))
+
+				kickstartMap.Data["kickstart"] = "ostreesetup --url=; curl "
+				configMapRepository.EXPECT().Create(ctx, &kickstartMap)
+
+				configMapRepository.EXPECT().Patch(ctx, &kickstartMap, gomock.Any())
+
+				osBuildConfigRepository.EXPECT().PatchStatus(ctx, gomock.Any(), gomock.Any())
+				osBuildRepository.EXPECT().Create(ctx, matchers.NewOSBuildMatcher(&expectedOSBuild))
+
+				// when
+				err := creator.Create(ctx, &osBuildConfig, v1alpha1.EdgeInstallerImageType, nil)
+
+				//then
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			It("should create with the overrides of the webhook call", func() {
+				// given
+				kickstartTmpl := "agent {{.version}}"
+				template.Spec.Iso = &v1alpha1.IsoConfiguration{
+					Kickstart: &v1alpha1.KickstartFile{
+						Raw: &kickstartTmpl,
+					},
+				}
+				template.Spec.Parameters = []v1alpha1.Parameter{{Name: "version", Type: "string"}}
+				osBuildConfig.Spec.Template.Parameters = []v1alpha1.ParameterValue{{Name: "version", Value: "1.0.0"}}
+				osBuildConfigTemplateRepository.EXPECT().Read(ctx, templateName, osBuildConfig.Namespace).Return(&template, nil)
+
+				configMapRepository.EXPECT().Read(ctx, kickstartMap.Name, osBuildConfig.Namespace).
+					Return(nil, errors.NewNotFound(schema.GroupResource{}, templateName))
+				kickstartMap.Data["kickstart"] = "agent 1.2.3"
+				configMapRepository.EXPECT().Create(ctx, &kickstartMap)
+				configMapRepository.EXPECT().Patch(ctx, &kickstartMap, gomock.Any())
+				osBuildConfigRepository.EXPECT().PatchStatus(ctx, gomock.Any(), gomock.Any())
+
+				expectedOSBuild.Spec.TriggeredBy = v1alpha1.TriggeredByWebhook
+				expectedOSBuild.Annotations = map[string]string{
+					v1alpha1.BuildOverridesAnnotationKey: `{"parameters":[{"name":"version","value":"1.2.3"}],"packages":["flotta-agent","a"]}`,
+				}
+				expectedCustomizations.Packages = append(expectedCustomizations.Packages, "flotta-agent")
+				var createdOSBuild *v1alpha1.OSBuild
+				osBuildRepository.EXPECT().Create(ctx, matchers.NewOSBuildMatcher(&expectedOSBuild)).
+					Do(func(_ context.Context, osBuild *v1alpha1.OSBuild) { createdOSBuild = osBuild })
+
+				// when
+				err := creator.Create(ctx, &osBuildConfig, v1alpha1.EdgeInstallerImageType, &v1alpha1.WebHookTriggerDetails{
+					TriggeredBy: v1alpha1.TriggeredByWebhook,
+					Overrides: &v1alpha1.BuildOverrides{
+						Parameters: []v1alpha1.ParameterValue{{Name: "version", Value: "1.2.3"}},
+						Packages:   []string{"flotta-agent", "a"},
+					},
+				})
 
 				//then
 				Expect(err).ToNot(HaveOccurred())
+				Expect(createdOSBuild.Spec.UserConfiguration.Template.Parameters).To(Equal([]v1alpha1.ParameterValue{{Name: "version", Value: "1.2.3"}}))
+				Expect(createdOSBuild.Spec.UserConfiguration.Customizations.Packages).To(Equal([]string{"a", "b", "flotta-agent"}))
+				Expect(osBuildConfig.Spec.Template.Parameters).To(Equal([]v1alpha1.ParameterValue{{Name: "version", Value: "1.0.0"}}))
+				Expect(osBuildConfig.Spec.Details.Customizations.Packages).To(Equal([]string{"a", "b"}))
 			})
 
 			It("should create with ConfigMap kickstart", func() {
@@ -397,7 +682,7 @@ var _ = Describe("OSBuild creation", func() {
 				osBuildRepository.EXPECT().Create(ctx, matchers.NewOSBuildMatcher(&expectedOSBuild))
 
 				// when
-				err := creator.Create(ctx, &osBuildConfig, v1alpha1.EdgeInstallerImageType)
+				err := creator.Create(ctx, &osBuildConfig, v1alpha1.EdgeInstallerImageType, nil)
 
 				//then
 				Expect(err).ToNot(HaveOccurred())
@@ -417,7 +702,7 @@ var _ = Describe("OSBuild creation", func() {
 					Return(nil, fmt.Errorf("boom"))
 
 				// when
-				err := creator.Create(ctx, &osBuildConfig, v1alpha1.EdgeInstallerImageType)
+				err := creator.Create(ctx, &osBuildConfig, v1alpha1.EdgeInstallerImageType, nil)
 
 				//then
 				Expect(err).To(HaveOccurred())
@@ -439,7 +724,7 @@ var _ = Describe("OSBuild creation", func() {
 				configMapRepository.EXPECT().Create(ctx, &kickstartMap).Return(fmt.Errorf("boom"))
 
 				// when
-				err := creator.Create(ctx, &osBuildConfig, v1alpha1.EdgeInstallerImageType)
+				err := creator.Create(ctx, &osBuildConfig, v1alpha1.EdgeInstallerImageType, nil)
 
 				//then
 				Expect(err).To(HaveOccurred())
@@ -474,7 +759,7 @@ var _ = Describe("OSBuild creation", func() {
 				osBuildConfigRepository.EXPECT().PatchStatus(ctx, matchers.NewOSBuildConfigStatusMatcher(cp), gomock.Any())
 
 				// when
-				err := creator.Create(ctx, &osBuildConfig, v1alpha1.EdgeInstallerImageType)
+				err := creator.Create(ctx, &osBuildConfig, v1alpha1.EdgeInstallerImageType, nil)
 
 				//then
 				Expect(err).To(HaveOccurred())
@@ -496,7 +781,7 @@ var _ = Describe("OSBuild creation", func() {
 					Return(nil, fmt.Errorf("boom"))
 
 				// when
-				err := creator.Create(ctx, &osBuildConfig, v1alpha1.EdgeInstallerImageType)
+				err := creator.Create(ctx, &osBuildConfig, v1alpha1.EdgeInstallerImageType, nil)
 
 				//then
 				Expect(err).To(HaveOccurred())
@@ -523,7 +808,7 @@ var _ = Describe("OSBuild creation", func() {
 					Return(&kickstartTemplateCM, nil)
 
 				// when
-				err := creator.Create(ctx, &osBuildConfig, v1alpha1.EdgeInstallerImageType)
+				err := creator.Create(ctx, &osBuildConfig, v1alpha1.EdgeInstallerImageType, nil)
 
 				//then
 				Expect(err).To(HaveOccurred())
@@ -0,0 +1,319 @@
+package osbuildconfig
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/project-flotta/osbuild-operator/api/v1alpha1"
+	"github.com/project-flotta/osbuild-operator/internal/httpapi"
+	loggerutil "github.com/project-flotta/osbuild-operator/internal/logger"
+	"github.com/project-flotta/osbuild-operator/restapi"
+)
+
+const (
+	// maxPayloadSize is the size GitHub caps the webhook payloads to
+	maxPayloadSize = 25 * 1024 * 1024
+
+	signaturePrefix    = "sha256="
+	gitHubPingEvent    = "ping"
+	gitHubPushEvent    = "push"
+	gitLabPushEvent    = "Push Hook"
+	gitLabTagPushEvent = "Tag Push Hook"
+
+	branchRefPrefix = "refs/heads/"
+	tagRefPrefix    = "refs/tags/"
+	// zeroSHA is the commit of the deleted refs
+	zeroSHA = "0000000000000000000000000000000000000000"
+)
+
+// gitPush is a push to a Git repository, as sent by GitHub or GitLab
+type gitPush struct {
+	ref     string
+	commit  string
+	author  string
+	deleted bool
+}
+
+type gitHubPushPayload struct {
+	Ref        string `json:"ref"`
+	After      string `json:"after"`
+	Deleted    bool   `json:"deleted"`
+	HeadCommit *struct {
+		ID     string `json:"id"`
+		Author struct {
+			Name string `json:"name"`
+		} `json:"author"`
+	} `json:"head_commit"`
+	Pusher struct {
+		Name string `json:"name"`
+	} `json:"pusher"`
+	Repository struct {
+		// PushedAt is the time of the push in seconds since the Unix epoch, signed along with the payload
+		PushedAt int64 `json:"pushed_at"`
+	} `json:"repository"`
+}
+
+type gitLabPushPayload struct {
+	Ref         string `json:"ref"`
+	After       string `json:"after"`
+	CheckoutSHA string `json:"checkout_sha"`
+	UserName    string `json:"user_name"`
+	Commits     []struct {
+		ID     string `json:"id"`
+		Author struct {
+			Name string `json:"name"`
+		} `json:"author"`
+	} `json:"commits"`
+}
+
+func (o *OSBuildConfigHandler) TriggerGitHubBuild(w http.ResponseWriter, r *http.Request, namespace string, name string, params restapi.TriggerGitHubBuildParams) {
+	logger, err := loggerutil.Logger(httpapi.GlobalHttpAPIConf.LogLevel)
+	if err != nil {
+		return
+	}
+
+	logger.Info("New GitHub event was sent ", "event ", params.XGitHubEvent, " OSBuildConfig ", name, " namespace ", namespace)
+
+	osBuildConfig, ok := o.readOSBuildConfig(w, r, logger, namespace, name)
+	if !ok {
+		return
+	}
+
+	trigger := osBuildConfig.Spec.Triggers.GitHub
+	if trigger == nil {
+		logger.Error("resource OSBuildConfig doesn't support triggers by GitHub webhook")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	secretVal, ok := o.readWebHookSecret(w, r, logger, namespace, trigger.SecretReference)
+	if !ok {
+		return
+	}
+
+	payload, ok := readPayload(w, r, logger)
+	if !ok {
+		return
+	}
+
+	if params.XHubSignature256 == nil {
+		logger.Error("the GitHub event is not signed")
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	if !isSignatureValid(payload, secretVal, *params.XHubSignature256) {
+		logger.Error("the signature of the GitHub event is forbidden")
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	switch params.XGitHubEvent {
+	case gitHubPingEvent:
+		logger.Info("GitHub webhook was pinged")
+		writeResponse(w, logger, http.StatusOK, "", directiveIgnored, "")
+		return
+	case gitHubPushEvent:
+	default:
+		logger.Info("GitHub event is ignored ", "event ", params.XGitHubEvent)
+		writeResponse(w, logger, http.StatusOK, "", directiveIgnored, "")
+		return
+	}
+
+	var pushPayload gitHubPushPayload
+	err = json.Unmarshal(payload, &pushPayload)
+	if err != nil {
+		logger.Error(err, "cannot parse the GitHub push event")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	// GitHub signs no nonce, but the signature is unique to the payload holding the time of the push
+	if !o.checkNotReplayed(w, logger, osBuildConfig, time.Unix(pushPayload.Repository.PushedAt, 0), *params.XHubSignature256) {
+		return
+	}
+
+	push := gitPush{
+		ref:     pushPayload.Ref,
+		commit:  pushPayload.After,
+		author:  pushPayload.Pusher.Name,
+		deleted: pushPayload.Deleted,
+	}
+	if pushPayload.HeadCommit != nil {
+		push.commit = pushPayload.HeadCommit.ID
+		push.author = pushPayload.HeadCommit.Author.Name
+	}
+
+	o.triggerGitPush(w, r, logger, osBuildConfig, trigger, v1alpha1.TriggeredByGitHub, push)
+}
+
+func (o *OSBuildConfigHandler) TriggerGitLabBuild(w http.ResponseWriter, r *http.Request, namespace string, name string, params restapi.TriggerGitLabBuildParams) {
+	logger, err := loggerutil.Logger(httpapi.GlobalHttpAPIConf.LogLevel)
+	if err != nil {
+		return
+	}
+
+	logger.Info("New GitLab event was sent ", "event ", params.XGitlabEvent, " OSBuildConfig ", name, " namespace ", namespace)
+
+	osBuildConfig, ok := o.readOSBuildConfig(w, r, logger, namespace, name)
+	if !ok {
+		return
+	}
+
+	trigger := osBuildConfig.Spec.Triggers.GitLab
+	if trigger == nil {
+		logger.Error("resource OSBuildConfig doesn't support triggers by GitLab webhook")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	secretVal, ok := o.readWebHookSecret(w, r, logger, namespace, trigger.SecretReference)
+	if !ok {
+		return
+	}
+
+	if params.XGitlabToken == nil {
+		logger.Error("the GitLab event has no token")
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	if subtle.ConstantTimeCompare([]byte(*params.XGitlabToken), []byte(secretVal)) != 1 {
+		logger.Error("the token of the GitLab event is forbidden")
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	if params.XGitlabEvent != gitLabPushEvent && params.XGitlabEvent != gitLabTagPushEvent {
+		logger.Info("GitLab event is ignored ", "event ", params.XGitlabEvent)
+		writeResponse(w, logger, http.StatusOK, "", directiveIgnored, "")
+		return
+	}
+
+	payload, ok := readPayload(w, r, logger)
+	if !ok {
+		return
+	}
+
+	var pushPayload gitLabPushPayload
+	err = json.Unmarshal(payload, &pushPayload)
+	if err != nil {
+		logger.Error(err, "cannot parse the GitLab push event")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	push := gitPush{
+		ref:     pushPayload.Ref,
+		commit:  pushPayload.CheckoutSHA,
+		author:  pushPayload.UserName,
+		deleted: pushPayload.After == zeroSHA,
+	}
+	if push.commit == "" {
+		push.commit = pushPayload.After
+	}
+	for _, commit := range pushPayload.Commits {
+		if commit.ID == push.commit {
+			push.author = commit.Author.Name
+			break
+		}
+	}
+
+	o.triggerGitPush(w, r, logger, osBuildConfig, trigger, v1alpha1.TriggeredByGitLab, push)
+}
+
+// triggerGitPush triggers a build for the push unless the trigger filters it out, in which case the event is
+// acknowledged without a build
+func (o *OSBuildConfigHandler) triggerGitPush(w http.ResponseWriter, r *http.Request, logger *zap.SugaredLogger, osBuildConfig *v1alpha1.OSBuildConfig,
+	trigger *v1alpha1.GitWebHookTrigger, triggeredBy v1alpha1.TriggeredBy, push gitPush) {
+	if push.deleted {
+		logger.Info("the push deleted the ref, no build is triggered ", "ref ", push.ref)
+		writeResponse(w, logger, http.StatusOK, "", directiveIgnored, "")
+		return
+	}
+	if !isPushAccepted(trigger, push.ref) {
+		logger.Info("the push is filtered out by the trigger, no build is triggered ", "ref ", push.ref)
+		writeResponse(w, logger, http.StatusOK, "", directiveIgnored, "")
+		return
+	}
+
+	o.triggerBuild(w, r, logger, osBuildConfig, &v1alpha1.WebHookTriggerDetails{
+		TriggeredBy: triggeredBy,
+		Commit:      push.commit,
+		Author:      push.author,
+		Ref:         push.ref,
+	})
+}
+
+func readPayload(w http.ResponseWriter, r *http.Request, logger *zap.SugaredLogger) ([]byte, bool) {
+	payload, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxPayloadSize))
+	if err != nil {
+		logger.Error(err, "cannot read the payload of the event")
+		w.WriteHeader(http.StatusBadRequest)
+		return nil, false
+	}
+	return payload, true
+}
+
+// isSignatureValid checks the sha256=<hex HMAC-SHA256 of the payload> signature GitHub sends in the
+// X-Hub-Signature-256 header, and the signed generic webhook calls in the X-Signature-256 one
+func isSignatureValid(payload []byte, secret string, signature string) bool {
+	if !strings.HasPrefix(signature, signaturePrefix) {
+		return false
+	}
+	actual, err := hex.DecodeString(strings.TrimPrefix(signature, signaturePrefix))
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return hmac.Equal(actual, mac.Sum(nil))
+}
+
+// isPushAccepted returns whether the event type of the pushed ref is one of the trigger, and whether the pushed
+// branch matches its branches. The tag pushes are not filtered by branch.
+func isPushAccepted(trigger *v1alpha1.GitWebHookTrigger, ref string) bool {
+	var eventType v1alpha1.GitEventType
+	switch {
+	case strings.HasPrefix(ref, branchRefPrefix):
+		eventType = v1alpha1.GitEventPush
+	case strings.HasPrefix(ref, tagRefPrefix):
+		eventType = v1alpha1.GitEventTagPush
+	default:
+		return false
+	}
+
+	events := trigger.Events
+	if len(events) == 0 {
+		events = []v1alpha1.GitEventType{v1alpha1.GitEventPush}
+	}
+	eventAccepted := false
+	for _, event := range events {
+		if event == eventType {
+			eventAccepted = true
+			break
+		}
+	}
+	if !eventAccepted {
+		return false
+	}
+
+	if eventType == v1alpha1.GitEventTagPush || len(trigger.Branches) == 0 {
+		return true
+	}
+	branch := strings.TrimPrefix(ref, branchRefPrefix)
+	for _, pattern := range trigger.Branches {
+		if matched, _ := path.Match(pattern, branch); matched {
+			return true
+		}
+	}
+	return false
+}
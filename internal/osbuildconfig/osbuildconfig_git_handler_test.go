@@ -0,0 +1,481 @@
+package osbuildconfig
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	buildv1 "github.com/openshift/api/build/v1"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+
+	"github.com/project-flotta/osbuild-operator/api/v1alpha1"
+	"github.com/project-flotta/osbuild-operator/internal/httpapi"
+	"github.com/project-flotta/osbuild-operator/internal/kubeauth"
+	"github.com/project-flotta/osbuild-operator/internal/osbuildwatch"
+	"github.com/project-flotta/osbuild-operator/internal/ratelimit"
+	"github.com/project-flotta/osbuild-operator/internal/replay"
+	repositoryosbuild "github.com/project-flotta/osbuild-operator/internal/repository/osbuild"
+	repositoryosbuildconfig "github.com/project-flotta/osbuild-operator/internal/repository/osbuildconfig"
+	repositoryosbuildconfigtemplate "github.com/project-flotta/osbuild-operator/internal/repository/osbuildconfigtemplate"
+	repositorysecret "github.com/project-flotta/osbuild-operator/internal/repository/secret"
+	"github.com/project-flotta/osbuild-operator/restapi"
+)
+
+const (
+	commitSHA    = "5f6a7b8c9d0e1f2a3b4c5d6e7f8a9b0c1d2e3f4a"
+	commitAuthor = "Jane Doe"
+)
+
+var _ = Describe("OSBuildConfig Git webhooks", func() {
+	var (
+		mockCtrl      *gomock.Controller
+		osbuildConfig v1alpha1.OSBuildConfig
+		secret        corev1.Secret
+		secretVal     = "123"
+
+		osBuildConfigRepository         *repositoryosbuildconfig.MockRepository
+		secretRepository                *repositorysecret.MockRepository
+		osBuildConfigTemplateRepository *repositoryosbuildconfigtemplate.MockRepository
+		osBuildRepository               *repositoryosbuild.MockRepository
+		authorizer                      *kubeauth.MockAuthorizer
+		responseWriter                  *httptest.ResponseRecorder
+		osbuildConfigHandler            *OSBuildConfigHandler
+	)
+
+	newRequest := func(payload []byte) *http.Request {
+		req, _ := http.NewRequest("POST", "test_request", bytes.NewReader(payload))
+		return req
+	}
+
+	sign := func(payload []byte, key string) *string {
+		mac := hmac.New(sha256.New, []byte(key))
+		mac.Write(payload)
+		signature := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+		return &signature
+	}
+
+	expectTriggerDetails := func(details v1alpha1.WebHookTriggerDetails) {
+		Expect(responseWriter.Result().StatusCode).To(Equal(http.StatusOK))
+		Expect(osbuildConfig.Annotations[webHookAnnotationKey]).ToNot(BeEmpty())
+		var actual v1alpha1.WebHookTriggerDetails
+		Expect(json.Unmarshal([]byte(osbuildConfig.Annotations[v1alpha1.WebHookTriggerDetailsAnnotationKey]), &actual)).To(Succeed())
+		Expect(actual).To(Equal(details))
+	}
+
+	expectNoTrigger := func() {
+		Expect(responseWriter.Result().StatusCode).To(Equal(http.StatusOK))
+		Expect(osbuildConfig.Annotations).To(BeEmpty())
+		response := readResponse(responseWriter)
+		Expect(*response.Directive).To(Equal(directiveIgnored))
+		Expect(response.Content).To(BeNil())
+	}
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		osBuildConfigRepository = repositoryosbuildconfig.NewMockRepository(mockCtrl)
+		secretRepository = repositorysecret.NewMockRepository(mockCtrl)
+		osBuildConfigTemplateRepository = repositoryosbuildconfigtemplate.NewMockRepository(mockCtrl)
+		osBuildRepository = repositoryosbuild.NewMockRepository(mockCtrl)
+		authorizer = kubeauth.NewMockAuthorizer(mockCtrl)
+		osbuildConfigHandler = NewOSBuildConfigHandler(osBuildConfigRepository, secretRepository, osBuildConfigTemplateRepository, osBuildRepository, authorizer,
+			ratelimit.NewKeyedLimiter(0.1, 3), replay.NewGuard(5*time.Minute), osbuildwatch.NewBroadcaster())
+
+		secret = corev1.Secret{
+			ObjectMeta: v1.ObjectMeta{
+				Namespace: Namespace,
+				Name:      SecretName,
+			},
+			Data: map[string][]byte{
+				"WebHookSecretKey": []byte(secretVal),
+			},
+		}
+		gitTrigger := &v1alpha1.GitWebHookTrigger{
+			SecretReference: &buildv1.SecretLocalReference{
+				Name: SecretName,
+			},
+		}
+
+		osbuildConfig = v1alpha1.OSBuildConfig{
+			ObjectMeta: v1.ObjectMeta{
+				Name: OSBuildConfigName,
+			},
+			Spec: v1alpha1.OSBuildConfigSpec{
+				Details: v1alpha1.BuildDetails{
+					Distribution: "rhel-86",
+					TargetImage: v1alpha1.TargetImage{
+						Architecture:    "x86_64",
+						TargetImageType: "edge-container",
+					},
+				},
+				Triggers: v1alpha1.BuildTriggers{
+					GitHub: gitTrigger,
+					GitLab: gitTrigger.DeepCopy(),
+				},
+			},
+		}
+
+		responseWriter = httptest.NewRecorder()
+
+		err := httpapi.Load()
+		if err != nil {
+			panic(err.Error())
+		}
+	})
+
+	AfterEach(func() {
+		mockCtrl.Finish()
+	})
+
+	Context("GitHub", func() {
+		var (
+			payload    []byte
+			repository string
+		)
+
+		BeforeEach(func() {
+			repository = `"repository": {"pushed_at": ` + strconv.FormatInt(time.Now().Unix(), 10) + `}`
+			payload = []byte(`{"ref": "refs/heads/main", "after": "` + commitSHA + `", "deleted": false,
+				"head_commit": {"id": "` + commitSHA + `", "author": {"name": "` + commitAuthor + `"}},
+				"pusher": {"name": "jdoe"}, ` + repository + `}`)
+		})
+
+		expectPatch := func(req *http.Request) {
+			osBuildConfigRepository.EXPECT().Read(req.Context(), OSBuildConfigName, Namespace).Return(&osbuildConfig, nil)
+			secretRepository.EXPECT().Read(req.Context(), SecretName, Namespace).Return(&secret, nil)
+			osBuildConfigRepository.EXPECT().Patch(req.Context(), osbuildConfig.DeepCopy(), gomock.Any()).Return(nil)
+		}
+
+		expectNoPatch := func(req *http.Request) {
+			osBuildConfigRepository.EXPECT().Read(req.Context(), OSBuildConfigName, Namespace).Return(&osbuildConfig, nil)
+			secretRepository.EXPECT().Read(req.Context(), SecretName, Namespace).Return(&secret, nil)
+		}
+
+		It("should trigger a build on a push", func() {
+			// given
+			req := newRequest(payload)
+			expectPatch(req)
+
+			// when
+			osbuildConfigHandler.TriggerGitHubBuild(responseWriter, req, Namespace, OSBuildConfigName,
+				restapi.TriggerGitHubBuildParams{XHubSignature256: sign(payload, secretVal), XGitHubEvent: "push"})
+
+			// then
+			expectTriggerDetails(v1alpha1.WebHookTriggerDetails{
+				TriggeredBy: v1alpha1.TriggeredByGitHub,
+				Commit:      commitSHA,
+				Author:      commitAuthor,
+				Ref:         "refs/heads/main",
+			})
+		})
+
+		It("should report the pending build when the push is delivered again without replay protection", func() {
+			// given
+			osbuildConfigHandler.ReplayGuard = replay.NewGuard(0)
+			req := newRequest(payload)
+			expectPatch(req)
+			osbuildConfigHandler.TriggerGitHubBuild(responseWriter, req, Namespace, OSBuildConfigName,
+				restapi.TriggerGitHubBuildParams{XHubSignature256: sign(payload, secretVal), XGitHubEvent: "push"})
+			triggerID := osbuildConfig.Annotations[webHookAnnotationKey]
+			req = newRequest(payload)
+			expectNoPatch(req)
+			responseWriter = httptest.NewRecorder()
+
+			// when
+			osbuildConfigHandler.TriggerGitHubBuild(responseWriter, req, Namespace, OSBuildConfigName,
+				restapi.TriggerGitHubBuildParams{XHubSignature256: sign(payload, secretVal), XGitHubEvent: "push"})
+
+			// then
+			Expect(responseWriter.Result().StatusCode).To(Equal(http.StatusAlreadyReported))
+			Expect(osbuildConfig.Annotations[webHookAnnotationKey]).To(Equal(triggerID))
+			response := readResponse(responseWriter)
+			Expect(*response.MessageId).To(Equal(triggerID))
+			Expect(*response.Content).To(Equal(map[string]interface{}{"osbuild": OSBuildConfigName + "-1"}))
+		})
+
+		It("with conflict response, because the push is replayed", func() {
+			// given
+			req := newRequest(payload)
+			expectPatch(req)
+			osbuildConfigHandler.TriggerGitHubBuild(responseWriter, req, Namespace, OSBuildConfigName,
+				restapi.TriggerGitHubBuildParams{XHubSignature256: sign(payload, secretVal), XGitHubEvent: "push"})
+			triggerID := osbuildConfig.Annotations[webHookAnnotationKey]
+			req = newRequest(payload)
+			expectNoPatch(req)
+			responseWriter = httptest.NewRecorder()
+
+			// when
+			osbuildConfigHandler.TriggerGitHubBuild(responseWriter, req, Namespace, OSBuildConfigName,
+				restapi.TriggerGitHubBuildParams{XHubSignature256: sign(payload, secretVal), XGitHubEvent: "push"})
+
+			// then
+			Expect(responseWriter.Result().StatusCode).To(Equal(http.StatusConflict))
+			Expect(osbuildConfig.Annotations[webHookAnnotationKey]).To(Equal(triggerID))
+		})
+
+		It("with forbidden response, because the push is out of the replay window", func() {
+			// given
+			payload = []byte(`{"ref": "refs/heads/main", "after": "` + commitSHA + `", "pusher": {"name": "jdoe"},
+				"repository": {"pushed_at": ` + strconv.FormatInt(time.Now().Add(-time.Hour).Unix(), 10) + `}}`)
+			req := newRequest(payload)
+			expectNoPatch(req)
+
+			// when
+			osbuildConfigHandler.TriggerGitHubBuild(responseWriter, req, Namespace, OSBuildConfigName,
+				restapi.TriggerGitHubBuildParams{XHubSignature256: sign(payload, secretVal), XGitHubEvent: "push"})
+
+			// then
+			Expect(responseWriter.Result().StatusCode).To(Equal(http.StatusForbidden))
+			Expect(osbuildConfig.Annotations).To(BeEmpty())
+		})
+
+		It("should trigger a build on a push to a branch matching a pattern", func() {
+			// given
+			osbuildConfig.Spec.Triggers.GitHub.Branches = []string{"release-*", "main"}
+			req := newRequest(payload)
+			expectPatch(req)
+
+			// when
+			osbuildConfigHandler.TriggerGitHubBuild(responseWriter, req, Namespace, OSBuildConfigName,
+				restapi.TriggerGitHubBuildParams{XHubSignature256: sign(payload, secretVal), XGitHubEvent: "push"})
+
+			// then
+			Expect(responseWriter.Result().StatusCode).To(Equal(http.StatusOK))
+			Expect(osbuildConfig.Annotations[webHookAnnotationKey]).ToNot(BeEmpty())
+		})
+
+		It("should not trigger a build on a push to another branch", func() {
+			// given
+			osbuildConfig.Spec.Triggers.GitHub.Branches = []string{"release-*"}
+			req := newRequest(payload)
+			expectNoPatch(req)
+
+			// when
+			osbuildConfigHandler.TriggerGitHubBuild(responseWriter, req, Namespace, OSBuildConfigName,
+				restapi.TriggerGitHubBuildParams{XHubSignature256: sign(payload, secretVal), XGitHubEvent: "push"})
+
+			// then
+			expectNoTrigger()
+		})
+
+		It("should not trigger a build on a tag push when only pushes trigger builds", func() {
+			// given
+			payload = []byte(`{"ref": "refs/tags/v1.0", "after": "` + commitSHA + `", ` + repository + `}`)
+			req := newRequest(payload)
+			expectNoPatch(req)
+
+			// when
+			osbuildConfigHandler.TriggerGitHubBuild(responseWriter, req, Namespace, OSBuildConfigName,
+				restapi.TriggerGitHubBuildParams{XHubSignature256: sign(payload, secretVal), XGitHubEvent: "push"})
+
+			// then
+			expectNoTrigger()
+		})
+
+		It("should trigger a build on a tag push whatever the branches", func() {
+			// given
+			osbuildConfig.Spec.Triggers.GitHub.Events = []v1alpha1.GitEventType{v1alpha1.GitEventTagPush}
+			osbuildConfig.Spec.Triggers.GitHub.Branches = []string{"main"}
+			payload = []byte(`{"ref": "refs/tags/v1.0", "after": "` + commitSHA + `", "pusher": {"name": "jdoe"}, ` + repository + `}`)
+			req := newRequest(payload)
+			expectPatch(req)
+
+			// when
+			osbuildConfigHandler.TriggerGitHubBuild(responseWriter, req, Namespace, OSBuildConfigName,
+				restapi.TriggerGitHubBuildParams{XHubSignature256: sign(payload, secretVal), XGitHubEvent: "push"})
+
+			// then
+			expectTriggerDetails(v1alpha1.WebHookTriggerDetails{
+				TriggeredBy: v1alpha1.TriggeredByGitHub,
+				Commit:      commitSHA,
+				Author:      "jdoe",
+				Ref:         "refs/tags/v1.0",
+			})
+		})
+
+		It("should not trigger a build when the branch is deleted", func() {
+			// given
+			payload = []byte(`{"ref": "refs/heads/main", "after": "` + zeroSHA + `", "deleted": true, ` + repository + `}`)
+			req := newRequest(payload)
+			expectNoPatch(req)
+
+			// when
+			osbuildConfigHandler.TriggerGitHubBuild(responseWriter, req, Namespace, OSBuildConfigName,
+				restapi.TriggerGitHubBuildParams{XHubSignature256: sign(payload, secretVal), XGitHubEvent: "push"})
+
+			// then
+			expectNoTrigger()
+		})
+
+		It("should acknowledge a ping without triggering a build", func() {
+			// given
+			payload = []byte(`{"zen": "Keep it logically awesome."}`)
+			req := newRequest(payload)
+			expectNoPatch(req)
+
+			// when
+			osbuildConfigHandler.TriggerGitHubBuild(responseWriter, req, Namespace, OSBuildConfigName,
+				restapi.TriggerGitHubBuildParams{XHubSignature256: sign(payload, secretVal), XGitHubEvent: "ping"})
+
+			// then
+			expectNoTrigger()
+		})
+
+		It("with unauthorized response, because the event is not signed", func() {
+			// given
+			req := newRequest(payload)
+			expectNoPatch(req)
+
+			// when
+			osbuildConfigHandler.TriggerGitHubBuild(responseWriter, req, Namespace, OSBuildConfigName,
+				restapi.TriggerGitHubBuildParams{XGitHubEvent: "push"})
+
+			// then
+			Expect(responseWriter.Result().StatusCode).To(Equal(http.StatusUnauthorized))
+		})
+
+		It("with forbidden response, because the event is signed with another secret", func() {
+			// given
+			req := newRequest(payload)
+			expectNoPatch(req)
+
+			// when
+			osbuildConfigHandler.TriggerGitHubBuild(responseWriter, req, Namespace, OSBuildConfigName,
+				restapi.TriggerGitHubBuildParams{XHubSignature256: sign(payload, "456"), XGitHubEvent: "push"})
+
+			// then
+			Expect(responseWriter.Result().StatusCode).To(Equal(http.StatusForbidden))
+		})
+
+		DescribeTable("with internalServerError response, because the secret has no value", func(data map[string][]byte) {
+			// given
+			secret.Data = data
+			req := newRequest(payload)
+			expectNoPatch(req)
+
+			// when
+			osbuildConfigHandler.TriggerGitHubBuild(responseWriter, req, Namespace, OSBuildConfigName,
+				restapi.TriggerGitHubBuildParams{XHubSignature256: sign(payload, ""), XGitHubEvent: "push"})
+
+			// then
+			Expect(responseWriter.Result().StatusCode).To(Equal(http.StatusInternalServerError))
+			Expect(osbuildConfig.Annotations).To(BeEmpty())
+		},
+			Entry("for the missing key", map[string][]byte{}),
+			Entry("for the empty key", map[string][]byte{"WebHookSecretKey": {}}),
+		)
+
+		It("with bad request response, because osbuildConfig hasn't a GitHub trigger", func() {
+			// given
+			osbuildConfig.Spec.Triggers.GitHub = nil
+			req := newRequest(payload)
+			osBuildConfigRepository.EXPECT().Read(req.Context(), OSBuildConfigName, Namespace).Return(&osbuildConfig, nil)
+
+			// when
+			osbuildConfigHandler.TriggerGitHubBuild(responseWriter, req, Namespace, OSBuildConfigName,
+				restapi.TriggerGitHubBuildParams{XHubSignature256: sign(payload, secretVal), XGitHubEvent: "push"})
+
+			// then
+			Expect(responseWriter.Result().StatusCode).To(Equal(http.StatusBadRequest))
+		})
+	})
+
+	Context("GitLab", func() {
+		var payload []byte
+
+		BeforeEach(func() {
+			payload = []byte(`{"object_kind": "push", "ref": "refs/heads/main", "after": "` + commitSHA + `",
+				"checkout_sha": "` + commitSHA + `", "user_name": "jdoe",
+				"commits": [{"id": "` + commitSHA + `", "author": {"name": "` + commitAuthor + `"}}]}`)
+		})
+
+		It("should trigger a build on a push", func() {
+			// given
+			req := newRequest(payload)
+			osBuildConfigRepository.EXPECT().Read(req.Context(), OSBuildConfigName, Namespace).Return(&osbuildConfig, nil)
+			secretRepository.EXPECT().Read(req.Context(), SecretName, Namespace).Return(&secret, nil)
+			osBuildConfigRepository.EXPECT().Patch(req.Context(), osbuildConfig.DeepCopy(), gomock.Any()).Return(nil)
+
+			// when
+			osbuildConfigHandler.TriggerGitLabBuild(responseWriter, req, Namespace, OSBuildConfigName,
+				restapi.TriggerGitLabBuildParams{XGitlabToken: &secretVal, XGitlabEvent: "Push Hook"})
+
+			// then
+			expectTriggerDetails(v1alpha1.WebHookTriggerDetails{
+				TriggeredBy: v1alpha1.TriggeredByGitLab,
+				Commit:      commitSHA,
+				Author:      commitAuthor,
+				Ref:         "refs/heads/main",
+			})
+		})
+
+		It("should not trigger a build on other events", func() {
+			// given
+			req := newRequest([]byte(`{"object_kind": "merge_request"}`))
+			osBuildConfigRepository.EXPECT().Read(req.Context(), OSBuildConfigName, Namespace).Return(&osbuildConfig, nil)
+			secretRepository.EXPECT().Read(req.Context(), SecretName, Namespace).Return(&secret, nil)
+
+			// when
+			osbuildConfigHandler.TriggerGitLabBuild(responseWriter, req, Namespace, OSBuildConfigName,
+				restapi.TriggerGitLabBuildParams{XGitlabToken: &secretVal, XGitlabEvent: "Merge Request Hook"})
+
+			// then
+			expectNoTrigger()
+		})
+
+		It("with unauthorized response, because the event has no token", func() {
+			// given
+			req := newRequest(payload)
+			osBuildConfigRepository.EXPECT().Read(req.Context(), OSBuildConfigName, Namespace).Return(&osbuildConfig, nil)
+			secretRepository.EXPECT().Read(req.Context(), SecretName, Namespace).Return(&secret, nil)
+
+			// when
+			osbuildConfigHandler.TriggerGitLabBuild(responseWriter, req, Namespace, OSBuildConfigName,
+				restapi.TriggerGitLabBuildParams{XGitlabEvent: "Push Hook"})
+
+			// then
+			Expect(responseWriter.Result().StatusCode).To(Equal(http.StatusUnauthorized))
+		})
+
+		It("with forbidden response, because the token is wrong", func() {
+			// given
+			req := newRequest(payload)
+			osBuildConfigRepository.EXPECT().Read(req.Context(), OSBuildConfigName, Namespace).Return(&osbuildConfig, nil)
+			secretRepository.EXPECT().Read(req.Context(), SecretName, Namespace).Return(&secret, nil)
+			token := "456"
+
+			// when
+			osbuildConfigHandler.TriggerGitLabBuild(responseWriter, req, Namespace, OSBuildConfigName,
+				restapi.TriggerGitLabBuildParams{XGitlabToken: &token, XGitlabEvent: "Push Hook"})
+
+			// then
+			Expect(responseWriter.Result().StatusCode).To(Equal(http.StatusForbidden))
+		})
+
+		It("with internalServerError response, because the secret has no value", func() {
+			// given
+			secret.Data = map[string][]byte{}
+			req := newRequest(payload)
+			osBuildConfigRepository.EXPECT().Read(req.Context(), OSBuildConfigName, Namespace).Return(&osbuildConfig, nil)
+			secretRepository.EXPECT().Read(req.Context(), SecretName, Namespace).Return(&secret, nil)
+
+			// when
+			osbuildConfigHandler.TriggerGitLabBuild(responseWriter, req, Namespace, OSBuildConfigName,
+				restapi.TriggerGitLabBuildParams{XGitlabToken: pointer.String(""), XGitlabEvent: "Push Hook"})
+
+			// then
+			Expect(responseWriter.Result().StatusCode).To(Equal(http.StatusInternalServerError))
+			Expect(osbuildConfig.Annotations).To(BeEmpty())
+		})
+	})
+})
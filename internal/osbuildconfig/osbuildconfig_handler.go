@@ -6,34 +6,83 @@
 package osbuildconfig
 
 import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	goerrors "errors"
 	"fmt"
+	"io"
 	"net/http"
+	"path"
+	"reflect"
+	"strings"
 	"time"
 
+	"github.com/google/uuid"
+	buildv1 "github.com/openshift/api/build/v1"
+	"go.uber.org/zap"
+	authorizationv1 "k8s.io/api/authorization/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 
+	"github.com/project-flotta/osbuild-operator/api/v1alpha1"
 	"github.com/project-flotta/osbuild-operator/internal/httpapi"
+	"github.com/project-flotta/osbuild-operator/internal/kubeauth"
 	loggerutil "github.com/project-flotta/osbuild-operator/internal/logger"
+	"github.com/project-flotta/osbuild-operator/internal/osbuildwatch"
+	"github.com/project-flotta/osbuild-operator/internal/ratelimit"
+	"github.com/project-flotta/osbuild-operator/internal/replay"
+	repositoryosbuild "github.com/project-flotta/osbuild-operator/internal/repository/osbuild"
 	repositoryosbuildconfig "github.com/project-flotta/osbuild-operator/internal/repository/osbuildconfig"
+	"github.com/project-flotta/osbuild-operator/internal/repository/osbuildconfigtemplate"
 	"github.com/project-flotta/osbuild-operator/internal/repository/secret"
+	"github.com/project-flotta/osbuild-operator/internal/templates"
 	"github.com/project-flotta/osbuild-operator/restapi"
 )
 
 const (
-	webHookAnnotationKey = "last_webhook_trigger_ts"
-	timeFormat           = "2006-01-02 15:04:05"
+	webHookAnnotationKey = v1alpha1.WebHookTriggerAnnotationKey
+	webHookSecretKey     = "WebHookSecretKey"
+	bearerPrefix         = "Bearer "
+
+	// the directives of the message-responses
+	directiveBuild      = "build"
+	directiveInProgress = "in-progress"
+	directiveIgnored    = "ignored"
 )
 
+// triggerContent is the content of the message-responses of the triggers
+type triggerContent struct {
+	OSBuild string `json:"osbuild"`
+}
+
 type OSBuildConfigHandler struct {
-	OSBuildConfigRepository repositoryosbuildconfig.Repository
-	SecretRepository        secret.Repository
+	OSBuildConfigRepository         repositoryosbuildconfig.Repository
+	SecretRepository                secret.Repository
+	OSBuildConfigTemplateRepository osbuildconfigtemplate.Repository
+	OSBuildRepository               repositoryosbuild.Repository
+	Authorizer                      kubeauth.Authorizer
+	// TriggerLimiter limits the rate of the builds triggered for each OSBuildConfig
+	TriggerLimiter *ratelimit.KeyedLimiter
+	// ReplayGuard rejects the replays of the signed triggers
+	ReplayGuard *replay.Guard
+	// Broadcaster sends the changes of the OSBuilds to the watch streams
+	Broadcaster *osbuildwatch.Broadcaster
 }
 
 func NewOSBuildConfigHandler(osBuildConfigRepository repositoryosbuildconfig.Repository,
-	secretRepository secret.Repository) *OSBuildConfigHandler {
+	secretRepository secret.Repository, osBuildConfigTemplateRepository osbuildconfigtemplate.Repository,
+	osBuildRepository repositoryosbuild.Repository, authorizer kubeauth.Authorizer, triggerLimiter *ratelimit.KeyedLimiter,
+	replayGuard *replay.Guard, broadcaster *osbuildwatch.Broadcaster) *OSBuildConfigHandler {
 	return &OSBuildConfigHandler{
-		OSBuildConfigRepository: osBuildConfigRepository,
-		SecretRepository:        secretRepository,
+		OSBuildConfigRepository:         osBuildConfigRepository,
+		SecretRepository:                secretRepository,
+		OSBuildConfigTemplateRepository: osBuildConfigTemplateRepository,
+		OSBuildRepository:               osBuildRepository,
+		Authorizer:                      authorizer,
+		TriggerLimiter:                  triggerLimiter,
+		ReplayGuard:                     replayGuard,
+		Broadcaster:                     broadcaster,
 	}
 }
 func (o *OSBuildConfigHandler) TriggerBuild(w http.ResponseWriter, r *http.Request, namespace string, name string, params restapi.TriggerBuildParams) {
@@ -44,57 +93,385 @@ func (o *OSBuildConfigHandler) TriggerBuild(w http.ResponseWriter, r *http.Reque
 
 	logger.Info("New OSBuild trigger was sent ", "OSBuildConfig ", name, " namespace ", namespace)
 
+	// the callers bearing a token are authorized by RBAC, without the webhook trigger
+	token, bearer := getBearerToken(params.Authorization)
+	if bearer {
+		attributes := kubeauth.ResourceAttributes("create", kubeauth.OSBuildConfigResource, kubeauth.InstantiateSubresource, namespace, name)
+		if !o.checkTokenAuthorized(w, r, logger, token, attributes) {
+			return
+		}
+	}
+
+	osBuildConfig, ok := o.readOSBuildConfig(w, r, logger, namespace, name)
+	if !ok {
+		return
+	}
+
+	if !bearer {
+		if osBuildConfig.Spec.Triggers.WebHook == nil {
+			logger.Error("resource OSBuildConfig doesn't support triggers by webhook")
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		secretVal, ok := o.readWebHookSecret(w, r, logger, namespace, osBuildConfig.Spec.Triggers.WebHook.SecretReference)
+		if !ok {
+			return
+		}
+
+		if params.XSignature256 != nil {
+			if !o.checkSignedRequest(w, r, logger, osBuildConfig, secretVal, params) {
+				return
+			}
+		} else {
+			if params.Secret == nil {
+				logger.Error("the request has neither a secret, a signature nor a bearer token")
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			if subtle.ConstantTimeCompare([]byte(*params.Secret), []byte(secretVal)) != 1 {
+				logger.Error("secret value is forbidden")
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+		}
+	}
+
+	overrides, ok := o.readBuildOverrides(w, r, logger, osBuildConfig)
+	if !ok {
+		return
+	}
+
+	o.triggerBuild(w, r, logger, osBuildConfig, &v1alpha1.WebHookTriggerDetails{
+		TriggeredBy: v1alpha1.TriggeredByWebhook,
+		Overrides:   overrides,
+	})
+}
+
+// readBuildOverrides returns the values of the request body that apply to the triggered build only, the parameters
+// being validated against the ones of the OSBuildConfigTemplate of the OSBuildConfig. A request without a body
+// overrides nothing
+func (o *OSBuildConfigHandler) readBuildOverrides(w http.ResponseWriter, r *http.Request, logger *zap.SugaredLogger, osBuildConfig *v1alpha1.OSBuildConfig) (*v1alpha1.BuildOverrides, bool) {
+	if r.Body == nil {
+		return nil, true
+	}
+	payload, ok := readPayload(w, r, logger)
+	if !ok {
+		return nil, false
+	}
+	if len(bytes.TrimSpace(payload)) == 0 {
+		return nil, true
+	}
+
+	var request restapi.TriggerBuildJSONRequestBody
+	decoder := json.NewDecoder(bytes.NewReader(payload))
+	decoder.DisallowUnknownFields()
+	err := decoder.Decode(&request)
+	if err != nil {
+		logger.Error(err, "cannot parse the request body")
+		w.WriteHeader(http.StatusBadRequest)
+		return nil, false
+	}
+
+	overrides := &v1alpha1.BuildOverrides{}
+	if request.Packages != nil {
+		for _, pkg := range *request.Packages {
+			if strings.TrimSpace(pkg) == "" {
+				logger.Error("the packages of the request body cannot be empty")
+				w.WriteHeader(http.StatusBadRequest)
+				return nil, false
+			}
+			overrides.Packages = append(overrides.Packages, pkg)
+		}
+	}
+	if request.Parameters != nil {
+		for _, parameter := range *request.Parameters {
+			overrides.Parameters = append(overrides.Parameters, v1alpha1.ParameterValue{Name: parameter.Name, Value: parameter.Value})
+		}
+	}
+
+	if len(overrides.Parameters) > 0 {
+		if osBuildConfig.Spec.Template == nil {
+			logger.Error("resource OSBuildConfig has no template to set the parameters of")
+			w.WriteHeader(http.StatusBadRequest)
+			return nil, false
+		}
+		templateName := osBuildConfig.Spec.Template.OSBuildConfigTemplateRef
+		osBuildConfigTemplate, err := o.OSBuildConfigTemplateRepository.Read(r.Context(), templateName, osBuildConfig.Namespace)
+		if err != nil {
+			if errors.IsNotFound(err) {
+				logger.Error("resource OSBuildConfigTemplate not found", "OSBuildConfigTemplate", templateName)
+				w.WriteHeader(http.StatusNotFound)
+				return nil, false
+			}
+			logger.Error(err, fmt.Sprintf("cannot retrieve OSBuildConfigTemplate %s", templateName))
+			w.WriteHeader(http.StatusInternalServerError)
+			return nil, false
+		}
+
+		err = templates.ValidateParameterValues(osBuildConfigTemplate.Spec.Parameters, overrides.Parameters)
+		if err != nil {
+			logger.Error(err, "invalid parameters in the request body")
+			w.WriteHeader(http.StatusBadRequest)
+			return nil, false
+		}
+	}
+
+	if len(overrides.Parameters) == 0 && len(overrides.Packages) == 0 {
+		return nil, true
+	}
+	return overrides, true
+}
+
+func (o *OSBuildConfigHandler) readOSBuildConfig(w http.ResponseWriter, r *http.Request, logger *zap.SugaredLogger, namespace string, name string) (*v1alpha1.OSBuildConfig, bool) {
 	osBuildConfig, err := o.OSBuildConfigRepository.Read(r.Context(), name, namespace)
 	if err != nil {
 		if errors.IsNotFound(err) {
 			logger.Error("resource OSBuildConfig not found")
 			w.WriteHeader(http.StatusNotFound)
-			return
+			return nil, false
 		}
 		logger.Error(err, fmt.Sprintf("cannot retrieve OSBuildConfig %s", name))
 		w.WriteHeader(http.StatusInternalServerError)
-		return
+		return nil, false
+	}
+	return osBuildConfig, true
+}
+
+// getBearerToken returns the token of the Authorization header, if it is a bearer one
+func getBearerToken(authorization *string) (string, bool) {
+	if authorization == nil || len(*authorization) <= len(bearerPrefix) || !strings.EqualFold((*authorization)[:len(bearerPrefix)], bearerPrefix) {
+		return "", false
 	}
+	return strings.TrimSpace((*authorization)[len(bearerPrefix):]), true
+}
 
-	if osBuildConfig.Spec.Triggers.WebHook == nil {
-		logger.Error("resource OSBuildConfig doesn't support triggers by webhook")
+// checkTokenAuthorized writes the error response when the token is not valid, or when its user is not allowed the
+// attributes
+func (o *OSBuildConfigHandler) checkTokenAuthorized(w http.ResponseWriter, r *http.Request, logger *zap.SugaredLogger, token string, attributes authorizationv1.ResourceAttributes) bool {
+	allowed, err := o.Authorizer.Authorize(r.Context(), token, attributes)
+	if err != nil {
+		if goerrors.Is(err, kubeauth.ErrUnauthenticated) {
+			logger.Error(err, "the bearer token is not valid")
+			w.WriteHeader(http.StatusUnauthorized)
+			return false
+		}
+		logger.Error(err, "cannot authorize the bearer token")
+		w.WriteHeader(http.StatusInternalServerError)
+		return false
+	}
+	if !allowed {
+		logger.Error("the user of the bearer token is not allowed ", "verb ", attributes.Verb, " resource ", attributes.Resource, " subresource ", attributes.Subresource, " name ", attributes.Name)
+		w.WriteHeader(http.StatusForbidden)
+		return false
+	}
+	return true
+}
+
+// readWebHookSecret returns the value of the WebHookSecretKey key of the secret the trigger references to. A secret
+// without a value for the key is a misconfiguration of the trigger
+func (o *OSBuildConfigHandler) readWebHookSecret(w http.ResponseWriter, r *http.Request, logger *zap.SugaredLogger, namespace string, secretReference *buildv1.SecretLocalReference) (string, bool) {
+	if secretReference == nil {
+		logger.Error("the webhook trigger of the resource OSBuildConfig doesn't reference a secret")
 		w.WriteHeader(http.StatusBadRequest)
-		return
+		return "", false
 	}
 
-	secretName := osBuildConfig.Spec.Triggers.WebHook.SecretReference.Name
-	secretVal := params.Secret
+	secretName := secretReference.Name
 	webhookSecret, err := o.SecretRepository.Read(r.Context(), secretName, namespace)
 	if err != nil {
 		if errors.IsNotFound(err) {
 			logger.Error("secret not found", "secret", secretName)
 			w.WriteHeader(http.StatusNotFound)
-			return
+			return "", false
 		}
 		logger.Error(err, "cannot read secret")
 		w.WriteHeader(http.StatusInternalServerError)
-		return
+		return "", false
+	}
+	secretVal := string(webhookSecret.Data[webHookSecretKey])
+	if secretVal == "" {
+		// an empty secret would authorize anyone and sign anything
+		logger.Error("the secret has no value for the webhook secret key", "secret", secretName, "key", webHookSecretKey)
+		w.WriteHeader(http.StatusInternalServerError)
+		return "", false
 	}
+	return secretVal, true
+}
 
-	if string(webhookSecret.Data["WebHookSecretKey"]) != secretVal {
-		logger.Error("secret value is forbidden")
+// checkSignedRequest checks the signature of the timestamp, the nonce and the body of the request, and that the
+// request is not a replay. The body is kept for the overrides to be read
+func (o *OSBuildConfigHandler) checkSignedRequest(w http.ResponseWriter, r *http.Request, logger *zap.SugaredLogger, osBuildConfig *v1alpha1.OSBuildConfig,
+	secretVal string, params restapi.TriggerBuildParams) bool {
+	if params.XSignatureTimestamp == nil || params.XSignatureNonce == nil {
+		logger.Error("the signed request has no timestamp or no nonce")
+		w.WriteHeader(http.StatusUnauthorized)
+		return false
+	}
+
+	var payload []byte
+	if r.Body != nil {
+		var ok bool
+		payload, ok = readPayload(w, r, logger)
+		if !ok {
+			return false
+		}
+		r.Body = io.NopCloser(bytes.NewReader(payload))
+	}
+
+	signed := append([]byte(fmt.Sprintf("%d.%s.", *params.XSignatureTimestamp, *params.XSignatureNonce)), payload...)
+	if !isSignatureValid(signed, secretVal, *params.XSignature256) {
+		logger.Error("the signature of the request is forbidden")
 		w.WriteHeader(http.StatusForbidden)
+		return false
+	}
+
+	return o.checkNotReplayed(w, logger, osBuildConfig, time.Unix(*params.XSignatureTimestamp, 0), *params.XSignatureNonce)
+}
+
+// checkNotReplayed writes the error response when the timestamp of a signed request is out of the replay window, or
+// when its nonce was already used for the OSBuildConfig
+func (o *OSBuildConfigHandler) checkNotReplayed(w http.ResponseWriter, logger *zap.SugaredLogger, osBuildConfig *v1alpha1.OSBuildConfig, timestamp time.Time, nonce string) bool {
+	err := o.ReplayGuard.Check(timestamp, path.Join(osBuildConfig.Namespace, osBuildConfig.Name, nonce))
+	switch {
+	case goerrors.Is(err, replay.ErrStale):
+		logger.Error(err, "the signed request is forbidden ", "timestamp ", timestamp)
+		w.WriteHeader(http.StatusForbidden)
+		return false
+	case goerrors.Is(err, replay.ErrReplayed):
+		logger.Error(err, "the signed request is replayed")
+		w.WriteHeader(http.StatusConflict)
+		return false
+	}
+	return true
+}
+
+// triggerBuild annotates the OSBuildConfig with a new trigger ID to trigger the OSBuildConfig controller reconcile
+// loop, along with the details of the webhook call unless the generic webhook was called without a body. When an
+// identical trigger is already in progress, its OSBuild is reported instead. The OSBuildConfig cannot be triggered
+// more often than its rate limit
+func (o *OSBuildConfigHandler) triggerBuild(w http.ResponseWriter, r *http.Request, logger *zap.SugaredLogger, osBuildConfig *v1alpha1.OSBuildConfig, details *v1alpha1.WebHookTriggerDetails) {
+	if osBuildName, ok := o.getInProgressOSBuild(r.Context(), logger, osBuildConfig, details); ok {
+		logger.Info("an identical trigger is already in progress ", "OSBuild ", osBuildName)
+		writeResponse(w, logger, http.StatusAlreadyReported, osBuildConfig.Annotations[webHookAnnotationKey], directiveInProgress, osBuildName)
+		return
+	}
+
+	if allowed, delay := o.TriggerLimiter.Allow(path.Join(osBuildConfig.Namespace, osBuildConfig.Name)); !allowed {
+		logger.Error("the OSBuildConfig exceeded its rate of triggered builds ", "retry after ", delay)
+		ratelimit.WriteTooManyRequests(w, delay)
 		return
 	}
 
-	// add annotation for triggering OSBuildConfig controller reconcile loop
+	triggerID := uuid.New().String()
 	osBuildConfigOld := osBuildConfig.DeepCopy()
 	if osBuildConfig.Annotations == nil {
 		osBuildConfig.Annotations = map[string]string{}
 	}
-	osBuildConfig.Annotations[webHookAnnotationKey] = time.Now().Format(timeFormat)
-	err = o.OSBuildConfigRepository.Patch(r.Context(), osBuildConfigOld, osBuildConfig)
+	osBuildConfig.Annotations[webHookAnnotationKey] = triggerID
+	if isGenericTrigger(details) {
+		// the details of a previous call must not be recorded on this build
+		delete(osBuildConfig.Annotations, v1alpha1.WebHookTriggerDetailsAnnotationKey)
+	} else {
+		// the details are plain strings, marshaling them cannot fail
+		data, _ := json.Marshal(details)
+		osBuildConfig.Annotations[v1alpha1.WebHookTriggerDetailsAnnotationKey] = string(data)
+	}
+	err := o.OSBuildConfigRepository.Patch(r.Context(), osBuildConfigOld, osBuildConfig)
 
 	if err != nil {
 		logger.Error(err, "cannot create trigger OSBuildConfig controller - patching the object was failed")
 		w.WriteHeader(http.StatusInternalServerError)
 		return
 	}
-	logger.Info("OSBuildConfig controller was triggered")
-	w.WriteHeader(http.StatusOK)
+	logger.Info("OSBuildConfig controller was triggered ", "trigger ", triggerID)
+	writeResponse(w, logger, http.StatusOK, triggerID, directiveBuild, getNextOSBuildName(osBuildConfig))
+}
+
+// getInProgressOSBuild returns the OSBuild of the last trigger of the OSBuildConfig when that trigger is identical to
+// the new one and its OSBuild is still to be built or building
+func (o *OSBuildConfigHandler) getInProgressOSBuild(ctx context.Context, logger *zap.SugaredLogger, osBuildConfig *v1alpha1.OSBuildConfig, details *v1alpha1.WebHookTriggerDetails) (string, bool) {
+	lastTriggerID, ok := osBuildConfig.Annotations[webHookAnnotationKey]
+	if !ok || !isSameTrigger(getLastTriggerDetails(osBuildConfig), details) {
+		return "", false
+	}
+
+	if lastTriggerID != osBuildConfig.Status.LastWebhookTriggerTS {
+		// the OSBuildConfig controller did not create the OSBuild of the last trigger yet
+		return getNextOSBuildName(osBuildConfig), true
+	}
+
+	if osBuildConfig.Status.LastVersion == nil {
+		return "", false
+	}
+	osBuildName := fmt.Sprintf("%s-%d", osBuildConfig.Name, *osBuildConfig.Status.LastVersion)
+	osBuild, err := o.OSBuildRepository.Read(ctx, osBuildName, osBuildConfig.Namespace)
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			logger.Error(err, fmt.Sprintf("cannot retrieve OSBuild %s, triggering a new build", osBuildName))
+		}
+		return "", false
+	}
+	if osBuild.Annotations[v1alpha1.TriggerIDAnnotationKey] != lastTriggerID || osBuild.IsFinished() {
+		return "", false
+	}
+	return osBuildName, true
+}
+
+// getLastTriggerDetails returns the details of the last webhook call recorded on the OSBuildConfig, the generic
+// webhook being called without a body when there are none
+func getLastTriggerDetails(osBuildConfig *v1alpha1.OSBuildConfig) *v1alpha1.WebHookTriggerDetails {
+	details := &v1alpha1.WebHookTriggerDetails{TriggeredBy: v1alpha1.TriggeredByWebhook}
+	if data, ok := osBuildConfig.Annotations[v1alpha1.WebHookTriggerDetailsAnnotationKey]; ok {
+		if err := json.Unmarshal([]byte(data), details); err != nil {
+			return nil
+		}
+	}
+	return details
+}
+
+// isSameTrigger compares the webhook calls, whatever their IDs
+func isSameTrigger(a *v1alpha1.WebHookTriggerDetails, b *v1alpha1.WebHookTriggerDetails) bool {
+	if a == nil || b == nil {
+		return false
+	}
+	a, b = a.DeepCopy(), b.DeepCopy()
+	a.ID, b.ID = "", ""
+	return reflect.DeepEqual(a, b)
+}
+
+func isGenericTrigger(details *v1alpha1.WebHookTriggerDetails) bool {
+	return isSameTrigger(details, &v1alpha1.WebHookTriggerDetails{TriggeredBy: v1alpha1.TriggeredByWebhook})
+}
+
+// getNextOSBuildName returns the name of the OSBuild the OSBuildConfig controller creates next
+func getNextOSBuildName(osBuildConfig *v1alpha1.OSBuildConfig) string {
+	lastVersion := 0
+	if osBuildConfig.Status.LastVersion != nil {
+		lastVersion = *osBuildConfig.Status.LastVersion
+	}
+	return fmt.Sprintf("%s-%d", osBuildConfig.Name, lastVersion+1)
+}
+
+// writeResponse writes a message-response whose content holds the name of the OSBuild of the trigger, if any
+func writeResponse(w http.ResponseWriter, logger *zap.SugaredLogger, statusCode int, triggerID string, directive string, osBuildName string) {
+	response := restapi.MessageResponse{Directive: &directive}
+	if triggerID != "" {
+		response.MessageId = &triggerID
+	}
+	if osBuildName != "" {
+		var content interface{} = triggerContent{OSBuild: osBuildName}
+		response.Content = &content
+	}
+
+	writeJSON(w, logger, statusCode, response)
+}
+
+func writeJSON(w http.ResponseWriter, logger *zap.SugaredLogger, statusCode int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	err := json.NewEncoder(w).Encode(body)
+	if err != nil {
+		logger.Error(err, "cannot write the response")
+	}
 }
@@ -1,9 +1,16 @@
 package osbuildconfig
 
 import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	. "github.com/onsi/ginkgo/v2"
@@ -13,10 +20,17 @@ import (
 	"k8s.io/apimachinery/pkg/api/errors"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/utils/pointer"
 
 	"github.com/project-flotta/osbuild-operator/api/v1alpha1"
 	"github.com/project-flotta/osbuild-operator/internal/httpapi"
+	"github.com/project-flotta/osbuild-operator/internal/kubeauth"
+	"github.com/project-flotta/osbuild-operator/internal/osbuildwatch"
+	"github.com/project-flotta/osbuild-operator/internal/ratelimit"
+	"github.com/project-flotta/osbuild-operator/internal/replay"
+	repositoryosbuild "github.com/project-flotta/osbuild-operator/internal/repository/osbuild"
 	repositoryosbuildconfig "github.com/project-flotta/osbuild-operator/internal/repository/osbuildconfig"
+	repositoryosbuildconfigtemplate "github.com/project-flotta/osbuild-operator/internal/repository/osbuildconfigtemplate"
 	repositorysecret "github.com/project-flotta/osbuild-operator/internal/repository/secret"
 	"github.com/project-flotta/osbuild-operator/restapi"
 )
@@ -43,18 +57,25 @@ var _ = Describe("OSBuildConfig rest API", func() {
 			"WebHookSecretKey": []byte(secretVal),
 		}
 
-		osBuildConfigRepository *repositoryosbuildconfig.MockRepository
-		secretRepository        *repositorysecret.MockRepository
-		responseWriter          *httptest.ResponseRecorder
-		osbuildConfigHandler    *OSBuildConfigHandler
-		req                     *http.Request
-		params                  restapi.TriggerBuildParams
+		osBuildConfigRepository         *repositoryosbuildconfig.MockRepository
+		secretRepository                *repositorysecret.MockRepository
+		osBuildConfigTemplateRepository *repositoryosbuildconfigtemplate.MockRepository
+		osBuildRepository               *repositoryosbuild.MockRepository
+		authorizer                      *kubeauth.MockAuthorizer
+		responseWriter                  *httptest.ResponseRecorder
+		osbuildConfigHandler            *OSBuildConfigHandler
+		req                             *http.Request
+		params                          restapi.TriggerBuildParams
 	)
 	BeforeEach(func() {
 		mockCtrl = gomock.NewController(GinkgoT())
 		osBuildConfigRepository = repositoryosbuildconfig.NewMockRepository(mockCtrl)
 		secretRepository = repositorysecret.NewMockRepository(mockCtrl)
-		osbuildConfigHandler = NewOSBuildConfigHandler(osBuildConfigRepository, secretRepository)
+		osBuildConfigTemplateRepository = repositoryosbuildconfigtemplate.NewMockRepository(mockCtrl)
+		osBuildRepository = repositoryosbuild.NewMockRepository(mockCtrl)
+		authorizer = kubeauth.NewMockAuthorizer(mockCtrl)
+		osbuildConfigHandler = NewOSBuildConfigHandler(osBuildConfigRepository, secretRepository, osBuildConfigTemplateRepository, osBuildRepository, authorizer,
+			ratelimit.NewKeyedLimiter(0.1, 3), replay.NewGuard(5*time.Minute), osbuildwatch.NewBroadcaster())
 
 		secret = corev1.Secret{
 			ObjectMeta: v1.ObjectMeta{
@@ -64,7 +85,7 @@ var _ = Describe("OSBuildConfig rest API", func() {
 			Data: secretData,
 		}
 		params = restapi.TriggerBuildParams{
-			Secret: secretVal,
+			Secret: &secretVal,
 		}
 		secretReference := &buildv1.SecretLocalReference{
 			Name: SecretName,
@@ -126,9 +147,509 @@ var _ = Describe("OSBuildConfig rest API", func() {
 			Expect(osbuildConfig.Annotations).ToNot(BeNil())
 			Expect(len(osbuildConfig.Annotations)).To(Equal(1))
 			Expect(osbuildConfig.Annotations[webHookAnnotationKey]).ToNot(BeNil())
+			response := readResponse(responseWriter)
+			Expect(*response.MessageId).To(Equal(osbuildConfig.Annotations[webHookAnnotationKey]))
+			Expect(*response.Directive).To(Equal(directiveBuild))
+			Expect(*response.Content).To(Equal(map[string]interface{}{"osbuild": OSBuildConfigName + "-1"}))
+		})
+
+		It("with unique trigger IDs", func() {
+			// given
+			lastVersion := 2
+			osbuildConfig.Namespace = Namespace
+			osbuildConfig.Status.LastVersion = &lastVersion
+			osBuildConfigRepository.EXPECT().Read(req.Context(), OSBuildConfigName, Namespace).Return(&osbuildConfig, nil).Times(2)
+			secretRepository.EXPECT().Read(req.Context(), SecretName, Namespace).Return(&secret, nil).Times(2)
+			osBuildConfigRepository.EXPECT().Patch(req.Context(), gomock.Any(), gomock.Any()).Return(nil).Times(2)
+			osbuildConfigHandler.TriggerBuild(responseWriter, req, Namespace, OSBuildConfigName, params)
+			firstID := osbuildConfig.Annotations[webHookAnnotationKey]
+			// the OSBuildConfig controller processed the first trigger and created an OSBuild of another trigger since
+			osbuildConfig.Status.LastWebhookTriggerTS = firstID
+			osBuildRepository.EXPECT().Read(req.Context(), OSBuildConfigName+"-2", Namespace).Return(&v1alpha1.OSBuild{}, nil)
+			secondResponseWriter := httptest.NewRecorder()
+
+			// when
+			osbuildConfigHandler.TriggerBuild(secondResponseWriter, req, Namespace, OSBuildConfigName, params)
+
+			// then
+			Expect(secondResponseWriter.Result().StatusCode).To(Equal(http.StatusOK))
+			secondID := osbuildConfig.Annotations[webHookAnnotationKey]
+			Expect(secondID).ToNot(Equal(firstID))
+			response := readResponse(secondResponseWriter)
+			Expect(*response.MessageId).To(Equal(secondID))
+			Expect(*response.Content).To(Equal(map[string]interface{}{"osbuild": OSBuildConfigName + "-3"}))
+		})
+
+		Context("when an identical trigger is in progress", func() {
+			const lastTriggerID = "0d3c2b1a-9f8e-4d7c-8b6a-5f4e3d2c1b0a"
+
+			var osBuild v1alpha1.OSBuild
+
+			BeforeEach(func() {
+				lastVersion := 4
+				osbuildConfig.Namespace = Namespace
+				osbuildConfig.Annotations = map[string]string{webHookAnnotationKey: lastTriggerID}
+				osbuildConfig.Status.LastVersion = &lastVersion
+				osbuildConfig.Status.LastWebhookTriggerTS = lastTriggerID
+				osBuild = v1alpha1.OSBuild{
+					ObjectMeta: v1.ObjectMeta{
+						Name:        OSBuildConfigName + "-4",
+						Namespace:   Namespace,
+						Annotations: map[string]string{v1alpha1.TriggerIDAnnotationKey: lastTriggerID},
+					},
+				}
+				osBuildConfigRepository.EXPECT().Read(req.Context(), OSBuildConfigName, Namespace).Return(&osbuildConfig, nil)
+				secretRepository.EXPECT().Read(req.Context(), SecretName, Namespace).Return(&secret, nil)
+			})
+
+			It("should report the building OSBuild", func() {
+				// given
+				osBuildRepository.EXPECT().Read(req.Context(), OSBuildConfigName+"-4", Namespace).Return(&osBuild, nil)
+
+				// when
+				osbuildConfigHandler.TriggerBuild(responseWriter, req, Namespace, OSBuildConfigName, params)
+
+				// then
+				Expect(responseWriter.Result().StatusCode).To(Equal(http.StatusAlreadyReported))
+				Expect(osbuildConfig.Annotations[webHookAnnotationKey]).To(Equal(lastTriggerID))
+				response := readResponse(responseWriter)
+				Expect(*response.MessageId).To(Equal(lastTriggerID))
+				Expect(*response.Directive).To(Equal(directiveInProgress))
+				Expect(*response.Content).To(Equal(map[string]interface{}{"osbuild": OSBuildConfigName + "-4"}))
+			})
+
+			It("should report the OSBuild still to be created", func() {
+				// given
+				osbuildConfig.Status.LastWebhookTriggerTS = "a-previous-trigger"
+
+				// when
+				osbuildConfigHandler.TriggerBuild(responseWriter, req, Namespace, OSBuildConfigName, params)
+
+				// then
+				Expect(responseWriter.Result().StatusCode).To(Equal(http.StatusAlreadyReported))
+				response := readResponse(responseWriter)
+				Expect(*response.Content).To(Equal(map[string]interface{}{"osbuild": OSBuildConfigName + "-5"}))
+			})
+
+			DescribeTable("should trigger a new build", func(prepare func()) {
+				// given
+				prepare()
+				osBuildRepository.EXPECT().Read(req.Context(), OSBuildConfigName+"-4", Namespace).Return(&osBuild, nil).AnyTimes()
+				osBuildConfigRepository.EXPECT().Patch(req.Context(), osbuildConfig.DeepCopy(), gomock.Any()).Return(nil)
+
+				// when
+				osbuildConfigHandler.TriggerBuild(responseWriter, req, Namespace, OSBuildConfigName, params)
+
+				// then
+				Expect(responseWriter.Result().StatusCode).To(Equal(http.StatusOK))
+				Expect(osbuildConfig.Annotations[webHookAnnotationKey]).ToNot(Equal(lastTriggerID))
+				response := readResponse(responseWriter)
+				Expect(*response.Content).To(Equal(map[string]interface{}{"osbuild": OSBuildConfigName + "-5"}))
+			},
+				Entry("when the OSBuild is ready", func() {
+					osBuild.Status.Conditions = []v1alpha1.Condition{{Type: v1alpha1.ConditionReady, Status: v1.ConditionTrue}}
+				}),
+				Entry("when the OSBuild failed", func() {
+					osBuild.Status.Conditions = []v1alpha1.Condition{{Type: v1alpha1.ConditionFailed, Status: v1.ConditionTrue}}
+				}),
+				Entry("when the OSBuild failed the validation", func() {
+					osBuild.Status.Conditions = []v1alpha1.Condition{{Type: v1alpha1.ConditionValidationFailed, Status: v1.ConditionTrue}}
+				}),
+				Entry("when the OSBuild has vulnerabilities", func() {
+					osBuild.Status.Conditions = []v1alpha1.Condition{{Type: v1alpha1.ConditionVulnerabilitiesFound, Status: v1.ConditionTrue}}
+				}),
+				Entry("when the OSBuild is of another trigger", func() {
+					osBuild.Annotations = nil
+				}),
+				Entry("when the last trigger was another one", func() {
+					osbuildConfig.Annotations[v1alpha1.WebHookTriggerDetailsAnnotationKey] = `{"triggeredBy":"Webhook","overrides":{"packages":["flotta-agent"]}}`
+				}),
+			)
+
+			It("should trigger a new build when the OSBuild was deleted", func() {
+				// given
+				returnErr := errors.NewNotFound(schema.GroupResource{Group: "", Resource: "notfound"}, "notfound")
+				osBuildRepository.EXPECT().Read(req.Context(), OSBuildConfigName+"-4", Namespace).Return(nil, returnErr)
+				osBuildConfigRepository.EXPECT().Patch(req.Context(), osbuildConfig.DeepCopy(), gomock.Any()).Return(nil)
+
+				// when
+				osbuildConfigHandler.TriggerBuild(responseWriter, req, Namespace, OSBuildConfigName, params)
+
+				// then
+				Expect(responseWriter.Result().StatusCode).To(Equal(http.StatusOK))
+			})
+		})
+
+		It("and drop the details of a previous Git webhook call", func() {
+			// given
+			osbuildConfig.Annotations = map[string]string{
+				webHookAnnotationKey:                        "2022-01-01 00:00:00",
+				v1alpha1.WebHookTriggerDetailsAnnotationKey: `{"triggeredBy":"GitHub"}`,
+			}
+			osBuildConfigRepository.EXPECT().Read(req.Context(), OSBuildConfigName, Namespace).Return(&osbuildConfig, nil)
+			secretRepository.EXPECT().Read(req.Context(), SecretName, Namespace).Return(&secret, nil)
+			osBuildConfigRepository.EXPECT().Patch(req.Context(), osbuildConfig.DeepCopy(), gomock.Any()).Return(nil)
+
+			// when
+			osbuildConfigHandler.TriggerBuild(responseWriter, req, Namespace, OSBuildConfigName, params)
+
+			// then
+			Expect(responseWriter.Result().StatusCode).To(Equal(http.StatusOK))
+			Expect(osbuildConfig.Annotations).To(HaveLen(1))
+			Expect(osbuildConfig.Annotations[webHookAnnotationKey]).ToNot(Equal("2022-01-01 00:00:00"))
+		})
+
+		Context("with overrides in the request body", func() {
+			var osBuildConfigTemplate v1alpha1.OSBuildConfigTemplate
+
+			BeforeEach(func() {
+				osbuildConfig.Namespace = Namespace
+				osbuildConfig.Spec.Template = &v1alpha1.Template{
+					OSBuildConfigTemplateRef: "test_template",
+					Parameters:               []v1alpha1.ParameterValue{{Name: "version", Value: "1.0.0"}},
+				}
+				osBuildConfigTemplate = v1alpha1.OSBuildConfigTemplate{
+					ObjectMeta: v1.ObjectMeta{Name: "test_template", Namespace: Namespace},
+					Spec: v1alpha1.OSBuildConfigTemplateSpec{
+						Parameters: []v1alpha1.Parameter{
+							{Name: "version", Type: "string"},
+							{Name: "replicas", Type: "int", DefaultValue: "1"},
+						},
+					},
+				}
+			})
+
+			newBodyRequest := func(body string) *http.Request {
+				bodyReq, _ := http.NewRequest("POST", "test_request", strings.NewReader(body))
+				osBuildConfigRepository.EXPECT().Read(bodyReq.Context(), OSBuildConfigName, Namespace).Return(&osbuildConfig, nil)
+				secretRepository.EXPECT().Read(bodyReq.Context(), SecretName, Namespace).Return(&secret, nil)
+				return bodyReq
+			}
+
+			It("should record them for the triggered build", func() {
+				// given
+				bodyReq := newBodyRequest(`{"parameters": [{"name": "version", "value": "1.2.3"}, {"name": "replicas", "value": "3"}], "packages": ["flotta-agent"]}`)
+				osBuildConfigTemplateRepository.EXPECT().Read(bodyReq.Context(), "test_template", Namespace).Return(&osBuildConfigTemplate, nil)
+				osBuildConfigRepository.EXPECT().Patch(bodyReq.Context(), osbuildConfig.DeepCopy(), gomock.Any()).Return(nil)
+
+				// when
+				osbuildConfigHandler.TriggerBuild(responseWriter, bodyReq, Namespace, OSBuildConfigName, params)
+
+				// then
+				Expect(responseWriter.Result().StatusCode).To(Equal(http.StatusOK))
+				var details v1alpha1.WebHookTriggerDetails
+				Expect(json.Unmarshal([]byte(osbuildConfig.Annotations[v1alpha1.WebHookTriggerDetailsAnnotationKey]), &details)).To(Succeed())
+				Expect(details).To(Equal(v1alpha1.WebHookTriggerDetails{
+					TriggeredBy: v1alpha1.TriggeredByWebhook,
+					Overrides: &v1alpha1.BuildOverrides{
+						Parameters: []v1alpha1.ParameterValue{{Name: "version", Value: "1.2.3"}, {Name: "replicas", Value: "3"}},
+						Packages:   []string{"flotta-agent"},
+					},
+				}))
+				Expect(osbuildConfig.Spec.Template.Parameters).To(Equal([]v1alpha1.ParameterValue{{Name: "version", Value: "1.0.0"}}))
+			})
+
+			It("should not read the template for packages only", func() {
+				// given
+				bodyReq := newBodyRequest(`{"packages": ["flotta-agent"]}`)
+				osBuildConfigRepository.EXPECT().Patch(bodyReq.Context(), osbuildConfig.DeepCopy(), gomock.Any()).Return(nil)
+
+				// when
+				osbuildConfigHandler.TriggerBuild(responseWriter, bodyReq, Namespace, OSBuildConfigName, params)
+
+				// then
+				Expect(responseWriter.Result().StatusCode).To(Equal(http.StatusOK))
+				Expect(osbuildConfig.Annotations[v1alpha1.WebHookTriggerDetailsAnnotationKey]).To(ContainSubstring("flotta-agent"))
+			})
+
+			It("should not record an empty body", func() {
+				// given
+				bodyReq := newBodyRequest(`{}`)
+				osBuildConfigRepository.EXPECT().Patch(bodyReq.Context(), osbuildConfig.DeepCopy(), gomock.Any()).Return(nil)
+
+				// when
+				osbuildConfigHandler.TriggerBuild(responseWriter, bodyReq, Namespace, OSBuildConfigName, params)
+
+				// then
+				Expect(responseWriter.Result().StatusCode).To(Equal(http.StatusOK))
+				Expect(osbuildConfig.Annotations).ToNot(HaveKey(v1alpha1.WebHookTriggerDetailsAnnotationKey))
+			})
+
+			DescribeTable("with bad request response, because the parameters are invalid", func(body string) {
+				// given
+				bodyReq := newBodyRequest(body)
+				osBuildConfigTemplateRepository.EXPECT().Read(bodyReq.Context(), "test_template", Namespace).Return(&osBuildConfigTemplate, nil)
+
+				// when
+				osbuildConfigHandler.TriggerBuild(responseWriter, bodyReq, Namespace, OSBuildConfigName, params)
+
+				// then
+				Expect(responseWriter.Result().StatusCode).To(Equal(http.StatusBadRequest))
+				Expect(osbuildConfig.Annotations).To(BeEmpty())
+			},
+				Entry("of another type", `{"parameters": [{"name": "replicas", "value": "three"}]}`),
+				Entry("not of the template", `{"parameters": [{"name": "unknown", "value": "1"}]}`),
+			)
+
+			DescribeTable("with bad request response, because the body is invalid", func(body string) {
+				// given
+				bodyReq := newBodyRequest(body)
+
+				// when
+				osbuildConfigHandler.TriggerBuild(responseWriter, bodyReq, Namespace, OSBuildConfigName, params)
+
+				// then
+				Expect(responseWriter.Result().StatusCode).To(Equal(http.StatusBadRequest))
+			},
+				Entry("not JSON", `parameters`),
+				Entry("with unknown fields", `{"customizations": {}}`),
+				Entry("with an empty package", `{"packages": [""]}`),
+			)
+
+			It("with bad request response, because osbuildConfig hasn't a template", func() {
+				// given
+				osbuildConfig.Spec.Template = nil
+				bodyReq := newBodyRequest(`{"parameters": [{"name": "version", "value": "1.2.3"}]}`)
+
+				// when
+				osbuildConfigHandler.TriggerBuild(responseWriter, bodyReq, Namespace, OSBuildConfigName, params)
+
+				// then
+				Expect(responseWriter.Result().StatusCode).To(Equal(http.StatusBadRequest))
+			})
+
+			It("with not found response, because the template doesn't exist", func() {
+				// given
+				bodyReq := newBodyRequest(`{"parameters": [{"name": "version", "value": "1.2.3"}]}`)
+				returnErr := errors.NewNotFound(schema.GroupResource{Group: "", Resource: "notfound"}, "notfound")
+				osBuildConfigTemplateRepository.EXPECT().Read(bodyReq.Context(), "test_template", Namespace).Return(nil, returnErr)
+
+				// when
+				osbuildConfigHandler.TriggerBuild(responseWriter, bodyReq, Namespace, OSBuildConfigName, params)
+
+				// then
+				Expect(responseWriter.Result().StatusCode).To(Equal(http.StatusNotFound))
+			})
+		})
+
+		Context("with a bearer token", func() {
+			const token = "eyJhbGciOiJSUzI1NiJ9.ci-token"
+
+			var attributes = kubeauth.ResourceAttributes("create", "osbuildconfigs", "instantiate", Namespace, OSBuildConfigName)
+
+			BeforeEach(func() {
+				params = restapi.TriggerBuildParams{Authorization: pointer.String("Bearer " + token)}
+			})
 
+			It("should trigger a build without a webhook trigger", func() {
+				// given
+				osbuildConfig.Spec.Triggers.WebHook = nil
+				authorizer.EXPECT().Authorize(req.Context(), token, attributes).Return(true, nil)
+				osBuildConfigRepository.EXPECT().Read(req.Context(), OSBuildConfigName, Namespace).Return(&osbuildConfig, nil)
+				osBuildConfigRepository.EXPECT().Patch(req.Context(), osbuildConfig.DeepCopy(), gomock.Any()).Return(nil)
+
+				// when
+				osbuildConfigHandler.TriggerBuild(responseWriter, req, Namespace, OSBuildConfigName, params)
+
+				// then
+				Expect(responseWriter.Result().StatusCode).To(Equal(http.StatusOK))
+				Expect(osbuildConfig.Annotations[webHookAnnotationKey]).ToNot(BeEmpty())
+			})
+
+			It("should ignore the secret", func() {
+				// given
+				params.Secret = pointer.String("456")
+				authorizer.EXPECT().Authorize(req.Context(), token, attributes).Return(true, nil)
+				osBuildConfigRepository.EXPECT().Read(req.Context(), OSBuildConfigName, Namespace).Return(&osbuildConfig, nil)
+				osBuildConfigRepository.EXPECT().Patch(req.Context(), osbuildConfig.DeepCopy(), gomock.Any()).Return(nil)
+
+				// when
+				osbuildConfigHandler.TriggerBuild(responseWriter, req, Namespace, OSBuildConfigName, params)
+
+				// then
+				Expect(responseWriter.Result().StatusCode).To(Equal(http.StatusOK))
+			})
+
+			DescribeTable("should not trigger a build", func(allowed bool, err error, expectedStatusCode int) {
+				// given
+				authorizer.EXPECT().Authorize(req.Context(), token, attributes).Return(allowed, err)
+
+				// when
+				osbuildConfigHandler.TriggerBuild(responseWriter, req, Namespace, OSBuildConfigName, params)
+
+				// then
+				Expect(responseWriter.Result().StatusCode).To(Equal(expectedStatusCode))
+				Expect(osbuildConfig.Annotations).To(BeEmpty())
+			},
+				Entry("with forbidden response, because the user is not allowed", false, nil, http.StatusForbidden),
+				Entry("with unauthorized response, because the token is not valid", false, kubeauth.ErrUnauthenticated, http.StatusUnauthorized),
+				Entry("with internalServerError response, because the token cannot be reviewed", false, errors.NewBadRequest("test"), http.StatusInternalServerError),
+			)
+		})
+
+		Context("with a signed request", func() {
+			const (
+				nonce = "6b1f9a2e-3c4d-4e5f-8a7b-9c0d1e2f3a4b"
+				body  = `{"packages": ["flotta-agent"]}`
+			)
+
+			signParams := func(timestamp time.Time, body string, key string) restapi.TriggerBuildParams {
+				mac := hmac.New(sha256.New, []byte(key))
+				mac.Write([]byte(fmt.Sprintf("%d.%s.%s", timestamp.Unix(), nonce, body)))
+				return restapi.TriggerBuildParams{
+					XSignature256:       pointer.String("sha256=" + hex.EncodeToString(mac.Sum(nil))),
+					XSignatureTimestamp: pointer.Int64(timestamp.Unix()),
+					XSignatureNonce:     pointer.String(nonce),
+				}
+			}
+
+			newSignedRequest := func(body string) *http.Request {
+				signedReq, _ := http.NewRequest("POST", "test_request", strings.NewReader(body))
+				osBuildConfigRepository.EXPECT().Read(signedReq.Context(), OSBuildConfigName, Namespace).Return(&osbuildConfig, nil)
+				secretRepository.EXPECT().Read(signedReq.Context(), SecretName, Namespace).Return(&secret, nil)
+				return signedReq
+			}
+
+			It("should trigger a build with the overrides of the body", func() {
+				// given
+				signedReq := newSignedRequest(body)
+				osBuildConfigRepository.EXPECT().Patch(signedReq.Context(), osbuildConfig.DeepCopy(), gomock.Any()).Return(nil)
+
+				// when
+				osbuildConfigHandler.TriggerBuild(responseWriter, signedReq, Namespace, OSBuildConfigName, signParams(time.Now(), body, secretVal))
+
+				// then
+				Expect(responseWriter.Result().StatusCode).To(Equal(http.StatusOK))
+				var details v1alpha1.WebHookTriggerDetails
+				Expect(json.Unmarshal([]byte(osbuildConfig.Annotations[v1alpha1.WebHookTriggerDetailsAnnotationKey]), &details)).To(Succeed())
+				Expect(details.Overrides.Packages).To(Equal([]string{"flotta-agent"}))
+			})
+
+			It("with conflict response, because the request is replayed", func() {
+				// given
+				signedParams := signParams(time.Now(), body, secretVal)
+				signedReq := newSignedRequest(body)
+				osBuildConfigRepository.EXPECT().Patch(signedReq.Context(), osbuildConfig.DeepCopy(), gomock.Any()).Return(nil)
+				osbuildConfigHandler.TriggerBuild(responseWriter, signedReq, Namespace, OSBuildConfigName, signedParams)
+				triggerID := osbuildConfig.Annotations[webHookAnnotationKey]
+				responseWriter = httptest.NewRecorder()
+
+				// when
+				osbuildConfigHandler.TriggerBuild(responseWriter, newSignedRequest(body), Namespace, OSBuildConfigName, signedParams)
+
+				// then
+				Expect(responseWriter.Result().StatusCode).To(Equal(http.StatusConflict))
+				Expect(osbuildConfig.Annotations[webHookAnnotationKey]).To(Equal(triggerID))
+			})
+
+			DescribeTable("with forbidden response", func(signedParams restapi.TriggerBuildParams) {
+				// given
+				signedReq := newSignedRequest(body)
+
+				// when
+				osbuildConfigHandler.TriggerBuild(responseWriter, signedReq, Namespace, OSBuildConfigName, signedParams)
+
+				// then
+				Expect(responseWriter.Result().StatusCode).To(Equal(http.StatusForbidden))
+				Expect(osbuildConfig.Annotations).To(BeEmpty())
+			},
+				Entry("because the request is signed with another secret", signParams(time.Now(), body, "456")),
+				Entry("because the body is not the signed one", signParams(time.Now(), `{"packages": ["vim"]}`, secretVal)),
+				Entry("because the timestamp is out of the replay window", signParams(time.Now().Add(-time.Hour), body, secretVal)),
+			)
+
+			It("with internalServerError response, because the secret has no value", func() {
+				// given
+				secret.Data = map[string][]byte{}
+				signedReq := newSignedRequest(body)
+
+				// when
+				osbuildConfigHandler.TriggerBuild(responseWriter, signedReq, Namespace, OSBuildConfigName, signParams(time.Now(), body, ""))
+
+				// then
+				Expect(responseWriter.Result().StatusCode).To(Equal(http.StatusInternalServerError))
+				Expect(osbuildConfig.Annotations).To(BeEmpty())
+			})
+
+			It("with unauthorized response, because the request has no nonce", func() {
+				// given
+				signedParams := signParams(time.Now(), body, secretVal)
+				signedParams.XSignatureNonce = nil
+				signedReq := newSignedRequest(body)
+
+				// when
+				osbuildConfigHandler.TriggerBuild(responseWriter, signedReq, Namespace, OSBuildConfigName, signedParams)
+
+				// then
+				Expect(responseWriter.Result().StatusCode).To(Equal(http.StatusUnauthorized))
+			})
+		})
+
+		Context("over the rate limit of the OSBuildConfig", func() {
+			BeforeEach(func() {
+				osbuildConfigHandler.TriggerLimiter = ratelimit.NewKeyedLimiter(0.1, 1)
+				osBuildConfigRepository.EXPECT().Read(gomock.Any(), OSBuildConfigName, Namespace).Return(&osbuildConfig, nil).Times(2)
+				secretRepository.EXPECT().Read(gomock.Any(), SecretName, Namespace).Return(&secret, nil).Times(2)
+				osBuildConfigRepository.EXPECT().Patch(req.Context(), gomock.Any(), gomock.Any()).Return(nil)
+				osbuildConfigHandler.TriggerBuild(responseWriter, req, Namespace, OSBuildConfigName, params)
+				Expect(responseWriter.Result().StatusCode).To(Equal(http.StatusOK))
+				responseWriter = httptest.NewRecorder()
+			})
+
+			It("with too many requests response, because another build is triggered", func() {
+				// given
+				triggerID := osbuildConfig.Annotations[webHookAnnotationKey]
+				bodyReq, _ := http.NewRequest("POST", "test_request", strings.NewReader(`{"packages": ["flotta-agent"]}`))
+
+				// when
+				osbuildConfigHandler.TriggerBuild(responseWriter, bodyReq, Namespace, OSBuildConfigName, params)
+
+				// then
+				Expect(responseWriter.Result().StatusCode).To(Equal(http.StatusTooManyRequests))
+				Expect(responseWriter.Result().Header.Get("Retry-After")).To(Equal("10"))
+				Expect(osbuildConfig.Annotations[webHookAnnotationKey]).To(Equal(triggerID))
+			})
+
+			It("should report the identical trigger in progress", func() {
+				// when
+				osbuildConfigHandler.TriggerBuild(responseWriter, req, Namespace, OSBuildConfigName, params)
+
+				// then
+				Expect(responseWriter.Result().StatusCode).To(Equal(http.StatusAlreadyReported))
+			})
+		})
+
+		It("with unauthorized response, because the request has neither a secret nor a bearer token", func() {
+			// given
+			params = restapi.TriggerBuildParams{Authorization: pointer.String("Basic dXNlcjpwYXNzd29yZA==")}
+			osBuildConfigRepository.EXPECT().Read(req.Context(), OSBuildConfigName, Namespace).Return(&osbuildConfig, nil)
+			secretRepository.EXPECT().Read(req.Context(), SecretName, Namespace).Return(&secret, nil)
+
+			// when
+			osbuildConfigHandler.TriggerBuild(responseWriter, req, Namespace, OSBuildConfigName, params)
+
+			// then
+			Expect(responseWriter.Result().StatusCode).To(Equal(http.StatusUnauthorized))
 		})
 
+		DescribeTable("with internalServerError response, because the secret has no value", func(data map[string][]byte) {
+			// given
+			secret.Data = data
+			params = restapi.TriggerBuildParams{Secret: pointer.String("")}
+			osBuildConfigRepository.EXPECT().Read(req.Context(), OSBuildConfigName, Namespace).Return(&osbuildConfig, nil)
+			secretRepository.EXPECT().Read(req.Context(), SecretName, Namespace).Return(&secret, nil)
+
+			// when
+			osbuildConfigHandler.TriggerBuild(responseWriter, req, Namespace, OSBuildConfigName, params)
+
+			// then
+			Expect(responseWriter.Result().StatusCode).To(Equal(http.StatusInternalServerError))
+			Expect(osbuildConfig.Annotations).To(BeEmpty())
+		},
+			Entry("for the missing key", map[string][]byte{}),
+			Entry("for the empty key", map[string][]byte{"WebHookSecretKey": {}}),
+		)
+
 		It("with not found response, because osbuildConfig doesn't exist", func() {
 			// given
 			returnErr := errors.NewNotFound(schema.GroupResource{Group: "", Resource: "notfound"}, "notfound")
@@ -195,7 +716,7 @@ var _ = Describe("OSBuildConfig rest API", func() {
 			// given
 			osBuildConfigRepository.EXPECT().Read(req.Context(), OSBuildConfigName, Namespace).Return(&osbuildConfig, nil)
 			secretRepository.EXPECT().Read(req.Context(), SecretName, Namespace).Return(&secret, nil)
-			params.Secret = "456"
+			params.Secret = pointer.String("456")
 
 			// when
 			osbuildConfigHandler.TriggerBuild(responseWriter, req, Namespace, OSBuildConfigName, params)
@@ -223,3 +744,9 @@ var _ = Describe("OSBuildConfig rest API", func() {
 	})
 
 })
+
+func readResponse(responseWriter *httptest.ResponseRecorder) restapi.MessageResponse {
+	var response restapi.MessageResponse
+	Expect(json.NewDecoder(responseWriter.Result().Body).Decode(&response)).To(Succeed())
+	return response
+}
@@ -0,0 +1,439 @@
+package osbuildconfig
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	buildv1 "github.com/openshift/api/build/v1"
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/api/errors"
+
+	"github.com/project-flotta/osbuild-operator/api/v1alpha1"
+	"github.com/project-flotta/osbuild-operator/internal/httpapi"
+	"github.com/project-flotta/osbuild-operator/internal/indexer"
+	"github.com/project-flotta/osbuild-operator/internal/kubeauth"
+	loggerutil "github.com/project-flotta/osbuild-operator/internal/logger"
+	"github.com/project-flotta/osbuild-operator/restapi"
+)
+
+func (o *OSBuildConfigHandler) ListOSBuildConfigs(w http.ResponseWriter, r *http.Request, namespace string, params restapi.ListOSBuildConfigsParams) {
+	logger, err := loggerutil.Logger(httpapi.GlobalHttpAPIConf.LogLevel)
+	if err != nil {
+		return
+	}
+
+	osBuildConfigs, ok := o.listAuthorizedOSBuildConfigs(w, r, logger, namespace, params.Secret, params.Authorization)
+	if !ok {
+		return
+	}
+
+	response := make([]restapi.Osbuildconfig, 0, len(osBuildConfigs))
+	for i := range osBuildConfigs {
+		response = append(response, toRestOSBuildConfig(&osBuildConfigs[i]))
+	}
+	writeJSON(w, logger, http.StatusOK, response)
+}
+
+func (o *OSBuildConfigHandler) GetOSBuildConfig(w http.ResponseWriter, r *http.Request, namespace string, name string, params restapi.GetOSBuildConfigParams) {
+	logger, err := loggerutil.Logger(httpapi.GlobalHttpAPIConf.LogLevel)
+	if err != nil {
+		return
+	}
+
+	osBuildConfig, ok := o.readAuthorizedOSBuildConfig(w, r, logger, namespace, name, params.Secret, params.Authorization)
+	if !ok {
+		return
+	}
+	writeJSON(w, logger, http.StatusOK, toRestOSBuildConfig(osBuildConfig))
+}
+
+func (o *OSBuildConfigHandler) ListOSBuilds(w http.ResponseWriter, r *http.Request, namespace string, params restapi.ListOSBuildsParams) {
+	logger, err := loggerutil.Logger(httpapi.GlobalHttpAPIConf.LogLevel)
+	if err != nil {
+		return
+	}
+
+	var osBuildConfigs []v1alpha1.OSBuildConfig
+	if token, bearer := getBearerToken(params.Authorization); bearer {
+		attributes := kubeauth.ResourceAttributes("list", kubeauth.OSBuildResource, "", namespace, "")
+		if !o.checkTokenAuthorized(w, r, logger, token, attributes) {
+			return
+		}
+		if params.Osbuildconfig != nil {
+			osBuildConfig, ok := o.readOSBuildConfig(w, r, logger, namespace, *params.Osbuildconfig)
+			if !ok {
+				return
+			}
+			osBuildConfigs = []v1alpha1.OSBuildConfig{*osBuildConfig}
+		} else {
+			osBuildConfigs, err = o.OSBuildConfigRepository.List(r.Context(), namespace)
+			if err != nil {
+				logger.Error(err, fmt.Sprintf("cannot list the OSBuildConfigs of namespace %s", namespace))
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+		}
+	} else if params.Osbuildconfig != nil {
+		osBuildConfig, ok := o.readAuthorizedOSBuildConfig(w, r, logger, namespace, *params.Osbuildconfig, params.Secret, nil)
+		if !ok {
+			return
+		}
+		osBuildConfigs = []v1alpha1.OSBuildConfig{*osBuildConfig}
+	} else {
+		var ok bool
+		osBuildConfigs, ok = o.listAuthorizedOSBuildConfigs(w, r, logger, namespace, params.Secret, nil)
+		if !ok {
+			return
+		}
+	}
+
+	authorized := map[string]bool{}
+	for _, osBuildConfig := range osBuildConfigs {
+		authorized[osBuildConfig.Name] = true
+	}
+
+	response := []restapi.Osbuild{}
+	if len(authorized) > 0 {
+		osBuilds, err := o.OSBuildRepository.List(r.Context(), namespace)
+		if err != nil {
+			logger.Error(err, fmt.Sprintf("cannot list the OSBuilds of namespace %s", namespace))
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		sort.SliceStable(osBuilds, func(i, j int) bool {
+			if osBuilds[i].CreationTimestamp.Equal(&osBuilds[j].CreationTimestamp) {
+				return osBuilds[i].Name < osBuilds[j].Name
+			}
+			return osBuilds[i].CreationTimestamp.Before(&osBuilds[j].CreationTimestamp)
+		})
+		for i := range osBuilds {
+			if authorized[getOSBuildConfigName(&osBuilds[i])] {
+				response = append(response, toRestOSBuild(&osBuilds[i]))
+			}
+		}
+	}
+	writeJSON(w, logger, http.StatusOK, response)
+}
+
+func (o *OSBuildConfigHandler) GetOSBuild(w http.ResponseWriter, r *http.Request, namespace string, name string, params restapi.GetOSBuildParams) {
+	logger, err := loggerutil.Logger(httpapi.GlobalHttpAPIConf.LogLevel)
+	if err != nil {
+		return
+	}
+
+	osBuild, ok := o.readAuthorizedOSBuild(w, r, logger, "get", namespace, name, params.Secret, params.Authorization)
+	if !ok {
+		return
+	}
+
+	if params.Wait != nil && *params.Wait > 0 && !osBuild.IsFinished() {
+		timeout := time.Duration(*params.Wait) * time.Second
+		if timeout > httpapi.GlobalHttpAPIConf.MaxWaitTimeout {
+			timeout = httpapi.GlobalHttpAPIConf.MaxWaitTimeout
+		}
+		osBuild, ok = o.waitForOSBuild(w, r, logger, osBuild, timeout)
+		if !ok {
+			return
+		}
+	}
+	writeJSON(w, logger, http.StatusOK, toRestOSBuild(osBuild))
+}
+
+// waitForOSBuild reads the OSBuild until it is finished or the timeout expires, and returns its last version
+func (o *OSBuildConfigHandler) waitForOSBuild(w http.ResponseWriter, r *http.Request, logger *zap.SugaredLogger, osBuild *v1alpha1.OSBuild, timeout time.Duration) (*v1alpha1.OSBuild, bool) {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	ticker := time.NewTicker(httpapi.GlobalHttpAPIConf.WaitPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return osBuild, true
+		case <-timer.C:
+			return osBuild, true
+		case <-ticker.C:
+			var ok bool
+			osBuild, ok = o.readOSBuild(w, r, logger, osBuild.Namespace, osBuild.Name)
+			if !ok {
+				return nil, false
+			}
+			if osBuild.IsFinished() {
+				return osBuild, true
+			}
+		}
+	}
+}
+
+func (o *OSBuildConfigHandler) readOSBuild(w http.ResponseWriter, r *http.Request, logger *zap.SugaredLogger, namespace string, name string) (*v1alpha1.OSBuild, bool) {
+	osBuild, err := o.OSBuildRepository.Read(r.Context(), name, namespace)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			logger.Error("resource OSBuild not found", "OSBuild", name)
+			w.WriteHeader(http.StatusNotFound)
+			return nil, false
+		}
+		logger.Error(err, fmt.Sprintf("cannot retrieve OSBuild %s", name))
+		w.WriteHeader(http.StatusInternalServerError)
+		return nil, false
+	}
+	return osBuild, true
+}
+
+// readAuthorizedOSBuild reads the OSBuild, the user of the bearer token being allowed the verb on it or the secret
+// being the one of one of the triggers of its OSBuildConfig
+func (o *OSBuildConfigHandler) readAuthorizedOSBuild(w http.ResponseWriter, r *http.Request, logger *zap.SugaredLogger, verb string, namespace string, name string, secretValue *string, authorization *string) (*v1alpha1.OSBuild, bool) {
+	token, bearer := getBearerToken(authorization)
+	if bearer {
+		attributes := kubeauth.ResourceAttributes(verb, kubeauth.OSBuildResource, "", namespace, name)
+		if !o.checkTokenAuthorized(w, r, logger, token, attributes) {
+			return nil, false
+		}
+	}
+
+	osBuild, ok := o.readOSBuild(w, r, logger, namespace, name)
+	if !ok {
+		return nil, false
+	}
+	if bearer {
+		return osBuild, true
+	}
+
+	// the OSBuilds are authorized by the secrets of their OSBuildConfig
+	osBuildConfigName := getOSBuildConfigName(osBuild)
+	if osBuildConfigName == "" {
+		logger.Error("resource OSBuild is not controlled by an OSBuildConfig", "OSBuild", name)
+		w.WriteHeader(http.StatusForbidden)
+		return nil, false
+	}
+	osBuildConfig, err := o.OSBuildConfigRepository.Read(r.Context(), osBuildConfigName, namespace)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			logger.Error("resource OSBuildConfig of the OSBuild not found", "OSBuildConfig", osBuildConfigName)
+			w.WriteHeader(http.StatusForbidden)
+			return nil, false
+		}
+		logger.Error(err, fmt.Sprintf("cannot retrieve OSBuildConfig %s", osBuildConfigName))
+		w.WriteHeader(http.StatusInternalServerError)
+		return nil, false
+	}
+	if !o.checkAuthorized(w, r, logger, osBuildConfig, secretValue) {
+		return nil, false
+	}
+	return osBuild, true
+}
+
+// readAuthorizedOSBuildConfig reads the OSBuildConfig, the user of the bearer token being allowed to get it or the
+// secret being the one of one of its triggers
+func (o *OSBuildConfigHandler) readAuthorizedOSBuildConfig(w http.ResponseWriter, r *http.Request, logger *zap.SugaredLogger, namespace string, name string, secretValue *string, authorization *string) (*v1alpha1.OSBuildConfig, bool) {
+	token, bearer := getBearerToken(authorization)
+	if bearer {
+		attributes := kubeauth.ResourceAttributes("get", kubeauth.OSBuildConfigResource, "", namespace, name)
+		if !o.checkTokenAuthorized(w, r, logger, token, attributes) {
+			return nil, false
+		}
+	}
+
+	osBuildConfig, ok := o.readOSBuildConfig(w, r, logger, namespace, name)
+	if !ok {
+		return nil, false
+	}
+	if !bearer && !o.checkAuthorized(w, r, logger, osBuildConfig, secretValue) {
+		return nil, false
+	}
+	return osBuildConfig, true
+}
+
+func (o *OSBuildConfigHandler) checkAuthorized(w http.ResponseWriter, r *http.Request, logger *zap.SugaredLogger, osBuildConfig *v1alpha1.OSBuildConfig, secretValue *string) bool {
+	if secretValue == nil {
+		logger.Error("the request has neither a secret nor a bearer token")
+		w.WriteHeader(http.StatusUnauthorized)
+		return false
+	}
+	authorized, err := o.isAuthorized(r.Context(), osBuildConfig, *secretValue)
+	if err != nil {
+		logger.Error(err, fmt.Sprintf("cannot read the secrets of OSBuildConfig %s", osBuildConfig.Name))
+		w.WriteHeader(http.StatusInternalServerError)
+		return false
+	}
+	if !authorized {
+		logger.Error("the secret does not grant access to the OSBuildConfig", "OSBuildConfig", osBuildConfig.Name)
+		w.WriteHeader(http.StatusForbidden)
+		return false
+	}
+	return true
+}
+
+// listAuthorizedOSBuildConfigs returns the OSBuildConfigs of the namespace, all of them when the user of the bearer
+// token is allowed to list them, otherwise the ones the secret grants access to
+func (o *OSBuildConfigHandler) listAuthorizedOSBuildConfigs(w http.ResponseWriter, r *http.Request, logger *zap.SugaredLogger, namespace string, secretValue *string, authorization *string) ([]v1alpha1.OSBuildConfig, bool) {
+	token, bearer := getBearerToken(authorization)
+	if bearer {
+		attributes := kubeauth.ResourceAttributes("list", kubeauth.OSBuildConfigResource, "", namespace, "")
+		if !o.checkTokenAuthorized(w, r, logger, token, attributes) {
+			return nil, false
+		}
+	} else if secretValue == nil {
+		logger.Error("the request has neither a secret nor a bearer token")
+		w.WriteHeader(http.StatusUnauthorized)
+		return nil, false
+	}
+
+	osBuildConfigs, err := o.OSBuildConfigRepository.List(r.Context(), namespace)
+	if err != nil {
+		logger.Error(err, fmt.Sprintf("cannot list the OSBuildConfigs of namespace %s", namespace))
+		w.WriteHeader(http.StatusInternalServerError)
+		return nil, false
+	}
+
+	var authorized []v1alpha1.OSBuildConfig
+	for i := range osBuildConfigs {
+		ok := bearer
+		if !ok {
+			ok, err = o.isAuthorized(r.Context(), &osBuildConfigs[i], *secretValue)
+			if err != nil {
+				logger.Error(err, fmt.Sprintf("cannot read the secrets of OSBuildConfig %s", osBuildConfigs[i].Name))
+				w.WriteHeader(http.StatusInternalServerError)
+				return nil, false
+			}
+		}
+		if ok {
+			authorized = append(authorized, osBuildConfigs[i])
+		}
+	}
+	sort.Slice(authorized, func(i, j int) bool {
+		return authorized[i].Name < authorized[j].Name
+	})
+	return authorized, true
+}
+
+// isAuthorized returns whether the secret value is the one of any of the webhook, GitHub and GitLab triggers of the
+// OSBuildConfig, i.e. whether the caller could trigger its builds. The secrets that do not exist authorize nothing
+func (o *OSBuildConfigHandler) isAuthorized(ctx context.Context, osBuildConfig *v1alpha1.OSBuildConfig, secretValue string) (bool, error) {
+	if secretValue == "" {
+		return false, nil
+	}
+
+	var secretReferences []*buildv1.SecretLocalReference
+	triggers := osBuildConfig.Spec.Triggers
+	if triggers.WebHook != nil {
+		secretReferences = append(secretReferences, triggers.WebHook.SecretReference)
+	}
+	if triggers.GitHub != nil {
+		secretReferences = append(secretReferences, triggers.GitHub.SecretReference)
+	}
+	if triggers.GitLab != nil {
+		secretReferences = append(secretReferences, triggers.GitLab.SecretReference)
+	}
+
+	for _, secretReference := range secretReferences {
+		if secretReference == nil {
+			continue
+		}
+		webhookSecret, err := o.SecretRepository.Read(ctx, secretReference.Name, osBuildConfig.Namespace)
+		if err != nil {
+			if errors.IsNotFound(err) {
+				continue
+			}
+			return false, err
+		}
+		expected, ok := webhookSecret.Data[webHookSecretKey]
+		if ok && subtle.ConstantTimeCompare(expected, []byte(secretValue)) == 1 {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// getOSBuildConfigName returns the name of the OSBuildConfig controlling the OSBuild, empty if none
+func getOSBuildConfigName(osBuild *v1alpha1.OSBuild) string {
+	names := indexer.BuildByConfigIndexFunc(osBuild)
+	if len(names) == 0 {
+		return ""
+	}
+	return names[0]
+}
+
+func toRestOSBuildConfig(osBuildConfig *v1alpha1.OSBuildConfig) restapi.Osbuildconfig {
+	targetImageType := string(osBuildConfig.Spec.Details.TargetImage.TargetImageType)
+	response := restapi.Osbuildconfig{
+		Name:            osBuildConfig.Name,
+		Namespace:       osBuildConfig.Namespace,
+		TargetImageType: &targetImageType,
+		Conditions:      toRestConditions(osBuildConfig.Status.Conditions),
+	}
+	if osBuildConfig.Status.LastVersion != nil {
+		lastVersion := *osBuildConfig.Status.LastVersion
+		lastOSBuild := fmt.Sprintf("%s-%d", osBuildConfig.Name, lastVersion)
+		response.LastVersion = &lastVersion
+		response.LastOSBuild = &lastOSBuild
+	}
+	return response
+}
+
+func toRestOSBuild(osBuild *v1alpha1.OSBuild) restapi.Osbuild {
+	response := restapi.Osbuild{
+		Name:         osBuild.Name,
+		Namespace:    osBuild.Namespace,
+		Phase:        getOSBuildPhase(osBuild),
+		Conditions:   toRestConditions(osBuild.Status.Conditions),
+		AccessUrl:    optionalString(osBuild.Status.AccessUrl),
+		ComposerIso:  optionalString(osBuild.Status.ComposerIso),
+		OstreeCommit: optionalString(osBuild.Status.OSTreeCommit),
+		TriggeredBy:  optionalString(string(osBuild.Spec.TriggeredBy)),
+		TriggerId:    optionalString(osBuild.Annotations[v1alpha1.TriggerIDAnnotationKey]),
+	}
+	response.OsbuildConfig = optionalString(getOSBuildConfigName(osBuild))
+	if !osBuild.CreationTimestamp.IsZero() {
+		creationTimestamp := osBuild.CreationTimestamp.Time
+		response.CreationTimestamp = &creationTimestamp
+	}
+	if osBuild.Spec.Details != nil {
+		response.TargetImageType = optionalString(string(osBuild.Spec.Details.TargetImage.TargetImageType))
+	} else if osBuild.Spec.EdgeInstallerDetails != nil {
+		response.TargetImageType = optionalString(string(v1alpha1.EdgeInstallerImageType))
+	}
+	return response
+}
+
+// getOSBuildPhase returns Ready or Failed for the finished OSBuilds, whatever the failure condition, Building otherwise
+func getOSBuildPhase(osBuild *v1alpha1.OSBuild) restapi.OsbuildPhase {
+	if osBuild.IsReady() {
+		return restapi.Ready
+	}
+	if osBuild.IsFailed() {
+		return restapi.Failed
+	}
+	return restapi.Building
+}
+
+func toRestConditions(conditions []v1alpha1.Condition) *[]restapi.Condition {
+	if len(conditions) == 0 {
+		return nil
+	}
+	response := make([]restapi.Condition, 0, len(conditions))
+	for _, condition := range conditions {
+		restCondition := restapi.Condition{
+			Type:    string(condition.Type),
+			Status:  string(condition.Status),
+			Message: condition.Message,
+		}
+		if condition.LastTransitionTime != nil {
+			lastTransitionTime := condition.LastTransitionTime.Time
+			restCondition.LastTransitionTime = &lastTransitionTime
+		}
+		response = append(response, restCondition)
+	}
+	return &response
+}
+
+func optionalString(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
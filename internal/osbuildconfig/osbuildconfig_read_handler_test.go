@@ -0,0 +1,517 @@
+package osbuildconfig
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	buildv1 "github.com/openshift/api/build/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/utils/pointer"
+
+	"github.com/project-flotta/osbuild-operator/api/v1alpha1"
+	"github.com/project-flotta/osbuild-operator/internal/httpapi"
+	"github.com/project-flotta/osbuild-operator/internal/kubeauth"
+	"github.com/project-flotta/osbuild-operator/internal/osbuildwatch"
+	"github.com/project-flotta/osbuild-operator/internal/ratelimit"
+	"github.com/project-flotta/osbuild-operator/internal/replay"
+	repositoryosbuild "github.com/project-flotta/osbuild-operator/internal/repository/osbuild"
+	repositoryosbuildconfig "github.com/project-flotta/osbuild-operator/internal/repository/osbuildconfig"
+	repositoryosbuildconfigtemplate "github.com/project-flotta/osbuild-operator/internal/repository/osbuildconfigtemplate"
+	repositorysecret "github.com/project-flotta/osbuild-operator/internal/repository/secret"
+	"github.com/project-flotta/osbuild-operator/restapi"
+)
+
+var _ = Describe("OSBuildConfig read API", func() {
+	const (
+		otherOSBuildConfigName = "other_osbuildconfig"
+		otherSecretName        = "other_secret"
+		accessUrl              = "https://s3.example.com/edge-container.tar"
+	)
+
+	var (
+		mockCtrl           *gomock.Controller
+		osbuildConfig      v1alpha1.OSBuildConfig
+		otherOSBuildConfig v1alpha1.OSBuildConfig
+		osBuild            v1alpha1.OSBuild
+		secretVal          = "123"
+
+		osBuildConfigRepository *repositoryosbuildconfig.MockRepository
+		secretRepository        *repositorysecret.MockRepository
+		osBuildRepository       *repositoryosbuild.MockRepository
+		authorizer              *kubeauth.MockAuthorizer
+		responseWriter          *httptest.ResponseRecorder
+		osbuildConfigHandler    *OSBuildConfigHandler
+		req                     *http.Request
+	)
+
+	newOSBuild := func(name string, osBuildConfigName string, creationTime time.Time) v1alpha1.OSBuild {
+		return v1alpha1.OSBuild{
+			ObjectMeta: v1.ObjectMeta{
+				Name:              name,
+				Namespace:         Namespace,
+				CreationTimestamp: v1.NewTime(creationTime),
+				OwnerReferences: []v1.OwnerReference{{
+					APIVersion: v1alpha1.GroupVersion.String(),
+					Kind:       "OSBuildConfig",
+					Name:       osBuildConfigName,
+					Controller: pointer.Bool(true),
+				}},
+			},
+			Spec: v1alpha1.OSBuildSpec{
+				Details: &v1alpha1.BuildDetails{
+					TargetImage: v1alpha1.TargetImage{TargetImageType: v1alpha1.EdgeContainerImageType},
+				},
+				TriggeredBy: v1alpha1.TriggeredByWebhook,
+			},
+		}
+	}
+
+	finished := func(osBuild v1alpha1.OSBuild) *v1alpha1.OSBuild {
+		osBuild.Status.Conditions = []v1alpha1.Condition{{Type: v1alpha1.ConditionReady, Status: v1.ConditionTrue}}
+		osBuild.Status.AccessUrl = accessUrl
+		return &osBuild
+	}
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		osBuildConfigRepository = repositoryosbuildconfig.NewMockRepository(mockCtrl)
+		secretRepository = repositorysecret.NewMockRepository(mockCtrl)
+		osBuildRepository = repositoryosbuild.NewMockRepository(mockCtrl)
+		authorizer = kubeauth.NewMockAuthorizer(mockCtrl)
+		osbuildConfigHandler = NewOSBuildConfigHandler(osBuildConfigRepository, secretRepository,
+			repositoryosbuildconfigtemplate.NewMockRepository(mockCtrl), osBuildRepository, authorizer,
+			ratelimit.NewKeyedLimiter(0.1, 3), replay.NewGuard(5*time.Minute), osbuildwatch.NewBroadcaster())
+
+		lastVersion := 2
+		osbuildConfig = v1alpha1.OSBuildConfig{
+			ObjectMeta: v1.ObjectMeta{Name: OSBuildConfigName, Namespace: Namespace},
+			Spec: v1alpha1.OSBuildConfigSpec{
+				Details: v1alpha1.BuildDetails{
+					TargetImage: v1alpha1.TargetImage{TargetImageType: v1alpha1.EdgeContainerImageType},
+				},
+				Triggers: v1alpha1.BuildTriggers{
+					WebHook: &buildv1.WebHookTrigger{SecretReference: &buildv1.SecretLocalReference{Name: SecretName}},
+				},
+			},
+			Status: v1alpha1.OSBuildConfigStatus{LastVersion: &lastVersion},
+		}
+		otherOSBuildConfig = v1alpha1.OSBuildConfig{
+			ObjectMeta: v1.ObjectMeta{Name: otherOSBuildConfigName, Namespace: Namespace},
+			Spec: v1alpha1.OSBuildConfigSpec{
+				Triggers: v1alpha1.BuildTriggers{
+					GitHub: &v1alpha1.GitWebHookTrigger{SecretReference: &buildv1.SecretLocalReference{Name: otherSecretName}},
+				},
+			},
+		}
+		osBuild = newOSBuild(OSBuildConfigName+"-2", OSBuildConfigName, time.Date(2022, 1, 2, 0, 0, 0, 0, time.UTC))
+		osBuild.Annotations = map[string]string{v1alpha1.TriggerIDAnnotationKey: "6b9f1c2e-5d4a-4b8e-9f3c-2a1d0e7b6c5f"}
+
+		secretRepository.EXPECT().Read(gomock.Any(), SecretName, Namespace).Return(&corev1.Secret{
+			Data: map[string][]byte{webHookSecretKey: []byte(secretVal)},
+		}, nil).AnyTimes()
+		secretRepository.EXPECT().Read(gomock.Any(), otherSecretName, Namespace).Return(&corev1.Secret{
+			Data: map[string][]byte{webHookSecretKey: []byte("456")},
+		}, nil).AnyTimes()
+
+		responseWriter = httptest.NewRecorder()
+		req, _ = http.NewRequest("GET", "test_request", nil)
+
+		err := httpapi.Load()
+		if err != nil {
+			panic(err.Error())
+		}
+		httpapi.GlobalHttpAPIConf.WaitPollInterval = time.Millisecond
+	})
+
+	AfterEach(func() {
+		mockCtrl.Finish()
+	})
+
+	decode := func(response interface{}) {
+		Expect(json.NewDecoder(responseWriter.Result().Body).Decode(response)).To(Succeed())
+	}
+
+	Context("list the OSBuildConfigs", func() {
+		It("should return the ones the secret grants access to", func() {
+			// given
+			osBuildConfigRepository.EXPECT().List(req.Context(), Namespace).Return([]v1alpha1.OSBuildConfig{otherOSBuildConfig, osbuildConfig}, nil)
+
+			// when
+			osbuildConfigHandler.ListOSBuildConfigs(responseWriter, req, Namespace, restapi.ListOSBuildConfigsParams{Secret: &secretVal})
+
+			// then
+			Expect(responseWriter.Result().StatusCode).To(Equal(http.StatusOK))
+			var response []restapi.Osbuildconfig
+			decode(&response)
+			Expect(response).To(HaveLen(1))
+			Expect(response[0].Name).To(Equal(OSBuildConfigName))
+			Expect(*response[0].LastVersion).To(Equal(2))
+			Expect(*response[0].LastOSBuild).To(Equal(OSBuildConfigName + "-2"))
+			Expect(*response[0].TargetImageType).To(Equal("edge-container"))
+		})
+
+		It("should authorize with the secret of the Git triggers", func() {
+			// given
+			osBuildConfigRepository.EXPECT().List(req.Context(), Namespace).Return([]v1alpha1.OSBuildConfig{otherOSBuildConfig, osbuildConfig}, nil)
+
+			// when
+			osbuildConfigHandler.ListOSBuildConfigs(responseWriter, req, Namespace, restapi.ListOSBuildConfigsParams{Secret: pointer.String("456")})
+
+			// then
+			var response []restapi.Osbuildconfig
+			decode(&response)
+			Expect(response).To(HaveLen(1))
+			Expect(response[0].Name).To(Equal(otherOSBuildConfigName))
+		})
+
+		It("should return an empty list when the secret grants access to none", func() {
+			// given
+			osBuildConfigRepository.EXPECT().List(req.Context(), Namespace).Return([]v1alpha1.OSBuildConfig{otherOSBuildConfig, osbuildConfig}, nil)
+
+			// when
+			osbuildConfigHandler.ListOSBuildConfigs(responseWriter, req, Namespace, restapi.ListOSBuildConfigsParams{Secret: pointer.String("789")})
+
+			// then
+			Expect(responseWriter.Result().StatusCode).To(Equal(http.StatusOK))
+			var response []restapi.Osbuildconfig
+			decode(&response)
+			Expect(response).To(BeEmpty())
+		})
+
+		It("with internalServerError response, because listing failed", func() {
+			// given
+			osBuildConfigRepository.EXPECT().List(req.Context(), Namespace).Return(nil, errors.NewBadRequest("test"))
+
+			// when
+			osbuildConfigHandler.ListOSBuildConfigs(responseWriter, req, Namespace, restapi.ListOSBuildConfigsParams{Secret: &secretVal})
+
+			// then
+			Expect(responseWriter.Result().StatusCode).To(Equal(http.StatusInternalServerError))
+		})
+	})
+
+	Context("with a bearer token", func() {
+		const token = "eyJhbGciOiJSUzI1NiJ9.ci-token"
+
+		bearer := pointer.String("Bearer " + token)
+
+		It("should list all the OSBuildConfigs when the user is allowed to list them", func() {
+			// given
+			authorizer.EXPECT().Authorize(req.Context(), token, kubeauth.ResourceAttributes("list", "osbuildconfigs", "", Namespace, "")).Return(true, nil)
+			osBuildConfigRepository.EXPECT().List(req.Context(), Namespace).Return([]v1alpha1.OSBuildConfig{otherOSBuildConfig, osbuildConfig}, nil)
+
+			// when
+			osbuildConfigHandler.ListOSBuildConfigs(responseWriter, req, Namespace, restapi.ListOSBuildConfigsParams{Authorization: bearer})
+
+			// then
+			Expect(responseWriter.Result().StatusCode).To(Equal(http.StatusOK))
+			var response []restapi.Osbuildconfig
+			decode(&response)
+			Expect(response).To(HaveLen(2))
+			Expect(response[0].Name).To(Equal(otherOSBuildConfigName))
+		})
+
+		It("should get an OSBuild the user is allowed to get", func() {
+			// given
+			authorizer.EXPECT().Authorize(req.Context(), token, kubeauth.ResourceAttributes("get", "osbuilds", "", Namespace, osBuild.Name)).Return(true, nil)
+			osBuildRepository.EXPECT().Read(req.Context(), osBuild.Name, Namespace).Return(finished(osBuild), nil)
+
+			// when
+			osbuildConfigHandler.GetOSBuild(responseWriter, req, Namespace, osBuild.Name, restapi.GetOSBuildParams{Authorization: bearer})
+
+			// then
+			Expect(responseWriter.Result().StatusCode).To(Equal(http.StatusOK))
+		})
+
+		It("should list the OSBuilds of an OSBuildConfig when the user is allowed to list them", func() {
+			// given
+			authorizer.EXPECT().Authorize(req.Context(), token, kubeauth.ResourceAttributes("list", "osbuilds", "", Namespace, "")).Return(true, nil)
+			osBuildConfigRepository.EXPECT().Read(req.Context(), OSBuildConfigName, Namespace).Return(&osbuildConfig, nil)
+			osBuildRepository.EXPECT().List(req.Context(), Namespace).Return([]v1alpha1.OSBuild{osBuild}, nil)
+
+			// when
+			osbuildConfigHandler.ListOSBuilds(responseWriter, req, Namespace, restapi.ListOSBuildsParams{
+				Osbuildconfig: pointer.String(OSBuildConfigName),
+				Authorization: bearer,
+			})
+
+			// then
+			var response []restapi.Osbuild
+			decode(&response)
+			Expect(response).To(HaveLen(1))
+		})
+
+		It("with forbidden response, because the user is not allowed to get the OSBuildConfig", func() {
+			// given
+			authorizer.EXPECT().Authorize(req.Context(), token, kubeauth.ResourceAttributes("get", "osbuildconfigs", "", Namespace, OSBuildConfigName)).Return(false, nil)
+
+			// when
+			osbuildConfigHandler.GetOSBuildConfig(responseWriter, req, Namespace, OSBuildConfigName, restapi.GetOSBuildConfigParams{Authorization: bearer})
+
+			// then
+			Expect(responseWriter.Result().StatusCode).To(Equal(http.StatusForbidden))
+		})
+
+		It("with unauthorized response, because the token is not valid", func() {
+			// given
+			authorizer.EXPECT().Authorize(req.Context(), token, gomock.Any()).Return(false, kubeauth.ErrUnauthenticated)
+
+			// when
+			osbuildConfigHandler.ListOSBuilds(responseWriter, req, Namespace, restapi.ListOSBuildsParams{Authorization: bearer})
+
+			// then
+			Expect(responseWriter.Result().StatusCode).To(Equal(http.StatusUnauthorized))
+		})
+	})
+
+	DescribeTable("with unauthorized response, because the request has neither a secret nor a bearer token", func(call func()) {
+		// given
+		osBuildConfigRepository.EXPECT().Read(req.Context(), OSBuildConfigName, Namespace).Return(&osbuildConfig, nil).AnyTimes()
+		osBuildRepository.EXPECT().Read(req.Context(), osBuild.Name, Namespace).Return(&osBuild, nil).AnyTimes()
+
+		// when
+		call()
+
+		// then
+		Expect(responseWriter.Result().StatusCode).To(Equal(http.StatusUnauthorized))
+	},
+		Entry("listing the OSBuildConfigs", func() {
+			osbuildConfigHandler.ListOSBuildConfigs(responseWriter, req, Namespace, restapi.ListOSBuildConfigsParams{})
+		}),
+		Entry("getting an OSBuildConfig", func() {
+			osbuildConfigHandler.GetOSBuildConfig(responseWriter, req, Namespace, OSBuildConfigName, restapi.GetOSBuildConfigParams{})
+		}),
+		Entry("listing the OSBuilds", func() {
+			osbuildConfigHandler.ListOSBuilds(responseWriter, req, Namespace, restapi.ListOSBuildsParams{})
+		}),
+		Entry("getting an OSBuild", func() {
+			osbuildConfigHandler.GetOSBuild(responseWriter, req, Namespace, osBuild.Name, restapi.GetOSBuildParams{})
+		}),
+	)
+
+	Context("get an OSBuildConfig", func() {
+		It("and succeed", func() {
+			// given
+			osBuildConfigRepository.EXPECT().Read(req.Context(), OSBuildConfigName, Namespace).Return(&osbuildConfig, nil)
+
+			// when
+			osbuildConfigHandler.GetOSBuildConfig(responseWriter, req, Namespace, OSBuildConfigName, restapi.GetOSBuildConfigParams{Secret: &secretVal})
+
+			// then
+			Expect(responseWriter.Result().StatusCode).To(Equal(http.StatusOK))
+			var response restapi.Osbuildconfig
+			decode(&response)
+			Expect(response.Name).To(Equal(OSBuildConfigName))
+			Expect(response.Namespace).To(Equal(Namespace))
+		})
+
+		It("with forbidden response, because the secret is another one", func() {
+			// given
+			osBuildConfigRepository.EXPECT().Read(req.Context(), OSBuildConfigName, Namespace).Return(&osbuildConfig, nil)
+
+			// when
+			osbuildConfigHandler.GetOSBuildConfig(responseWriter, req, Namespace, OSBuildConfigName, restapi.GetOSBuildConfigParams{Secret: pointer.String("456")})
+
+			// then
+			Expect(responseWriter.Result().StatusCode).To(Equal(http.StatusForbidden))
+		})
+
+		It("with not found response, because osbuildConfig doesn't exist", func() {
+			// given
+			returnErr := errors.NewNotFound(schema.GroupResource{Group: "", Resource: "notfound"}, "notfound")
+			osBuildConfigRepository.EXPECT().Read(req.Context(), OSBuildConfigName, Namespace).Return(nil, returnErr)
+
+			// when
+			osbuildConfigHandler.GetOSBuildConfig(responseWriter, req, Namespace, OSBuildConfigName, restapi.GetOSBuildConfigParams{Secret: &secretVal})
+
+			// then
+			Expect(responseWriter.Result().StatusCode).To(Equal(http.StatusNotFound))
+		})
+	})
+
+	Context("list the OSBuilds", func() {
+		var osBuilds []v1alpha1.OSBuild
+
+		BeforeEach(func() {
+			osBuilds = []v1alpha1.OSBuild{
+				osBuild,
+				newOSBuild(otherOSBuildConfigName+"-1", otherOSBuildConfigName, time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)),
+				newOSBuild(OSBuildConfigName+"-1", OSBuildConfigName, time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)),
+			}
+		})
+
+		It("should return the ones of the OSBuildConfigs the secret grants access to, by creation time", func() {
+			// given
+			osBuildConfigRepository.EXPECT().List(req.Context(), Namespace).Return([]v1alpha1.OSBuildConfig{otherOSBuildConfig, osbuildConfig}, nil)
+			osBuildRepository.EXPECT().List(req.Context(), Namespace).Return(osBuilds, nil)
+
+			// when
+			osbuildConfigHandler.ListOSBuilds(responseWriter, req, Namespace, restapi.ListOSBuildsParams{Secret: &secretVal})
+
+			// then
+			Expect(responseWriter.Result().StatusCode).To(Equal(http.StatusOK))
+			var response []restapi.Osbuild
+			decode(&response)
+			Expect(response).To(HaveLen(2))
+			Expect(response[0].Name).To(Equal(OSBuildConfigName + "-1"))
+			Expect(response[1].Name).To(Equal(OSBuildConfigName + "-2"))
+			Expect(*response[1].OsbuildConfig).To(Equal(OSBuildConfigName))
+			Expect(*response[1].TriggerId).To(Equal("6b9f1c2e-5d4a-4b8e-9f3c-2a1d0e7b6c5f"))
+		})
+
+		It("should return the ones of the requested OSBuildConfig", func() {
+			// given
+			osBuildConfigRepository.EXPECT().Read(req.Context(), otherOSBuildConfigName, Namespace).Return(&otherOSBuildConfig, nil)
+			osBuildRepository.EXPECT().List(req.Context(), Namespace).Return(osBuilds, nil)
+
+			// when
+			osbuildConfigHandler.ListOSBuilds(responseWriter, req, Namespace, restapi.ListOSBuildsParams{
+				Osbuildconfig: pointer.String(otherOSBuildConfigName),
+				Secret:        pointer.String("456"),
+			})
+
+			// then
+			var response []restapi.Osbuild
+			decode(&response)
+			Expect(response).To(HaveLen(1))
+			Expect(response[0].Name).To(Equal(otherOSBuildConfigName + "-1"))
+		})
+
+		It("with forbidden response, because the secret does not grant access to the requested OSBuildConfig", func() {
+			// given
+			osBuildConfigRepository.EXPECT().Read(req.Context(), otherOSBuildConfigName, Namespace).Return(&otherOSBuildConfig, nil)
+
+			// when
+			osbuildConfigHandler.ListOSBuilds(responseWriter, req, Namespace, restapi.ListOSBuildsParams{
+				Osbuildconfig: pointer.String(otherOSBuildConfigName),
+				Secret:        &secretVal,
+			})
+
+			// then
+			Expect(responseWriter.Result().StatusCode).To(Equal(http.StatusForbidden))
+		})
+	})
+
+	Context("get an OSBuild", func() {
+		It("and succeed", func() {
+			// given
+			osBuildRepository.EXPECT().Read(req.Context(), osBuild.Name, Namespace).Return(finished(osBuild), nil)
+			osBuildConfigRepository.EXPECT().Read(req.Context(), OSBuildConfigName, Namespace).Return(&osbuildConfig, nil)
+
+			// when
+			osbuildConfigHandler.GetOSBuild(responseWriter, req, Namespace, osBuild.Name, restapi.GetOSBuildParams{Secret: &secretVal})
+
+			// then
+			Expect(responseWriter.Result().StatusCode).To(Equal(http.StatusOK))
+			var response restapi.Osbuild
+			decode(&response)
+			Expect(response.Phase).To(Equal(restapi.Ready))
+			Expect(*response.AccessUrl).To(Equal(accessUrl))
+			Expect(*response.TargetImageType).To(Equal("edge-container"))
+			Expect(*response.TriggeredBy).To(Equal("Webhook"))
+			Expect(*response.Conditions).To(Equal([]restapi.Condition{{Type: "Ready", Status: "True"}}))
+		})
+
+		It("should wait for the OSBuild to finish", func() {
+			// given
+			gomock.InOrder(
+				osBuildRepository.EXPECT().Read(req.Context(), osBuild.Name, Namespace).Return(osBuild.DeepCopy(), nil).Times(2),
+				osBuildRepository.EXPECT().Read(req.Context(), osBuild.Name, Namespace).Return(finished(osBuild), nil),
+			)
+			osBuildConfigRepository.EXPECT().Read(req.Context(), OSBuildConfigName, Namespace).Return(&osbuildConfig, nil)
+
+			// when
+			osbuildConfigHandler.GetOSBuild(responseWriter, req, Namespace, osBuild.Name, restapi.GetOSBuildParams{Secret: &secretVal, Wait: pointer.Int(60)})
+
+			// then
+			Expect(responseWriter.Result().StatusCode).To(Equal(http.StatusOK))
+			var response restapi.Osbuild
+			decode(&response)
+			Expect(response.Phase).To(Equal(restapi.Ready))
+		})
+
+		DescribeTable("should report a failed OSBuild and stop waiting", func(conditionType v1alpha1.ConditionType) {
+			// given
+			failed := osBuild.DeepCopy()
+			failed.Status.Conditions = []v1alpha1.Condition{{Type: conditionType, Status: v1.ConditionTrue}}
+			gomock.InOrder(
+				osBuildRepository.EXPECT().Read(req.Context(), osBuild.Name, Namespace).Return(osBuild.DeepCopy(), nil).Times(2),
+				osBuildRepository.EXPECT().Read(req.Context(), osBuild.Name, Namespace).Return(failed, nil),
+			)
+			osBuildConfigRepository.EXPECT().Read(req.Context(), OSBuildConfigName, Namespace).Return(&osbuildConfig, nil)
+
+			// when
+			osbuildConfigHandler.GetOSBuild(responseWriter, req, Namespace, osBuild.Name, restapi.GetOSBuildParams{Secret: &secretVal, Wait: pointer.Int(60)})
+
+			// then
+			Expect(responseWriter.Result().StatusCode).To(Equal(http.StatusOK))
+			var response restapi.Osbuild
+			decode(&response)
+			Expect(response.Phase).To(Equal(restapi.Failed))
+		},
+			Entry("when the build failed", v1alpha1.ConditionFailed),
+			Entry("when the packages failed the validation", v1alpha1.ConditionValidationFailed),
+			Entry("when vulnerabilities were found", v1alpha1.ConditionVulnerabilitiesFound),
+		)
+
+		It("should return the building OSBuild when the wait times out", func() {
+			// given
+			httpapi.GlobalHttpAPIConf.MaxWaitTimeout = 20 * time.Millisecond
+			osBuildRepository.EXPECT().Read(req.Context(), osBuild.Name, Namespace).Return(osBuild.DeepCopy(), nil).MinTimes(1)
+			osBuildConfigRepository.EXPECT().Read(req.Context(), OSBuildConfigName, Namespace).Return(&osbuildConfig, nil)
+
+			// when
+			osbuildConfigHandler.GetOSBuild(responseWriter, req, Namespace, osBuild.Name, restapi.GetOSBuildParams{Secret: &secretVal, Wait: pointer.Int(60)})
+
+			// then
+			Expect(responseWriter.Result().StatusCode).To(Equal(http.StatusOK))
+			var response restapi.Osbuild
+			decode(&response)
+			Expect(response.Phase).To(Equal(restapi.Building))
+		})
+
+		It("with forbidden response, because the secret is another one", func() {
+			// given
+			osBuildRepository.EXPECT().Read(req.Context(), osBuild.Name, Namespace).Return(&osBuild, nil)
+			osBuildConfigRepository.EXPECT().Read(req.Context(), OSBuildConfigName, Namespace).Return(&osbuildConfig, nil)
+
+			// when
+			osbuildConfigHandler.GetOSBuild(responseWriter, req, Namespace, osBuild.Name, restapi.GetOSBuildParams{Secret: pointer.String("456")})
+
+			// then
+			Expect(responseWriter.Result().StatusCode).To(Equal(http.StatusForbidden))
+		})
+
+		It("with forbidden response, because the OSBuild has no OSBuildConfig", func() {
+			// given
+			osBuild.OwnerReferences = nil
+			osBuildRepository.EXPECT().Read(req.Context(), osBuild.Name, Namespace).Return(&osBuild, nil)
+
+			// when
+			osbuildConfigHandler.GetOSBuild(responseWriter, req, Namespace, osBuild.Name, restapi.GetOSBuildParams{Secret: &secretVal})
+
+			// then
+			Expect(responseWriter.Result().StatusCode).To(Equal(http.StatusForbidden))
+		})
+
+		It("with not found response, because the OSBuild doesn't exist", func() {
+			// given
+			returnErr := errors.NewNotFound(schema.GroupResource{Group: "", Resource: "notfound"}, "notfound")
+			osBuildRepository.EXPECT().Read(req.Context(), osBuild.Name, Namespace).Return(nil, returnErr)
+
+			// when
+			osbuildConfigHandler.GetOSBuild(responseWriter, req, Namespace, osBuild.Name, restapi.GetOSBuildParams{Secret: &secretVal})
+
+			// then
+			Expect(responseWriter.Result().StatusCode).To(Equal(http.StatusNotFound))
+		})
+	})
+})
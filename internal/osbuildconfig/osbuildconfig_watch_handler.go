@@ -0,0 +1,206 @@
+package osbuildconfig
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/project-flotta/osbuild-operator/api/v1alpha1"
+	"github.com/project-flotta/osbuild-operator/internal/httpapi"
+	"github.com/project-flotta/osbuild-operator/internal/kubeauth"
+	loggerutil "github.com/project-flotta/osbuild-operator/internal/logger"
+	"github.com/project-flotta/osbuild-operator/internal/osbuildwatch"
+	"github.com/project-flotta/osbuild-operator/restapi"
+)
+
+const (
+	// the events of the watch streams
+	eventOSBuild = "osbuild"
+	eventDeleted = "deleted"
+)
+
+// osBuildStream writes the phase and condition changes of the followed OSBuilds as Server-Sent Events
+type osBuildStream struct {
+	w       http.ResponseWriter
+	flusher http.Flusher
+	logger  *zap.SugaredLogger
+	follows func(osBuild *v1alpha1.OSBuild) bool
+
+	// sent holds the last OSBuilds sent, inProgress the followed OSBuilds to be Ready or Failed, and done the ones
+	// that are not followed anymore
+	sent       map[string]restapi.Osbuild
+	inProgress map[string]bool
+	done       map[string]bool
+}
+
+func (o *OSBuildConfigHandler) WatchOSBuilds(w http.ResponseWriter, r *http.Request, namespace string, params restapi.WatchOSBuildsParams) {
+	logger, err := loggerutil.Logger(httpapi.GlobalHttpAPIConf.LogLevel)
+	if err != nil {
+		return
+	}
+
+	if (params.Osbuild == nil) == (params.Osbuildconfig == nil) {
+		logger.Error("the watch requires either an OSBuild or an OSBuildConfig")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		logger.Error("the response cannot be streamed")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	// the watch starts before reading the OSBuilds, so that no change is missed in between
+	watcher := o.Broadcaster.Watch(namespace)
+	defer watcher.Stop()
+
+	stream := &osBuildStream{
+		w:          w,
+		flusher:    flusher,
+		logger:     logger,
+		sent:       map[string]restapi.Osbuild{},
+		inProgress: map[string]bool{},
+		done:       map[string]bool{},
+	}
+	var current []v1alpha1.OSBuild
+	if params.Osbuild != nil {
+		osBuild, ok := o.readAuthorizedOSBuild(w, r, logger, "watch", namespace, *params.Osbuild, params.Secret, params.Authorization)
+		if !ok {
+			return
+		}
+		stream.follows = func(osBuild *v1alpha1.OSBuild) bool {
+			return osBuild.Name == *params.Osbuild
+		}
+		current = []v1alpha1.OSBuild{*osBuild}
+	} else {
+		current, ok = o.listWatchedOSBuilds(w, r, logger, namespace, *params.Osbuildconfig, params.Secret, params.Authorization)
+		if !ok {
+			return
+		}
+		stream.follows = func(osBuild *v1alpha1.OSBuild) bool {
+			return getOSBuildConfigName(osBuild) == *params.Osbuildconfig
+		}
+		// the OSBuilds finished before the watch are not followed
+		for i := range current {
+			if current[i].IsFinished() {
+				stream.done[current[i].Name] = true
+			}
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	for i := range current {
+		if stream.handle(osbuildwatch.Event{Type: osbuildwatch.Added, OSBuild: &current[i]}) {
+			return
+		}
+	}
+
+	heartbeat := time.NewTicker(httpapi.GlobalHttpAPIConf.WatchHeartbeatInterval)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case event, open := <-watcher.Events():
+			if !open {
+				logger.Error("the watch lagged behind the changes of the OSBuilds and is closed")
+				return
+			}
+			if stream.handle(event) {
+				return
+			}
+		case <-heartbeat.C:
+			stream.write(": heartbeat\n\n")
+		}
+	}
+}
+
+// listWatchedOSBuilds returns the OSBuilds of the OSBuildConfig, the user of the bearer token being allowed to watch
+// the OSBuilds of the namespace or the secret being the one of one of the triggers of the OSBuildConfig
+func (o *OSBuildConfigHandler) listWatchedOSBuilds(w http.ResponseWriter, r *http.Request, logger *zap.SugaredLogger, namespace string, name string, secretValue *string, authorization *string) ([]v1alpha1.OSBuild, bool) {
+	if token, bearer := getBearerToken(authorization); bearer {
+		attributes := kubeauth.ResourceAttributes("watch", kubeauth.OSBuildResource, "", namespace, "")
+		if !o.checkTokenAuthorized(w, r, logger, token, attributes) {
+			return nil, false
+		}
+		if _, ok := o.readOSBuildConfig(w, r, logger, namespace, name); !ok {
+			return nil, false
+		}
+	} else if _, ok := o.readAuthorizedOSBuildConfig(w, r, logger, namespace, name, secretValue, nil); !ok {
+		return nil, false
+	}
+
+	osBuilds, err := o.OSBuildRepository.List(r.Context(), namespace)
+	if err != nil {
+		logger.Error(err, fmt.Sprintf("cannot list the OSBuilds of namespace %s", namespace))
+		w.WriteHeader(http.StatusInternalServerError)
+		return nil, false
+	}
+	var watched []v1alpha1.OSBuild
+	for i := range osBuilds {
+		if getOSBuildConfigName(&osBuilds[i]) == name {
+			watched = append(watched, osBuilds[i])
+		}
+	}
+	return watched, true
+}
+
+// handle writes the event when it changes the phase or the conditions of a followed OSBuild, and returns whether the
+// stream is over, i.e. when the last OSBuild in progress is Ready, Failed or deleted
+func (s *osBuildStream) handle(event osbuildwatch.Event) bool {
+	osBuild := event.OSBuild
+	if s.done[osBuild.Name] || !s.follows(osBuild) {
+		return false
+	}
+
+	restOSBuild := toRestOSBuild(osBuild)
+	if event.Type == osbuildwatch.Deleted {
+		if !s.inProgress[osBuild.Name] {
+			return false
+		}
+		s.writeEvent(eventDeleted, osBuild.ResourceVersion, restOSBuild)
+		return s.finish(osBuild.Name)
+	}
+
+	last, sent := s.sent[osBuild.Name]
+	if !sent || last.Phase != restOSBuild.Phase || !reflect.DeepEqual(last.Conditions, restOSBuild.Conditions) {
+		s.writeEvent(eventOSBuild, osBuild.ResourceVersion, restOSBuild)
+		s.sent[osBuild.Name] = restOSBuild
+	}
+	if osBuild.IsFinished() {
+		return s.finish(osBuild.Name)
+	}
+	s.inProgress[osBuild.Name] = true
+	return false
+}
+
+func (s *osBuildStream) finish(name string) bool {
+	delete(s.inProgress, name)
+	delete(s.sent, name)
+	s.done[name] = true
+	return len(s.inProgress) == 0
+}
+
+func (s *osBuildStream) writeEvent(event string, id string, osBuild restapi.Osbuild) {
+	// the OSBuild holds plain values only, marshaling it cannot fail
+	data, _ := json.Marshal(osBuild)
+	s.write(fmt.Sprintf("id: %s\nevent: %s\ndata: %s\n\n", id, event, data))
+}
+
+func (s *osBuildStream) write(message string) {
+	_, err := fmt.Fprint(s.w, message)
+	if err != nil {
+		s.logger.Error(err, "cannot write the watch stream")
+		return
+	}
+	s.flusher.Flush()
+}
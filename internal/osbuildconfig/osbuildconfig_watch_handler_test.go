@@ -0,0 +1,410 @@
+package osbuildconfig
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	buildv1 "github.com/openshift/api/build/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/utils/pointer"
+
+	"github.com/project-flotta/osbuild-operator/api/v1alpha1"
+	"github.com/project-flotta/osbuild-operator/internal/httpapi"
+	"github.com/project-flotta/osbuild-operator/internal/kubeauth"
+	"github.com/project-flotta/osbuild-operator/internal/osbuildwatch"
+	"github.com/project-flotta/osbuild-operator/internal/ratelimit"
+	"github.com/project-flotta/osbuild-operator/internal/replay"
+	repositoryosbuild "github.com/project-flotta/osbuild-operator/internal/repository/osbuild"
+	repositoryosbuildconfig "github.com/project-flotta/osbuild-operator/internal/repository/osbuildconfig"
+	repositoryosbuildconfigtemplate "github.com/project-flotta/osbuild-operator/internal/repository/osbuildconfigtemplate"
+	repositorysecret "github.com/project-flotta/osbuild-operator/internal/repository/secret"
+	"github.com/project-flotta/osbuild-operator/restapi"
+)
+
+// streamEvent is an event of a watch stream
+type streamEvent struct {
+	id      string
+	event   string
+	osBuild restapi.Osbuild
+}
+
+var _ = Describe("OSBuild watch API", func() {
+	var (
+		mockCtrl      *gomock.Controller
+		osbuildConfig v1alpha1.OSBuildConfig
+		osBuild       v1alpha1.OSBuild
+		secretVal     = "123"
+
+		osBuildConfigRepository *repositoryosbuildconfig.MockRepository
+		osBuildRepository       *repositoryosbuild.MockRepository
+		authorizer              *kubeauth.MockAuthorizer
+		broadcaster             *osbuildwatch.Broadcaster
+		responseWriter          *httptest.ResponseRecorder
+		osbuildConfigHandler    *OSBuildConfigHandler
+		req                     *http.Request
+	)
+
+	newOSBuild := func(name string, osBuildConfigName string, resourceVersion string, conditions ...v1alpha1.Condition) *v1alpha1.OSBuild {
+		return &v1alpha1.OSBuild{
+			ObjectMeta: v1.ObjectMeta{
+				Name:            name,
+				Namespace:       Namespace,
+				ResourceVersion: resourceVersion,
+				OwnerReferences: []v1.OwnerReference{{
+					APIVersion: v1alpha1.GroupVersion.String(),
+					Kind:       "OSBuildConfig",
+					Name:       osBuildConfigName,
+					Controller: pointer.Bool(true),
+				}},
+			},
+			Status: v1alpha1.OSBuildStatus{Conditions: conditions},
+		}
+	}
+
+	var (
+		started = v1alpha1.Condition{Type: v1alpha1.ConditionInProgress, Status: v1.ConditionTrue}
+		ready   = v1alpha1.Condition{Type: v1alpha1.ConditionReady, Status: v1.ConditionTrue}
+		failed  = v1alpha1.Condition{Type: v1alpha1.ConditionFailed, Status: v1.ConditionTrue}
+	)
+
+	publish := func(events ...osbuildwatch.Event) {
+		for _, event := range events {
+			switch event.Type {
+			case osbuildwatch.Added:
+				broadcaster.OnAdd(event.OSBuild)
+			case osbuildwatch.Modified:
+				broadcaster.OnUpdate(nil, event.OSBuild)
+			case osbuildwatch.Deleted:
+				broadcaster.OnDelete(event.OSBuild)
+			}
+		}
+	}
+
+	// publishOnRead publishes the events as if the OSBuild changed while its current state was read
+	publishOnRead := func(events ...osbuildwatch.Event) func(context.Context, string, string) {
+		return func(context.Context, string, string) {
+			publish(events...)
+		}
+	}
+
+	// publishOnList publishes the events as if the OSBuilds changed while they were listed
+	publishOnList := func(events ...osbuildwatch.Event) func(context.Context, string) {
+		return func(context.Context, string) {
+			publish(events...)
+		}
+	}
+
+	readEvents := func() []streamEvent {
+		var events []streamEvent
+		var event streamEvent
+		scanner := bufio.NewScanner(responseWriter.Result().Body)
+		for scanner.Scan() {
+			line := scanner.Text()
+			switch {
+			case strings.HasPrefix(line, "id: "):
+				event.id = strings.TrimPrefix(line, "id: ")
+			case strings.HasPrefix(line, "event: "):
+				event.event = strings.TrimPrefix(line, "event: ")
+			case strings.HasPrefix(line, "data: "):
+				Expect(json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &event.osBuild)).To(Succeed())
+			case line == "" && event.event != "":
+				events = append(events, event)
+				event = streamEvent{}
+			}
+		}
+		return events
+	}
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		osBuildConfigRepository = repositoryosbuildconfig.NewMockRepository(mockCtrl)
+		secretRepository := repositorysecret.NewMockRepository(mockCtrl)
+		osBuildRepository = repositoryosbuild.NewMockRepository(mockCtrl)
+		authorizer = kubeauth.NewMockAuthorizer(mockCtrl)
+		broadcaster = osbuildwatch.NewBroadcaster()
+		osbuildConfigHandler = NewOSBuildConfigHandler(osBuildConfigRepository, secretRepository,
+			repositoryosbuildconfigtemplate.NewMockRepository(mockCtrl), osBuildRepository, authorizer,
+			ratelimit.NewKeyedLimiter(0.1, 3), replay.NewGuard(5*time.Minute), broadcaster)
+
+		osbuildConfig = v1alpha1.OSBuildConfig{
+			ObjectMeta: v1.ObjectMeta{Name: OSBuildConfigName, Namespace: Namespace},
+			Spec: v1alpha1.OSBuildConfigSpec{
+				Triggers: v1alpha1.BuildTriggers{
+					WebHook: &buildv1.WebHookTrigger{SecretReference: &buildv1.SecretLocalReference{Name: SecretName}},
+				},
+			},
+		}
+		osBuild = *newOSBuild(OSBuildConfigName+"-2", OSBuildConfigName, "10", started)
+
+		secretRepository.EXPECT().Read(gomock.Any(), SecretName, Namespace).Return(&corev1.Secret{
+			Data: map[string][]byte{webHookSecretKey: []byte(secretVal)},
+		}, nil).AnyTimes()
+		osBuildConfigRepository.EXPECT().Read(gomock.Any(), OSBuildConfigName, Namespace).Return(&osbuildConfig, nil).AnyTimes()
+
+		responseWriter = httptest.NewRecorder()
+		req, _ = http.NewRequest("GET", "test_request", nil)
+
+		err := httpapi.Load()
+		if err != nil {
+			panic(err.Error())
+		}
+	})
+
+	AfterEach(func() {
+		mockCtrl.Finish()
+	})
+
+	Context("watch an OSBuild", func() {
+		params := func() restapi.WatchOSBuildsParams {
+			return restapi.WatchOSBuildsParams{Osbuild: pointer.String(osBuild.Name), Secret: &secretVal}
+		}
+
+		It("should stream the changes until the OSBuild is ready", func() {
+			// given
+			osBuildRepository.EXPECT().Read(req.Context(), osBuild.Name, Namespace).Return(&osBuild, nil).Do(publishOnRead(
+				osbuildwatch.Event{Type: osbuildwatch.Modified, OSBuild: newOSBuild(osBuild.Name, OSBuildConfigName, "11", started, ready)},
+			))
+
+			// when
+			osbuildConfigHandler.WatchOSBuilds(responseWriter, req, Namespace, params())
+
+			// then
+			Expect(responseWriter.Result().StatusCode).To(Equal(http.StatusOK))
+			Expect(responseWriter.Result().Header.Get("Content-Type")).To(Equal("text/event-stream"))
+			events := readEvents()
+			Expect(events).To(HaveLen(2))
+			Expect(events[0].id).To(Equal("10"))
+			Expect(events[0].event).To(Equal(eventOSBuild))
+			Expect(events[0].osBuild.Phase).To(Equal(restapi.Building))
+			Expect(events[1].id).To(Equal("11"))
+			Expect(events[1].osBuild.Phase).To(Equal(restapi.Ready))
+			Expect(*events[1].osBuild.Conditions).To(HaveLen(2))
+		})
+
+		It("should not stream the unchanged OSBuilds and the other ones", func() {
+			// given
+			osBuildRepository.EXPECT().Read(req.Context(), osBuild.Name, Namespace).Return(&osBuild, nil).Do(publishOnRead(
+				osbuildwatch.Event{Type: osbuildwatch.Modified, OSBuild: newOSBuild(osBuild.Name, OSBuildConfigName, "11", started)},
+				osbuildwatch.Event{Type: osbuildwatch.Modified, OSBuild: newOSBuild(OSBuildConfigName+"-3", OSBuildConfigName, "12", started)},
+				osbuildwatch.Event{Type: osbuildwatch.Modified, OSBuild: newOSBuild(osBuild.Name, OSBuildConfigName, "13", started, failed)},
+			))
+
+			// when
+			osbuildConfigHandler.WatchOSBuilds(responseWriter, req, Namespace, params())
+
+			// then
+			events := readEvents()
+			Expect(events).To(HaveLen(2))
+			Expect(events[0].id).To(Equal("10"))
+			Expect(events[1].id).To(Equal("13"))
+			Expect(events[1].osBuild.Phase).To(Equal(restapi.Failed))
+		})
+
+		DescribeTable("should close the stream when the OSBuild failed", func(conditionType v1alpha1.ConditionType) {
+			// given
+			failure := v1alpha1.Condition{Type: conditionType, Status: v1.ConditionTrue}
+			osBuildRepository.EXPECT().Read(req.Context(), osBuild.Name, Namespace).Return(&osBuild, nil).Do(publishOnRead(
+				osbuildwatch.Event{Type: osbuildwatch.Modified, OSBuild: newOSBuild(osBuild.Name, OSBuildConfigName, "11", failure)},
+			))
+
+			// when
+			osbuildConfigHandler.WatchOSBuilds(responseWriter, req, Namespace, params())
+
+			// then
+			events := readEvents()
+			Expect(events).To(HaveLen(2))
+			Expect(events[1].id).To(Equal("11"))
+			Expect(events[1].osBuild.Phase).To(Equal(restapi.Failed))
+		},
+			Entry("when the build failed", v1alpha1.ConditionFailed),
+			Entry("when the packages failed the validation", v1alpha1.ConditionValidationFailed),
+			Entry("when vulnerabilities were found", v1alpha1.ConditionVulnerabilitiesFound),
+		)
+
+		It("should close the stream at once when the OSBuild is finished", func() {
+			// given
+			finishedOSBuild := newOSBuild(osBuild.Name, OSBuildConfigName, "10", ready)
+			osBuildRepository.EXPECT().Read(req.Context(), osBuild.Name, Namespace).Return(finishedOSBuild, nil)
+
+			// when
+			osbuildConfigHandler.WatchOSBuilds(responseWriter, req, Namespace, params())
+
+			// then
+			events := readEvents()
+			Expect(events).To(HaveLen(1))
+			Expect(events[0].osBuild.Phase).To(Equal(restapi.Ready))
+		})
+
+		It("should close the stream when the OSBuild is deleted", func() {
+			// given
+			osBuildRepository.EXPECT().Read(req.Context(), osBuild.Name, Namespace).Return(&osBuild, nil).Do(publishOnRead(
+				osbuildwatch.Event{Type: osbuildwatch.Deleted, OSBuild: newOSBuild(osBuild.Name, OSBuildConfigName, "11", started)},
+			))
+
+			// when
+			osbuildConfigHandler.WatchOSBuilds(responseWriter, req, Namespace, params())
+
+			// then
+			events := readEvents()
+			Expect(events).To(HaveLen(2))
+			Expect(events[1].event).To(Equal(eventDeleted))
+		})
+
+		It("should close the stream when the client disconnects", func() {
+			// given
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			req = req.WithContext(ctx)
+			osBuildRepository.EXPECT().Read(req.Context(), osBuild.Name, Namespace).Return(&osBuild, nil)
+
+			// when
+			osbuildConfigHandler.WatchOSBuilds(responseWriter, req, Namespace, params())
+
+			// then
+			Expect(readEvents()).To(HaveLen(1))
+		})
+
+		It("should authorize the bearer token to watch the OSBuild", func() {
+			// given
+			osBuildRepository.EXPECT().Read(req.Context(), osBuild.Name, Namespace).Return(newOSBuild(osBuild.Name, OSBuildConfigName, "10", ready), nil)
+			authorizer.EXPECT().Authorize(req.Context(), "token", kubeauth.ResourceAttributes("watch", "osbuilds", "", Namespace, osBuild.Name)).Return(true, nil)
+
+			// when
+			osbuildConfigHandler.WatchOSBuilds(responseWriter, req, Namespace, restapi.WatchOSBuildsParams{
+				Osbuild:       pointer.String(osBuild.Name),
+				Authorization: pointer.String("Bearer token"),
+			})
+
+			// then
+			Expect(responseWriter.Result().StatusCode).To(Equal(http.StatusOK))
+			Expect(readEvents()).To(HaveLen(1))
+		})
+
+		It("with forbidden response, because the secret is another one", func() {
+			// given
+			osBuildRepository.EXPECT().Read(req.Context(), osBuild.Name, Namespace).Return(&osBuild, nil)
+
+			// when
+			osbuildConfigHandler.WatchOSBuilds(responseWriter, req, Namespace, restapi.WatchOSBuildsParams{
+				Osbuild: pointer.String(osBuild.Name),
+				Secret:  pointer.String("456"),
+			})
+
+			// then
+			Expect(responseWriter.Result().StatusCode).To(Equal(http.StatusForbidden))
+		})
+
+		It("with not found response, because the OSBuild doesn't exist", func() {
+			// given
+			returnErr := errors.NewNotFound(schema.GroupResource{Group: "", Resource: "notfound"}, "notfound")
+			osBuildRepository.EXPECT().Read(req.Context(), osBuild.Name, Namespace).Return(nil, returnErr)
+
+			// when
+			osbuildConfigHandler.WatchOSBuilds(responseWriter, req, Namespace, params())
+
+			// then
+			Expect(responseWriter.Result().StatusCode).To(Equal(http.StatusNotFound))
+		})
+	})
+
+	Context("watch the OSBuilds of an OSBuildConfig", func() {
+		params := func() restapi.WatchOSBuildsParams {
+			return restapi.WatchOSBuildsParams{Osbuildconfig: pointer.String(OSBuildConfigName), Secret: &secretVal}
+		}
+
+		It("should stream the new OSBuild until it is ready", func() {
+			// given
+			finishedOSBuild := newOSBuild(OSBuildConfigName+"-1", OSBuildConfigName, "5", ready)
+			osBuildRepository.EXPECT().List(req.Context(), Namespace).Return([]v1alpha1.OSBuild{*finishedOSBuild}, nil).Do(publishOnList(
+				osbuildwatch.Event{Type: osbuildwatch.Modified, OSBuild: newOSBuild(OSBuildConfigName+"-1", OSBuildConfigName, "6", ready, started)},
+				osbuildwatch.Event{Type: osbuildwatch.Added, OSBuild: newOSBuild("other-1", "other", "7")},
+				osbuildwatch.Event{Type: osbuildwatch.Added, OSBuild: newOSBuild(osBuild.Name, OSBuildConfigName, "8")},
+				osbuildwatch.Event{Type: osbuildwatch.Modified, OSBuild: newOSBuild(osBuild.Name, OSBuildConfigName, "9", started)},
+				osbuildwatch.Event{Type: osbuildwatch.Modified, OSBuild: newOSBuild(osBuild.Name, OSBuildConfigName, "10", started, ready)},
+			))
+
+			// when
+			osbuildConfigHandler.WatchOSBuilds(responseWriter, req, Namespace, params())
+
+			// then
+			Expect(responseWriter.Result().StatusCode).To(Equal(http.StatusOK))
+			events := readEvents()
+			Expect(events).To(HaveLen(3))
+			Expect(events[0].id).To(Equal("8"))
+			Expect(events[1].id).To(Equal("9"))
+			Expect(events[2].id).To(Equal("10"))
+			Expect(events[2].osBuild.Phase).To(Equal(restapi.Ready))
+		})
+
+		It("should stream the OSBuilds in progress until they are all finished", func() {
+			// given
+			nextOSBuild := newOSBuild(OSBuildConfigName+"-3", OSBuildConfigName, "11", started)
+			osBuildRepository.EXPECT().List(req.Context(), Namespace).Return([]v1alpha1.OSBuild{osBuild, *nextOSBuild}, nil).Do(publishOnList(
+				osbuildwatch.Event{Type: osbuildwatch.Modified, OSBuild: newOSBuild(osBuild.Name, OSBuildConfigName, "12", started, failed)},
+				osbuildwatch.Event{Type: osbuildwatch.Modified, OSBuild: newOSBuild(osBuild.Name, OSBuildConfigName, "13", started, failed, ready)},
+				osbuildwatch.Event{Type: osbuildwatch.Modified, OSBuild: newOSBuild(nextOSBuild.Name, OSBuildConfigName, "14", started, ready)},
+			))
+
+			// when
+			osbuildConfigHandler.WatchOSBuilds(responseWriter, req, Namespace, params())
+
+			// then
+			events := readEvents()
+			Expect(events).To(HaveLen(4))
+			Expect([]string{events[0].id, events[1].id, events[2].id, events[3].id}).To(Equal([]string{"10", "11", "12", "14"}))
+		})
+
+		It("should authorize the bearer token to watch the OSBuilds of the namespace", func() {
+			// given
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			req = req.WithContext(ctx)
+			authorizer.EXPECT().Authorize(req.Context(), "token", kubeauth.ResourceAttributes("watch", "osbuilds", "", Namespace, "")).Return(true, nil)
+			osBuildRepository.EXPECT().List(req.Context(), Namespace).Return([]v1alpha1.OSBuild{*newOSBuild(osBuild.Name, OSBuildConfigName, "10", ready)}, nil)
+
+			// when
+			osbuildConfigHandler.WatchOSBuilds(responseWriter, req, Namespace, restapi.WatchOSBuildsParams{
+				Osbuildconfig: pointer.String(OSBuildConfigName),
+				Authorization: pointer.String("Bearer token"),
+			})
+
+			// then
+			Expect(responseWriter.Result().StatusCode).To(Equal(http.StatusOK))
+			Expect(readEvents()).To(BeEmpty())
+		})
+
+		It("with unauthorized response, because the request has neither a secret nor a bearer token", func() {
+			// when
+			osbuildConfigHandler.WatchOSBuilds(responseWriter, req, Namespace, restapi.WatchOSBuildsParams{Osbuildconfig: pointer.String(OSBuildConfigName)})
+
+			// then
+			Expect(responseWriter.Result().StatusCode).To(Equal(http.StatusUnauthorized))
+		})
+	})
+
+	DescribeTable("with bad request response, because the watched resource is ambiguous", func(params restapi.WatchOSBuildsParams) {
+		// when
+		osbuildConfigHandler.WatchOSBuilds(responseWriter, req, Namespace, params)
+
+		// then
+		Expect(responseWriter.Result().StatusCode).To(Equal(http.StatusBadRequest))
+	},
+		Entry("with neither an OSBuild nor an OSBuildConfig", restapi.WatchOSBuildsParams{Secret: &secretVal}),
+		Entry("with both an OSBuild and an OSBuildConfig", restapi.WatchOSBuildsParams{
+			Osbuild:       pointer.String(OSBuildConfigName + "-2"),
+			Osbuildconfig: pointer.String(OSBuildConfigName),
+			Secret:        &secretVal,
+		}),
+	)
+})
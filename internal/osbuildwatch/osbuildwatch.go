@@ -0,0 +1,111 @@
+package osbuildwatch
+
+import (
+	"sync"
+
+	toolscache "k8s.io/client-go/tools/cache"
+
+	"github.com/project-flotta/osbuild-operator/api/v1alpha1"
+)
+
+// EventType is the kind of change of an OSBuild
+type EventType string
+
+const (
+	Added    EventType = "added"
+	Modified EventType = "modified"
+	Deleted  EventType = "deleted"
+
+	// bufferSize is the number of events a watcher can lag behind before it is closed
+	bufferSize = 64
+)
+
+// Event is a change of an OSBuild, the OSBuild being shared with the informer and read-only
+type Event struct {
+	Type    EventType
+	OSBuild *v1alpha1.OSBuild
+}
+
+// Broadcaster is the event handler of the OSBuild informer that fans out its events to the watchers, as the event
+// handlers of the informers cannot be removed once added
+type Broadcaster struct {
+	mu       sync.Mutex
+	watchers map[*Watcher]struct{}
+}
+
+// Watcher receives the events of the OSBuilds of a namespace until it is stopped
+type Watcher struct {
+	namespace   string
+	events      chan Event
+	broadcaster *Broadcaster
+}
+
+var _ toolscache.ResourceEventHandler = &Broadcaster{}
+
+func NewBroadcaster() *Broadcaster {
+	return &Broadcaster{watchers: map[*Watcher]struct{}{}}
+}
+
+// Watch returns a watcher of the OSBuilds of the namespace, which must be stopped once done
+func (b *Broadcaster) Watch(namespace string) *Watcher {
+	watcher := &Watcher{
+		namespace:   namespace,
+		events:      make(chan Event, bufferSize),
+		broadcaster: b,
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.watchers[watcher] = struct{}{}
+	return watcher
+}
+
+func (b *Broadcaster) OnAdd(obj interface{}) {
+	b.broadcast(Added, obj)
+}
+
+func (b *Broadcaster) OnUpdate(_, newObj interface{}) {
+	b.broadcast(Modified, newObj)
+}
+
+func (b *Broadcaster) OnDelete(obj interface{}) {
+	if tombstone, ok := obj.(toolscache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	b.broadcast(Deleted, obj)
+}
+
+// broadcast sends the event to the watchers of the namespace of the OSBuild without blocking the informer: the
+// watchers lagging too far behind are closed, and their clients are to watch again
+func (b *Broadcaster) broadcast(eventType EventType, obj interface{}) {
+	osBuild, ok := obj.(*v1alpha1.OSBuild)
+	if !ok {
+		return
+	}
+	event := Event{Type: eventType, OSBuild: osBuild}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for watcher := range b.watchers {
+		if watcher.namespace != osBuild.Namespace {
+			continue
+		}
+		select {
+		case watcher.events <- event:
+		default:
+			delete(b.watchers, watcher)
+			close(watcher.events)
+		}
+	}
+}
+
+// Events returns the channel of the events, which is closed when the watcher lags too far behind
+func (w *Watcher) Events() <-chan Event {
+	return w.events
+}
+
+// Stop stops sending the events to the watcher
+func (w *Watcher) Stop() {
+	w.broadcaster.mu.Lock()
+	defer w.broadcaster.mu.Unlock()
+	delete(w.broadcaster.watchers, w)
+}
@@ -0,0 +1,13 @@
+package osbuildwatch_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestOSBuildWatch(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "OSBuildWatch Spec")
+}
@@ -0,0 +1,98 @@
+package osbuildwatch_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	toolscache "k8s.io/client-go/tools/cache"
+
+	"github.com/project-flotta/osbuild-operator/api/v1alpha1"
+	"github.com/project-flotta/osbuild-operator/internal/osbuildwatch"
+)
+
+var _ = Describe("OSBuild watch", func() {
+	var (
+		broadcaster *osbuildwatch.Broadcaster
+		osBuild     *v1alpha1.OSBuild
+	)
+
+	BeforeEach(func() {
+		broadcaster = osbuildwatch.NewBroadcaster()
+		osBuild = &v1alpha1.OSBuild{ObjectMeta: metav1.ObjectMeta{Name: "edge-1", Namespace: "edge"}}
+	})
+
+	It("should send the events of the namespace to its watchers", func() {
+		// given
+		watcher := broadcaster.Watch("edge")
+		defer watcher.Stop()
+		otherWatcher := broadcaster.Watch("other")
+		defer otherWatcher.Stop()
+		updated := osBuild.DeepCopy()
+		updated.Status.Conditions = []v1alpha1.Condition{{Type: v1alpha1.ConditionReady, Status: metav1.ConditionTrue}}
+
+		// when
+		broadcaster.OnAdd(osBuild)
+		broadcaster.OnUpdate(osBuild, updated)
+		broadcaster.OnDelete(updated)
+
+		// then
+		Expect(watcher.Events()).To(Receive(Equal(osbuildwatch.Event{Type: osbuildwatch.Added, OSBuild: osBuild})))
+		Expect(watcher.Events()).To(Receive(Equal(osbuildwatch.Event{Type: osbuildwatch.Modified, OSBuild: updated})))
+		Expect(watcher.Events()).To(Receive(Equal(osbuildwatch.Event{Type: osbuildwatch.Deleted, OSBuild: updated})))
+		Expect(otherWatcher.Events()).ToNot(Receive())
+	})
+
+	It("should send the last state of the OSBuilds deleted while disconnected", func() {
+		// given
+		watcher := broadcaster.Watch("edge")
+		defer watcher.Stop()
+
+		// when
+		broadcaster.OnDelete(toolscache.DeletedFinalStateUnknown{Key: "edge/edge-1", Obj: osBuild})
+
+		// then
+		Expect(watcher.Events()).To(Receive(Equal(osbuildwatch.Event{Type: osbuildwatch.Deleted, OSBuild: osBuild})))
+	})
+
+	It("should ignore the other objects", func() {
+		// given
+		watcher := broadcaster.Watch("edge")
+		defer watcher.Stop()
+
+		// when
+		broadcaster.OnAdd(&v1alpha1.OSBuildConfig{ObjectMeta: metav1.ObjectMeta{Name: "edge", Namespace: "edge"}})
+
+		// then
+		Expect(watcher.Events()).ToNot(Receive())
+	})
+
+	It("should not send the events to the stopped watchers", func() {
+		// given
+		watcher := broadcaster.Watch("edge")
+		watcher.Stop()
+
+		// when
+		broadcaster.OnAdd(osBuild)
+
+		// then
+		Expect(watcher.Events()).ToNot(Receive())
+	})
+
+	It("should close the watchers lagging behind", func() {
+		// given
+		watcher := broadcaster.Watch("edge")
+		defer watcher.Stop()
+
+		// when
+		for i := 0; i < 100; i++ {
+			broadcaster.OnUpdate(osBuild, osBuild)
+		}
+
+		// then
+		received := 0
+		for range watcher.Events() {
+			received++
+		}
+		Expect(received).To(Equal(64))
+	})
+})
@@ -36,5 +36,11 @@ func (OSBuildConfigChangedPredicate) Update(e event.UpdateEvent) bool {
 	templateTriggerEnabled := newConfig.Spec.Triggers.TemplateConfigChange == nil || *newConfig.Spec.Triggers.TemplateConfigChange
 	templateChanged = templateChanged && templateTriggerEnabled
 
-	return generationChanged || templateChanged
+	_, rollbackRequested := newConfig.Annotations[v1alpha1.RollbackAnnotationKey]
+
+	// the webhooks only annotate the OSBuildConfig, which does not change its generation
+	webhookTriggerTS, webhookTriggered := newConfig.Annotations[v1alpha1.WebHookTriggerAnnotationKey]
+	webhookTriggered = webhookTriggered && webhookTriggerTS != newConfig.Status.LastWebhookTriggerTS
+
+	return generationChanged || templateChanged || rollbackRequested || webhookTriggered
 }
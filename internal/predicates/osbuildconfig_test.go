@@ -40,6 +40,41 @@ var _ = Describe("OSBuildConfig reconciliation predicate", func() {
 				ObjectMeta: v1.ObjectMeta{Generation: 2},
 			},
 		),
+		Entry("when a rollback is requested",
+			&v1alpha1.OSBuildConfig{
+				ObjectMeta: v1.ObjectMeta{Generation: 1},
+			},
+			&v1alpha1.OSBuildConfig{
+				ObjectMeta: v1.ObjectMeta{
+					Generation:  1,
+					Annotations: map[string]string{v1alpha1.RollbackAnnotationKey: "2"},
+				},
+				Spec: v1alpha1.OSBuildConfigSpec{
+					Triggers: v1alpha1.BuildTriggers{
+						ConfigChange: &AFalse,
+					},
+				},
+			},
+		),
+		Entry("when a webhook is triggered",
+			&v1alpha1.OSBuildConfig{
+				ObjectMeta: v1.ObjectMeta{Generation: 1},
+			},
+			&v1alpha1.OSBuildConfig{
+				ObjectMeta: v1.ObjectMeta{
+					Generation:  1,
+					Annotations: map[string]string{v1alpha1.WebHookTriggerAnnotationKey: "2"},
+				},
+				Spec: v1alpha1.OSBuildConfigSpec{
+					Triggers: v1alpha1.BuildTriggers{
+						ConfigChange: &AFalse,
+					},
+				},
+				Status: v1alpha1.OSBuildConfigStatus{
+					LastWebhookTriggerTS: "1",
+				},
+			},
+		),
 		Entry("when template version changed",
 			&v1alpha1.OSBuildConfig{
 				ObjectMeta: v1.ObjectMeta{Generation: 1},
@@ -120,6 +155,20 @@ var _ = Describe("OSBuildConfig reconciliation predicate", func() {
 				ObjectMeta: v1.ObjectMeta{Generation: 1},
 			},
 		),
+		Entry("when the webhook trigger was already processed",
+			&v1alpha1.OSBuildConfig{
+				ObjectMeta: v1.ObjectMeta{Generation: 1},
+			},
+			&v1alpha1.OSBuildConfig{
+				ObjectMeta: v1.ObjectMeta{
+					Generation:  1,
+					Annotations: map[string]string{v1alpha1.WebHookTriggerAnnotationKey: "2"},
+				},
+				Status: v1alpha1.OSBuildConfigStatus{
+					LastWebhookTriggerTS: "2",
+				},
+			},
+		),
 		Entry("when last template version is missing",
 			&v1alpha1.OSBuildConfig{
 				ObjectMeta: v1.ObjectMeta{Generation: 1},
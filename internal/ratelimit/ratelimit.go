@@ -0,0 +1,128 @@
+package ratelimit
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// sweepInterval is the period at which the limiters of the idle keys are forgotten
+const sweepInterval = time.Minute
+
+// KeyedLimiter limits the rate of the events of each key, e.g. of each client or of each OSBuildConfig, with a token
+// bucket per key
+type KeyedLimiter struct {
+	limit rate.Limit
+	burst int
+
+	mu        sync.Mutex
+	limiters  map[string]*keyLimiter
+	lastSweep time.Time
+}
+
+type keyLimiter struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+// NewKeyedLimiter returns a limiter allowing limit events per second to each key, in bursts of up to burst events.
+// A limit of 0 allows all the events
+func NewKeyedLimiter(limit float64, burst int) *KeyedLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &KeyedLimiter{
+		limit:     rate.Limit(limit),
+		burst:     burst,
+		limiters:  map[string]*keyLimiter{},
+		lastSweep: time.Now(),
+	}
+}
+
+// Allow returns whether an event of the key is allowed now, and otherwise how long to wait until it will be
+func (l *KeyedLimiter) Allow(key string) (bool, time.Duration) {
+	if l.limit <= 0 {
+		return true, 0
+	}
+
+	now := time.Now()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.sweep(now)
+
+	entry, ok := l.limiters[key]
+	if !ok {
+		entry = &keyLimiter{limiter: rate.NewLimiter(l.limit, l.burst)}
+		l.limiters[key] = entry
+	}
+	entry.lastSeen = now
+
+	reservation := entry.limiter.ReserveN(now, 1)
+	delay := reservation.DelayFrom(now)
+	if delay > 0 {
+		reservation.CancelAt(now)
+		return false, delay
+	}
+	return true, 0
+}
+
+// sweep forgets the keys idle long enough for their buckets to be full again, as they are then the same as new ones
+func (l *KeyedLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < sweepInterval {
+		return
+	}
+	l.lastSweep = now
+	refill := time.Duration(float64(l.burst) / float64(l.limit) * float64(time.Second))
+	for key, entry := range l.limiters {
+		if now.Sub(entry.lastSeen) >= refill {
+			delete(l.limiters, key)
+		}
+	}
+}
+
+// Middleware rejects the requests of the clients exceeding the limiter. The clients are identified by the last
+// address of the clientIPHeader, e.g. X-Forwarded-For behind a proxy, or by their remote address when it is empty.
+// The header is only to be set behind a proxy that sets it or appends the address of the client to it, since the
+// clients could send another address otherwise
+func Middleware(limiter *KeyedLimiter, clientIPHeader string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			allowed, delay := limiter.Allow(getClientIP(r, clientIPHeader))
+			if !allowed {
+				WriteTooManyRequests(w, delay)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// WriteTooManyRequests responds with 429 Too Many Requests, telling the client when to retry
+func WriteTooManyRequests(w http.ResponseWriter, delay time.Duration) {
+	w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(delay.Seconds()))))
+	w.WriteHeader(http.StatusTooManyRequests)
+}
+
+// getClientIP returns the last address of the header, the one the proxy appended, since the ones before it are sent
+// by the client and cannot be trusted
+func getClientIP(r *http.Request, clientIPHeader string) string {
+	if clientIPHeader != "" {
+		if values := r.Header.Values(clientIPHeader); len(values) > 0 {
+			addresses := strings.Split(values[len(values)-1], ",")
+			if address := strings.TrimSpace(addresses[len(addresses)-1]); address != "" {
+				return address
+			}
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
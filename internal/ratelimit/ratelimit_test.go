@@ -0,0 +1,165 @@
+package ratelimit_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/project-flotta/osbuild-operator/internal/ratelimit"
+)
+
+var _ = Describe("Rate limit", func() {
+	Context("KeyedLimiter", func() {
+		It("should allow the bursts and then tell when to retry", func() {
+			// given
+			limiter := ratelimit.NewKeyedLimiter(0.1, 2)
+
+			// when
+			first, _ := limiter.Allow("ns/config")
+			second, _ := limiter.Allow("ns/config")
+			third, delay := limiter.Allow("ns/config")
+
+			// then
+			Expect(first).To(BeTrue())
+			Expect(second).To(BeTrue())
+			Expect(third).To(BeFalse())
+			Expect(delay).To(BeNumerically(">", 9*time.Second))
+			Expect(delay).To(BeNumerically("<=", 10*time.Second))
+		})
+
+		It("should limit each key separately", func() {
+			// given
+			limiter := ratelimit.NewKeyedLimiter(0.1, 1)
+			allowed, _ := limiter.Allow("ns/config")
+			Expect(allowed).To(BeTrue())
+
+			// when
+			other, _ := limiter.Allow("ns/other-config")
+			again, _ := limiter.Allow("ns/config")
+
+			// then
+			Expect(other).To(BeTrue())
+			Expect(again).To(BeFalse())
+		})
+
+		It("should not consume the bucket with the rejected events", func() {
+			// given
+			limiter := ratelimit.NewKeyedLimiter(10, 1)
+			allowed, _ := limiter.Allow("client")
+			Expect(allowed).To(BeTrue())
+			for i := 0; i < 5; i++ {
+				limiter.Allow("client")
+			}
+
+			// when
+			time.Sleep(110 * time.Millisecond)
+			allowed, _ = limiter.Allow("client")
+
+			// then
+			Expect(allowed).To(BeTrue())
+		})
+
+		It("should allow all the events when disabled", func() {
+			// given
+			limiter := ratelimit.NewKeyedLimiter(0, 0)
+
+			for i := 0; i < 100; i++ {
+				// when
+				allowed, delay := limiter.Allow("client")
+
+				// then
+				Expect(allowed).To(BeTrue())
+				Expect(delay).To(BeZero())
+			}
+		})
+	})
+
+	Context("Middleware", func() {
+		var (
+			served  int
+			handler http.Handler
+		)
+
+		serve := func(remoteAddr string, forwardedFor string) *httptest.ResponseRecorder {
+			req := httptest.NewRequest(http.MethodGet, "/api/osbuild/v1/osbuild/test", nil)
+			req.RemoteAddr = remoteAddr
+			if forwardedFor != "" {
+				req.Header.Set("X-Forwarded-For", forwardedFor)
+			}
+			responseWriter := httptest.NewRecorder()
+			handler.ServeHTTP(responseWriter, req)
+			return responseWriter
+		}
+
+		BeforeEach(func() {
+			served = 0
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				served++
+				w.WriteHeader(http.StatusOK)
+			})
+			handler = ratelimit.Middleware(ratelimit.NewKeyedLimiter(0.1, 1), "")(next)
+		})
+
+		It("should reject the requests of the clients over the limit", func() {
+			// given
+			Expect(serve("10.0.0.1:40000", "").Code).To(Equal(http.StatusOK))
+
+			// when
+			responseWriter := serve("10.0.0.1:40001", "")
+
+			// then
+			Expect(responseWriter.Code).To(Equal(http.StatusTooManyRequests))
+			Expect(responseWriter.Header().Get("Retry-After")).To(Equal("10"))
+			Expect(served).To(Equal(1))
+		})
+
+		It("should serve the other clients", func() {
+			// given
+			Expect(serve("10.0.0.1:40000", "").Code).To(Equal(http.StatusOK))
+
+			// when
+			responseWriter := serve("10.0.0.2:40000", "")
+
+			// then
+			Expect(responseWriter.Code).To(Equal(http.StatusOK))
+			Expect(served).To(Equal(2))
+		})
+
+		It("should identify the clients by the header when configured", func() {
+			// given
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})
+			handler = ratelimit.Middleware(ratelimit.NewKeyedLimiter(0.1, 1), "X-Forwarded-For")(next)
+			Expect(serve("10.128.0.5:40000", "192.0.2.10").Code).To(Equal(http.StatusOK))
+
+			// when
+			otherClient := serve("10.128.0.5:40001", "192.0.2.11")
+			sameClient := serve("10.128.0.6:40000", "192.0.2.10")
+
+			// then
+			Expect(otherClient.Code).To(Equal(http.StatusOK))
+			Expect(sameClient.Code).To(Equal(http.StatusTooManyRequests))
+		})
+
+		It("should identify the clients by the address the proxy appended to the header", func() {
+			// given
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})
+			handler = ratelimit.Middleware(ratelimit.NewKeyedLimiter(0.1, 1), "X-Forwarded-For")(next)
+			Expect(serve("10.128.0.5:40000", "192.0.2.10").Code).To(Equal(http.StatusOK))
+
+			// when
+			spoofedClient := serve("10.128.0.5:40001", "203.0.113.1, 192.0.2.10")
+			otherClient := serve("10.128.0.5:40002", "192.0.2.10, 192.0.2.11")
+
+			// then
+			Expect(spoofedClient.Code).To(Equal(http.StatusTooManyRequests))
+			Expect(otherClient.Code).To(Equal(http.StatusOK))
+		})
+	})
+})
@@ -0,0 +1,71 @@
+package replay
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrStale is returned when the timestamp of the request is out of the replay window
+	ErrStale = errors.New("the timestamp of the request is out of the replay window")
+	// ErrReplayed is returned when the nonce of the request was already used
+	ErrReplayed = errors.New("the nonce of the request was already used")
+)
+
+// Guard rejects the replays of signed requests, whose signature covers a timestamp and a nonce: the timestamps must
+// be within the window around the current time, and the nonces are remembered until their timestamp gets out of it.
+// The Guard remembers the nonces in memory, i.e. for the requests served by the same replica
+type Guard struct {
+	window time.Duration
+
+	mu        sync.Mutex
+	nonces    map[string]time.Time
+	lastSweep time.Time
+}
+
+// NewGuard returns a Guard accepting the timestamps within window of the current time. A window of 0 accepts all the
+// requests
+func NewGuard(window time.Duration) *Guard {
+	return &Guard{
+		window:    window,
+		nonces:    map[string]time.Time{},
+		lastSweep: time.Now(),
+	}
+}
+
+// Check returns ErrStale when the timestamp is out of the window, and ErrReplayed when the nonce was already used.
+// Otherwise the nonce is remembered. The request must be authenticated first, so that nobody else can use its nonce
+func (g *Guard) Check(timestamp time.Time, nonce string) error {
+	if g.window <= 0 {
+		return nil
+	}
+
+	now := time.Now()
+	if timestamp.Before(now.Add(-g.window)) || timestamp.After(now.Add(g.window)) {
+		return ErrStale
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.sweep(now)
+
+	if _, ok := g.nonces[nonce]; ok {
+		return ErrReplayed
+	}
+	// a replay after the expiry is rejected as stale
+	g.nonces[nonce] = timestamp.Add(g.window)
+	return nil
+}
+
+func (g *Guard) sweep(now time.Time) {
+	if now.Sub(g.lastSweep) < g.window {
+		return
+	}
+	g.lastSweep = now
+	for nonce, expiry := range g.nonces {
+		if now.After(expiry) {
+			delete(g.nonces, nonce)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package replay_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/project-flotta/osbuild-operator/internal/replay"
+)
+
+var _ = Describe("Replay guard", func() {
+	var guard *replay.Guard
+
+	BeforeEach(func() {
+		guard = replay.NewGuard(5 * time.Minute)
+	})
+
+	It("should accept a fresh request", func() {
+		// when
+		err := guard.Check(time.Now(), "nonce-1")
+
+		// then
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("should reject a replayed nonce", func() {
+		// given
+		Expect(guard.Check(time.Now(), "nonce-1")).To(Succeed())
+
+		// when
+		err := guard.Check(time.Now(), "nonce-1")
+
+		// then
+		Expect(err).To(MatchError(replay.ErrReplayed))
+	})
+
+	It("should accept different nonces", func() {
+		// given
+		Expect(guard.Check(time.Now(), "nonce-1")).To(Succeed())
+
+		// when
+		err := guard.Check(time.Now(), "nonce-2")
+
+		// then
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	DescribeTable("should reject the timestamps out of the window", func(offset time.Duration) {
+		// when
+		err := guard.Check(time.Now().Add(offset), "nonce-1")
+
+		// then
+		Expect(err).To(MatchError(replay.ErrStale))
+	},
+		Entry("too old", -6*time.Minute),
+		Entry("in the future", 6*time.Minute),
+	)
+
+	It("should not remember the nonces of the stale requests", func() {
+		// given
+		Expect(guard.Check(time.Now().Add(-time.Hour), "nonce-1")).To(MatchError(replay.ErrStale))
+
+		// when
+		err := guard.Check(time.Now(), "nonce-1")
+
+		// then
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("should accept all the requests when disabled", func() {
+		// given
+		guard = replay.NewGuard(0)
+		Expect(guard.Check(time.Now().Add(-time.Hour), "nonce-1")).To(Succeed())
+
+		// when
+		err := guard.Check(time.Now().Add(-time.Hour), "nonce-1")
+
+		// then
+		Expect(err).ToNot(HaveOccurred())
+	})
+})
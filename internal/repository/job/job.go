@@ -6,6 +6,7 @@ import (
 	_ "github.com/golang/mock/mockgen/model"
 
 	batchv1 "k8s.io/api/batch/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -13,6 +14,7 @@ import (
 type Repository interface {
 	Read(ctx context.Context, name string, namespace string) (*batchv1.Job, error)
 	Create(ctx context.Context, job *batchv1.Job) error
+	Delete(ctx context.Context, job *batchv1.Job) error
 }
 
 type CRRepository struct {
@@ -32,3 +34,7 @@ func (r *CRRepository) Read(ctx context.Context, name string, namespace string)
 func (r *CRRepository) Create(ctx context.Context, job *batchv1.Job) error {
 	return r.client.Create(ctx, job)
 }
+
+func (r *CRRepository) Delete(ctx context.Context, job *batchv1.Job) error {
+	return r.client.Delete(ctx, job, client.PropagationPolicy(metav1.DeletePropagationBackground))
+}
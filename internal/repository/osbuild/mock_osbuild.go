@@ -50,6 +50,50 @@ func (mr *MockRepositoryMockRecorder) Create(arg0, arg1 interface{}) *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockRepository)(nil).Create), arg0, arg1)
 }
 
+// Delete mocks base method.
+func (m *MockRepository) Delete(arg0 context.Context, arg1 *v1alpha1.OSBuild) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockRepositoryMockRecorder) Delete(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockRepository)(nil).Delete), arg0, arg1)
+}
+
+// List mocks base method.
+func (m *MockRepository) List(arg0 context.Context, arg1 string) ([]v1alpha1.OSBuild, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", arg0, arg1)
+	ret0, _ := ret[0].([]v1alpha1.OSBuild)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockRepositoryMockRecorder) List(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockRepository)(nil).List), arg0, arg1)
+}
+
+// ListByOSBuildConfig mocks base method.
+func (m *MockRepository) ListByOSBuildConfig(arg0 context.Context, arg1, arg2 string) ([]v1alpha1.OSBuild, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListByOSBuildConfig", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]v1alpha1.OSBuild)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListByOSBuildConfig indicates an expected call of ListByOSBuildConfig.
+func (mr *MockRepositoryMockRecorder) ListByOSBuildConfig(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListByOSBuildConfig", reflect.TypeOf((*MockRepository)(nil).ListByOSBuildConfig), arg0, arg1, arg2)
+}
+
 // Patch mocks base method.
 func (m *MockRepository) Patch(arg0 context.Context, arg1, arg2 *v1alpha1.OSBuild) error {
 	m.ctrl.T.Helper()
@@ -4,6 +4,8 @@ import (
 	"context"
 	_ "github.com/golang/mock/mockgen/model"
 	"github.com/project-flotta/osbuild-operator/api/v1alpha1"
+	"github.com/project-flotta/osbuild-operator/internal/indexer"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -13,6 +15,9 @@ type Repository interface {
 	Create(ctx context.Context, osBuild *v1alpha1.OSBuild) error
 	PatchStatus(ctx context.Context, osbuild *v1alpha1.OSBuild, patch *client.Patch) error
 	Patch(ctx context.Context, old, new *v1alpha1.OSBuild) error
+	ListByOSBuildConfig(ctx context.Context, osBuildConfigName string, namespace string) ([]v1alpha1.OSBuild, error)
+	List(ctx context.Context, namespace string) ([]v1alpha1.OSBuild, error)
+	Delete(ctx context.Context, osBuild *v1alpha1.OSBuild) error
 }
 
 type CRRepository struct {
@@ -41,3 +46,28 @@ func (r *CRRepository) Patch(ctx context.Context, old, new *v1alpha1.OSBuild) er
 	patch := client.MergeFrom(old)
 	return r.client.Patch(ctx, new, patch)
 }
+
+func (r *CRRepository) ListByOSBuildConfig(ctx context.Context, osBuildConfigName string, namespace string) ([]v1alpha1.OSBuild, error) {
+	osBuilds := v1alpha1.OSBuildList{}
+	err := r.client.List(ctx, &osBuilds,
+		client.MatchingFields{indexer.BuildByConfig: osBuildConfigName},
+		client.InNamespace(namespace),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return osBuilds.Items, nil
+}
+
+func (r *CRRepository) List(ctx context.Context, namespace string) ([]v1alpha1.OSBuild, error) {
+	osBuilds := v1alpha1.OSBuildList{}
+	err := r.client.List(ctx, &osBuilds, client.InNamespace(namespace))
+	if err != nil {
+		return nil, err
+	}
+	return osBuilds.Items, nil
+}
+
+func (r *CRRepository) Delete(ctx context.Context, osBuild *v1alpha1.OSBuild) error {
+	return r.client.Delete(ctx, osBuild, client.PropagationPolicy(metav1.DeletePropagationBackground))
+}
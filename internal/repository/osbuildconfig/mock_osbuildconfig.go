@@ -36,6 +36,21 @@ func (m *MockRepository) EXPECT() *MockRepositoryMockRecorder {
 	return m.recorder
 }
 
+// List mocks base method.
+func (m *MockRepository) List(arg0 context.Context, arg1 string) ([]v1alpha1.OSBuildConfig, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", arg0, arg1)
+	ret0, _ := ret[0].([]v1alpha1.OSBuildConfig)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockRepositoryMockRecorder) List(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockRepository)(nil).List), arg0, arg1)
+}
+
 // ListByOSBuildConfigTemplate mocks base method.
 func (m *MockRepository) ListByOSBuildConfigTemplate(arg0 context.Context, arg1, arg2 string) ([]v1alpha1.OSBuildConfig, error) {
 	m.ctrl.T.Helper()
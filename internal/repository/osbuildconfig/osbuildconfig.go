@@ -14,6 +14,7 @@ type Repository interface {
 	Patch(ctx context.Context, old, new *v1alpha1.OSBuildConfig) error
 	PatchStatus(ctx context.Context, osbuildConfig *v1alpha1.OSBuildConfig, patch *client.Patch) error
 	ListByOSBuildConfigTemplate(ctx context.Context, templateName string, namespace string) ([]v1alpha1.OSBuildConfig, error)
+	List(ctx context.Context, namespace string) ([]v1alpha1.OSBuildConfig, error)
 }
 
 type CRRepository struct {
@@ -50,3 +51,12 @@ func (r *CRRepository) ListByOSBuildConfigTemplate(ctx context.Context, template
 	}
 	return configs.Items, nil
 }
+
+func (r *CRRepository) List(ctx context.Context, namespace string) ([]v1alpha1.OSBuildConfig, error) {
+	configs := v1alpha1.OSBuildConfigList{}
+	err := r.client.List(ctx, &configs, client.InNamespace(namespace))
+	if err != nil {
+		return nil, err
+	}
+	return configs.Items, nil
+}
@@ -14,6 +14,7 @@ import (
 type Repository interface {
 	Read(ctx context.Context, name string) (*v1alpha1.OSBuildEnvConfig, error)
 	Patch(ctx context.Context, old, new *v1alpha1.OSBuildEnvConfig) error
+	List(ctx context.Context) ([]v1alpha1.OSBuildEnvConfig, error)
 }
 
 type CRRepository struct {
@@ -34,3 +35,12 @@ func (r *CRRepository) Patch(ctx context.Context, old, new *v1alpha1.OSBuildEnvC
 	patch := client.MergeFrom(old)
 	return r.client.Patch(ctx, new, patch)
 }
+
+func (r *CRRepository) List(ctx context.Context) ([]v1alpha1.OSBuildEnvConfig, error) {
+	osBuildEnvConfigs := v1alpha1.OSBuildEnvConfigList{}
+	err := r.client.List(ctx, &osBuildEnvConfigs)
+	if err != nil {
+		return nil, err
+	}
+	return osBuildEnvConfigs.Items, nil
+}
@@ -0,0 +1,58 @@
+package osbuildrelease
+
+import (
+	"context"
+
+	_ "github.com/golang/mock/mockgen/model"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/project-flotta/osbuild-operator/api/v1alpha1"
+	"github.com/project-flotta/osbuild-operator/internal/indexer"
+)
+
+//go:generate mockgen -package=osbuildrelease -destination=mock_osbuildrelease.go . Repository
+type Repository interface {
+	Read(ctx context.Context, name string, namespace string) (*v1alpha1.OSBuildRelease, error)
+	PatchStatus(ctx context.Context, osBuildRelease *v1alpha1.OSBuildRelease, patch *client.Patch) error
+	List(ctx context.Context, namespace string) ([]v1alpha1.OSBuildRelease, error)
+	ListByOSBuild(ctx context.Context, osBuildName string, namespace string) ([]v1alpha1.OSBuildRelease, error)
+}
+
+type CRRepository struct {
+	client client.Client
+}
+
+func NewOSBuildReleaseRepository(client client.Client) *CRRepository {
+	return &CRRepository{client: client}
+}
+
+func (r *CRRepository) Read(ctx context.Context, name string, namespace string) (*v1alpha1.OSBuildRelease, error) {
+	osBuildRelease := v1alpha1.OSBuildRelease{}
+	err := r.client.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, &osBuildRelease)
+	return &osBuildRelease, err
+}
+
+func (r *CRRepository) PatchStatus(ctx context.Context, osBuildRelease *v1alpha1.OSBuildRelease, patch *client.Patch) error {
+	return r.client.Status().Patch(ctx, osBuildRelease, *patch)
+}
+
+func (r *CRRepository) List(ctx context.Context, namespace string) ([]v1alpha1.OSBuildRelease, error) {
+	osBuildReleases := v1alpha1.OSBuildReleaseList{}
+	err := r.client.List(ctx, &osBuildReleases, client.InNamespace(namespace))
+	if err != nil {
+		return nil, err
+	}
+	return osBuildReleases.Items, nil
+}
+
+func (r *CRRepository) ListByOSBuild(ctx context.Context, osBuildName string, namespace string) ([]v1alpha1.OSBuildRelease, error) {
+	osBuildReleases := v1alpha1.OSBuildReleaseList{}
+	err := r.client.List(ctx, &osBuildReleases,
+		client.MatchingFields{indexer.ReleaseByBuild: osBuildName},
+		client.InNamespace(namespace),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return osBuildReleases.Items, nil
+}
@@ -0,0 +1,81 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/project-flotta/osbuild-operator/internal/repository/ostreerepository (interfaces: Repository)
+
+// Package ostreerepository is a generated GoMock package.
+package ostreerepository
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	v1alpha1 "github.com/project-flotta/osbuild-operator/api/v1alpha1"
+	client "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// MockRepository is a mock of Repository interface.
+type MockRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockRepositoryMockRecorder
+}
+
+// MockRepositoryMockRecorder is the mock recorder for MockRepository.
+type MockRepositoryMockRecorder struct {
+	mock *MockRepository
+}
+
+// NewMockRepository creates a new mock instance.
+func NewMockRepository(ctrl *gomock.Controller) *MockRepository {
+	mock := &MockRepository{ctrl: ctrl}
+	mock.recorder = &MockRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRepository) EXPECT() *MockRepositoryMockRecorder {
+	return m.recorder
+}
+
+// ListByOSBuildConfig mocks base method.
+func (m *MockRepository) ListByOSBuildConfig(arg0 context.Context, arg1, arg2 string) ([]v1alpha1.OSTreeRepository, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListByOSBuildConfig", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]v1alpha1.OSTreeRepository)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListByOSBuildConfig indicates an expected call of ListByOSBuildConfig.
+func (mr *MockRepositoryMockRecorder) ListByOSBuildConfig(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListByOSBuildConfig", reflect.TypeOf((*MockRepository)(nil).ListByOSBuildConfig), arg0, arg1, arg2)
+}
+
+// PatchStatus mocks base method.
+func (m *MockRepository) PatchStatus(arg0 context.Context, arg1 *v1alpha1.OSTreeRepository, arg2 *client.Patch) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PatchStatus", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PatchStatus indicates an expected call of PatchStatus.
+func (mr *MockRepositoryMockRecorder) PatchStatus(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PatchStatus", reflect.TypeOf((*MockRepository)(nil).PatchStatus), arg0, arg1, arg2)
+}
+
+// Read mocks base method.
+func (m *MockRepository) Read(arg0 context.Context, arg1, arg2 string) (*v1alpha1.OSTreeRepository, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Read", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*v1alpha1.OSTreeRepository)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Read indicates an expected call of Read.
+func (mr *MockRepositoryMockRecorder) Read(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Read", reflect.TypeOf((*MockRepository)(nil).Read), arg0, arg1, arg2)
+}
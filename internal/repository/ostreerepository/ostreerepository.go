@@ -0,0 +1,48 @@
+package ostreerepository
+
+import (
+	"context"
+
+	_ "github.com/golang/mock/mockgen/model"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/project-flotta/osbuild-operator/api/v1alpha1"
+	"github.com/project-flotta/osbuild-operator/internal/indexer"
+)
+
+//go:generate mockgen -package=ostreerepository -destination=mock_ostreerepository.go . Repository
+type Repository interface {
+	Read(ctx context.Context, name string, namespace string) (*v1alpha1.OSTreeRepository, error)
+	PatchStatus(ctx context.Context, ostreeRepository *v1alpha1.OSTreeRepository, patch *client.Patch) error
+	ListByOSBuildConfig(ctx context.Context, osBuildConfigName string, namespace string) ([]v1alpha1.OSTreeRepository, error)
+}
+
+type CRRepository struct {
+	client client.Client
+}
+
+func NewOSTreeRepositoryRepository(client client.Client) *CRRepository {
+	return &CRRepository{client: client}
+}
+
+func (r *CRRepository) Read(ctx context.Context, name string, namespace string) (*v1alpha1.OSTreeRepository, error) {
+	ostreeRepository := v1alpha1.OSTreeRepository{}
+	err := r.client.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, &ostreeRepository)
+	return &ostreeRepository, err
+}
+
+func (r *CRRepository) PatchStatus(ctx context.Context, ostreeRepository *v1alpha1.OSTreeRepository, patch *client.Patch) error {
+	return r.client.Status().Patch(ctx, ostreeRepository, *patch)
+}
+
+func (r *CRRepository) ListByOSBuildConfig(ctx context.Context, osBuildConfigName string, namespace string) ([]v1alpha1.OSTreeRepository, error) {
+	ostreeRepositories := v1alpha1.OSTreeRepositoryList{}
+	err := r.client.List(ctx, &ostreeRepositories,
+		client.MatchingFields{indexer.RepositoryByConfig: osBuildConfigName},
+		client.InNamespace(namespace),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return ostreeRepositories.Items, nil
+}
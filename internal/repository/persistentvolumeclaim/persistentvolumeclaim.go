@@ -0,0 +1,34 @@
+package persistentvolumeclaim
+
+import (
+	"context"
+
+	_ "github.com/golang/mock/mockgen/model"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+//go:generate mockgen -package=persistentvolumeclaim -destination=mock_persistentvolumeclaim.go . Repository
+type Repository interface {
+	Read(ctx context.Context, name string, namespace string) (*corev1.PersistentVolumeClaim, error)
+	Create(ctx context.Context, persistentVolumeClaim *corev1.PersistentVolumeClaim) error
+}
+
+type CRRepository struct {
+	client client.Client
+}
+
+func NewPersistentVolumeClaimRepository(client client.Client) *CRRepository {
+	return &CRRepository{client: client}
+}
+
+func (r *CRRepository) Read(ctx context.Context, name string, namespace string) (*corev1.PersistentVolumeClaim, error) {
+	persistentVolumeClaim := corev1.PersistentVolumeClaim{}
+	err := r.client.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, &persistentVolumeClaim)
+	return &persistentVolumeClaim, err
+}
+
+func (r *CRRepository) Create(ctx context.Context, persistentVolumeClaim *corev1.PersistentVolumeClaim) error {
+	return r.client.Create(ctx, persistentVolumeClaim)
+}
@@ -0,0 +1,35 @@
+package pod
+
+import (
+	"context"
+
+	_ "github.com/golang/mock/mockgen/model"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// jobNameLabel is set by the job controller on the pods of a job
+const jobNameLabel = "job-name"
+
+//go:generate mockgen -package=pod -destination=mock_pod.go . Repository
+type Repository interface {
+	ListByJob(ctx context.Context, jobName string, namespace string) ([]corev1.Pod, error)
+}
+
+type CRRepository struct {
+	client client.Client
+}
+
+func NewPodRepository(client client.Client) *CRRepository {
+	return &CRRepository{client: client}
+}
+
+func (r *CRRepository) ListByJob(ctx context.Context, jobName string, namespace string) ([]corev1.Pod, error) {
+	podList := corev1.PodList{}
+	err := r.client.List(ctx, &podList, client.InNamespace(namespace), client.MatchingLabels{jobNameLabel: jobName})
+	if err != nil {
+		return nil, err
+	}
+	return podList.Items, nil
+}
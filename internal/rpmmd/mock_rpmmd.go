@@ -0,0 +1,89 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/project-flotta/osbuild-operator/internal/rpmmd (interfaces: PackageResolver,RevisionReader)
+
+// Package rpmmd is a generated GoMock package.
+package rpmmd
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	v1alpha1 "github.com/project-flotta/osbuild-operator/api/v1alpha1"
+)
+
+// MockPackageResolver is a mock of PackageResolver interface.
+type MockPackageResolver struct {
+	ctrl     *gomock.Controller
+	recorder *MockPackageResolverMockRecorder
+}
+
+// MockPackageResolverMockRecorder is the mock recorder for MockPackageResolver.
+type MockPackageResolverMockRecorder struct {
+	mock *MockPackageResolver
+}
+
+// NewMockPackageResolver creates a new mock instance.
+func NewMockPackageResolver(ctrl *gomock.Controller) *MockPackageResolver {
+	mock := &MockPackageResolver{ctrl: ctrl}
+	mock.recorder = &MockPackageResolverMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPackageResolver) EXPECT() *MockPackageResolverMockRecorder {
+	return m.recorder
+}
+
+// FindMissingPackages mocks base method.
+func (m *MockPackageResolver) FindMissingPackages(arg0 context.Context, arg1 []v1alpha1.Repository, arg2 v1alpha1.Architecture, arg3 []string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindMissingPackages", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindMissingPackages indicates an expected call of FindMissingPackages.
+func (mr *MockPackageResolverMockRecorder) FindMissingPackages(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindMissingPackages", reflect.TypeOf((*MockPackageResolver)(nil).FindMissingPackages), arg0, arg1, arg2, arg3)
+}
+
+// MockRevisionReader is a mock of RevisionReader interface.
+type MockRevisionReader struct {
+	ctrl     *gomock.Controller
+	recorder *MockRevisionReaderMockRecorder
+}
+
+// MockRevisionReaderMockRecorder is the mock recorder for MockRevisionReader.
+type MockRevisionReaderMockRecorder struct {
+	mock *MockRevisionReader
+}
+
+// NewMockRevisionReader creates a new mock instance.
+func NewMockRevisionReader(ctrl *gomock.Controller) *MockRevisionReader {
+	mock := &MockRevisionReader{ctrl: ctrl}
+	mock.recorder = &MockRevisionReaderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRevisionReader) EXPECT() *MockRevisionReaderMockRecorder {
+	return m.recorder
+}
+
+// GetRevisions mocks base method.
+func (m *MockRevisionReader) GetRevisions(arg0 context.Context, arg1 []v1alpha1.Repository) ([]v1alpha1.RepositoryRevision, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRevisions", arg0, arg1)
+	ret0, _ := ret[0].([]v1alpha1.RepositoryRevision)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRevisions indicates an expected call of GetRevisions.
+func (mr *MockRevisionReaderMockRecorder) GetRevisions(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRevisions", reflect.TypeOf((*MockRevisionReader)(nil).GetRevisions), arg0, arg1)
+}
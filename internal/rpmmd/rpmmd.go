@@ -0,0 +1,356 @@
+package rpmmd
+
+import (
+	"compress/bzip2"
+	"compress/gzip"
+	"context"
+	"crypto/tls"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	_ "github.com/golang/mock/mockgen/model"
+
+	"github.com/project-flotta/osbuild-operator/api/v1alpha1"
+)
+
+const (
+	repomdPath       = "repodata/repomd.xml"
+	primaryDataType  = "primary"
+	noarch           = "noarch"
+	groupPrefix      = "@"
+	globCharacters   = "*?["
+	osPackageSetName = "os"
+)
+
+// ErrValidationNotSupported is returned when the packages cannot be validated against the repositories, whatever the
+// number of attempts, e.g. as one of them has no baseurl
+var ErrValidationNotSupported = errors.New("repository without a baseurl cannot be validated")
+
+//go:generate mockgen -package=rpmmd -destination=mock_rpmmd.go . PackageResolver,RevisionReader
+
+// PackageResolver resolves the packages requested by a build against the metadata of its RPM repositories
+type PackageResolver interface {
+	// FindMissingPackages returns the packages that neither a package nor a provide of the repositories matches for
+	// the architecture. An error is returned when the metadata of one of the repositories cannot be loaded, as the
+	// packages cannot be validated then, ErrValidationNotSupported when they can never be.
+	FindMissingPackages(ctx context.Context, repositories []v1alpha1.Repository, arch v1alpha1.Architecture, packages []string) ([]string, error)
+}
+
+// RevisionReader reads the revisions of RPM repositories, to find out whether their content changed
+type RevisionReader interface {
+	// GetRevisions returns the revisions of the repositories sorted by base URL. An error is returned when the
+	// metadata of one of the repositories cannot be loaded.
+	GetRevisions(ctx context.Context, repositories []v1alpha1.Repository) ([]v1alpha1.RepositoryRevision, error)
+}
+
+// repoPackages holds the names and provides of the packages of a repository for one architecture
+type repoPackages struct {
+	checksum string
+	names    []string
+	provides map[string]bool
+}
+
+// MetadataPackageResolver downloads the repomd and primary metadata of the repositories and caches it until the
+// checksum of the primary metadata changes. It reads the revisions of the repositories from their repomd as well
+type MetadataPackageResolver struct {
+	client         *http.Client
+	insecureClient *http.Client
+
+	mutex sync.Mutex
+	cache map[string]*repoPackages
+}
+
+func NewMetadataPackageResolver(timeout time.Duration) *MetadataPackageResolver {
+	insecureTransport := http.DefaultTransport.(*http.Transport).Clone()
+	insecureTransport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true} // #nosec G402
+	return &MetadataPackageResolver{
+		client:         &http.Client{Timeout: timeout},
+		insecureClient: &http.Client{Timeout: timeout, Transport: insecureTransport},
+		cache:          map[string]*repoPackages{},
+	}
+}
+
+func (r *MetadataPackageResolver) FindMissingPackages(ctx context.Context, repositories []v1alpha1.Repository, arch v1alpha1.Architecture, packages []string) ([]string, error) {
+	var loaded []*repoPackages
+	for _, repository := range repositories {
+		if !isOSRepository(repository) {
+			continue
+		}
+		if repository.Baseurl == nil || *repository.Baseurl == "" {
+			return nil, ErrValidationNotSupported
+		}
+		pkgs, err := r.loadRepository(ctx, repository, string(arch))
+		if err != nil {
+			return nil, fmt.Errorf("failed to load the metadata of repository %s: %w", *repository.Baseurl, err)
+		}
+		loaded = append(loaded, pkgs)
+	}
+
+	var missing []string
+	for _, pkg := range packages {
+		if strings.HasPrefix(pkg, groupPrefix) {
+			continue
+		}
+		if !isPackageAvailable(loaded, pkg) {
+			missing = append(missing, pkg)
+		}
+	}
+	return missing, nil
+}
+
+func (r *MetadataPackageResolver) GetRevisions(ctx context.Context, repositories []v1alpha1.Repository) ([]v1alpha1.RepositoryRevision, error) {
+	revisions := map[string]string{}
+	for _, repository := range repositories {
+		if repository.Baseurl == nil || *repository.Baseurl == "" {
+			return nil, fmt.Errorf("repository without a baseurl has no revision")
+		}
+		baseUrl := strings.TrimSuffix(*repository.Baseurl, "/")
+		if _, ok := revisions[baseUrl]; ok {
+			continue
+		}
+
+		metadata, err := r.getRepomd(ctx, repository)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load the metadata of repository %s: %w", *repository.Baseurl, err)
+		}
+		revision := metadata.Revision
+		if revision == "" {
+			_, revision, err = getPrimaryData(metadata)
+			if err != nil {
+				return nil, err
+			}
+		}
+		revisions[baseUrl] = revision
+	}
+
+	result := make([]v1alpha1.RepositoryRevision, 0, len(revisions))
+	for baseUrl, revision := range revisions {
+		result = append(result, v1alpha1.RepositoryRevision{Baseurl: baseUrl, Revision: revision})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Baseurl < result[j].Baseurl
+	})
+	return result, nil
+}
+
+// isOSRepository returns whether the packages of the image may be installed from the repository
+func isOSRepository(repository v1alpha1.Repository) bool {
+	if repository.PackageSets == nil || len(*repository.PackageSets) == 0 {
+		return true
+	}
+	for _, packageSet := range *repository.PackageSets {
+		if packageSet == osPackageSetName {
+			return true
+		}
+	}
+	return false
+}
+
+func isPackageAvailable(repositories []*repoPackages, pkg string) bool {
+	candidates := getPackageNameCandidates(pkg)
+	for _, repository := range repositories {
+		for _, candidate := range candidates {
+			if repository.provides[candidate] {
+				return true
+			}
+			if !strings.ContainsAny(candidate, globCharacters) {
+				continue
+			}
+			for _, name := range repository.names {
+				if matched, _ := path.Match(candidate, name); matched {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// getPackageNameCandidates returns the names the package may refer to, as a package may be requested with its
+// version, release or architecture, e.g. vim-enhanced-8.2, vim-enhanced-8.2-1.el9 or vim-enhanced.x86_64
+func getPackageNameCandidates(pkg string) []string {
+	candidates := []string{pkg}
+	if i := strings.LastIndex(pkg, "."); i > 0 {
+		candidates = append(candidates, pkg[:i])
+	}
+	name := pkg
+	for i := 0; i < 2; i++ {
+		separator := strings.LastIndex(name, "-")
+		if separator <= 0 || separator == len(name)-1 || name[separator+1] < '0' || name[separator+1] > '9' {
+			break
+		}
+		name = name[:separator]
+		candidates = append(candidates, name)
+	}
+	return candidates
+}
+
+func (r *MetadataPackageResolver) loadRepository(ctx context.Context, repository v1alpha1.Repository, arch string) (*repoPackages, error) {
+	metadata, err := r.getRepomd(ctx, repository)
+	if err != nil {
+		return nil, err
+	}
+	location, checksum, err := getPrimaryData(metadata)
+	if err != nil {
+		return nil, err
+	}
+	baseUrl := strings.TrimSuffix(*repository.Baseurl, "/")
+
+	cacheKey := baseUrl + "|" + arch
+	r.mutex.Lock()
+	cached, ok := r.cache[cacheKey]
+	r.mutex.Unlock()
+	if ok && cached.checksum == checksum {
+		return cached, nil
+	}
+
+	primaryBody, err := r.get(ctx, r.getClient(repository), baseUrl+"/"+location)
+	if err != nil {
+		return nil, err
+	}
+	defer primaryBody.Close()
+
+	primaryReader, err := decompress(location, primaryBody)
+	if err != nil {
+		return nil, err
+	}
+
+	pkgs, err := parsePrimary(primaryReader, arch)
+	if err != nil {
+		return nil, err
+	}
+	pkgs.checksum = checksum
+
+	r.mutex.Lock()
+	r.cache[cacheKey] = pkgs
+	r.mutex.Unlock()
+	return pkgs, nil
+}
+
+func (r *MetadataPackageResolver) getClient(repository v1alpha1.Repository) *http.Client {
+	if repository.IgnoreSsl != nil && *repository.IgnoreSsl {
+		return r.insecureClient
+	}
+	return r.client
+}
+
+func (r *MetadataPackageResolver) getRepomd(ctx context.Context, repository v1alpha1.Repository) (*repomd, error) {
+	baseUrl := strings.TrimSuffix(*repository.Baseurl, "/")
+	body, err := r.get(ctx, r.getClient(repository), baseUrl+"/"+repomdPath)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	var metadata repomd
+	err = xml.NewDecoder(body).Decode(&metadata)
+	if err != nil {
+		return nil, err
+	}
+	return &metadata, nil
+}
+
+func (r *MetadataPackageResolver) get(ctx context.Context, client *http.Client, url string) (io.ReadCloser, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	response, err := client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	if response.StatusCode != http.StatusOK {
+		response.Body.Close()
+		return nil, fmt.Errorf("request to %s failed with status code %d", url, response.StatusCode)
+	}
+	return response.Body, nil
+}
+
+type repomd struct {
+	Revision string `xml:"revision"`
+	Data     []struct {
+		Type     string `xml:"type,attr"`
+		Checksum string `xml:"checksum"`
+		Location struct {
+			Href string `xml:"href,attr"`
+		} `xml:"location"`
+	} `xml:"data"`
+}
+
+// getPrimaryData returns the location and the checksum of the primary metadata
+func getPrimaryData(metadata *repomd) (string, string, error) {
+	for _, data := range metadata.Data {
+		if data.Type == primaryDataType && data.Location.Href != "" {
+			return data.Location.Href, data.Checksum, nil
+		}
+	}
+	return "", "", fmt.Errorf("repomd has no %s metadata", primaryDataType)
+}
+
+func decompress(location string, reader io.Reader) (io.Reader, error) {
+	switch path.Ext(location) {
+	case ".gz":
+		return gzip.NewReader(reader)
+	case ".bz2":
+		return bzip2.NewReader(reader), nil
+	case ".xml":
+		return reader, nil
+	default:
+		return nil, fmt.Errorf("unsupported compression of %s", location)
+	}
+}
+
+type primaryPackage struct {
+	Name     string `xml:"name"`
+	Arch     string `xml:"arch"`
+	Provides []struct {
+		Name string `xml:"name,attr"`
+	} `xml:"format>provides>entry"`
+	Files []string `xml:"format>file"`
+}
+
+// parsePrimary streams the primary metadata and collects the packages built for the architecture or noarch
+func parsePrimary(reader io.Reader, arch string) (*repoPackages, error) {
+	pkgs := &repoPackages{provides: map[string]bool{}}
+	decoder := xml.NewDecoder(reader)
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return pkgs, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		start, ok := token.(xml.StartElement)
+		if !ok || start.Name.Local != "package" {
+			continue
+		}
+
+		var pkg primaryPackage
+		err = decoder.DecodeElement(&pkg, &start)
+		if err != nil {
+			return nil, err
+		}
+		if pkg.Arch != arch && pkg.Arch != noarch {
+			continue
+		}
+
+		pkgs.names = append(pkgs.names, pkg.Name)
+		pkgs.provides[pkg.Name] = true
+		for _, provide := range pkg.Provides {
+			pkgs.provides[provide.Name] = true
+		}
+		for _, file := range pkg.Files {
+			pkgs.provides[file] = true
+		}
+	}
+}
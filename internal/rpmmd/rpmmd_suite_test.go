@@ -0,0 +1,13 @@
+package rpmmd_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestRPMMD(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "RPMMD Spec")
+}
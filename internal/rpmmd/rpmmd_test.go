@@ -0,0 +1,245 @@
+package rpmmd_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/project-flotta/osbuild-operator/api/v1alpha1"
+	"github.com/project-flotta/osbuild-operator/internal/rpmmd"
+)
+
+const (
+	repomdTemplate = `<?xml version="1.0" encoding="UTF-8"?>
+<repomd xmlns="http://linux.duke.edu/metadata/repo" xmlns:rpm="http://linux.duke.edu/metadata/rpm">
+  <revision>%s</revision>
+  <data type="filelists">
+    <checksum type="sha256">filelists</checksum>
+    <location href="repodata/filelists.xml.gz"/>
+  </data>
+  <data type="primary">
+    <checksum type="sha256">%s</checksum>
+    <location href="repodata/%s-primary.xml.gz"/>
+  </data>
+</repomd>`
+
+	primary = `<?xml version="1.0" encoding="UTF-8"?>
+<metadata xmlns="http://linux.duke.edu/metadata/common" xmlns:rpm="http://linux.duke.edu/metadata/rpm" packages="3">
+<package type="rpm">
+  <name>vim-enhanced</name>
+  <arch>x86_64</arch>
+  <version epoch="2" ver="8.2.2637" rel="16.el9"/>
+  <format>
+    <rpm:provides>
+      <rpm:entry name="vim-enhanced" flags="EQ" epoch="2" ver="8.2.2637" rel="16.el9"/>
+      <rpm:entry name="vim"/>
+    </rpm:provides>
+    <file>/usr/bin/vim</file>
+  </format>
+</package>
+<package type="rpm">
+  <name>python3-requests</name>
+  <arch>noarch</arch>
+  <format>
+    <rpm:provides>
+      <rpm:entry name="python3dist(requests)"/>
+    </rpm:provides>
+  </format>
+</package>
+<package type="rpm">
+  <name>arm-only</name>
+  <arch>aarch64</arch>
+  <format/>
+</package>
+</metadata>`
+)
+
+var _ = Describe("RPM metadata package resolver", func() {
+	var (
+		ctx          = context.Background()
+		server       *httptest.Server
+		requests     map[string]int
+		checksum     string
+		revision     string
+		resolver     *rpmmd.MetadataPackageResolver
+		repositories []v1alpha1.Repository
+	)
+
+	BeforeEach(func() {
+		requests = map[string]int{}
+		checksum = "abc"
+		revision = "1"
+
+		var compressed bytes.Buffer
+		writer := gzip.NewWriter(&compressed)
+		_, err := writer.Write([]byte(primary))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(writer.Close()).To(Succeed())
+
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests[r.URL.Path]++
+			switch r.URL.Path {
+			case "/repo/repodata/repomd.xml":
+				_, _ = fmt.Fprintf(w, repomdTemplate, revision, checksum, checksum)
+			case fmt.Sprintf("/repo/repodata/%s-primary.xml.gz", checksum):
+				_, _ = w.Write(compressed.Bytes())
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+
+		baseUrl := server.URL + "/repo/"
+		repositories = []v1alpha1.Repository{{Baseurl: &baseUrl}}
+		resolver = rpmmd.NewMetadataPackageResolver(time.Second * 5)
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	DescribeTable("should resolve the package", func(pkg string) {
+		// when
+		missing, err := resolver.FindMissingPackages(ctx, repositories, "x86_64", []string{pkg})
+
+		// then
+		Expect(err).NotTo(HaveOccurred())
+		Expect(missing).To(BeEmpty())
+	},
+		Entry("name", "vim-enhanced"),
+		Entry("noarch name", "python3-requests"),
+		Entry("provide", "python3dist(requests)"),
+		Entry("file", "/usr/bin/vim"),
+		Entry("glob", "vim-*"),
+		Entry("name and version", "vim-enhanced-8.2.2637"),
+		Entry("name, version and release", "vim-enhanced-8.2.2637-16.el9"),
+		Entry("name and architecture", "vim-enhanced.x86_64"),
+		Entry("group", "@core"),
+	)
+
+	It("should return the missing packages", func() {
+		// when
+		missing, err := resolver.FindMissingPackages(ctx, repositories, "x86_64", []string{"vim-enhanced", "arm-only", "unknown", "unknown-*"})
+
+		// then
+		Expect(err).NotTo(HaveOccurred())
+		Expect(missing).To(Equal([]string{"arm-only", "unknown", "unknown-*"}))
+	})
+
+	It("should cache the primary metadata until its checksum changes", func() {
+		// given
+		_, err := resolver.FindMissingPackages(ctx, repositories, "x86_64", []string{"vim"})
+		Expect(err).NotTo(HaveOccurred())
+
+		// when
+		_, err = resolver.FindMissingPackages(ctx, repositories, "x86_64", []string{"vim"})
+		Expect(err).NotTo(HaveOccurred())
+		checksum = "def"
+		_, err = resolver.FindMissingPackages(ctx, repositories, "x86_64", []string{"vim"})
+
+		// then
+		Expect(err).NotTo(HaveOccurred())
+		Expect(requests["/repo/repodata/repomd.xml"]).To(Equal(3))
+		Expect(requests["/repo/repodata/abc-primary.xml.gz"]).To(Equal(1))
+		Expect(requests["/repo/repodata/def-primary.xml.gz"]).To(Equal(1))
+	})
+
+	It("should skip the repositories not used by the os package set", func() {
+		// given
+		missingUrl := server.URL + "/missing/"
+		packageSets := []string{"build"}
+		repositories = append(repositories, v1alpha1.Repository{Baseurl: &missingUrl, PackageSets: &packageSets})
+
+		// when
+		missing, err := resolver.FindMissingPackages(ctx, repositories, "x86_64", []string{"vim"})
+
+		// then
+		Expect(err).NotTo(HaveOccurred())
+		Expect(missing).To(BeEmpty())
+		Expect(requests).NotTo(HaveKey("/missing/repodata/repomd.xml"))
+	})
+
+	It("should fail when the metadata of a repository cannot be loaded", func() {
+		// given
+		missingUrl := server.URL + "/missing/"
+		repositories = append(repositories, v1alpha1.Repository{Baseurl: &missingUrl})
+
+		// when
+		_, err := resolver.FindMissingPackages(ctx, repositories, "x86_64", []string{"vim"})
+
+		// then
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should fail when a repository has no baseurl", func() {
+		// given
+		metalink := server.URL + "/metalink"
+		repositories = append(repositories, v1alpha1.Repository{Metalink: &metalink})
+
+		// when
+		_, err := resolver.FindMissingPackages(ctx, repositories, "x86_64", []string{"vim"})
+
+		// then
+		Expect(err).To(HaveOccurred())
+	})
+
+	Context("Reading the revisions", func() {
+		It("should return the revisions of the repositories once per base URL", func() {
+			// given
+			baseUrl := *repositories[0].Baseurl
+			otherUrl := server.URL + "/repo"
+			repositories = append(repositories, v1alpha1.Repository{Baseurl: &otherUrl})
+
+			// when
+			revisions, err := resolver.GetRevisions(ctx, repositories)
+
+			// then
+			Expect(err).NotTo(HaveOccurred())
+			Expect(revisions).To(Equal([]v1alpha1.RepositoryRevision{{Baseurl: baseUrl[:len(baseUrl)-1], Revision: "1"}}))
+			Expect(requests["/repo/repodata/repomd.xml"]).To(Equal(1))
+		})
+
+		It("should return the checksum of the primary metadata when the repository has no revision", func() {
+			// given
+			revision = ""
+
+			// when
+			revisions, err := resolver.GetRevisions(ctx, repositories)
+
+			// then
+			Expect(err).NotTo(HaveOccurred())
+			Expect(revisions).To(HaveLen(1))
+			Expect(revisions[0].Revision).To(Equal("abc"))
+		})
+
+		It("should fail when the metadata of a repository cannot be loaded", func() {
+			// given
+			missingUrl := server.URL + "/missing/"
+			repositories = append(repositories, v1alpha1.Repository{Baseurl: &missingUrl})
+
+			// when
+			_, err := resolver.GetRevisions(ctx, repositories)
+
+			// then
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("should fail when a repository has no baseurl", func() {
+			// given
+			metalink := server.URL + "/metalink"
+			repositories = append(repositories, v1alpha1.Repository{Metalink: &metalink})
+
+			// when
+			_, err := resolver.GetRevisions(ctx, repositories)
+
+			// then
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
@@ -0,0 +1,143 @@
+package rpmmd
+
+import (
+	"strings"
+	"unicode"
+)
+
+// CompareEVR compares two [epoch:]version[-release] strings the way RPM does, returning -1, 0 or 1 when a is older,
+// equal or newer than b. The releases are only compared when both are set.
+func CompareEVR(a string, b string) int {
+	epochA, versionA, releaseA := parseEVR(a)
+	epochB, versionB, releaseB := parseEVR(b)
+	if result := rpmvercmp(epochA, epochB); result != 0 {
+		return result
+	}
+	if result := rpmvercmp(versionA, versionB); result != 0 {
+		return result
+	}
+	if releaseA == "" || releaseB == "" {
+		return 0
+	}
+	return rpmvercmp(releaseA, releaseB)
+}
+
+func parseEVR(evr string) (string, string, string) {
+	epoch := "0"
+	if i := strings.Index(evr, ":"); i >= 0 {
+		if i > 0 {
+			epoch = evr[:i]
+		}
+		evr = evr[i+1:]
+	}
+	version, release := evr, ""
+	if i := strings.LastIndex(evr, "-"); i >= 0 {
+		version, release = evr[:i], evr[i+1:]
+	}
+	return epoch, version, release
+}
+
+// rpmvercmp compares two version strings segment by segment: numeric segments are compared as numbers and are newer
+// than alphabetic ones, a tilde sorts before anything and a caret after the end of the string
+func rpmvercmp(a string, b string) int {
+	if a == b {
+		return 0
+	}
+
+	for len(a) > 0 || len(b) > 0 {
+		a = strings.TrimLeftFunc(a, isSeparator)
+		b = strings.TrimLeftFunc(b, isSeparator)
+
+		if strings.HasPrefix(a, "~") || strings.HasPrefix(b, "~") {
+			if !strings.HasPrefix(a, "~") {
+				return 1
+			}
+			if !strings.HasPrefix(b, "~") {
+				return -1
+			}
+			a, b = a[1:], b[1:]
+			continue
+		}
+
+		if strings.HasPrefix(a, "^") || strings.HasPrefix(b, "^") {
+			if a == "" {
+				return -1
+			}
+			if b == "" {
+				return 1
+			}
+			if !strings.HasPrefix(a, "^") {
+				return 1
+			}
+			if !strings.HasPrefix(b, "^") {
+				return -1
+			}
+			a, b = a[1:], b[1:]
+			continue
+		}
+
+		if a == "" || b == "" {
+			break
+		}
+
+		var segmentA, segmentB string
+		numeric := isDigit(rune(a[0]))
+		if numeric {
+			segmentA, a = splitSegment(a, isDigit)
+			segmentB, b = splitSegment(b, isDigit)
+		} else {
+			segmentA, a = splitSegment(a, isLetter)
+			segmentB, b = splitSegment(b, isLetter)
+		}
+
+		// segments of different types: the numeric one is newer
+		if segmentB == "" {
+			if numeric {
+				return 1
+			}
+			return -1
+		}
+
+		if numeric {
+			segmentA = strings.TrimLeft(segmentA, "0")
+			segmentB = strings.TrimLeft(segmentB, "0")
+			if len(segmentA) != len(segmentB) {
+				if len(segmentA) > len(segmentB) {
+					return 1
+				}
+				return -1
+			}
+		}
+		if result := strings.Compare(segmentA, segmentB); result != 0 {
+			return result
+		}
+	}
+
+	if a == "" && b == "" {
+		return 0
+	}
+	if a == "" {
+		return -1
+	}
+	return 1
+}
+
+func isSeparator(r rune) bool {
+	return !isDigit(r) && !isLetter(r) && r != '~' && r != '^'
+}
+
+func isDigit(r rune) bool {
+	return r >= '0' && r <= '9'
+}
+
+func isLetter(r rune) bool {
+	return r < unicode.MaxASCII && unicode.IsLetter(r)
+}
+
+func splitSegment(s string, belongs func(rune) bool) (string, string) {
+	i := strings.IndexFunc(s, func(r rune) bool { return !belongs(r) })
+	if i < 0 {
+		return s, ""
+	}
+	return s[:i], s[i:]
+}
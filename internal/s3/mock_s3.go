@@ -0,0 +1,64 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/project-flotta/osbuild-operator/internal/s3 (interfaces: ObjectStore)
+
+// Package s3 is a generated GoMock package.
+package s3
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockObjectStore is a mock of ObjectStore interface.
+type MockObjectStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockObjectStoreMockRecorder
+}
+
+// MockObjectStoreMockRecorder is the mock recorder for MockObjectStore.
+type MockObjectStoreMockRecorder struct {
+	mock *MockObjectStore
+}
+
+// NewMockObjectStore creates a new mock instance.
+func NewMockObjectStore(ctrl *gomock.Controller) *MockObjectStore {
+	mock := &MockObjectStore{ctrl: ctrl}
+	mock.recorder = &MockObjectStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockObjectStore) EXPECT() *MockObjectStoreMockRecorder {
+	return m.recorder
+}
+
+// CopyObject mocks base method.
+func (m *MockObjectStore) CopyObject(arg0 context.Context, arg1, arg2 string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CopyObject", arg0, arg1, arg2)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CopyObject indicates an expected call of CopyObject.
+func (mr *MockObjectStoreMockRecorder) CopyObject(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CopyObject", reflect.TypeOf((*MockObjectStore)(nil).CopyObject), arg0, arg1, arg2)
+}
+
+// RemoveObject mocks base method.
+func (m *MockObjectStore) RemoveObject(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveObject", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveObject indicates an expected call of RemoveObject.
+func (mr *MockObjectStoreMockRecorder) RemoveObject(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveObject", reflect.TypeOf((*MockObjectStore)(nil).RemoveObject), arg0, arg1)
+}
@@ -0,0 +1,184 @@
+package s3
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	awss3 "github.com/aws/aws-sdk-go/service/s3"
+	_ "github.com/golang/mock/mockgen/model"
+
+	"github.com/project-flotta/osbuild-operator/api/v1alpha1"
+	"github.com/project-flotta/osbuild-operator/internal/conf"
+	"github.com/project-flotta/osbuild-operator/internal/repository/osbuildenvconfig"
+	"github.com/project-flotta/osbuild-operator/internal/repository/secret"
+)
+
+const (
+	accessKeyIDKey     = "access-key-id"
+	secretAccessKeyKey = "secret-access-key" // #nosec G101
+	caBundleKey        = "ca-bundle"
+)
+
+//go:generate mockgen -package=s3 -destination=mock_s3.go . ObjectStore
+
+// ObjectStore manages the objects uploaded by the builds to the S3 service of the OSBuildEnvConfig
+type ObjectStore interface {
+	// RemoveObject removes the object the URL, e.g. a presigned URL returned by composer, points at
+	RemoveObject(ctx context.Context, objectUrl string) error
+	// CopyObject copies the object the URL points at to the key in the same bucket and returns the s3:// URL of the copy
+	CopyObject(ctx context.Context, sourceUrl string, destinationKey string) (string, error)
+}
+
+type EnvConfigObjectStore struct {
+	osBuildEnvConfigRepository osbuildenvconfig.Repository
+	secretRepository           secret.Repository
+}
+
+func NewEnvConfigObjectStore(osBuildEnvConfigRepository osbuildenvconfig.Repository, secretRepository secret.Repository) *EnvConfigObjectStore {
+	return &EnvConfigObjectStore{
+		osBuildEnvConfigRepository: osBuildEnvConfigRepository,
+		secretRepository:           secretRepository,
+	}
+}
+
+func (r *EnvConfigObjectStore) RemoveObject(ctx context.Context, objectUrl string) error {
+	client, bucket, err := r.getClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	key, err := GetObjectKey(objectUrl, bucket)
+	if err != nil {
+		return err
+	}
+
+	_, err = client.DeleteObjectWithContext(ctx, &awss3.DeleteObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	return err
+}
+
+func (r *EnvConfigObjectStore) CopyObject(ctx context.Context, sourceUrl string, destinationKey string) (string, error) {
+	client, bucket, err := r.getClient(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	sourceKey, err := GetObjectKey(sourceUrl, bucket)
+	if err != nil {
+		return "", err
+	}
+
+	destinationKey = strings.TrimPrefix(destinationKey, "/")
+	_, err = client.CopyObjectWithContext(ctx, &awss3.CopyObjectInput{
+		Bucket:     aws.String(bucket),
+		CopySource: aws.String(url.PathEscape(bucket + "/" + sourceKey)),
+		Key:        aws.String(destinationKey),
+	})
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("s3://%s/%s", bucket, destinationKey), nil
+}
+
+// getClient returns a client of the S3 service of the OSBuildEnvConfig and the bucket the builds are uploaded to
+func (r *EnvConfigObjectStore) getClient(ctx context.Context) (*awss3.S3, string, error) {
+	osBuildEnvConfigs, err := r.osBuildEnvConfigRepository.List(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(osBuildEnvConfigs) == 0 {
+		return nil, "", fmt.Errorf("no OSBuildEnvConfig defines the S3 service")
+	}
+	s3ServiceConfig := osBuildEnvConfigs[0].Spec.S3Service
+
+	var awsConfig *v1alpha1.AWSS3ServiceConfig
+	if s3ServiceConfig.AWS != nil {
+		awsConfig = s3ServiceConfig.AWS
+	} else if s3ServiceConfig.GenericS3 != nil && s3ServiceConfig.GenericS3.AWSS3ServiceConfig != nil {
+		awsConfig = s3ServiceConfig.GenericS3.AWSS3ServiceConfig
+	} else {
+		return nil, "", fmt.Errorf("the S3 service is not configured")
+	}
+
+	sess, err := r.getSession(ctx, awsConfig, s3ServiceConfig.GenericS3)
+	if err != nil {
+		return nil, "", err
+	}
+	return awss3.New(sess), awsConfig.Bucket, nil
+}
+
+func (r *EnvConfigObjectStore) getSession(ctx context.Context, awsConfig *v1alpha1.AWSS3ServiceConfig, genericS3Config *v1alpha1.GenericS3ServiceConfig) (*session.Session, error) {
+	credsSecret, err := r.secretRepository.Read(ctx, awsConfig.CredsSecretReference.Name, conf.GlobalConf.WorkingNamespace)
+	if err != nil {
+		return nil, err
+	}
+
+	config := &aws.Config{
+		Region:           aws.String(awsConfig.Region),
+		S3ForcePathStyle: aws.Bool(true),
+		Credentials: credentials.NewStaticCredentials(
+			string(credsSecret.Data[accessKeyIDKey]),
+			string(credsSecret.Data[secretAccessKeyKey]),
+			"",
+		),
+	}
+
+	if genericS3Config != nil {
+		config.Endpoint = aws.String(genericS3Config.Endpoint)
+
+		tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+		if genericS3Config.SkipSSLVerification != nil && *genericS3Config.SkipSSLVerification {
+			tlsConfig.InsecureSkipVerify = true // #nosec G402
+		}
+		if genericS3Config.CABundleSecretReference != nil {
+			caBundleSecret, err := r.secretRepository.Read(ctx, genericS3Config.CABundleSecretReference.Name, conf.GlobalConf.WorkingNamespace)
+			if err != nil {
+				return nil, err
+			}
+			certPool := x509.NewCertPool()
+			if !certPool.AppendCertsFromPEM(caBundleSecret.Data[caBundleKey]) {
+				return nil, fmt.Errorf("failed to parse the CA bundle of the S3 service")
+			}
+			tlsConfig.RootCAs = certPool
+		}
+
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = tlsConfig
+		config.HTTPClient = &http.Client{Transport: transport}
+	}
+
+	return session.NewSession(config)
+}
+
+// GetObjectKey returns the key of the object in the bucket, for both path-style and virtual-hosted-style URLs
+func GetObjectKey(objectUrl string, bucket string) (string, error) {
+	u, err := url.Parse(objectUrl)
+	if err != nil {
+		return "", err
+	}
+
+	key := strings.TrimPrefix(u.Path, "/")
+	if u.Scheme == "s3" || strings.HasPrefix(u.Host, bucket+".") {
+		// s3://<bucket>/<key> or https://<bucket>.<endpoint>/<key>
+		if key == "" {
+			return "", fmt.Errorf("no object key in %s", objectUrl)
+		}
+		return key, nil
+	}
+
+	key = strings.TrimPrefix(key, bucket+"/")
+	if key == "" || key == strings.TrimPrefix(u.Path, "/") {
+		return "", fmt.Errorf("the url %s does not point at an object of bucket %s", objectUrl, bucket)
+	}
+	return key, nil
+}
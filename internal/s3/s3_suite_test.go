@@ -0,0 +1,13 @@
+package s3_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestS3(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "S3 Spec")
+}
@@ -0,0 +1,39 @@
+package s3_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/project-flotta/osbuild-operator/internal/s3"
+)
+
+var _ = Describe("S3", func() {
+	const bucket = "images"
+
+	DescribeTable("should get the object key", func(objectUrl string, expectedKey string) {
+		// when
+		key, err := s3.GetObjectKey(objectUrl, bucket)
+
+		// then
+		Expect(err).NotTo(HaveOccurred())
+		Expect(key).To(Equal(expectedKey))
+	},
+		Entry("path-style presigned URL", "https://minio.example.com/images/composer-api-1234-installer.iso?X-Amz-Signature=abc",
+			"composer-api-1234-installer.iso"),
+		Entry("virtual-hosted-style presigned URL", "https://images.s3.us-east-1.amazonaws.com/dir/installer.iso?X-Amz-Signature=abc",
+			"dir/installer.iso"),
+		Entry("S3 URI", "s3://images/ns_build-1_uid.iso", "ns_build-1_uid.iso"),
+	)
+
+	DescribeTable("should fail getting the object key", func(objectUrl string) {
+		// when
+		_, err := s3.GetObjectKey(objectUrl, bucket)
+
+		// then
+		Expect(err).To(HaveOccurred())
+	},
+		Entry("another bucket", "https://minio.example.com/other/installer.iso"),
+		Entry("no key", "https://images.s3.amazonaws.com/"),
+		Entry("invalid URL", "://images"),
+	)
+})
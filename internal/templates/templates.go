@@ -12,12 +12,25 @@ import (
 	"github.com/project-flotta/osbuild-operator/internal/conf"
 )
 
+const (
+	// OSTreeRepoUrlValue is the built-in template value holding the URL of the OSTree repository of the OSBuildConfig
+	OSTreeRepoUrlValue = "OSTreeRepoUrl"
+	// OSTreeGPGKeyUrlValue is the built-in template value holding the URL of the public GPG key of that repository
+	OSTreeGPGKeyUrlValue = "OSTreeGPGKeyUrl"
+)
+
+// ProcessOSBuildConfigTemplate renders the template with the built-in values and the parameters, parameters taking
+// precedence over built-in values of the same name
 func ProcessOSBuildConfigTemplate(textTemplate string, expectedParameters []osbuilderprojectflottaiov1alpha1.Parameter,
-	values []osbuilderprojectflottaiov1alpha1.ParameterValue) (string, error) {
+	values []osbuilderprojectflottaiov1alpha1.ParameterValue, builtInValues map[string]string) (string, error) {
 
 	keyTypes := make(map[string]string)
 	keyValues := make(map[string]string)
 
+	for name, value := range builtInValues {
+		keyValues[name] = value
+	}
+
 	for _, p := range expectedParameters {
 		keyTypes[p.Name] = p.Type
 		keyValues[p.Name] = p.DefaultValue
@@ -44,6 +57,26 @@ func ProcessOSBuildConfigTemplate(textTemplate string, expectedParameters []osbu
 	return buf.String(), nil
 }
 
+// ValidateParameterValues checks that the values are given for parameters of the template and can be represented as
+// their types
+func ValidateParameterValues(expectedParameters []osbuilderprojectflottaiov1alpha1.Parameter, values []osbuilderprojectflottaiov1alpha1.ParameterValue) error {
+	keyTypes := make(map[string]string)
+	for _, p := range expectedParameters {
+		keyTypes[p.Name] = p.Type
+	}
+
+	for _, v := range values {
+		kType, ok := keyTypes[v.Name]
+		if !ok {
+			return fmt.Errorf("parameter %s is not a parameter of the template", v.Name)
+		}
+		if !validateParameter(v.Value, kType) {
+			return fmt.Errorf("parameter %s of type %s was given %s value, which can't be represented as %[2]s", v.Name, kType, v.Value)
+		}
+	}
+	return nil
+}
+
 func validateParameter(value, pType string) bool {
 	switch pType {
 	case "bool":
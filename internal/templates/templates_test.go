@@ -12,7 +12,7 @@ var _ = Describe("Templates", func() {
 	DescribeTable("should replace parameters", func(template string, expectedParameters []api.Parameter,
 		values []api.ParameterValue, expectedResult string) {
 		// when
-		result, err := templates.ProcessOSBuildConfigTemplate(template, expectedParameters, values)
+		result, err := templates.ProcessOSBuildConfigTemplate(template, expectedParameters, values, nil)
 
 		// then
 		Expect(err).NotTo(HaveOccurred())
@@ -76,10 +76,37 @@ var _ = Describe("Templates", func() {
 			"String <no value>"),
 	)
 
+	DescribeTable("should replace built-in values", func(template string, expectedParameters []api.Parameter,
+		values []api.ParameterValue, expectedResult string) {
+		// given
+		builtInValues := map[string]string{
+			templates.OSTreeRepoUrlValue:   "https://repo.example.com/",
+			templates.OSTreeGPGKeyUrlValue: "https://repo.example.com/key.gpg",
+		}
+
+		// when
+		result, err := templates.ProcessOSBuildConfigTemplate(template, expectedParameters, values, builtInValues)
+
+		// then
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).To(Equal(expectedResult))
+	},
+		Entry("Built-in values", "ostreesetup --url={{.OSTreeRepoUrl}}; curl {{.OSTreeGPGKeyUrl}}", nil, nil,
+			"ostreesetup --url=https://repo.example.com/; curl https://repo.example.com/key.gpg"),
+		Entry("Parameter overriding a built-in value", "ostreesetup --url={{.OSTreeRepoUrl}}",
+			[]api.Parameter{
+				{Name: "OSTreeRepoUrl", DefaultValue: "", Type: "string"},
+			},
+			[]api.ParameterValue{
+				{Name: "OSTreeRepoUrl", Value: "https://mirror.example.com/"},
+			},
+			"ostreesetup --url=https://mirror.example.com/"),
+	)
+
 	DescribeTable("should fail processing template", func(template string, expectedParameters []api.Parameter,
 		values []api.ParameterValue) {
 		// when
-		_, err := templates.ProcessOSBuildConfigTemplate(template, expectedParameters, values)
+		_, err := templates.ProcessOSBuildConfigTemplate(template, expectedParameters, values, nil)
 
 		// then
 		Expect(err).To(HaveOccurred())
@@ -96,4 +123,33 @@ var _ = Describe("Templates", func() {
 		),
 		Entry("Invalid template", "{{range .}}{{else}}{{continue}}{{end}}", nil, nil),
 	)
+
+	DescribeTable("should validate parameter values", func(values []api.ParameterValue, valid bool) {
+		// given
+		expectedParameters := []api.Parameter{
+			{Name: "version", DefaultValue: "", Type: "string"},
+			{Name: "replicas", DefaultValue: "1", Type: "int"},
+			{Name: "debug", DefaultValue: "false", Type: "bool"},
+		}
+
+		// when
+		err := templates.ValidateParameterValues(expectedParameters, values)
+
+		// then
+		if valid {
+			Expect(err).NotTo(HaveOccurred())
+		} else {
+			Expect(err).To(HaveOccurred())
+		}
+	},
+		Entry("No values", nil, true),
+		Entry("Values of the parameter types", []api.ParameterValue{
+			{Name: "version", Value: "1.2.3"},
+			{Name: "replicas", Value: "3"},
+			{Name: "debug", Value: "true"},
+		}, true),
+		Entry("Int parameter given a string", []api.ParameterValue{{Name: "replicas", Value: "three"}}, false),
+		Entry("Bool parameter given a string", []api.ParameterValue{{Name: "debug", Value: "yes please"}}, false),
+		Entry("Unknown parameter", []api.ParameterValue{{Name: "unknown", Value: "1"}}, false),
+	)
 })
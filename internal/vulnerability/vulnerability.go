@@ -0,0 +1,282 @@
+package vulnerability
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/project-flotta/osbuild-operator/api/v1alpha1"
+	"github.com/project-flotta/osbuild-operator/internal/composer"
+	"github.com/project-flotta/osbuild-operator/internal/repository/configmap"
+	"github.com/project-flotta/osbuild-operator/internal/rpmmd"
+)
+
+const (
+	gzipSuffix = ".gz"
+
+	eventIntroduced   = "introduced"
+	eventFixed        = "fixed"
+	eventLastAffected = "last_affected"
+	rangeTypeGit      = "GIT"
+
+	// SeverityUnknown is the severity of the vulnerabilities whose entry has no severity
+	SeverityUnknown v1alpha1.VulnerabilitySeverity = "Unknown"
+)
+
+var (
+	// severityRanks orders the severities, the higher the more severe
+	severityRanks = map[v1alpha1.VulnerabilitySeverity]int{
+		SeverityUnknown:                        0,
+		v1alpha1.VulnerabilitySeverityLow:      1,
+		v1alpha1.VulnerabilitySeverityMedium:   2,
+		v1alpha1.VulnerabilitySeverityHigh:     3,
+		v1alpha1.VulnerabilitySeverityCritical: 4,
+	}
+
+	// severityNames maps the severities used by the feeds to the ones of the API
+	severityNames = map[string]v1alpha1.VulnerabilitySeverity{
+		"critical":  v1alpha1.VulnerabilitySeverityCritical,
+		"important": v1alpha1.VulnerabilitySeverityHigh,
+		"high":      v1alpha1.VulnerabilitySeverityHigh,
+		"moderate":  v1alpha1.VulnerabilitySeverityMedium,
+		"medium":    v1alpha1.VulnerabilitySeverityMedium,
+		"low":       v1alpha1.VulnerabilitySeverityLow,
+	}
+)
+
+// Entry is an OSV entry of the feed, limited to the fields used to match the packages
+type Entry struct {
+	ID               string           `json:"id"`
+	Affected         []Affected       `json:"affected"`
+	DatabaseSpecific *SpecificSection `json:"database_specific,omitempty"`
+}
+
+type Affected struct {
+	Package           AffectedPackage  `json:"package"`
+	Ranges            []Range          `json:"ranges,omitempty"`
+	Versions          []string         `json:"versions,omitempty"`
+	EcosystemSpecific *SpecificSection `json:"ecosystem_specific,omitempty"`
+	DatabaseSpecific  *SpecificSection `json:"database_specific,omitempty"`
+}
+
+type AffectedPackage struct {
+	Name string `json:"name"`
+}
+
+type Range struct {
+	Type   string              `json:"type"`
+	Events []map[string]string `json:"events"`
+}
+
+// SpecificSection holds the severity set by the ecosystems and the databases, e.g. Critical or Important
+type SpecificSection struct {
+	Severity string `json:"severity,omitempty"`
+}
+
+// Finding is a vulnerability of a package of the build
+type Finding struct {
+	ID       string
+	Package  string
+	Version  string
+	Severity v1alpha1.VulnerabilitySeverity
+}
+
+// ParseFeed parses a JSON list of OSV entries, or an object listing them under vulns like the OSV API does. The feed
+// is decompressed first when its name ends with .gz
+func ParseFeed(name string, data []byte) ([]Entry, error) {
+	if strings.HasSuffix(name, gzipSuffix) {
+		reader, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		data, err = io.ReadAll(reader)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	data = bytes.TrimSpace(data)
+	if bytes.HasPrefix(data, []byte("{")) {
+		var response struct {
+			Vulns []Entry `json:"vulns"`
+		}
+		err := json.Unmarshal(data, &response)
+		return response.Vulns, err
+	}
+
+	var entries []Entry
+	err := json.Unmarshal(data, &entries)
+	return entries, err
+}
+
+// Scan returns the vulnerabilities of the packages, matched by name and [epoch:]version-release. The ecosystems of
+// the entries are not checked, the feed is expected to cover the distribution of the builds
+func Scan(entries []Entry, packages []composer.PackageMetadata) []Finding {
+	versions := map[string][]string{}
+	for _, pkg := range packages {
+		versions[pkg.Name] = append(versions[pkg.Name], getEVR(pkg))
+	}
+
+	var findings []Finding
+	for _, entry := range entries {
+		for _, affected := range entry.Affected {
+			for _, version := range versions[affected.Package.Name] {
+				if isAffected(affected, version) {
+					findings = append(findings, Finding{
+						ID:       entry.ID,
+						Package:  affected.Package.Name,
+						Version:  version,
+						Severity: getSeverity(entry, affected),
+					})
+				}
+			}
+		}
+	}
+	return findings
+}
+
+// Summarize counts the vulnerabilities by severity, each vulnerability being counted once whatever the number of
+// packages it affects, and lists the ones the policy applies to
+func Summarize(findings []Finding, policy *v1alpha1.VulnerabilityPolicy) *v1alpha1.VulnerabilitySummary {
+	severities := map[string]v1alpha1.VulnerabilitySeverity{}
+	for _, finding := range findings {
+		if severity, ok := severities[finding.ID]; !ok || severityRanks[finding.Severity] > severityRanks[severity] {
+			severities[finding.ID] = finding.Severity
+		}
+	}
+
+	summary := &v1alpha1.VulnerabilitySummary{}
+	for id, severity := range severities {
+		switch severity {
+		case v1alpha1.VulnerabilitySeverityCritical:
+			summary.Critical++
+		case v1alpha1.VulnerabilitySeverityHigh:
+			summary.High++
+		case v1alpha1.VulnerabilitySeverityMedium:
+			summary.Medium++
+		case v1alpha1.VulnerabilitySeverityLow:
+			summary.Low++
+		default:
+			summary.Unknown++
+		}
+
+		if policy != nil && severityRanks[severity] >= severityRanks[GetPolicySeverity(policy)] {
+			summary.PolicyViolations = append(summary.PolicyViolations, id)
+		}
+	}
+	sort.Strings(summary.PolicyViolations)
+	return summary
+}
+
+// GetPolicySeverity returns the lowest severity the policy applies to
+func GetPolicySeverity(policy *v1alpha1.VulnerabilityPolicy) v1alpha1.VulnerabilitySeverity {
+	if policy.Severity == "" {
+		return v1alpha1.VulnerabilitySeverityCritical
+	}
+	return policy.Severity
+}
+
+// GetPolicyAction returns what happens to the builds violating the policy
+func GetPolicyAction(policy *v1alpha1.VulnerabilityPolicy) v1alpha1.VulnerabilityPolicyAction {
+	if policy.Action == "" {
+		return v1alpha1.VulnerabilityPolicyBlockRelease
+	}
+	return policy.Action
+}
+
+// IsPolicyViolated returns whether the policy of the scan applies to the build, a build that was not or could not be
+// scanned violating any policy
+func IsPolicyViolated(scan *v1alpha1.VulnerabilityScan, summary *v1alpha1.VulnerabilitySummary) bool {
+	if scan == nil || scan.Policy == nil {
+		return false
+	}
+	return summary == nil || summary.Error != "" || len(summary.PolicyViolations) > 0
+}
+
+// LoadFeed reads the feed from the key of the ConfigMap in the namespace, or from the file under the feeds directory
+func LoadFeed(ctx context.Context, configMapRepository configmap.Repository, namespace string, feed v1alpha1.VulnerabilityFeed, feedsDir string) ([]Entry, error) {
+	if (feed.ConfigMapKeyRef == nil) == (feed.Path == nil) {
+		return nil, fmt.Errorf("exactly one of configMapKeyRef and path must be set in the vulnerability feed")
+	}
+
+	if feed.Path != nil {
+		// the path is cleaned as an absolute one so that it cannot escape the feeds directory
+		feedPath := filepath.Join(feedsDir, filepath.Clean("/"+*feed.Path))
+		data, err := os.ReadFile(feedPath)
+		if err != nil {
+			return nil, err
+		}
+		return ParseFeed(feedPath, data)
+	}
+
+	configMap, err := configMapRepository.Read(ctx, feed.ConfigMapKeyRef.Name, namespace)
+	if err != nil {
+		return nil, err
+	}
+	key := feed.ConfigMapKeyRef.Key
+	if data, ok := configMap.Data[key]; ok {
+		return ParseFeed(key, []byte(data))
+	}
+	if data, ok := configMap.BinaryData[key]; ok {
+		return ParseFeed(key, data)
+	}
+	return nil, fmt.Errorf("key %s not found in ConfigMap %s", key, feed.ConfigMapKeyRef.Name)
+}
+
+func getEVR(pkg composer.PackageMetadata) string {
+	evr := fmt.Sprintf("%s-%s", pkg.Version, pkg.Release)
+	if pkg.Epoch != nil && *pkg.Epoch != "" && *pkg.Epoch != "0" {
+		evr = fmt.Sprintf("%s:%s", *pkg.Epoch, evr)
+	}
+	return evr
+}
+
+// isAffected evaluates the affected versions and the ranges of an entry, whose events are expected to be sorted
+func isAffected(affected Affected, version string) bool {
+	for _, affectedVersion := range affected.Versions {
+		if rpmmd.CompareEVR(version, affectedVersion) == 0 {
+			return true
+		}
+	}
+
+	for _, affectedRange := range affected.Ranges {
+		if affectedRange.Type == rangeTypeGit {
+			continue
+		}
+		vulnerable := false
+		for _, event := range affectedRange.Events {
+			if introduced, ok := event[eventIntroduced]; ok && (introduced == "0" || rpmmd.CompareEVR(version, introduced) >= 0) {
+				vulnerable = true
+			}
+			if fixed, ok := event[eventFixed]; ok && rpmmd.CompareEVR(version, fixed) >= 0 {
+				vulnerable = false
+			}
+			if lastAffected, ok := event[eventLastAffected]; ok && rpmmd.CompareEVR(version, lastAffected) > 0 {
+				vulnerable = false
+			}
+		}
+		if vulnerable {
+			return true
+		}
+	}
+	return false
+}
+
+func getSeverity(entry Entry, affected Affected) v1alpha1.VulnerabilitySeverity {
+	for _, section := range []*SpecificSection{affected.EcosystemSpecific, affected.DatabaseSpecific, entry.DatabaseSpecific} {
+		if section == nil {
+			continue
+		}
+		if severity, ok := severityNames[strings.ToLower(section.Severity)]; ok {
+			return severity
+		}
+	}
+	return SeverityUnknown
+}
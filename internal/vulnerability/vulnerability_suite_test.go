@@ -0,0 +1,13 @@
+package vulnerability_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestVulnerability(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Vulnerability Spec")
+}
@@ -0,0 +1,209 @@
+package vulnerability_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"os"
+	"path/filepath"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/project-flotta/osbuild-operator/api/v1alpha1"
+	"github.com/project-flotta/osbuild-operator/internal/composer"
+	"github.com/project-flotta/osbuild-operator/internal/repository/configmap"
+	"github.com/project-flotta/osbuild-operator/internal/vulnerability"
+)
+
+const feed = `[
+  {
+    "id": "CVE-2022-0001",
+    "affected": [{
+      "package": {"ecosystem": "Rocky Linux:9", "name": "openssl"},
+      "ranges": [{"type": "ECOSYSTEM", "events": [{"introduced": "0"}, {"fixed": "1:3.0.1-41.el9"}]}],
+      "ecosystem_specific": {"severity": "Important"}
+    }]
+  },
+  {
+    "id": "CVE-2022-0002",
+    "affected": [
+      {
+        "package": {"name": "bash"},
+        "ranges": [{"type": "ECOSYSTEM", "events": [{"introduced": "5.1"}, {"last_affected": "5.1.8-4.el9"}]}]
+      },
+      {
+        "package": {"name": "openssl"},
+        "versions": ["1:3.0.1-40.el9"]
+      }
+    ],
+    "database_specific": {"severity": "CRITICAL"}
+  },
+  {
+    "id": "CVE-2022-0003",
+    "affected": [{
+      "package": {"name": "bash"},
+      "ranges": [{"type": "ECOSYSTEM", "events": [{"introduced": "0"}, {"fixed": "5.1.8-2.el9"}]}]
+    }]
+  },
+  {
+    "id": "CVE-2022-0004",
+    "affected": [{
+      "package": {"name": "vim-minimal"},
+      "ranges": [{"type": "ECOSYSTEM", "events": [{"introduced": "0"}]}]
+    }]
+  }
+]`
+
+var _ = Describe("Vulnerability", func() {
+	var (
+		epoch    = "1"
+		packages = []composer.PackageMetadata{
+			{Name: "openssl", Epoch: &epoch, Version: "3.0.1", Release: "40.el9", Arch: "x86_64"},
+			{Name: "bash", Version: "5.1.8", Release: "4.el9", Arch: "x86_64"},
+		}
+	)
+
+	It("should match the packages against the ranges and versions of the entries", func() {
+		// given
+		entries, err := vulnerability.ParseFeed("feed.json", []byte(feed))
+		Expect(err).NotTo(HaveOccurred())
+
+		// when
+		findings := vulnerability.Scan(entries, packages)
+
+		// then
+		Expect(findings).To(ConsistOf(
+			vulnerability.Finding{ID: "CVE-2022-0001", Package: "openssl", Version: "1:3.0.1-40.el9", Severity: v1alpha1.VulnerabilitySeverityHigh},
+			vulnerability.Finding{ID: "CVE-2022-0002", Package: "bash", Version: "5.1.8-4.el9", Severity: v1alpha1.VulnerabilitySeverityCritical},
+			vulnerability.Finding{ID: "CVE-2022-0002", Package: "openssl", Version: "1:3.0.1-40.el9", Severity: v1alpha1.VulnerabilitySeverityCritical},
+		))
+	})
+
+	It("should parse the feeds returned by the OSV API and gzip-compressed ones", func() {
+		// given
+		var compressed bytes.Buffer
+		writer := gzip.NewWriter(&compressed)
+		_, err := writer.Write([]byte(`{"vulns": ` + feed + `}`))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(writer.Close()).To(Succeed())
+
+		// when
+		entries, err := vulnerability.ParseFeed("feed.json.gz", compressed.Bytes())
+
+		// then
+		Expect(err).NotTo(HaveOccurred())
+		Expect(entries).To(HaveLen(4))
+	})
+
+	DescribeTable("should summarize the vulnerabilities", func(policy *v1alpha1.VulnerabilityPolicy, violations []string) {
+		// given
+		findings := []vulnerability.Finding{
+			{ID: "CVE-1", Package: "a", Severity: v1alpha1.VulnerabilitySeverityCritical},
+			{ID: "CVE-1", Package: "b", Severity: v1alpha1.VulnerabilitySeverityCritical},
+			{ID: "CVE-2", Package: "a", Severity: v1alpha1.VulnerabilitySeverityHigh},
+			{ID: "CVE-3", Package: "a", Severity: vulnerability.SeverityUnknown},
+		}
+
+		// when
+		summary := vulnerability.Summarize(findings, policy)
+
+		// then
+		Expect(summary.Critical).To(Equal(1))
+		Expect(summary.High).To(Equal(1))
+		Expect(summary.Unknown).To(Equal(1))
+		Expect(summary.PolicyViolations).To(Equal(violations))
+	},
+		Entry("without a policy", nil, nil),
+		Entry("with the default severity", &v1alpha1.VulnerabilityPolicy{}, []string{"CVE-1"}),
+		Entry("with the High severity", &v1alpha1.VulnerabilityPolicy{Severity: v1alpha1.VulnerabilitySeverityHigh}, []string{"CVE-1", "CVE-2"}),
+	)
+
+	DescribeTable("should tell whether the policy is violated", func(scan *v1alpha1.VulnerabilityScan, summary *v1alpha1.VulnerabilitySummary, violated bool) {
+		Expect(vulnerability.IsPolicyViolated(scan, summary)).To(Equal(violated))
+	},
+		Entry("no scan", nil, nil, false),
+		Entry("no policy", &v1alpha1.VulnerabilityScan{}, &v1alpha1.VulnerabilitySummary{PolicyViolations: []string{"CVE-1"}}, false),
+		Entry("no violation", &v1alpha1.VulnerabilityScan{Policy: &v1alpha1.VulnerabilityPolicy{}}, &v1alpha1.VulnerabilitySummary{Critical: 0}, false),
+		Entry("violations", &v1alpha1.VulnerabilityScan{Policy: &v1alpha1.VulnerabilityPolicy{}}, &v1alpha1.VulnerabilitySummary{PolicyViolations: []string{"CVE-1"}}, true),
+		Entry("scan error", &v1alpha1.VulnerabilityScan{Policy: &v1alpha1.VulnerabilityPolicy{}}, &v1alpha1.VulnerabilitySummary{Error: "failed"}, true),
+		Entry("not scanned", &v1alpha1.VulnerabilityScan{Policy: &v1alpha1.VulnerabilityPolicy{}}, nil, true),
+	)
+
+	Context("Loading the feed", func() {
+		var (
+			ctx                 = context.Background()
+			mockCtrl            *gomock.Controller
+			configMapRepository *configmap.MockRepository
+		)
+
+		BeforeEach(func() {
+			mockCtrl = gomock.NewController(GinkgoT())
+			configMapRepository = configmap.NewMockRepository(mockCtrl)
+		})
+
+		AfterEach(func() {
+			mockCtrl.Finish()
+		})
+
+		It("should read the feed from a ConfigMap", func() {
+			// given
+			configMapRepository.EXPECT().Read(ctx, "feeds", "ns").Return(&corev1.ConfigMap{Data: map[string]string{"osv.json": feed}}, nil)
+			feedRef := v1alpha1.VulnerabilityFeed{ConfigMapKeyRef: &corev1.ConfigMapKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "feeds"},
+				Key:                  "osv.json",
+			}}
+
+			// when
+			entries, err := vulnerability.LoadFeed(ctx, configMapRepository, "ns", feedRef, "")
+
+			// then
+			Expect(err).NotTo(HaveOccurred())
+			Expect(entries).To(HaveLen(4))
+		})
+
+		It("should fail when the key is missing from the ConfigMap", func() {
+			// given
+			configMapRepository.EXPECT().Read(ctx, "feeds", "ns").Return(&corev1.ConfigMap{}, nil)
+			feedRef := v1alpha1.VulnerabilityFeed{ConfigMapKeyRef: &corev1.ConfigMapKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "feeds"},
+				Key:                  "osv.json",
+			}}
+
+			// when
+			_, err := vulnerability.LoadFeed(ctx, configMapRepository, "ns", feedRef, "")
+
+			// then
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("should read the feed from the feeds directory only", func() {
+			// given
+			feedsDir := GinkgoT().TempDir()
+			Expect(os.MkdirAll(filepath.Join(feedsDir, "rocky"), 0755)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(feedsDir, "rocky", "osv.json"), []byte(feed), 0600)).To(Succeed())
+			feedPath := "../../rocky/osv.json"
+
+			// when
+			entries, err := vulnerability.LoadFeed(ctx, configMapRepository, "ns", v1alpha1.VulnerabilityFeed{Path: &feedPath}, feedsDir)
+
+			// then
+			Expect(err).NotTo(HaveOccurred())
+			Expect(entries).To(HaveLen(4))
+		})
+
+		It("should fail when both the ConfigMap and the path are set", func() {
+			// given
+			feedPath := "osv.json"
+			feedRef := v1alpha1.VulnerabilityFeed{Path: &feedPath, ConfigMapKeyRef: &corev1.ConfigMapKeySelector{Key: "osv.json"}}
+
+			// when
+			_, err := vulnerability.LoadFeed(ctx, configMapRepository, "ns", feedRef, "")
+
+			// then
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
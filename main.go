@@ -55,10 +55,16 @@ import (
 	"github.com/project-flotta/osbuild-operator/internal/repository/osbuildconfig"
 	"github.com/project-flotta/osbuild-operator/internal/repository/osbuildconfigtemplate"
 	"github.com/project-flotta/osbuild-operator/internal/repository/osbuildenvconfig"
+	"github.com/project-flotta/osbuild-operator/internal/repository/osbuildrelease"
+	"github.com/project-flotta/osbuild-operator/internal/repository/ostreerepository"
+	"github.com/project-flotta/osbuild-operator/internal/repository/persistentvolumeclaim"
+	"github.com/project-flotta/osbuild-operator/internal/repository/pod"
 	"github.com/project-flotta/osbuild-operator/internal/repository/route"
 	"github.com/project-flotta/osbuild-operator/internal/repository/secret"
 	"github.com/project-flotta/osbuild-operator/internal/repository/service"
 	"github.com/project-flotta/osbuild-operator/internal/repository/virtualmachine"
+	"github.com/project-flotta/osbuild-operator/internal/rpmmd"
+	"github.com/project-flotta/osbuild-operator/internal/s3"
 	"github.com/project-flotta/osbuild-operator/internal/sshkey"
 	//+kubebuilder:scaffold:imports
 )
@@ -125,6 +131,21 @@ func main() {
 		setupLog.Error(err, "Failed to create indexer for OSBuildConfig")
 		os.Exit(1)
 	}
+	err = mgr.GetFieldIndexer().IndexField(ctx, &v1alpha1.OSBuild{}, indexer.BuildByConfig, indexer.BuildByConfigIndexFunc)
+	if err != nil {
+		setupLog.Error(err, "Failed to create indexer for OSBuild")
+		os.Exit(1)
+	}
+	err = mgr.GetFieldIndexer().IndexField(ctx, &v1alpha1.OSTreeRepository{}, indexer.RepositoryByConfig, indexer.RepositoryByConfigIndexFunc)
+	if err != nil {
+		setupLog.Error(err, "Failed to create indexer for OSTreeRepository")
+		os.Exit(1)
+	}
+	err = mgr.GetFieldIndexer().IndexField(ctx, &v1alpha1.OSBuildRelease{}, indexer.ReleaseByBuild, indexer.ReleaseByBuildIndexFunc)
+	if err != nil {
+		setupLog.Error(err, "Failed to create indexer for OSBuildRelease")
+		os.Exit(1)
+	}
 
 	osBuildEnvConfigRepository := osbuildenvconfig.NewOSBuildEnvConfigRepository(mgr.GetClient())
 	osBuildConfigRepository := osbuildconfig.NewOSBuildConfigRepository(mgr.GetClient())
@@ -138,14 +159,23 @@ func main() {
 	secretRepository := secret.NewSecretRepository(mgr.GetClient())
 	routeRepository := route.NewRouteRepository(mgr.GetClient())
 	virtualMachineRepository := virtualmachine.NewVirtualMachineRepository(mgr.GetClient())
+	ostreeRepositoryRepository := ostreerepository.NewOSTreeRepositoryRepository(mgr.GetClient())
+	persistentVolumeClaimRepository := persistentvolumeclaim.NewPersistentVolumeClaimRepository(mgr.GetClient())
+	podRepository := pod.NewPodRepository(mgr.GetClient())
+	osBuildReleaseRepository := osbuildrelease.NewOSBuildReleaseRepository(mgr.GetClient())
+	s3ObjectStore := s3.NewEnvConfigObjectStore(osBuildEnvConfigRepository, secretRepository)
 	sshkeyGenerator := sshkey.NewSSHKeyGenerator()
 
-	osBuildCRCreator := manifests.NewOSBuildCRCreator(osBuildConfigRepository, osBuildRepository, scheme, osBuildConfigTemplateRepository, configMapRepository)
+	osBuildCRCreator := manifests.NewOSBuildCRCreator(osBuildConfigRepository, osBuildRepository, scheme, osBuildConfigTemplateRepository, configMapRepository, ostreeRepositoryRepository)
+	repositoryMetadataResolver := rpmmd.NewMetadataPackageResolver(conf.GlobalConf.PackageValidationTimeout)
 
 	if err = (&controllers.OSBuildConfigReconciler{
-		OSBuildConfigRepository: osBuildConfigRepository,
-		OSBuildRepository:       osBuildRepository,
-		OSBuildCRCreator:        osBuildCRCreator,
+		OSBuildConfigRepository:  osBuildConfigRepository,
+		OSBuildRepository:        osBuildRepository,
+		OSBuildCRCreator:         osBuildCRCreator,
+		S3ObjectStore:            s3ObjectStore,
+		OSBuildReleaseRepository: osBuildReleaseRepository,
+		RevisionReader:           repositoryMetadataResolver,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "OSBuildConfig")
 		os.Exit(1)
@@ -166,10 +196,19 @@ func main() {
 		os.Exit(1)
 	}
 
+	var packageResolver rpmmd.PackageResolver
+	if conf.GlobalConf.EnablePackageValidation {
+		packageResolver = repositoryMetadataResolver
+	}
+
 	if err = (&controllers.OSBuildReconciler{
-		Scheme:            mgr.GetScheme(),
-		OSBuildRepository: osBuildRepository,
-		ComposerClient:    composerClient,
+		Scheme:              mgr.GetScheme(),
+		OSBuildRepository:   osBuildRepository,
+		SecretRepository:    secretRepository,
+		ConfigMapRepository: configMapRepository,
+		ComposerClient:      composerClient,
+		PackageResolver:     packageResolver,
+		EventRecorder:       mgr.GetEventRecorderFor("osbuild-controller"),
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "OSBuild")
 		os.Exit(1)
@@ -205,6 +244,31 @@ func main() {
 		setupLog.Error(err, "unable to create controller", "controller", "OSBuildConfigTemplate")
 		os.Exit(1)
 	}
+	if err = (&controllers.OSTreeRepositoryReconciler{
+		Scheme:                          mgr.GetScheme(),
+		OSTreeRepositoryRepository:      ostreeRepositoryRepository,
+		OSBuildRepository:               osBuildRepository,
+		PersistentVolumeClaimRepository: persistentVolumeClaimRepository,
+		DeploymentRepository:            deploymentRepository,
+		ServiceRepository:               serviceRepository,
+		RouteRepository:                 routeRepository,
+		JobRepository:                   jobRepository,
+		PodRepository:                   podRepository,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "OSTreeRepository")
+		os.Exit(1)
+	}
+	if err = (&controllers.OSBuildReleaseReconciler{
+		Scheme:                     mgr.GetScheme(),
+		OSBuildReleaseRepository:   osBuildReleaseRepository,
+		OSBuildRepository:          osBuildRepository,
+		OSTreeRepositoryRepository: ostreeRepositoryRepository,
+		JobRepository:              jobRepository,
+		S3ObjectStore:              s3ObjectStore,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "OSBuildRelease")
+		os.Exit(1)
+	}
 	//+kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
@@ -4,9 +4,11 @@
 package restapi
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
 	"net/url"
@@ -88,148 +90,1246 @@ func WithRequestEditorFn(fn RequestEditorFn) ClientOption {
 
 // The interface specification for the client above.
 type ClientInterface interface {
-	// TriggerBuild request
-	TriggerBuild(ctx context.Context, namespace string, name string, params *TriggerBuildParams, reqEditors ...RequestEditorFn) (*http.Response, error)
+	// ListOSBuilds request
+	ListOSBuilds(ctx context.Context, namespace string, params *ListOSBuildsParams, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// GetOSBuild request
+	GetOSBuild(ctx context.Context, namespace string, name string, params *GetOSBuildParams, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// ListOSBuildConfigs request
+	ListOSBuildConfigs(ctx context.Context, namespace string, params *ListOSBuildConfigsParams, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// GetOSBuildConfig request
+	GetOSBuildConfig(ctx context.Context, namespace string, name string, params *GetOSBuildConfigParams, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// TriggerBuild request with any body
+	TriggerBuildWithBody(ctx context.Context, namespace string, name string, params *TriggerBuildParams, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	TriggerBuild(ctx context.Context, namespace string, name string, params *TriggerBuildParams, body TriggerBuildJSONRequestBody, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// TriggerGitHubBuild request with any body
+	TriggerGitHubBuildWithBody(ctx context.Context, namespace string, name string, params *TriggerGitHubBuildParams, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	TriggerGitHubBuild(ctx context.Context, namespace string, name string, params *TriggerGitHubBuildParams, body TriggerGitHubBuildJSONRequestBody, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// TriggerGitLabBuild request with any body
+	TriggerGitLabBuildWithBody(ctx context.Context, namespace string, name string, params *TriggerGitLabBuildParams, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	TriggerGitLabBuild(ctx context.Context, namespace string, name string, params *TriggerGitLabBuildParams, body TriggerGitLabBuildJSONRequestBody, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// WatchOSBuilds request
+	WatchOSBuilds(ctx context.Context, namespace string, params *WatchOSBuildsParams, reqEditors ...RequestEditorFn) (*http.Response, error)
+}
+
+func (c *Client) ListOSBuilds(ctx context.Context, namespace string, params *ListOSBuildsParams, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewListOSBuildsRequest(c.Server, namespace, params)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) GetOSBuild(ctx context.Context, namespace string, name string, params *GetOSBuildParams, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewGetOSBuildRequest(c.Server, namespace, name, params)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) ListOSBuildConfigs(ctx context.Context, namespace string, params *ListOSBuildConfigsParams, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewListOSBuildConfigsRequest(c.Server, namespace, params)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) GetOSBuildConfig(ctx context.Context, namespace string, name string, params *GetOSBuildConfigParams, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewGetOSBuildConfigRequest(c.Server, namespace, name, params)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) TriggerBuildWithBody(ctx context.Context, namespace string, name string, params *TriggerBuildParams, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewTriggerBuildRequestWithBody(c.Server, namespace, name, params, contentType, body)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) TriggerBuild(ctx context.Context, namespace string, name string, params *TriggerBuildParams, body TriggerBuildJSONRequestBody, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewTriggerBuildRequest(c.Server, namespace, name, params, body)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) TriggerGitHubBuildWithBody(ctx context.Context, namespace string, name string, params *TriggerGitHubBuildParams, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewTriggerGitHubBuildRequestWithBody(c.Server, namespace, name, params, contentType, body)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) TriggerGitHubBuild(ctx context.Context, namespace string, name string, params *TriggerGitHubBuildParams, body TriggerGitHubBuildJSONRequestBody, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewTriggerGitHubBuildRequest(c.Server, namespace, name, params, body)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) TriggerGitLabBuildWithBody(ctx context.Context, namespace string, name string, params *TriggerGitLabBuildParams, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewTriggerGitLabBuildRequestWithBody(c.Server, namespace, name, params, contentType, body)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) TriggerGitLabBuild(ctx context.Context, namespace string, name string, params *TriggerGitLabBuildParams, body TriggerGitLabBuildJSONRequestBody, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewTriggerGitLabBuildRequest(c.Server, namespace, name, params, body)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) WatchOSBuilds(ctx context.Context, namespace string, params *WatchOSBuildsParams, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewWatchOSBuildsRequest(c.Server, namespace, params)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+// NewListOSBuildsRequest generates requests for ListOSBuilds
+func NewListOSBuildsRequest(server string, namespace string, params *ListOSBuildsParams) (*http.Request, error) {
+	var err error
+
+	var pathParam0 string
+
+	pathParam0, err = runtime.StyleParamWithLocation("simple", false, "namespace", runtime.ParamLocationPath, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/api/osbuild/v1/namespaces/%s/osbuild", pathParam0)
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	queryValues := queryURL.Query()
+
+	if params.Osbuildconfig != nil {
+
+		if queryFrag, err := runtime.StyleParamWithLocation("form", true, "osbuildconfig", runtime.ParamLocationQuery, *params.Osbuildconfig); err != nil {
+			return nil, err
+		} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+			return nil, err
+		} else {
+			for k, v := range parsed {
+				for _, v2 := range v {
+					queryValues.Add(k, v2)
+				}
+			}
+		}
+
+	}
+
+	queryURL.RawQuery = queryValues.Encode()
+
+	req, err := http.NewRequest("GET", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if params.Secret != nil {
+		var headerParam0 string
+
+		headerParam0, err = runtime.StyleParamWithLocation("simple", false, "secret", runtime.ParamLocationHeader, *params.Secret)
+		if err != nil {
+			return nil, err
+		}
+
+		req.Header.Set("secret", headerParam0)
+	}
+
+	if params.Authorization != nil {
+		var headerParam1 string
+
+		headerParam1, err = runtime.StyleParamWithLocation("simple", false, "Authorization", runtime.ParamLocationHeader, *params.Authorization)
+		if err != nil {
+			return nil, err
+		}
+
+		req.Header.Set("Authorization", headerParam1)
+	}
+
+	return req, nil
+}
+
+// NewGetOSBuildRequest generates requests for GetOSBuild
+func NewGetOSBuildRequest(server string, namespace string, name string, params *GetOSBuildParams) (*http.Request, error) {
+	var err error
+
+	var pathParam0 string
+
+	pathParam0, err = runtime.StyleParamWithLocation("simple", false, "namespace", runtime.ParamLocationPath, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	var pathParam1 string
+
+	pathParam1, err = runtime.StyleParamWithLocation("simple", false, "name", runtime.ParamLocationPath, name)
+	if err != nil {
+		return nil, err
+	}
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/api/osbuild/v1/namespaces/%s/osbuild/%s", pathParam0, pathParam1)
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	queryValues := queryURL.Query()
+
+	if params.Wait != nil {
+
+		if queryFrag, err := runtime.StyleParamWithLocation("form", true, "wait", runtime.ParamLocationQuery, *params.Wait); err != nil {
+			return nil, err
+		} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+			return nil, err
+		} else {
+			for k, v := range parsed {
+				for _, v2 := range v {
+					queryValues.Add(k, v2)
+				}
+			}
+		}
+
+	}
+
+	queryURL.RawQuery = queryValues.Encode()
+
+	req, err := http.NewRequest("GET", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if params.Secret != nil {
+		var headerParam0 string
+
+		headerParam0, err = runtime.StyleParamWithLocation("simple", false, "secret", runtime.ParamLocationHeader, *params.Secret)
+		if err != nil {
+			return nil, err
+		}
+
+		req.Header.Set("secret", headerParam0)
+	}
+
+	if params.Authorization != nil {
+		var headerParam1 string
+
+		headerParam1, err = runtime.StyleParamWithLocation("simple", false, "Authorization", runtime.ParamLocationHeader, *params.Authorization)
+		if err != nil {
+			return nil, err
+		}
+
+		req.Header.Set("Authorization", headerParam1)
+	}
+
+	return req, nil
+}
+
+// NewListOSBuildConfigsRequest generates requests for ListOSBuildConfigs
+func NewListOSBuildConfigsRequest(server string, namespace string, params *ListOSBuildConfigsParams) (*http.Request, error) {
+	var err error
+
+	var pathParam0 string
+
+	pathParam0, err = runtime.StyleParamWithLocation("simple", false, "namespace", runtime.ParamLocationPath, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/api/osbuild/v1/namespaces/%s/osbuildconfig", pathParam0)
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if params.Secret != nil {
+		var headerParam0 string
+
+		headerParam0, err = runtime.StyleParamWithLocation("simple", false, "secret", runtime.ParamLocationHeader, *params.Secret)
+		if err != nil {
+			return nil, err
+		}
+
+		req.Header.Set("secret", headerParam0)
+	}
+
+	if params.Authorization != nil {
+		var headerParam1 string
+
+		headerParam1, err = runtime.StyleParamWithLocation("simple", false, "Authorization", runtime.ParamLocationHeader, *params.Authorization)
+		if err != nil {
+			return nil, err
+		}
+
+		req.Header.Set("Authorization", headerParam1)
+	}
+
+	return req, nil
+}
+
+// NewGetOSBuildConfigRequest generates requests for GetOSBuildConfig
+func NewGetOSBuildConfigRequest(server string, namespace string, name string, params *GetOSBuildConfigParams) (*http.Request, error) {
+	var err error
+
+	var pathParam0 string
+
+	pathParam0, err = runtime.StyleParamWithLocation("simple", false, "namespace", runtime.ParamLocationPath, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	var pathParam1 string
+
+	pathParam1, err = runtime.StyleParamWithLocation("simple", false, "name", runtime.ParamLocationPath, name)
+	if err != nil {
+		return nil, err
+	}
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/api/osbuild/v1/namespaces/%s/osbuildconfig/%s", pathParam0, pathParam1)
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if params.Secret != nil {
+		var headerParam0 string
+
+		headerParam0, err = runtime.StyleParamWithLocation("simple", false, "secret", runtime.ParamLocationHeader, *params.Secret)
+		if err != nil {
+			return nil, err
+		}
+
+		req.Header.Set("secret", headerParam0)
+	}
+
+	if params.Authorization != nil {
+		var headerParam1 string
+
+		headerParam1, err = runtime.StyleParamWithLocation("simple", false, "Authorization", runtime.ParamLocationHeader, *params.Authorization)
+		if err != nil {
+			return nil, err
+		}
+
+		req.Header.Set("Authorization", headerParam1)
+	}
+
+	return req, nil
+}
+
+// NewTriggerBuildRequest calls the generic TriggerBuild builder with application/json body
+func NewTriggerBuildRequest(server string, namespace string, name string, params *TriggerBuildParams, body TriggerBuildJSONRequestBody) (*http.Request, error) {
+	var bodyReader io.Reader
+	buf, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	bodyReader = bytes.NewReader(buf)
+	return NewTriggerBuildRequestWithBody(server, namespace, name, params, "application/json", bodyReader)
+}
+
+// NewTriggerBuildRequestWithBody generates requests for TriggerBuild with any type of body
+func NewTriggerBuildRequestWithBody(server string, namespace string, name string, params *TriggerBuildParams, contentType string, body io.Reader) (*http.Request, error) {
+	var err error
+
+	var pathParam0 string
+
+	pathParam0, err = runtime.StyleParamWithLocation("simple", false, "namespace", runtime.ParamLocationPath, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	var pathParam1 string
+
+	pathParam1, err = runtime.StyleParamWithLocation("simple", false, "name", runtime.ParamLocationPath, name)
+	if err != nil {
+		return nil, err
+	}
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/api/osbuild/v1/namespaces/%s/osbuildconfig/%s/webhooks", pathParam0, pathParam1)
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("POST", queryURL.String(), body)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Add("Content-Type", contentType)
+
+	if params.Secret != nil {
+		var headerParam0 string
+
+		headerParam0, err = runtime.StyleParamWithLocation("simple", false, "secret", runtime.ParamLocationHeader, *params.Secret)
+		if err != nil {
+			return nil, err
+		}
+
+		req.Header.Set("secret", headerParam0)
+	}
+
+	if params.Authorization != nil {
+		var headerParam1 string
+
+		headerParam1, err = runtime.StyleParamWithLocation("simple", false, "Authorization", runtime.ParamLocationHeader, *params.Authorization)
+		if err != nil {
+			return nil, err
+		}
+
+		req.Header.Set("Authorization", headerParam1)
+	}
+
+	if params.XSignature256 != nil {
+		var headerParam2 string
+
+		headerParam2, err = runtime.StyleParamWithLocation("simple", false, "X-Signature-256", runtime.ParamLocationHeader, *params.XSignature256)
+		if err != nil {
+			return nil, err
+		}
+
+		req.Header.Set("X-Signature-256", headerParam2)
+	}
+
+	if params.XSignatureTimestamp != nil {
+		var headerParam3 string
+
+		headerParam3, err = runtime.StyleParamWithLocation("simple", false, "X-Signature-Timestamp", runtime.ParamLocationHeader, *params.XSignatureTimestamp)
+		if err != nil {
+			return nil, err
+		}
+
+		req.Header.Set("X-Signature-Timestamp", headerParam3)
+	}
+
+	if params.XSignatureNonce != nil {
+		var headerParam4 string
+
+		headerParam4, err = runtime.StyleParamWithLocation("simple", false, "X-Signature-Nonce", runtime.ParamLocationHeader, *params.XSignatureNonce)
+		if err != nil {
+			return nil, err
+		}
+
+		req.Header.Set("X-Signature-Nonce", headerParam4)
+	}
+
+	return req, nil
+}
+
+// NewTriggerGitHubBuildRequest calls the generic TriggerGitHubBuild builder with application/json body
+func NewTriggerGitHubBuildRequest(server string, namespace string, name string, params *TriggerGitHubBuildParams, body TriggerGitHubBuildJSONRequestBody) (*http.Request, error) {
+	var bodyReader io.Reader
+	buf, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	bodyReader = bytes.NewReader(buf)
+	return NewTriggerGitHubBuildRequestWithBody(server, namespace, name, params, "application/json", bodyReader)
+}
+
+// NewTriggerGitHubBuildRequestWithBody generates requests for TriggerGitHubBuild with any type of body
+func NewTriggerGitHubBuildRequestWithBody(server string, namespace string, name string, params *TriggerGitHubBuildParams, contentType string, body io.Reader) (*http.Request, error) {
+	var err error
+
+	var pathParam0 string
+
+	pathParam0, err = runtime.StyleParamWithLocation("simple", false, "namespace", runtime.ParamLocationPath, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	var pathParam1 string
+
+	pathParam1, err = runtime.StyleParamWithLocation("simple", false, "name", runtime.ParamLocationPath, name)
+	if err != nil {
+		return nil, err
+	}
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/api/osbuild/v1/namespaces/%s/osbuildconfig/%s/webhooks/github", pathParam0, pathParam1)
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("POST", queryURL.String(), body)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Add("Content-Type", contentType)
+
+	if params.XHubSignature256 != nil {
+		var headerParam0 string
+
+		headerParam0, err = runtime.StyleParamWithLocation("simple", false, "X-Hub-Signature-256", runtime.ParamLocationHeader, *params.XHubSignature256)
+		if err != nil {
+			return nil, err
+		}
+
+		req.Header.Set("X-Hub-Signature-256", headerParam0)
+	}
+
+	var headerParam1 string
+
+	headerParam1, err = runtime.StyleParamWithLocation("simple", false, "X-GitHub-Event", runtime.ParamLocationHeader, params.XGitHubEvent)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("X-GitHub-Event", headerParam1)
+
+	return req, nil
+}
+
+// NewTriggerGitLabBuildRequest calls the generic TriggerGitLabBuild builder with application/json body
+func NewTriggerGitLabBuildRequest(server string, namespace string, name string, params *TriggerGitLabBuildParams, body TriggerGitLabBuildJSONRequestBody) (*http.Request, error) {
+	var bodyReader io.Reader
+	buf, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	bodyReader = bytes.NewReader(buf)
+	return NewTriggerGitLabBuildRequestWithBody(server, namespace, name, params, "application/json", bodyReader)
+}
+
+// NewTriggerGitLabBuildRequestWithBody generates requests for TriggerGitLabBuild with any type of body
+func NewTriggerGitLabBuildRequestWithBody(server string, namespace string, name string, params *TriggerGitLabBuildParams, contentType string, body io.Reader) (*http.Request, error) {
+	var err error
+
+	var pathParam0 string
+
+	pathParam0, err = runtime.StyleParamWithLocation("simple", false, "namespace", runtime.ParamLocationPath, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	var pathParam1 string
+
+	pathParam1, err = runtime.StyleParamWithLocation("simple", false, "name", runtime.ParamLocationPath, name)
+	if err != nil {
+		return nil, err
+	}
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/api/osbuild/v1/namespaces/%s/osbuildconfig/%s/webhooks/gitlab", pathParam0, pathParam1)
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("POST", queryURL.String(), body)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Add("Content-Type", contentType)
+
+	if params.XGitlabToken != nil {
+		var headerParam0 string
+
+		headerParam0, err = runtime.StyleParamWithLocation("simple", false, "X-Gitlab-Token", runtime.ParamLocationHeader, *params.XGitlabToken)
+		if err != nil {
+			return nil, err
+		}
+
+		req.Header.Set("X-Gitlab-Token", headerParam0)
+	}
+
+	var headerParam1 string
+
+	headerParam1, err = runtime.StyleParamWithLocation("simple", false, "X-Gitlab-Event", runtime.ParamLocationHeader, params.XGitlabEvent)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("X-Gitlab-Event", headerParam1)
+
+	return req, nil
+}
+
+// NewWatchOSBuildsRequest generates requests for WatchOSBuilds
+func NewWatchOSBuildsRequest(server string, namespace string, params *WatchOSBuildsParams) (*http.Request, error) {
+	var err error
+
+	var pathParam0 string
+
+	pathParam0, err = runtime.StyleParamWithLocation("simple", false, "namespace", runtime.ParamLocationPath, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/api/osbuild/v1/namespaces/%s/watch", pathParam0)
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	queryValues := queryURL.Query()
+
+	if params.Osbuild != nil {
+
+		if queryFrag, err := runtime.StyleParamWithLocation("form", true, "osbuild", runtime.ParamLocationQuery, *params.Osbuild); err != nil {
+			return nil, err
+		} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+			return nil, err
+		} else {
+			for k, v := range parsed {
+				for _, v2 := range v {
+					queryValues.Add(k, v2)
+				}
+			}
+		}
+
+	}
+
+	if params.Osbuildconfig != nil {
+
+		if queryFrag, err := runtime.StyleParamWithLocation("form", true, "osbuildconfig", runtime.ParamLocationQuery, *params.Osbuildconfig); err != nil {
+			return nil, err
+		} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+			return nil, err
+		} else {
+			for k, v := range parsed {
+				for _, v2 := range v {
+					queryValues.Add(k, v2)
+				}
+			}
+		}
+
+	}
+
+	queryURL.RawQuery = queryValues.Encode()
+
+	req, err := http.NewRequest("GET", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if params.Secret != nil {
+		var headerParam0 string
+
+		headerParam0, err = runtime.StyleParamWithLocation("simple", false, "secret", runtime.ParamLocationHeader, *params.Secret)
+		if err != nil {
+			return nil, err
+		}
+
+		req.Header.Set("secret", headerParam0)
+	}
+
+	if params.Authorization != nil {
+		var headerParam1 string
+
+		headerParam1, err = runtime.StyleParamWithLocation("simple", false, "Authorization", runtime.ParamLocationHeader, *params.Authorization)
+		if err != nil {
+			return nil, err
+		}
+
+		req.Header.Set("Authorization", headerParam1)
+	}
+
+	return req, nil
+}
+
+func (c *Client) applyEditors(ctx context.Context, req *http.Request, additionalEditors []RequestEditorFn) error {
+	for _, r := range c.RequestEditors {
+		if err := r(ctx, req); err != nil {
+			return err
+		}
+	}
+	for _, r := range additionalEditors {
+		if err := r(ctx, req); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ClientWithResponses builds on ClientInterface to offer response payloads
+type ClientWithResponses struct {
+	ClientInterface
+}
+
+// NewClientWithResponses creates a new ClientWithResponses, which wraps
+// Client with return type handling
+func NewClientWithResponses(server string, opts ...ClientOption) (*ClientWithResponses, error) {
+	client, err := NewClient(server, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &ClientWithResponses{client}, nil
+}
+
+// WithBaseURL overrides the baseURL.
+func WithBaseURL(baseURL string) ClientOption {
+	return func(c *Client) error {
+		newBaseURL, err := url.Parse(baseURL)
+		if err != nil {
+			return err
+		}
+		c.Server = newBaseURL.String()
+		return nil
+	}
+}
+
+// ClientWithResponsesInterface is the interface specification for the client with responses above.
+type ClientWithResponsesInterface interface {
+	// ListOSBuilds request
+	ListOSBuildsWithResponse(ctx context.Context, namespace string, params *ListOSBuildsParams, reqEditors ...RequestEditorFn) (*ListOSBuildsResponse, error)
+
+	// GetOSBuild request
+	GetOSBuildWithResponse(ctx context.Context, namespace string, name string, params *GetOSBuildParams, reqEditors ...RequestEditorFn) (*GetOSBuildResponse, error)
+
+	// ListOSBuildConfigs request
+	ListOSBuildConfigsWithResponse(ctx context.Context, namespace string, params *ListOSBuildConfigsParams, reqEditors ...RequestEditorFn) (*ListOSBuildConfigsResponse, error)
+
+	// GetOSBuildConfig request
+	GetOSBuildConfigWithResponse(ctx context.Context, namespace string, name string, params *GetOSBuildConfigParams, reqEditors ...RequestEditorFn) (*GetOSBuildConfigResponse, error)
+
+	// TriggerBuild request with any body
+	TriggerBuildWithBodyWithResponse(ctx context.Context, namespace string, name string, params *TriggerBuildParams, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*TriggerBuildResponse, error)
+
+	TriggerBuildWithResponse(ctx context.Context, namespace string, name string, params *TriggerBuildParams, body TriggerBuildJSONRequestBody, reqEditors ...RequestEditorFn) (*TriggerBuildResponse, error)
+
+	// TriggerGitHubBuild request with any body
+	TriggerGitHubBuildWithBodyWithResponse(ctx context.Context, namespace string, name string, params *TriggerGitHubBuildParams, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*TriggerGitHubBuildResponse, error)
+
+	TriggerGitHubBuildWithResponse(ctx context.Context, namespace string, name string, params *TriggerGitHubBuildParams, body TriggerGitHubBuildJSONRequestBody, reqEditors ...RequestEditorFn) (*TriggerGitHubBuildResponse, error)
+
+	// TriggerGitLabBuild request with any body
+	TriggerGitLabBuildWithBodyWithResponse(ctx context.Context, namespace string, name string, params *TriggerGitLabBuildParams, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*TriggerGitLabBuildResponse, error)
+
+	TriggerGitLabBuildWithResponse(ctx context.Context, namespace string, name string, params *TriggerGitLabBuildParams, body TriggerGitLabBuildJSONRequestBody, reqEditors ...RequestEditorFn) (*TriggerGitLabBuildResponse, error)
+
+	// WatchOSBuilds request
+	WatchOSBuildsWithResponse(ctx context.Context, namespace string, params *WatchOSBuildsParams, reqEditors ...RequestEditorFn) (*WatchOSBuildsResponse, error)
+}
+
+type ListOSBuildsResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *[]Osbuild
+}
+
+// Status returns HTTPResponse.Status
+func (r ListOSBuildsResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r ListOSBuildsResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type GetOSBuildResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *Osbuild
+}
+
+// Status returns HTTPResponse.Status
+func (r GetOSBuildResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r GetOSBuildResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type ListOSBuildConfigsResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *[]Osbuildconfig
+}
+
+// Status returns HTTPResponse.Status
+func (r ListOSBuildConfigsResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r ListOSBuildConfigsResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type GetOSBuildConfigResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *Osbuildconfig
+}
+
+// Status returns HTTPResponse.Status
+func (r GetOSBuildConfigResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r GetOSBuildConfigResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type TriggerBuildResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *MessageResponse
+	JSON208      *MessageResponse
+}
+
+// Status returns HTTPResponse.Status
+func (r TriggerBuildResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r TriggerBuildResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type TriggerGitHubBuildResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *MessageResponse
+	JSON208      *MessageResponse
+}
+
+// Status returns HTTPResponse.Status
+func (r TriggerGitHubBuildResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r TriggerGitHubBuildResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type TriggerGitLabBuildResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *MessageResponse
+	JSON208      *MessageResponse
 }
 
-func (c *Client) TriggerBuild(ctx context.Context, namespace string, name string, params *TriggerBuildParams, reqEditors ...RequestEditorFn) (*http.Response, error) {
-	req, err := NewTriggerBuildRequest(c.Server, namespace, name, params)
-	if err != nil {
-		return nil, err
+// Status returns HTTPResponse.Status
+func (r TriggerGitLabBuildResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
 	}
-	req = req.WithContext(ctx)
-	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
-		return nil, err
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r TriggerGitLabBuildResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
 	}
-	return c.Client.Do(req)
+	return 0
 }
 
-// NewTriggerBuildRequest generates requests for TriggerBuild
-func NewTriggerBuildRequest(server string, namespace string, name string, params *TriggerBuildParams) (*http.Request, error) {
-	var err error
+type WatchOSBuildsResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+}
 
-	var pathParam0 string
+// Status returns HTTPResponse.Status
+func (r WatchOSBuildsResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
 
-	pathParam0, err = runtime.StyleParamWithLocation("simple", false, "namespace", runtime.ParamLocationPath, namespace)
+// StatusCode returns HTTPResponse.StatusCode
+func (r WatchOSBuildsResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+// ListOSBuildsWithResponse request returning *ListOSBuildsResponse
+func (c *ClientWithResponses) ListOSBuildsWithResponse(ctx context.Context, namespace string, params *ListOSBuildsParams, reqEditors ...RequestEditorFn) (*ListOSBuildsResponse, error) {
+	rsp, err := c.ListOSBuilds(ctx, namespace, params, reqEditors...)
 	if err != nil {
 		return nil, err
 	}
+	return ParseListOSBuildsResponse(rsp)
+}
 
-	var pathParam1 string
+// GetOSBuildWithResponse request returning *GetOSBuildResponse
+func (c *ClientWithResponses) GetOSBuildWithResponse(ctx context.Context, namespace string, name string, params *GetOSBuildParams, reqEditors ...RequestEditorFn) (*GetOSBuildResponse, error) {
+	rsp, err := c.GetOSBuild(ctx, namespace, name, params, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseGetOSBuildResponse(rsp)
+}
 
-	pathParam1, err = runtime.StyleParamWithLocation("simple", false, "name", runtime.ParamLocationPath, name)
+// ListOSBuildConfigsWithResponse request returning *ListOSBuildConfigsResponse
+func (c *ClientWithResponses) ListOSBuildConfigsWithResponse(ctx context.Context, namespace string, params *ListOSBuildConfigsParams, reqEditors ...RequestEditorFn) (*ListOSBuildConfigsResponse, error) {
+	rsp, err := c.ListOSBuildConfigs(ctx, namespace, params, reqEditors...)
 	if err != nil {
 		return nil, err
 	}
+	return ParseListOSBuildConfigsResponse(rsp)
+}
 
-	serverURL, err := url.Parse(server)
+// GetOSBuildConfigWithResponse request returning *GetOSBuildConfigResponse
+func (c *ClientWithResponses) GetOSBuildConfigWithResponse(ctx context.Context, namespace string, name string, params *GetOSBuildConfigParams, reqEditors ...RequestEditorFn) (*GetOSBuildConfigResponse, error) {
+	rsp, err := c.GetOSBuildConfig(ctx, namespace, name, params, reqEditors...)
 	if err != nil {
 		return nil, err
 	}
+	return ParseGetOSBuildConfigResponse(rsp)
+}
 
-	operationPath := fmt.Sprintf("/api/osbuild/v1/namespaces/%s/osbuildconfig/%s/webhooks", pathParam0, pathParam1)
-	if operationPath[0] == '/' {
-		operationPath = "." + operationPath
+// TriggerBuildWithBodyWithResponse request with arbitrary body returning *TriggerBuildResponse
+func (c *ClientWithResponses) TriggerBuildWithBodyWithResponse(ctx context.Context, namespace string, name string, params *TriggerBuildParams, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*TriggerBuildResponse, error) {
+	rsp, err := c.TriggerBuildWithBody(ctx, namespace, name, params, contentType, body, reqEditors...)
+	if err != nil {
+		return nil, err
 	}
+	return ParseTriggerBuildResponse(rsp)
+}
 
-	queryURL, err := serverURL.Parse(operationPath)
+func (c *ClientWithResponses) TriggerBuildWithResponse(ctx context.Context, namespace string, name string, params *TriggerBuildParams, body TriggerBuildJSONRequestBody, reqEditors ...RequestEditorFn) (*TriggerBuildResponse, error) {
+	rsp, err := c.TriggerBuild(ctx, namespace, name, params, body, reqEditors...)
 	if err != nil {
 		return nil, err
 	}
+	return ParseTriggerBuildResponse(rsp)
+}
 
-	req, err := http.NewRequest("POST", queryURL.String(), nil)
+// TriggerGitHubBuildWithBodyWithResponse request with arbitrary body returning *TriggerGitHubBuildResponse
+func (c *ClientWithResponses) TriggerGitHubBuildWithBodyWithResponse(ctx context.Context, namespace string, name string, params *TriggerGitHubBuildParams, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*TriggerGitHubBuildResponse, error) {
+	rsp, err := c.TriggerGitHubBuildWithBody(ctx, namespace, name, params, contentType, body, reqEditors...)
 	if err != nil {
 		return nil, err
 	}
+	return ParseTriggerGitHubBuildResponse(rsp)
+}
 
-	var headerParam0 string
+func (c *ClientWithResponses) TriggerGitHubBuildWithResponse(ctx context.Context, namespace string, name string, params *TriggerGitHubBuildParams, body TriggerGitHubBuildJSONRequestBody, reqEditors ...RequestEditorFn) (*TriggerGitHubBuildResponse, error) {
+	rsp, err := c.TriggerGitHubBuild(ctx, namespace, name, params, body, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseTriggerGitHubBuildResponse(rsp)
+}
 
-	headerParam0, err = runtime.StyleParamWithLocation("simple", false, "secret", runtime.ParamLocationHeader, params.Secret)
+// TriggerGitLabBuildWithBodyWithResponse request with arbitrary body returning *TriggerGitLabBuildResponse
+func (c *ClientWithResponses) TriggerGitLabBuildWithBodyWithResponse(ctx context.Context, namespace string, name string, params *TriggerGitLabBuildParams, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*TriggerGitLabBuildResponse, error) {
+	rsp, err := c.TriggerGitLabBuildWithBody(ctx, namespace, name, params, contentType, body, reqEditors...)
 	if err != nil {
 		return nil, err
 	}
+	return ParseTriggerGitLabBuildResponse(rsp)
+}
 
-	req.Header.Set("secret", headerParam0)
+func (c *ClientWithResponses) TriggerGitLabBuildWithResponse(ctx context.Context, namespace string, name string, params *TriggerGitLabBuildParams, body TriggerGitLabBuildJSONRequestBody, reqEditors ...RequestEditorFn) (*TriggerGitLabBuildResponse, error) {
+	rsp, err := c.TriggerGitLabBuild(ctx, namespace, name, params, body, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseTriggerGitLabBuildResponse(rsp)
+}
 
-	return req, nil
+// WatchOSBuildsWithResponse request returning *WatchOSBuildsResponse
+func (c *ClientWithResponses) WatchOSBuildsWithResponse(ctx context.Context, namespace string, params *WatchOSBuildsParams, reqEditors ...RequestEditorFn) (*WatchOSBuildsResponse, error) {
+	rsp, err := c.WatchOSBuilds(ctx, namespace, params, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseWatchOSBuildsResponse(rsp)
 }
 
-func (c *Client) applyEditors(ctx context.Context, req *http.Request, additionalEditors []RequestEditorFn) error {
-	for _, r := range c.RequestEditors {
-		if err := r(ctx, req); err != nil {
-			return err
-		}
+// ParseListOSBuildsResponse parses an HTTP response from a ListOSBuildsWithResponse call
+func ParseListOSBuildsResponse(rsp *http.Response) (*ListOSBuildsResponse, error) {
+	bodyBytes, err := ioutil.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
 	}
-	for _, r := range additionalEditors {
-		if err := r(ctx, req); err != nil {
-			return err
+
+	response := &ListOSBuildsResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest []Osbuild
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
 		}
+		response.JSON200 = &dest
+
 	}
-	return nil
-}
 
-// ClientWithResponses builds on ClientInterface to offer response payloads
-type ClientWithResponses struct {
-	ClientInterface
+	return response, nil
 }
 
-// NewClientWithResponses creates a new ClientWithResponses, which wraps
-// Client with return type handling
-func NewClientWithResponses(server string, opts ...ClientOption) (*ClientWithResponses, error) {
-	client, err := NewClient(server, opts...)
+// ParseGetOSBuildResponse parses an HTTP response from a GetOSBuildWithResponse call
+func ParseGetOSBuildResponse(rsp *http.Response) (*GetOSBuildResponse, error) {
+	bodyBytes, err := ioutil.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
 	if err != nil {
 		return nil, err
 	}
-	return &ClientWithResponses{client}, nil
-}
 
-// WithBaseURL overrides the baseURL.
-func WithBaseURL(baseURL string) ClientOption {
-	return func(c *Client) error {
-		newBaseURL, err := url.Parse(baseURL)
-		if err != nil {
-			return err
+	response := &GetOSBuildResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest Osbuild
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
 		}
-		c.Server = newBaseURL.String()
-		return nil
+		response.JSON200 = &dest
+
 	}
-}
 
-// ClientWithResponsesInterface is the interface specification for the client with responses above.
-type ClientWithResponsesInterface interface {
-	// TriggerBuild request
-	TriggerBuildWithResponse(ctx context.Context, namespace string, name string, params *TriggerBuildParams, reqEditors ...RequestEditorFn) (*TriggerBuildResponse, error)
+	return response, nil
 }
 
-type TriggerBuildResponse struct {
-	Body         []byte
-	HTTPResponse *http.Response
-	JSON200      *MessageResponse
-}
+// ParseListOSBuildConfigsResponse parses an HTTP response from a ListOSBuildConfigsWithResponse call
+func ParseListOSBuildConfigsResponse(rsp *http.Response) (*ListOSBuildConfigsResponse, error) {
+	bodyBytes, err := ioutil.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
 
-// Status returns HTTPResponse.Status
-func (r TriggerBuildResponse) Status() string {
-	if r.HTTPResponse != nil {
-		return r.HTTPResponse.Status
+	response := &ListOSBuildConfigsResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
 	}
-	return http.StatusText(0)
-}
 
-// StatusCode returns HTTPResponse.StatusCode
-func (r TriggerBuildResponse) StatusCode() int {
-	if r.HTTPResponse != nil {
-		return r.HTTPResponse.StatusCode
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest []Osbuildconfig
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
 	}
-	return 0
+
+	return response, nil
 }
 
-// TriggerBuildWithResponse request returning *TriggerBuildResponse
-func (c *ClientWithResponses) TriggerBuildWithResponse(ctx context.Context, namespace string, name string, params *TriggerBuildParams, reqEditors ...RequestEditorFn) (*TriggerBuildResponse, error) {
-	rsp, err := c.TriggerBuild(ctx, namespace, name, params, reqEditors...)
+// ParseGetOSBuildConfigResponse parses an HTTP response from a GetOSBuildConfigWithResponse call
+func ParseGetOSBuildConfigResponse(rsp *http.Response) (*GetOSBuildConfigResponse, error) {
+	bodyBytes, err := ioutil.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
 	if err != nil {
 		return nil, err
 	}
-	return ParseTriggerBuildResponse(rsp)
+
+	response := &GetOSBuildConfigResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest Osbuildconfig
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	}
+
+	return response, nil
 }
 
 // ParseTriggerBuildResponse parses an HTTP response from a TriggerBuildWithResponse call
@@ -253,6 +1353,95 @@ func ParseTriggerBuildResponse(rsp *http.Response) (*TriggerBuildResponse, error
 		}
 		response.JSON200 = &dest
 
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 208:
+		var dest MessageResponse
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON208 = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseTriggerGitHubBuildResponse parses an HTTP response from a TriggerGitHubBuildWithResponse call
+func ParseTriggerGitHubBuildResponse(rsp *http.Response) (*TriggerGitHubBuildResponse, error) {
+	bodyBytes, err := ioutil.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &TriggerGitHubBuildResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest MessageResponse
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 208:
+		var dest MessageResponse
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON208 = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseTriggerGitLabBuildResponse parses an HTTP response from a TriggerGitLabBuildWithResponse call
+func ParseTriggerGitLabBuildResponse(rsp *http.Response) (*TriggerGitLabBuildResponse, error) {
+	bodyBytes, err := ioutil.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &TriggerGitLabBuildResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest MessageResponse
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 208:
+		var dest MessageResponse
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON208 = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseWatchOSBuildsResponse parses an HTTP response from a WatchOSBuildsWithResponse call
+func ParseWatchOSBuildsResponse(rsp *http.Response) (*WatchOSBuildsResponse, error) {
+	bodyBytes, err := ioutil.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &WatchOSBuildsResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
 	}
 
 	return response, nil
@@ -14,8 +14,29 @@ import (
 // ServerInterface represents all server handlers.
 type ServerInterface interface {
 
+	// (GET /api/osbuild/v1/namespaces/{namespace}/osbuild)
+	ListOSBuilds(w http.ResponseWriter, r *http.Request, namespace string, params ListOSBuildsParams)
+
+	// (GET /api/osbuild/v1/namespaces/{namespace}/osbuild/{name})
+	GetOSBuild(w http.ResponseWriter, r *http.Request, namespace string, name string, params GetOSBuildParams)
+
+	// (GET /api/osbuild/v1/namespaces/{namespace}/osbuildconfig)
+	ListOSBuildConfigs(w http.ResponseWriter, r *http.Request, namespace string, params ListOSBuildConfigsParams)
+
+	// (GET /api/osbuild/v1/namespaces/{namespace}/osbuildconfig/{name})
+	GetOSBuildConfig(w http.ResponseWriter, r *http.Request, namespace string, name string, params GetOSBuildConfigParams)
+
 	// (POST /api/osbuild/v1/namespaces/{namespace}/osbuildconfig/{name}/webhooks)
 	TriggerBuild(w http.ResponseWriter, r *http.Request, namespace string, name string, params TriggerBuildParams)
+
+	// (POST /api/osbuild/v1/namespaces/{namespace}/osbuildconfig/{name}/webhooks/github)
+	TriggerGitHubBuild(w http.ResponseWriter, r *http.Request, namespace string, name string, params TriggerGitHubBuildParams)
+
+	// (POST /api/osbuild/v1/namespaces/{namespace}/osbuildconfig/{name}/webhooks/gitlab)
+	TriggerGitLabBuild(w http.ResponseWriter, r *http.Request, namespace string, name string, params TriggerGitLabBuildParams)
+
+	// (GET /api/osbuild/v1/namespaces/{namespace}/watch)
+	WatchOSBuilds(w http.ResponseWriter, r *http.Request, namespace string, params WatchOSBuildsParams)
 }
 
 // ServerInterfaceWrapper converts contexts to parameters.
@@ -27,6 +48,322 @@ type ServerInterfaceWrapper struct {
 
 type MiddlewareFunc func(http.HandlerFunc) http.HandlerFunc
 
+// ListOSBuilds operation middleware
+func (siw *ServerInterfaceWrapper) ListOSBuilds(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// ------------- Path parameter "namespace" -------------
+	var namespace string
+
+	err = runtime.BindStyledParameter("simple", false, "namespace", chi.URLParam(r, "namespace"), &namespace)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "namespace", Err: err})
+		return
+	}
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params ListOSBuildsParams
+
+	// ------------- Optional query parameter "osbuildconfig" -------------
+	if paramValue := r.URL.Query().Get("osbuildconfig"); paramValue != "" {
+
+	}
+
+	err = runtime.BindQueryParameter("form", true, false, "osbuildconfig", r.URL.Query(), &params.Osbuildconfig)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "osbuildconfig", Err: err})
+		return
+	}
+
+	headers := r.Header
+
+	// ------------- Optional header parameter "secret" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("secret")]; found {
+		var Secret string
+		n := len(valueList)
+		if n != 1 {
+			siw.ErrorHandlerFunc(w, r, &TooManyValuesForParamError{ParamName: "secret", Count: n})
+			return
+		}
+
+		err = runtime.BindStyledParameterWithLocation("simple", false, "secret", runtime.ParamLocationHeader, valueList[0], &Secret)
+		if err != nil {
+			siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "secret", Err: err})
+			return
+		}
+
+		params.Secret = &Secret
+
+	}
+
+	// ------------- Optional header parameter "Authorization" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("Authorization")]; found {
+		var Authorization string
+		n := len(valueList)
+		if n != 1 {
+			siw.ErrorHandlerFunc(w, r, &TooManyValuesForParamError{ParamName: "Authorization", Count: n})
+			return
+		}
+
+		err = runtime.BindStyledParameterWithLocation("simple", false, "Authorization", runtime.ParamLocationHeader, valueList[0], &Authorization)
+		if err != nil {
+			siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "Authorization", Err: err})
+			return
+		}
+
+		params.Authorization = &Authorization
+
+	}
+
+	var handler = func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.ListOSBuilds(w, r, namespace, params)
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler(w, r.WithContext(ctx))
+}
+
+// GetOSBuild operation middleware
+func (siw *ServerInterfaceWrapper) GetOSBuild(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// ------------- Path parameter "namespace" -------------
+	var namespace string
+
+	err = runtime.BindStyledParameter("simple", false, "namespace", chi.URLParam(r, "namespace"), &namespace)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "namespace", Err: err})
+		return
+	}
+
+	// ------------- Path parameter "name" -------------
+	var name string
+
+	err = runtime.BindStyledParameter("simple", false, "name", chi.URLParam(r, "name"), &name)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "name", Err: err})
+		return
+	}
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetOSBuildParams
+
+	// ------------- Optional query parameter "wait" -------------
+	if paramValue := r.URL.Query().Get("wait"); paramValue != "" {
+
+	}
+
+	err = runtime.BindQueryParameter("form", true, false, "wait", r.URL.Query(), &params.Wait)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "wait", Err: err})
+		return
+	}
+
+	headers := r.Header
+
+	// ------------- Optional header parameter "secret" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("secret")]; found {
+		var Secret string
+		n := len(valueList)
+		if n != 1 {
+			siw.ErrorHandlerFunc(w, r, &TooManyValuesForParamError{ParamName: "secret", Count: n})
+			return
+		}
+
+		err = runtime.BindStyledParameterWithLocation("simple", false, "secret", runtime.ParamLocationHeader, valueList[0], &Secret)
+		if err != nil {
+			siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "secret", Err: err})
+			return
+		}
+
+		params.Secret = &Secret
+
+	}
+
+	// ------------- Optional header parameter "Authorization" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("Authorization")]; found {
+		var Authorization string
+		n := len(valueList)
+		if n != 1 {
+			siw.ErrorHandlerFunc(w, r, &TooManyValuesForParamError{ParamName: "Authorization", Count: n})
+			return
+		}
+
+		err = runtime.BindStyledParameterWithLocation("simple", false, "Authorization", runtime.ParamLocationHeader, valueList[0], &Authorization)
+		if err != nil {
+			siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "Authorization", Err: err})
+			return
+		}
+
+		params.Authorization = &Authorization
+
+	}
+
+	var handler = func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetOSBuild(w, r, namespace, name, params)
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler(w, r.WithContext(ctx))
+}
+
+// ListOSBuildConfigs operation middleware
+func (siw *ServerInterfaceWrapper) ListOSBuildConfigs(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// ------------- Path parameter "namespace" -------------
+	var namespace string
+
+	err = runtime.BindStyledParameter("simple", false, "namespace", chi.URLParam(r, "namespace"), &namespace)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "namespace", Err: err})
+		return
+	}
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params ListOSBuildConfigsParams
+
+	headers := r.Header
+
+	// ------------- Optional header parameter "secret" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("secret")]; found {
+		var Secret string
+		n := len(valueList)
+		if n != 1 {
+			siw.ErrorHandlerFunc(w, r, &TooManyValuesForParamError{ParamName: "secret", Count: n})
+			return
+		}
+
+		err = runtime.BindStyledParameterWithLocation("simple", false, "secret", runtime.ParamLocationHeader, valueList[0], &Secret)
+		if err != nil {
+			siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "secret", Err: err})
+			return
+		}
+
+		params.Secret = &Secret
+
+	}
+
+	// ------------- Optional header parameter "Authorization" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("Authorization")]; found {
+		var Authorization string
+		n := len(valueList)
+		if n != 1 {
+			siw.ErrorHandlerFunc(w, r, &TooManyValuesForParamError{ParamName: "Authorization", Count: n})
+			return
+		}
+
+		err = runtime.BindStyledParameterWithLocation("simple", false, "Authorization", runtime.ParamLocationHeader, valueList[0], &Authorization)
+		if err != nil {
+			siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "Authorization", Err: err})
+			return
+		}
+
+		params.Authorization = &Authorization
+
+	}
+
+	var handler = func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.ListOSBuildConfigs(w, r, namespace, params)
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler(w, r.WithContext(ctx))
+}
+
+// GetOSBuildConfig operation middleware
+func (siw *ServerInterfaceWrapper) GetOSBuildConfig(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// ------------- Path parameter "namespace" -------------
+	var namespace string
+
+	err = runtime.BindStyledParameter("simple", false, "namespace", chi.URLParam(r, "namespace"), &namespace)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "namespace", Err: err})
+		return
+	}
+
+	// ------------- Path parameter "name" -------------
+	var name string
+
+	err = runtime.BindStyledParameter("simple", false, "name", chi.URLParam(r, "name"), &name)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "name", Err: err})
+		return
+	}
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetOSBuildConfigParams
+
+	headers := r.Header
+
+	// ------------- Optional header parameter "secret" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("secret")]; found {
+		var Secret string
+		n := len(valueList)
+		if n != 1 {
+			siw.ErrorHandlerFunc(w, r, &TooManyValuesForParamError{ParamName: "secret", Count: n})
+			return
+		}
+
+		err = runtime.BindStyledParameterWithLocation("simple", false, "secret", runtime.ParamLocationHeader, valueList[0], &Secret)
+		if err != nil {
+			siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "secret", Err: err})
+			return
+		}
+
+		params.Secret = &Secret
+
+	}
+
+	// ------------- Optional header parameter "Authorization" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("Authorization")]; found {
+		var Authorization string
+		n := len(valueList)
+		if n != 1 {
+			siw.ErrorHandlerFunc(w, r, &TooManyValuesForParamError{ParamName: "Authorization", Count: n})
+			return
+		}
+
+		err = runtime.BindStyledParameterWithLocation("simple", false, "Authorization", runtime.ParamLocationHeader, valueList[0], &Authorization)
+		if err != nil {
+			siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "Authorization", Err: err})
+			return
+		}
+
+		params.Authorization = &Authorization
+
+	}
+
+	var handler = func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetOSBuildConfig(w, r, namespace, name, params)
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler(w, r.WithContext(ctx))
+}
+
 // TriggerBuild operation middleware
 func (siw *ServerInterfaceWrapper) TriggerBuild(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
@@ -56,7 +393,7 @@ func (siw *ServerInterfaceWrapper) TriggerBuild(w http.ResponseWriter, r *http.R
 
 	headers := r.Header
 
-	// ------------- Required header parameter "secret" -------------
+	// ------------- Optional header parameter "secret" -------------
 	if valueList, found := headers[http.CanonicalHeaderKey("secret")]; found {
 		var Secret string
 		n := len(valueList)
@@ -71,16 +408,343 @@ func (siw *ServerInterfaceWrapper) TriggerBuild(w http.ResponseWriter, r *http.R
 			return
 		}
 
-		params.Secret = Secret
+		params.Secret = &Secret
+
+	}
+
+	// ------------- Optional header parameter "Authorization" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("Authorization")]; found {
+		var Authorization string
+		n := len(valueList)
+		if n != 1 {
+			siw.ErrorHandlerFunc(w, r, &TooManyValuesForParamError{ParamName: "Authorization", Count: n})
+			return
+		}
+
+		err = runtime.BindStyledParameterWithLocation("simple", false, "Authorization", runtime.ParamLocationHeader, valueList[0], &Authorization)
+		if err != nil {
+			siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "Authorization", Err: err})
+			return
+		}
+
+		params.Authorization = &Authorization
+
+	}
+
+	// ------------- Optional header parameter "X-Signature-256" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("X-Signature-256")]; found {
+		var XSignature256 string
+		n := len(valueList)
+		if n != 1 {
+			siw.ErrorHandlerFunc(w, r, &TooManyValuesForParamError{ParamName: "X-Signature-256", Count: n})
+			return
+		}
+
+		err = runtime.BindStyledParameterWithLocation("simple", false, "X-Signature-256", runtime.ParamLocationHeader, valueList[0], &XSignature256)
+		if err != nil {
+			siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "X-Signature-256", Err: err})
+			return
+		}
+
+		params.XSignature256 = &XSignature256
+
+	}
+
+	// ------------- Optional header parameter "X-Signature-Timestamp" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("X-Signature-Timestamp")]; found {
+		var XSignatureTimestamp int64
+		n := len(valueList)
+		if n != 1 {
+			siw.ErrorHandlerFunc(w, r, &TooManyValuesForParamError{ParamName: "X-Signature-Timestamp", Count: n})
+			return
+		}
+
+		err = runtime.BindStyledParameterWithLocation("simple", false, "X-Signature-Timestamp", runtime.ParamLocationHeader, valueList[0], &XSignatureTimestamp)
+		if err != nil {
+			siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "X-Signature-Timestamp", Err: err})
+			return
+		}
+
+		params.XSignatureTimestamp = &XSignatureTimestamp
+
+	}
+
+	// ------------- Optional header parameter "X-Signature-Nonce" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("X-Signature-Nonce")]; found {
+		var XSignatureNonce string
+		n := len(valueList)
+		if n != 1 {
+			siw.ErrorHandlerFunc(w, r, &TooManyValuesForParamError{ParamName: "X-Signature-Nonce", Count: n})
+			return
+		}
+
+		err = runtime.BindStyledParameterWithLocation("simple", false, "X-Signature-Nonce", runtime.ParamLocationHeader, valueList[0], &XSignatureNonce)
+		if err != nil {
+			siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "X-Signature-Nonce", Err: err})
+			return
+		}
+
+		params.XSignatureNonce = &XSignatureNonce
+
+	}
+
+	var handler = func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.TriggerBuild(w, r, namespace, name, params)
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler(w, r.WithContext(ctx))
+}
+
+// TriggerGitHubBuild operation middleware
+func (siw *ServerInterfaceWrapper) TriggerGitHubBuild(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// ------------- Path parameter "namespace" -------------
+	var namespace string
+
+	err = runtime.BindStyledParameter("simple", false, "namespace", chi.URLParam(r, "namespace"), &namespace)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "namespace", Err: err})
+		return
+	}
+
+	// ------------- Path parameter "name" -------------
+	var name string
+
+	err = runtime.BindStyledParameter("simple", false, "name", chi.URLParam(r, "name"), &name)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "name", Err: err})
+		return
+	}
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params TriggerGitHubBuildParams
+
+	headers := r.Header
+
+	// ------------- Optional header parameter "X-Hub-Signature-256" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("X-Hub-Signature-256")]; found {
+		var XHubSignature256 string
+		n := len(valueList)
+		if n != 1 {
+			siw.ErrorHandlerFunc(w, r, &TooManyValuesForParamError{ParamName: "X-Hub-Signature-256", Count: n})
+			return
+		}
+
+		err = runtime.BindStyledParameterWithLocation("simple", false, "X-Hub-Signature-256", runtime.ParamLocationHeader, valueList[0], &XHubSignature256)
+		if err != nil {
+			siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "X-Hub-Signature-256", Err: err})
+			return
+		}
+
+		params.XHubSignature256 = &XHubSignature256
+
+	}
+
+	// ------------- Required header parameter "X-GitHub-Event" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("X-GitHub-Event")]; found {
+		var XGitHubEvent string
+		n := len(valueList)
+		if n != 1 {
+			siw.ErrorHandlerFunc(w, r, &TooManyValuesForParamError{ParamName: "X-GitHub-Event", Count: n})
+			return
+		}
+
+		err = runtime.BindStyledParameterWithLocation("simple", false, "X-GitHub-Event", runtime.ParamLocationHeader, valueList[0], &XGitHubEvent)
+		if err != nil {
+			siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "X-GitHub-Event", Err: err})
+			return
+		}
+
+		params.XGitHubEvent = XGitHubEvent
 
 	} else {
-		err := fmt.Errorf("Header parameter secret is required, but not found")
-		siw.ErrorHandlerFunc(w, r, &RequiredHeaderError{ParamName: "secret", Err: err})
+		err := fmt.Errorf("Header parameter X-GitHub-Event is required, but not found")
+		siw.ErrorHandlerFunc(w, r, &RequiredHeaderError{ParamName: "X-GitHub-Event", Err: err})
 		return
 	}
 
 	var handler = func(w http.ResponseWriter, r *http.Request) {
-		siw.Handler.TriggerBuild(w, r, namespace, name, params)
+		siw.Handler.TriggerGitHubBuild(w, r, namespace, name, params)
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler(w, r.WithContext(ctx))
+}
+
+// TriggerGitLabBuild operation middleware
+func (siw *ServerInterfaceWrapper) TriggerGitLabBuild(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// ------------- Path parameter "namespace" -------------
+	var namespace string
+
+	err = runtime.BindStyledParameter("simple", false, "namespace", chi.URLParam(r, "namespace"), &namespace)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "namespace", Err: err})
+		return
+	}
+
+	// ------------- Path parameter "name" -------------
+	var name string
+
+	err = runtime.BindStyledParameter("simple", false, "name", chi.URLParam(r, "name"), &name)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "name", Err: err})
+		return
+	}
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params TriggerGitLabBuildParams
+
+	headers := r.Header
+
+	// ------------- Optional header parameter "X-Gitlab-Token" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("X-Gitlab-Token")]; found {
+		var XGitlabToken string
+		n := len(valueList)
+		if n != 1 {
+			siw.ErrorHandlerFunc(w, r, &TooManyValuesForParamError{ParamName: "X-Gitlab-Token", Count: n})
+			return
+		}
+
+		err = runtime.BindStyledParameterWithLocation("simple", false, "X-Gitlab-Token", runtime.ParamLocationHeader, valueList[0], &XGitlabToken)
+		if err != nil {
+			siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "X-Gitlab-Token", Err: err})
+			return
+		}
+
+		params.XGitlabToken = &XGitlabToken
+
+	}
+
+	// ------------- Required header parameter "X-Gitlab-Event" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("X-Gitlab-Event")]; found {
+		var XGitlabEvent string
+		n := len(valueList)
+		if n != 1 {
+			siw.ErrorHandlerFunc(w, r, &TooManyValuesForParamError{ParamName: "X-Gitlab-Event", Count: n})
+			return
+		}
+
+		err = runtime.BindStyledParameterWithLocation("simple", false, "X-Gitlab-Event", runtime.ParamLocationHeader, valueList[0], &XGitlabEvent)
+		if err != nil {
+			siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "X-Gitlab-Event", Err: err})
+			return
+		}
+
+		params.XGitlabEvent = XGitlabEvent
+
+	} else {
+		err := fmt.Errorf("Header parameter X-Gitlab-Event is required, but not found")
+		siw.ErrorHandlerFunc(w, r, &RequiredHeaderError{ParamName: "X-Gitlab-Event", Err: err})
+		return
+	}
+
+	var handler = func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.TriggerGitLabBuild(w, r, namespace, name, params)
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler(w, r.WithContext(ctx))
+}
+
+// WatchOSBuilds operation middleware
+func (siw *ServerInterfaceWrapper) WatchOSBuilds(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// ------------- Path parameter "namespace" -------------
+	var namespace string
+
+	err = runtime.BindStyledParameter("simple", false, "namespace", chi.URLParam(r, "namespace"), &namespace)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "namespace", Err: err})
+		return
+	}
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params WatchOSBuildsParams
+
+	// ------------- Optional query parameter "osbuild" -------------
+	if paramValue := r.URL.Query().Get("osbuild"); paramValue != "" {
+
+	}
+
+	err = runtime.BindQueryParameter("form", true, false, "osbuild", r.URL.Query(), &params.Osbuild)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "osbuild", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "osbuildconfig" -------------
+	if paramValue := r.URL.Query().Get("osbuildconfig"); paramValue != "" {
+
+	}
+
+	err = runtime.BindQueryParameter("form", true, false, "osbuildconfig", r.URL.Query(), &params.Osbuildconfig)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "osbuildconfig", Err: err})
+		return
+	}
+
+	headers := r.Header
+
+	// ------------- Optional header parameter "secret" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("secret")]; found {
+		var Secret string
+		n := len(valueList)
+		if n != 1 {
+			siw.ErrorHandlerFunc(w, r, &TooManyValuesForParamError{ParamName: "secret", Count: n})
+			return
+		}
+
+		err = runtime.BindStyledParameterWithLocation("simple", false, "secret", runtime.ParamLocationHeader, valueList[0], &Secret)
+		if err != nil {
+			siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "secret", Err: err})
+			return
+		}
+
+		params.Secret = &Secret
+
+	}
+
+	// ------------- Optional header parameter "Authorization" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("Authorization")]; found {
+		var Authorization string
+		n := len(valueList)
+		if n != 1 {
+			siw.ErrorHandlerFunc(w, r, &TooManyValuesForParamError{ParamName: "Authorization", Count: n})
+			return
+		}
+
+		err = runtime.BindStyledParameterWithLocation("simple", false, "Authorization", runtime.ParamLocationHeader, valueList[0], &Authorization)
+		if err != nil {
+			siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "Authorization", Err: err})
+			return
+		}
+
+		params.Authorization = &Authorization
+
+	}
+
+	var handler = func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.WatchOSBuilds(w, r, namespace, params)
 	}
 
 	for _, middleware := range siw.HandlerMiddlewares {
@@ -203,9 +867,30 @@ func HandlerWithOptions(si ServerInterface, options ChiServerOptions) http.Handl
 		ErrorHandlerFunc:   options.ErrorHandlerFunc,
 	}
 
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/api/osbuild/v1/namespaces/{namespace}/osbuild", wrapper.ListOSBuilds)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/api/osbuild/v1/namespaces/{namespace}/osbuild/{name}", wrapper.GetOSBuild)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/api/osbuild/v1/namespaces/{namespace}/osbuildconfig", wrapper.ListOSBuildConfigs)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/api/osbuild/v1/namespaces/{namespace}/osbuildconfig/{name}", wrapper.GetOSBuildConfig)
+	})
 	r.Group(func(r chi.Router) {
 		r.Post(options.BaseURL+"/api/osbuild/v1/namespaces/{namespace}/osbuildconfig/{name}/webhooks", wrapper.TriggerBuild)
 	})
+	r.Group(func(r chi.Router) {
+		r.Post(options.BaseURL+"/api/osbuild/v1/namespaces/{namespace}/osbuildconfig/{name}/webhooks/github", wrapper.TriggerGitHubBuild)
+	})
+	r.Group(func(r chi.Router) {
+		r.Post(options.BaseURL+"/api/osbuild/v1/namespaces/{namespace}/osbuildconfig/{name}/webhooks/gitlab", wrapper.TriggerGitLabBuild)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/api/osbuild/v1/namespaces/{namespace}/watch", wrapper.WatchOSBuilds)
+	})
 
 	return r
 }
@@ -3,16 +3,199 @@
 // Code generated by github.com/deepmap/oapi-codegen version v1.11.0 DO NOT EDIT.
 package restapi
 
+import (
+	"time"
+)
+
+// Defines values for OsbuildPhase.
+const (
+	Building OsbuildPhase = "Building"
+	Failed   OsbuildPhase = "Failed"
+	Ready    OsbuildPhase = "Ready"
+)
+
+// Condition defines model for condition.
+type Condition struct {
+	LastTransitionTime *time.Time `json:"lastTransitionTime,omitempty"`
+	Message            *string    `json:"message,omitempty"`
+	Status             string     `json:"status"`
+	Type               string     `json:"type"`
+}
+
 // MessageResponse defines model for message-response.
 type MessageResponse struct {
-	// Content
+	// Content, for the trigger responses an object whose osbuild property is the name of the OSBuild that will be built, or is already being built
 	Content   *interface{} `json:"content,omitempty"`
 	Directive *string      `json:"directive,omitempty"`
 	MessageId *string      `json:"message_id,omitempty"`
 }
 
+// Osbuild defines model for osbuild.
+type Osbuild struct {
+	// URL of the image of the OSBuild
+	AccessUrl *string `json:"accessUrl,omitempty"`
+
+	// URL of the ISO built by composer, before it is packaged with the kickstart
+	ComposerIso       *string      `json:"composerIso,omitempty"`
+	Conditions        *[]Condition `json:"conditions,omitempty"`
+	CreationTimestamp *time.Time   `json:"creationTimestamp,omitempty"`
+	Name              string       `json:"name"`
+	Namespace         string       `json:"namespace"`
+
+	// Name of the OSBuildConfig of the OSBuild
+	OsbuildConfig *string `json:"osbuildConfig,omitempty"`
+
+	// ID of the OSTree commit built by the OSBuild
+	OstreeCommit *string `json:"ostreeCommit,omitempty"`
+
+	// Building until the OSBuild is Ready or Failed, Failed including the OSBuilds that failed the package validation or found vulnerabilities
+	Phase           OsbuildPhase `json:"phase"`
+	TargetImageType *string      `json:"targetImageType,omitempty"`
+
+	// ID of the webhook call that triggered the OSBuild
+	TriggerId   *string `json:"triggerId,omitempty"`
+	TriggeredBy *string `json:"triggeredBy,omitempty"`
+}
+
+// Building until the OSBuild is Ready or Failed, Failed including the OSBuilds that failed the package validation or found vulnerabilities
+type OsbuildPhase string
+
+// Osbuildconfig defines model for osbuildconfig.
+type Osbuildconfig struct {
+	Conditions *[]Condition `json:"conditions,omitempty"`
+
+	// Name of the last OSBuild of the OSBuildConfig
+	LastOSBuild *string `json:"lastOSBuild,omitempty"`
+
+	// Number of the last OSBuild of the OSBuildConfig
+	LastVersion     *int    `json:"lastVersion,omitempty"`
+	Name            string  `json:"name"`
+	Namespace       string  `json:"namespace"`
+	TargetImageType *string `json:"targetImageType,omitempty"`
+}
+
+// ParameterValue defines model for parameter-value.
+type ParameterValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// TriggerBuildRequest defines model for trigger-build-request.
+type TriggerBuildRequest struct {
+	// Packages to install in addition to the packages of the OSBuildConfig
+	Packages *[]string `json:"packages,omitempty"`
+
+	// Values of the parameters of the OSBuildConfigTemplate of the OSBuildConfig, overriding the ones of the OSBuildConfig
+	Parameters *[]ParameterValue `json:"parameters,omitempty"`
+}
+
+// ListOSBuildsParams defines parameters for ListOSBuilds.
+type ListOSBuildsParams struct {
+	// Lists the OSBuild CRs of this OSBuildConfig only
+	Osbuildconfig *string `form:"osbuildconfig,omitempty" json:"osbuildconfig,omitempty"`
+
+	// The secret value of one of the webhook, github or gitlab triggers of the OSBuildConfig, i.e. the value of the key named WebHookSecretKey of the secret that the trigger references to. Required unless the request is authorized by a bearer token
+	Secret *string `json:"secret,omitempty"`
+
+	// A Kubernetes bearer token, e.g. of a service account, whose user is allowed to list the osbuilds of the namespace
+	Authorization *string `json:"Authorization,omitempty"`
+}
+
+// GetOSBuildParams defines parameters for GetOSBuild.
+type GetOSBuildParams struct {
+	// Number of seconds to wait for the OSBuild to be Ready or Failed before responding, capped by the MAX_WAIT_TIMEOUT of the server. The OSBuild is returned as is when the wait times out
+	Wait *int `form:"wait,omitempty" json:"wait,omitempty"`
+
+	// The secret value of one of the webhook, github or gitlab triggers of the OSBuildConfig, i.e. the value of the key named WebHookSecretKey of the secret that the trigger references to. Required unless the request is authorized by a bearer token
+	Secret *string `json:"secret,omitempty"`
+
+	// A Kubernetes bearer token, e.g. of a service account, whose user is allowed to get the OSBuild
+	Authorization *string `json:"Authorization,omitempty"`
+}
+
+// ListOSBuildConfigsParams defines parameters for ListOSBuildConfigs.
+type ListOSBuildConfigsParams struct {
+	// The secret value of one of the webhook, github or gitlab triggers of the OSBuildConfig, i.e. the value of the key named WebHookSecretKey of the secret that the trigger references to. Required unless the request is authorized by a bearer token
+	Secret *string `json:"secret,omitempty"`
+
+	// A Kubernetes bearer token, e.g. of a service account, whose user is allowed to list the osbuildconfigs of the namespace
+	Authorization *string `json:"Authorization,omitempty"`
+}
+
+// GetOSBuildConfigParams defines parameters for GetOSBuildConfig.
+type GetOSBuildConfigParams struct {
+	// The secret value of one of the webhook, github or gitlab triggers of the OSBuildConfig, i.e. the value of the key named WebHookSecretKey of the secret that the trigger references to. Required unless the request is authorized by a bearer token
+	Secret *string `json:"secret,omitempty"`
+
+	// A Kubernetes bearer token, e.g. of a service account, whose user is allowed to get the OSBuildConfig
+	Authorization *string `json:"Authorization,omitempty"`
+}
+
+// TriggerBuildJSONBody defines parameters for TriggerBuild.
+type TriggerBuildJSONBody = TriggerBuildRequest
+
 // TriggerBuildParams defines parameters for TriggerBuild.
 type TriggerBuildParams struct {
-	// The secret value of the secret with a key named WebHookSecretKey that the webhook definition reference to. The secret ensures the uniqueness of the URL, preventing others from triggering the build
-	Secret string `json:"secret"`
+	// The secret value of the secret with a key named WebHookSecretKey that the webhook definition reference to. The secret ensures the uniqueness of the URL, preventing others from triggering the build. Required unless the request is signed or authorized by a bearer token
+	Secret *string `json:"secret,omitempty"`
+
+	// A Kubernetes bearer token, e.g. of a service account, whose user is allowed to create the osbuildconfigs/instantiate subresource of the OSBuildConfig. The webhook trigger and its secret are not needed then
+	Authorization *string `json:"Authorization,omitempty"`
+
+	// Instead of the secret header, the HMAC-SHA256 hex digest, keyed by the secret value, of the timestamp, the nonce and the body joined by dots, prefixed with sha256=. Signed requests cannot be replayed
+	XSignature256 *string `json:"X-Signature-256,omitempty"`
+
+	// The time of the signed request, in seconds since the Unix epoch. It must be within the replay window of the server time
+	XSignatureTimestamp *int64 `json:"X-Signature-Timestamp,omitempty"`
+
+	// A unique value of the signed request, e.g. a UUID, that the server rejects when used again within the replay window
+	XSignatureNonce *string `json:"X-Signature-Nonce,omitempty"`
+}
+
+// TriggerGitHubBuildJSONBody defines parameters for TriggerGitHubBuild.
+type TriggerGitHubBuildJSONBody = map[string]interface{}
+
+// TriggerGitHubBuildParams defines parameters for TriggerGitHubBuild.
+type TriggerGitHubBuildParams struct {
+	// The HMAC-SHA256 hex digest of the payload, prefixed with sha256=
+	XHubSignature256 *string `json:"X-Hub-Signature-256,omitempty"`
+
+	// The type of the GitHub event, only push and ping events are handled
+	XGitHubEvent string `json:"X-GitHub-Event"`
+}
+
+// TriggerGitLabBuildJSONBody defines parameters for TriggerGitLabBuild.
+type TriggerGitLabBuildJSONBody = map[string]interface{}
+
+// TriggerGitLabBuildParams defines parameters for TriggerGitLabBuild.
+type TriggerGitLabBuildParams struct {
+	// The secret token of the GitLab webhook, the value of the key named WebHookSecretKey of the secret that the gitlab trigger references to
+	XGitlabToken *string `json:"X-Gitlab-Token,omitempty"`
+
+	// The type of the GitLab event, only Push Hook and Tag Push Hook events are handled
+	XGitlabEvent string `json:"X-Gitlab-Event"`
+}
+
+// WatchOSBuildsParams defines parameters for WatchOSBuilds.
+type WatchOSBuildsParams struct {
+	// Watches this OSBuild. Either osbuild or osbuildconfig is required
+	Osbuild *string `form:"osbuild,omitempty" json:"osbuild,omitempty"`
+
+	// Watches the OSBuilds of this OSBuildConfig, e.g. to follow a build just triggered whose OSBuild may not be created yet. Either osbuild or osbuildconfig is required
+	Osbuildconfig *string `form:"osbuildconfig,omitempty" json:"osbuildconfig,omitempty"`
+
+	// The secret value of one of the webhook, github or gitlab triggers of the OSBuildConfig, i.e. the value of the key named WebHookSecretKey of the secret that the trigger references to. Required unless the request is authorized by a bearer token
+	Secret *string `json:"secret,omitempty"`
+
+	// A Kubernetes bearer token, e.g. of a service account, whose user is allowed to watch the OSBuild, or the osbuilds of the namespace for an OSBuildConfig
+	Authorization *string `json:"Authorization,omitempty"`
 }
+
+// TriggerBuildJSONRequestBody defines body for TriggerBuild for application/json ContentType.
+type TriggerBuildJSONRequestBody = TriggerBuildJSONBody
+
+// TriggerGitHubBuildJSONRequestBody defines body for TriggerGitHubBuild for application/json ContentType.
+type TriggerGitHubBuildJSONRequestBody = TriggerGitHubBuildJSONBody
+
+// TriggerGitLabBuildJSONRequestBody defines body for TriggerGitLabBuild for application/json ContentType.
+type TriggerGitLabBuildJSONRequestBody = TriggerGitLabBuildJSONBody